@@ -81,6 +81,33 @@ type ClusterStatus struct {
 	State         *ClusterState `json:"state,omitempty"`
 	Reason        string        `json:"reason,omitempty"`
 	LastHeartbeat *metav1.Time  `json:"lastHeartbeat,omitempty"`
+
+	// WardenImage the image of warden rolled out and healthy in cluster
+	// +optional
+	WardenImage string `json:"wardenImage,omitempty"`
+
+	// WardenRollout the rolling of warden in progress
+	// +optional
+	WardenRollout *WardenRollout `json:"wardenRollout,omitempty"`
+
+	// FailedWardenImage the image of warden failed health check in cluster,
+	// it is not rolled to any cluster until target image of warden changed
+	// +optional
+	FailedWardenImage string `json:"failedWardenImage,omitempty"`
+}
+
+// WardenRollout describes the rolling of warden image in cluster
+type WardenRollout struct {
+	// Image the image of warden rolling to
+	Image string `json:"image"`
+
+	// PreviousImage the image of warden before rolled, warden is rolled back
+	// to it when not healthy in time
+	// +optional
+	PreviousImage string `json:"previousImage,omitempty"`
+
+	// StartTime the time rolling started
+	StartTime metav1.Time `json:"startTime"`
 }
 
 //+kubebuilder:object:root=true
@@ -116,6 +116,11 @@ func (in *ClusterStatus) DeepCopyInto(out *ClusterStatus) {
 		in, out := &in.LastHeartbeat, &out.LastHeartbeat
 		*out = (*in).DeepCopy()
 	}
+	if in.WardenRollout != nil {
+		in, out := &in.WardenRollout, &out.WardenRollout
+		*out = new(WardenRollout)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterStatus.
@@ -127,3 +132,19 @@ func (in *ClusterStatus) DeepCopy() *ClusterStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WardenRollout) DeepCopyInto(out *WardenRollout) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WardenRollout.
+func (in *WardenRollout) DeepCopy() *WardenRollout {
+	if in == nil {
+		return nil
+	}
+	out := new(WardenRollout)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -24,6 +24,97 @@ import (
 type ExternalResourceSpec struct {
 	// Namespaced the scope of resource
 	Namespaced bool `json:"namespaced,omitempty"`
+
+	// Display describes how to show the custom resource in list and detail
+	// view, the resource is served by generic extend api only when it is set
+	// +optional
+	Display *DisplaySpec `json:"display,omitempty"`
+}
+
+// DisplaySpec declarative display metadata of custom resource, the plural
+// resource name is the name of ExternalResource
+type DisplaySpec struct {
+	// Group api group of resource, empty means core group
+	// +optional
+	Group string `json:"group,omitempty"`
+
+	// Version api version of resource
+	Version string `json:"version"`
+
+	// Kind kind of resource
+	Kind string `json:"kind"`
+
+	// PrinterColumns the columns shown for each object
+	// +optional
+	PrinterColumns []PrinterColumn `json:"printerColumns,omitempty"`
+
+	// HealthRules map status of object to health, the first matched rule wins,
+	// health is Unknown when no rule matched
+	// +optional
+	HealthRules []HealthRule `json:"healthRules,omitempty"`
+
+	// RelatedLinks the objects related to resource shown in detail view
+	// +optional
+	RelatedLinks []RelatedLink `json:"relatedLinks,omitempty"`
+}
+
+// PrinterColumn a column of object
+type PrinterColumn struct {
+	// Name header of column
+	Name string `json:"name"`
+
+	// JSONPath simple JSONPath evaluated against object, such as .status.phase
+	JSONPath string `json:"jsonPath"`
+}
+
+// HealthRule map value of JSONPath to health
+type HealthRule struct {
+	// JSONPath simple JSONPath evaluated against object,
+	// such as .status.conditions[?(@.type=="Ready")].status
+	JSONPath string `json:"jsonPath"`
+
+	// Values the rule matches when any value of JSONPath is one of values,
+	// empty means the rule matches when JSONPath has any value
+	// +optional
+	Values []string `json:"values,omitempty"`
+
+	// Health health of object when rule matches
+	// +kubebuilder:validation:Enum=Healthy;Progressing;Degraded;Suspended;Unknown
+	Health string `json:"health"`
+
+	// MessageJSONPath JSONPath of message shown with health
+	// +optional
+	MessageJSONPath string `json:"messageJSONPath,omitempty"`
+}
+
+// RelatedLink objects related to resource, they are looked up in the
+// namespace of object
+type RelatedLink struct {
+	// Name name of link
+	Name string `json:"name"`
+
+	// Group api group of related objects, empty means core group
+	// +optional
+	Group string `json:"group,omitempty"`
+
+	// Version api version of related objects
+	Version string `json:"version"`
+
+	// Kind kind of related objects
+	Kind string `json:"kind"`
+
+	// Resource plural resource name of related objects used to check
+	// permission, guessed from kind when empty
+	// +optional
+	Resource string `json:"resource,omitempty"`
+
+	// NameJSONPath JSONPath of names of related objects, such as .spec.secretName
+	// +optional
+	NameJSONPath string `json:"nameJSONPath,omitempty"`
+
+	// Owned the related objects are those owned by object, NameJSONPath is ignored when set
+	// +optional
+	Owned bool `json:"owned,omitempty"`
 }
 
 //+kubebuilder:object:root=true
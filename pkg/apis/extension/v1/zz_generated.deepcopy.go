@@ -25,12 +25,44 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DisplaySpec) DeepCopyInto(out *DisplaySpec) {
+	*out = *in
+	if in.PrinterColumns != nil {
+		in, out := &in.PrinterColumns, &out.PrinterColumns
+		*out = make([]PrinterColumn, len(*in))
+		copy(*out, *in)
+	}
+	if in.HealthRules != nil {
+		in, out := &in.HealthRules, &out.HealthRules
+		*out = make([]HealthRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RelatedLinks != nil {
+		in, out := &in.RelatedLinks, &out.RelatedLinks
+		*out = make([]RelatedLink, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DisplaySpec.
+func (in *DisplaySpec) DeepCopy() *DisplaySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DisplaySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExternalResource) DeepCopyInto(out *ExternalResource) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalResource.
@@ -86,6 +118,11 @@ func (in *ExternalResourceList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExternalResourceSpec) DeepCopyInto(out *ExternalResourceSpec) {
 	*out = *in
+	if in.Display != nil {
+		in, out := &in.Display, &out.Display
+		*out = new(DisplaySpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalResourceSpec.
@@ -97,3 +134,53 @@ func (in *ExternalResourceSpec) DeepCopy() *ExternalResourceSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HealthRule) DeepCopyInto(out *HealthRule) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthRule.
+func (in *HealthRule) DeepCopy() *HealthRule {
+	if in == nil {
+		return nil
+	}
+	out := new(HealthRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrinterColumn) DeepCopyInto(out *PrinterColumn) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrinterColumn.
+func (in *PrinterColumn) DeepCopy() *PrinterColumn {
+	if in == nil {
+		return nil
+	}
+	out := new(PrinterColumn)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RelatedLink) DeepCopyInto(out *RelatedLink) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RelatedLink.
+func (in *RelatedLink) DeepCopy() *RelatedLink {
+	if in == nil {
+		return nil
+	}
+	out := new(RelatedLink)
+	in.DeepCopyInto(out)
+	return out
+}
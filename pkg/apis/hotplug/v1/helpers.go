@@ -0,0 +1,37 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// Revision returns the hash of components of spec, rollout policy is not
+// included so that changing policy does not start a new rollout
+func (s *HotplugSpec) Revision() string {
+	b, _ := json.Marshal(s.Component)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:8])
+}
+
+// AutoRollbackDisabled returns true if release should be kept as it is when
+// upgrade failed or component not ready after upgrade
+func (c *ComponentConfig) AutoRollbackDisabled() bool {
+	return c.DisableAutoRollback != nil && *c.DisableAutoRollback
+}
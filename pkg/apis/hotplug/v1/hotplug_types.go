@@ -18,6 +18,7 @@ package v1
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 type ComponentConfig struct {
@@ -26,6 +27,141 @@ type ComponentConfig struct {
 	Status    string `json:"status,omitempty"`
 	PkgName   string `json:"pkgName,omitempty"`
 	Env       string `json:"env,omitempty"`
+
+	// ValuesFrom references values in secrets, which are resolved only by
+	// warden at install time and merged over Env in order
+	// +optional
+	ValuesFrom []ValuesReference `json:"valuesFrom,omitempty"`
+
+	// Chart refers to chart in helm repository, oci registry, configmap or
+	// secret, chart named PkgName in local helmchartpkg directory is used if not set
+	// +optional
+	Chart *ChartSource `json:"chart,omitempty"`
+
+	// DependsOn names of components that must be ready before this
+	// component installed, and uninstalled after this component
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty"`
+
+	// Priority orders components having no dependency between each other,
+	// the smaller one installs first
+	// +optional
+	Priority int `json:"priority,omitempty"`
+
+	// Readiness describes how to judge the component is ready, workloads
+	// of release are checked with default timeout if not set
+	// +optional
+	Readiness *ReadinessProbe `json:"readiness,omitempty"`
+
+	// DisableAutoRollback keeps release as it is when upgrade failed or
+	// component not ready after upgrade, instead of rolling back to the
+	// last good revision, set false in cluster hotplug to enable rollback
+	// disabled in common hotplug
+	// +optional
+	DisableAutoRollback *bool `json:"disableAutoRollback,omitempty"`
+}
+
+// ValuesReference refers to key of secret holding values of component
+type ValuesReference struct {
+	// Namespace of secret, kubeworkz namespace if not set, only kubeworkz
+	// namespace and namespace of component are allowed
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name the name of secret
+	Name string `json:"name"`
+
+	// Key the key of value in secret
+	Key string `json:"key"`
+
+	// TargetPath the dot separated path in values where the value set to,
+	// such as database.password, value is parsed as yaml and merged into
+	// values if empty
+	// +optional
+	TargetPath string `json:"targetPath,omitempty"`
+
+	// Optional ignores the reference if secret or key not found
+	// +optional
+	Optional bool `json:"optional,omitempty"`
+}
+
+// ChartSource describes where to pull chart of component from, exactly one
+// of RepoURL, ConfigMapRef and SecretRef should be set
+type ChartSource struct {
+	// RepoURL the url of helm http repository, or of oci registry when
+	// prefixed with oci:// in which case Name is appended as repository path
+	// +optional
+	RepoURL string `json:"repoURL,omitempty"`
+
+	// Name the name of chart in repository
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Version the version or semver constraint of chart such as ~1.2.0,
+	// latest version is used if empty
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// Digest pins sha256 digest of chart archive, cached archive is used
+	// without pulling when digest matched
+	// +kubebuilder:validation:Pattern=`^sha256:[a-f0-9]{64}$`
+	// +optional
+	Digest string `json:"digest,omitempty"`
+
+	// CredentialsSecret the name of secret in kubeworkz namespace holding
+	// username and password of repository
+	// +optional
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
+
+	// InsecureSkipTLSVerify skips tls verification of repository
+	// +optional
+	InsecureSkipTLSVerify bool `json:"insecureSkipTLSVerify,omitempty"`
+
+	// ConfigMapRef refers to configmap holding chart archive in binaryData
+	// +optional
+	ConfigMapRef *ChartArchiveReference `json:"configMapRef,omitempty"`
+
+	// SecretRef refers to secret holding chart archive
+	// +optional
+	SecretRef *ChartArchiveReference `json:"secretRef,omitempty"`
+}
+
+// ChartArchiveReference refers to key of configmap or secret holding chart archive
+type ChartArchiveReference struct {
+	// Namespace of object, kubeworkz namespace if not set, only kubeworkz
+	// namespace and namespace of component are allowed
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	Name string `json:"name"`
+
+	// Key of chart archive in object, chart.tgz if not set
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// ReadinessProbe describes workloads to wait for after component installed
+type ReadinessProbe struct {
+	// Disabled treats component as ready once helm release deployed
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+
+	// TimeoutSeconds the max duration to wait for workloads ready since
+	// release last deployed, component is failed when exceeded
+	// +optional
+	TimeoutSeconds int64 `json:"timeoutSeconds,omitempty"`
+
+	// Workloads to be checked, all Deployments, StatefulSets and DaemonSets
+	// labeled with app.kubernetes.io/instance=<release> are checked if empty
+	// +optional
+	Workloads []WorkloadReference `json:"workloads,omitempty"`
+}
+
+// WorkloadReference refers to a workload in namespace of component
+type WorkloadReference struct {
+	// +kubebuilder:validation:Enum=Deployment;StatefulSet;DaemonSet
+	Kind string `json:"kind"`
+	Name string `json:"name"`
 }
 
 type DeployResult struct {
@@ -35,15 +171,167 @@ type DeployResult struct {
 	Message string `json:"message,omitempty"`
 }
 
+// ComponentCondition describes the readiness of a component
+type ComponentCondition struct {
+	// Name the name of component
+	Name string `json:"name"`
+
+	// Status of the condition, one of True, False, Unknown
+	Status metav1.ConditionStatus `json:"status"`
+
+	// Reason is a brief CamelCase reason for the condition's last transition
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Message is a human readable message indicating details about the transition
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// LastTransitionTime last time the condition transitioned from one status to another
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// ReleaseRevision describes a revision of helm release of component
+type ReleaseRevision struct {
+	Revision     int    `json:"revision"`
+	ChartVersion string `json:"chartVersion,omitempty"`
+	AppVersion   string `json:"appVersion,omitempty"`
+	Status       string `json:"status,omitempty"`
+	Description  string `json:"description,omitempty"`
+	// +optional
+	Updated metav1.Time `json:"updated,omitempty"`
+}
+
+// ComponentHistory records recent revisions of helm release of component
+type ComponentHistory struct {
+	// Name the name of component
+	Name string `json:"name"`
+
+	// Revisions recent revisions of release, newest first
+	// +optional
+	Revisions []ReleaseRevision `json:"revisions,omitempty"`
+
+	// ReadyRevision the latest revision observed ready, which is the target
+	// of rollback when upgraded revision never gets ready
+	// +optional
+	ReadyRevision int `json:"readyRevision,omitempty"`
+
+	// RolledBackConfig is the hash of component config whose upgrade was
+	// rolled back, the upgrade is not retried until config changed
+	// +optional
+	RolledBackConfig string `json:"rolledBackConfig,omitempty"`
+}
+
+// RolloutPolicy describes how changes of common hotplug are released to
+// clusters, canary clusters first and then the rest by batches
+type RolloutPolicy struct {
+	// CanaryClusters are released first, one batch before all others
+	// +optional
+	CanaryClusters []string `json:"canaryClusters,omitempty"`
+
+	// BatchLabel the label key of cluster whose values group clusters into
+	// batches ordered by value, clusters without the label are in the last
+	// batch, all clusters are in one batch if empty
+	// +optional
+	BatchLabel string `json:"batchLabel,omitempty"`
+
+	// MaxUnavailable the max number or percentage of clusters of a batch
+	// being updated at the same time, defaults to 1
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// PauseOnFailure stops releasing to more clusters once any released
+	// cluster failed
+	// +optional
+	PauseOnFailure bool `json:"pauseOnFailure,omitempty"`
+
+	// Paused stops releasing to more clusters
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+}
+
 // HotplugSpec defines the desired state of Hotplug
 type HotplugSpec struct {
 	Component []ComponentConfig `json:"component,omitempty"`
+
+	// Rollout releases changes of components cluster by cluster, only
+	// takes effect on common hotplug, all clusters apply at once if not set
+	// +optional
+	Rollout *RolloutPolicy `json:"rollout,omitempty"`
+}
+
+// ClusterRolloutStatus describes progress of rollout in a cluster
+type ClusterRolloutStatus struct {
+	// Name the name of cluster
+	Name string `json:"name"`
+
+	// Batch the batch cluster belongs to, 0 for canary clusters
+	Batch int `json:"batch"`
+
+	// Released is true when revision released to cluster
+	// +optional
+	Released bool `json:"released,omitempty"`
+
+	// Revision the revision of common hotplug applied in cluster
+	// +optional
+	Revision string `json:"revision,omitempty"`
+
+	// Phase the phase of hotplug in cluster
+	// +optional
+	Phase string `json:"phase,omitempty"`
+
+	// Results the results of components in cluster
+	// +optional
+	Results []*DeployResult `json:"results,omitempty"`
+
+	// Message describes why status of cluster unknown
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// RolloutStatus describes progress of releasing common hotplug to clusters
+type RolloutStatus struct {
+	// Revision the revision of components being released
+	Revision string `json:"revision"`
+
+	// Phase one of Progressing, Paused and Completed
+	Phase string `json:"phase"`
+
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Clusters progress of each cluster in order of release
+	// +optional
+	Clusters []ClusterRolloutStatus `json:"clusters,omitempty"`
 }
 
 // HotplugStatus defines the observed state of Hotplug
 type HotplugStatus struct {
 	Phase   string          `json:"phase,omitempty"`
 	Results []*DeployResult `json:"results,omitempty"`
+
+	// Conditions the readiness of each component
+	// +optional
+	Conditions []ComponentCondition `json:"conditions,omitempty"`
+
+	// History the recent revisions of each component
+	// +optional
+	History []ComponentHistory `json:"history,omitempty"`
+
+	// AppliedRevision the revision of common components applied in cluster
+	// +optional
+	AppliedRevision string `json:"appliedRevision,omitempty"`
+
+	// AppliedComponents the common components applied in cluster, which are
+	// kept applying until newer revision released to cluster
+	// +optional
+	AppliedComponents []ComponentConfig `json:"appliedComponents,omitempty"`
+
+	// Rollout the progress of releasing common hotplug to clusters, only
+	// reported on common hotplug of control plane
+	// +optional
+	Rollout *RolloutStatus `json:"rollout,omitempty"`
 }
 
 //+kubebuilder:object:root=true
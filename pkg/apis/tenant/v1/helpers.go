@@ -0,0 +1,154 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SetClusterCondition puts given condition into conditions, the condition
+// of same cluster will be replaced. LastTransitionTime keeps unchanged if
+// status not changed. It returns false if nothing changed.
+func SetClusterCondition(conditions []ClusterCondition, cond ClusterCondition) ([]ClusterCondition, bool) {
+	for i, c := range conditions {
+		if c.Cluster != cond.Cluster {
+			continue
+		}
+		if c.Status == cond.Status {
+			cond.LastTransitionTime = c.LastTransitionTime
+		} else {
+			cond.LastTransitionTime = metav1.Now()
+		}
+		if equality.Semantic.DeepEqual(c, cond) {
+			return conditions, false
+		}
+		conditions[i] = cond
+		return conditions, true
+	}
+
+	cond.LastTransitionTime = metav1.Now()
+	conditions = append(conditions, cond)
+	sort.Slice(conditions, func(i, j int) bool {
+		return conditions[i].Cluster < conditions[j].Cluster
+	})
+
+	return conditions, true
+}
+
+// RemoveClusterCondition removes the condition of given cluster
+func RemoveClusterCondition(conditions []ClusterCondition, cluster string) ([]ClusterCondition, bool) {
+	for i, c := range conditions {
+		if c.Cluster == cluster {
+			return append(conditions[:i], conditions[i+1:]...), true
+		}
+	}
+	return conditions, false
+}
+
+// PruneClusterConditions removes conditions of clusters not in given clusters,
+// so that conditions of removed clusters do not stay in status forever.
+// It returns false if nothing changed.
+func PruneClusterConditions(conditions []ClusterCondition, clusters []string) ([]ClusterCondition, bool) {
+	exists := make(map[string]bool, len(clusters))
+	for _, cluster := range clusters {
+		exists[cluster] = true
+	}
+
+	var stale []string
+	for _, c := range conditions {
+		if !exists[c.Cluster] {
+			stale = append(stale, c.Cluster)
+		}
+	}
+
+	for _, cluster := range stale {
+		conditions, _ = RemoveClusterCondition(conditions, cluster)
+	}
+
+	return conditions, len(stale) > 0
+}
+
+// ComputePhase computes phase by conditions reported from given clusters.
+// Phase is Active only when all clusters reported ready.
+func ComputePhase(conditions []ClusterCondition, clusters []string, deleting bool) Phase {
+	if deleting {
+		return PhaseTerminating
+	}
+
+	reported := make(map[string]metav1.ConditionStatus, len(conditions))
+	for _, c := range conditions {
+		reported[c.Cluster] = c.Status
+		if c.Status == metav1.ConditionFalse {
+			return PhaseFailed
+		}
+	}
+
+	for _, cluster := range clusters {
+		if reported[cluster] != metav1.ConditionTrue {
+			return PhasePending
+		}
+	}
+
+	return PhaseActive
+}
+
+// SummarizeConditions sums namespaces and quota of all clusters,
+// member count should be filled by caller.
+func SummarizeConditions(conditions []ClusterCondition) ResourceSummary {
+	summary := ResourceSummary{}
+	for _, c := range conditions {
+		summary.NamespaceCount += c.NamespaceCount
+		summary.Hard = AddResourceList(summary.Hard, c.Hard)
+		summary.Used = AddResourceList(summary.Used, c.Used)
+	}
+	return summary
+}
+
+// AddResourceList adds resource list b into a
+func AddResourceList(a, b corev1.ResourceList) corev1.ResourceList {
+	if len(b) == 0 {
+		return a
+	}
+	if a == nil {
+		a = corev1.ResourceList{}
+	}
+	for name, quantity := range b {
+		if v, ok := a[name]; ok {
+			v.Add(quantity)
+			a[name] = v
+		} else {
+			a[name] = quantity.DeepCopy()
+		}
+	}
+	return a
+}
+
+// EffectiveNetworkIsolation returns isolation mode of project if set,
+// otherwise inherits from tenant
+func EffectiveNetworkIsolation(tenant *Tenant, project *Project) NetworkIsolation {
+	if project != nil && len(project.Spec.NetworkIsolation) > 0 {
+		return project.Spec.NetworkIsolation
+	}
+	if tenant != nil && len(tenant.Spec.NetworkIsolation) > 0 {
+		return tenant.Spec.NetworkIsolation
+	}
+	return NetworkIsolationNone
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestComputePhase(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions []ClusterCondition
+		clusters   []string
+		deleting   bool
+		want       Phase
+	}{
+		{
+			name:     "deleting",
+			deleting: true,
+			want:     PhaseTerminating,
+		},
+		{
+			name:       "not all clusters reported",
+			conditions: []ClusterCondition{{Cluster: "a", Status: metav1.ConditionTrue}},
+			clusters:   []string{"a", "b"},
+			want:       PhasePending,
+		},
+		{
+			name: "one cluster failed",
+			conditions: []ClusterCondition{
+				{Cluster: "a", Status: metav1.ConditionTrue},
+				{Cluster: "b", Status: metav1.ConditionFalse},
+			},
+			clusters: []string{"a", "b"},
+			want:     PhaseFailed,
+		},
+		{
+			name: "all clusters ready",
+			conditions: []ClusterCondition{
+				{Cluster: "a", Status: metav1.ConditionTrue},
+				{Cluster: "b", Status: metav1.ConditionTrue},
+			},
+			clusters: []string{"a", "b"},
+			want:     PhaseActive,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ComputePhase(tt.conditions, tt.clusters, tt.deleting); got != tt.want {
+				t.Errorf("ComputePhase() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetClusterCondition(t *testing.T) {
+	conditions, changed := SetClusterCondition(nil, ClusterCondition{Cluster: "b", Status: metav1.ConditionTrue})
+	if !changed || len(conditions) != 1 {
+		t.Fatalf("expect condition added, got %v", conditions)
+	}
+
+	conditions, changed = SetClusterCondition(conditions, ClusterCondition{Cluster: "a", Status: metav1.ConditionTrue})
+	if !changed || len(conditions) != 2 || conditions[0].Cluster != "a" {
+		t.Fatalf("expect conditions sorted by cluster, got %v", conditions)
+	}
+
+	_, changed = SetClusterCondition(conditions, ClusterCondition{Cluster: "a", Status: metav1.ConditionTrue})
+	if changed {
+		t.Errorf("expect nothing changed when set same condition")
+	}
+
+	conditions, changed = SetClusterCondition(conditions, ClusterCondition{Cluster: "a", Status: metav1.ConditionFalse})
+	if !changed || conditions[0].Status != metav1.ConditionFalse {
+		t.Errorf("expect condition replaced, got %v", conditions)
+	}
+}
+
+func TestSummarizeConditions(t *testing.T) {
+	conditions := []ClusterCondition{
+		{
+			Cluster:        "a",
+			NamespaceCount: 2,
+			Hard:           corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("2")},
+			Used:           corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("500m")},
+		},
+		{
+			Cluster:        "b",
+			NamespaceCount: 1,
+			Hard:           corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("1")},
+		},
+	}
+
+	summary := SummarizeConditions(conditions)
+	if summary.NamespaceCount != 3 {
+		t.Errorf("expect 3 namespaces, got %v", summary.NamespaceCount)
+	}
+	hard := summary.Hard[corev1.ResourceRequestsCPU]
+	if hard.Cmp(resource.MustParse("3")) != 0 {
+		t.Errorf("expect hard cpu 3, got %v", hard.String())
+	}
+	used := summary.Used[corev1.ResourceRequestsCPU]
+	if used.Cmp(resource.MustParse("500m")) != 0 {
+		t.Errorf("expect used cpu 500m, got %v", used.String())
+	}
+}
+
+func TestPruneClusterConditions(t *testing.T) {
+	conditions := []ClusterCondition{
+		{Cluster: "a", Status: metav1.ConditionTrue},
+		{Cluster: "b", Status: metav1.ConditionFalse},
+		{Cluster: "c", Status: metav1.ConditionTrue},
+	}
+
+	conditions, changed := PruneClusterConditions(conditions, []string{"a", "c"})
+	if !changed || len(conditions) != 2 || conditions[0].Cluster != "a" || conditions[1].Cluster != "c" {
+		t.Fatalf("expect condition of removed cluster pruned, got %v", conditions)
+	}
+	if phase := ComputePhase(conditions, []string{"a", "c"}, false); phase != PhaseActive {
+		t.Errorf("expect phase Active after failed cluster removed, got %v", phase)
+	}
+
+	_, changed = PruneClusterConditions(conditions, []string{"a", "c"})
+	if changed {
+		t.Errorf("expect nothing changed when all clusters exist")
+	}
+}
@@ -33,13 +33,33 @@ type ProjectSpec struct {
 	Namespace string `json:"namespace,omitempty"`
 
 	IngressDomainSuffix []string `json:"ingressDomainSuffix,omitempty"`
+
+	// Template the name of ProjectTemplate used to bootstrap namespaces of project
+	// +optional
+	Template string `json:"template,omitempty"`
+
+	// NetworkIsolation overrides isolation mode of tenant for namespaces of project
+	// +optional
+	NetworkIsolation NetworkIsolation `json:"networkIsolation,omitempty"`
 }
 
 // ProjectStatus defines the observed state of Project
 type ProjectStatus struct {
+	// Phase the lifecycle phase of project
+	// +optional
+	Phase Phase `json:"phase,omitempty"`
+
+	// Conditions the condition of project in each cluster
+	// +optional
+	Conditions []ClusterCondition `json:"conditions,omitempty"`
+
+	// Summary aggregates members, namespaces and quota of project
+	// +optional
+	Summary ResourceSummary `json:"summary,omitempty"`
 }
 
 //+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
 
 // Project is the Schema for the projects API
 // +kubebuilder:resource:categories="kubeworkz",scope="Cluster"
@@ -47,6 +67,7 @@ type ProjectStatus struct {
 // +kubebuilder:printcolumn:name="DisplayName",type=string,JSONPath=`.spec.displayName`
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=`.metadata.creationTimestamp`
 // +kubebuilder:printcolumn:name="Namespace",type="string",JSONPath=".spec.namespace"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
 type Project struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -0,0 +1,112 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ProjectTemplateSpec defines the default policies applied to namespaces
+// of projects created from this template
+type ProjectTemplateSpec struct {
+	// +kubebuilder:validation:MaxLength=100
+	// +optional
+	DisplayName string `json:"displayName,omitempty"`
+
+	// +kubebuilder:validation:MaxLength=200
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// Labels will be added into namespaces
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations will be added into namespaces
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// LimitRanges will be created in namespaces
+	// +optional
+	LimitRanges []LimitRangeTemplate `json:"limitRanges,omitempty"`
+
+	// ResourceQuotas will be created in namespaces as skeletons
+	// +optional
+	ResourceQuotas []ResourceQuotaTemplate `json:"resourceQuotas,omitempty"`
+
+	// NetworkPolicies will be created in namespaces
+	// +optional
+	NetworkPolicies []NetworkPolicyTemplate `json:"networkPolicies,omitempty"`
+
+	// RoleBindings will be created in namespaces for default groups
+	// +optional
+	RoleBindings []RoleBindingTemplate `json:"roleBindings,omitempty"`
+
+	// ImagePullSecrets are names of secrets in kubeworkz system namespace,
+	// they will be copied into namespaces and referenced by default service account
+	// +optional
+	ImagePullSecrets []string `json:"imagePullSecrets,omitempty"`
+}
+
+type LimitRangeTemplate struct {
+	Name string                `json:"name"`
+	Spec corev1.LimitRangeSpec `json:"spec"`
+}
+
+type ResourceQuotaTemplate struct {
+	Name string                   `json:"name"`
+	Spec corev1.ResourceQuotaSpec `json:"spec"`
+}
+
+type NetworkPolicyTemplate struct {
+	Name string                         `json:"name"`
+	Spec networkingv1.NetworkPolicySpec `json:"spec"`
+}
+
+type RoleBindingTemplate struct {
+	Name     string           `json:"name"`
+	Subjects []rbacv1.Subject `json:"subjects,omitempty"`
+	RoleRef  rbacv1.RoleRef   `json:"roleRef"`
+}
+
+//+kubebuilder:object:root=true
+
+// ProjectTemplate is the Schema for the projecttemplates API
+// +kubebuilder:resource:categories="kubeworkz",scope="Cluster"
+// +kubebuilder:printcolumn:name="DisplayName",type=string,JSONPath=`.spec.displayName`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=`.metadata.creationTimestamp`
+type ProjectTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ProjectTemplateSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ProjectTemplateList contains a list of ProjectTemplate
+type ProjectTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ProjectTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ProjectTemplate{}, &ProjectTemplateList{})
+}
@@ -17,9 +17,101 @@ limitations under the License.
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// Phase is the lifecycle phase of tenant or project
+type Phase string
+
+const (
+	// PhasePending means not all clusters have reported the resources
+	// of tenant or project are ready
+	PhasePending Phase = "Pending"
+
+	// PhaseActive means namespaces, hnc anchors, quotas and bindings
+	// were created in all clusters
+	PhaseActive Phase = "Active"
+
+	// PhaseTerminating means tenant or project is under deleting
+	PhaseTerminating Phase = "Terminating"
+
+	// PhaseFailed means at least one cluster failed to create resources
+	PhaseFailed Phase = "Failed"
+
+	// PhaseSuspended means workloads were scaled to zero and new writes
+	// into namespaces are blocked
+	PhaseSuspended Phase = "Suspended"
+)
+
+// NetworkIsolation is the mode of network isolation between namespaces
+// +kubebuilder:validation:Enum=none;tenant-isolated;project-isolated
+type NetworkIsolation string
+
+const (
+	// NetworkIsolationNone means no network policy will be created
+	NetworkIsolationNone NetworkIsolation = "none"
+
+	// NetworkIsolationTenant only allows traffic from namespaces of same tenant
+	NetworkIsolationTenant NetworkIsolation = "tenant-isolated"
+
+	// NetworkIsolationProject only allows traffic from namespaces of same project
+	NetworkIsolationProject NetworkIsolation = "project-isolated"
+)
+
+// ClusterCondition describes the state of tenant or project in a cluster,
+// reported by warden of that cluster
+type ClusterCondition struct {
+	// Cluster the name of cluster where condition reported from
+	Cluster string `json:"cluster"`
+
+	// Status of the condition, one of True, False, Unknown
+	Status metav1.ConditionStatus `json:"status"`
+
+	// Reason is a brief CamelCase reason for the condition's last transition
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Message is a human readable message indicating details about the transition
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// LastTransitionTime last time the condition transitioned from one status to another
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// NamespaceCount the count of hnc namespaces in cluster
+	// +optional
+	NamespaceCount int `json:"namespaceCount,omitempty"`
+
+	// Hard the sum of hard limits of kube resource quota in cluster
+	// +optional
+	Hard corev1.ResourceList `json:"hard,omitempty"`
+
+	// Used the sum of used of kube resource quota in cluster
+	// +optional
+	Used corev1.ResourceList `json:"used,omitempty"`
+}
+
+// ResourceSummary aggregates resource of tenant or project over all clusters
+type ResourceSummary struct {
+	// MemberCount the count of users belong to
+	// +optional
+	MemberCount int `json:"memberCount,omitempty"`
+
+	// NamespaceCount the count of hnc namespaces over all clusters
+	// +optional
+	NamespaceCount int `json:"namespaceCount,omitempty"`
+
+	// Hard the sum of hard limits of kube resource quota over all clusters
+	// +optional
+	Hard corev1.ResourceList `json:"hard,omitempty"`
+
+	// Used the sum of used of kube resource quota over all clusters
+	// +optional
+	Used corev1.ResourceList `json:"used,omitempty"`
+}
+
 // TenantSpec defines the desired state of Tenant
 type TenantSpec struct {
 	// +kubebuilder:validation:MaxLength=100
@@ -31,19 +123,61 @@ type TenantSpec struct {
 	Description string `json:"description,omitempty"`
 
 	Namespace string `json:"namespace,omitempty"`
+
+	// Suspended scales workloads of tenant to zero and blocks new writes
+	// into namespaces of tenant
+	// +optional
+	Suspended bool `json:"suspended,omitempty"`
+
+	// DeletionGracePeriodSeconds is the duration tenant can be restored
+	// after deletion requested, default value is set by env
+	// +optional
+	DeletionGracePeriodSeconds *int64 `json:"deletionGracePeriodSeconds,omitempty"`
+
+	// NetworkIsolation the default isolation mode of namespaces of tenant,
+	// none if not set
+	// +optional
+	NetworkIsolation NetworkIsolation `json:"networkIsolation,omitempty"`
+
+	// AllowedTenants are tenants whose namespaces are allowed to access
+	// namespaces of this tenant when isolated
+	// +optional
+	AllowedTenants []string `json:"allowedTenants,omitempty"`
 }
 
 // TenantStatus defines the observed state of Tenant
 type TenantStatus struct {
+	// Phase the lifecycle phase of tenant
+	// +optional
+	Phase Phase `json:"phase,omitempty"`
+
+	// Conditions the condition of tenant in each cluster
+	// +optional
+	Conditions []ClusterCondition `json:"conditions,omitempty"`
+
+	// ProjectCount the count of projects under tenant
+	// +optional
+	ProjectCount int `json:"projectCount,omitempty"`
+
+	// Summary aggregates members, namespaces and quota of tenant
+	// +optional
+	Summary ResourceSummary `json:"summary,omitempty"`
+
+	// DeletionDeadline is the time tenant will be deleted, it is
+	// set when deletion requested and cleared when tenant restored
+	// +optional
+	DeletionDeadline *metav1.Time `json:"deletionDeadline,omitempty"`
 }
 
 //+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
 
 // Tenant is the Schema for the tenants API
 // +kubebuilder:resource:categories="kubeworkz",scope="Cluster"
 // +kubebuilder:printcolumn:name="DisplayName",type=string,JSONPath=`.spec.displayName`
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:printcolumn:name="Namespace",type="string",JSONPath=".spec.namespace"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
 type Tenant struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -0,0 +1,156 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type ReviewDecision string
+
+const (
+	DecisionPending  ReviewDecision = "Pending"
+	DecisionApproved ReviewDecision = "Approved"
+	DecisionRevoked  ReviewDecision = "Revoked"
+)
+
+const (
+	AccessReviewInProgress = "InProgress"
+	AccessReviewCompleted  = "Completed"
+)
+
+// AccessReviewSpec defines the desired state of AccessReview
+type AccessReviewSpec struct {
+	// Tenant the tenant whose scope bindings are reviewed, bindings of
+	// tenant and all projects of tenant are reviewed if Project not set
+	Tenant string `json:"tenant"`
+
+	// Project only reviews scope bindings of given project of tenant
+	// +optional
+	Project string `json:"project,omitempty"`
+
+	// Reviewers the users who are able to approve or revoke bindings,
+	// admins of tenant are reviewers if empty
+	// +optional
+	Reviewers []string `json:"reviewers,omitempty"`
+
+	// Duration the review window since campaign created, defaults to 7 days
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+
+	// AutoRevoke revokes bindings still pending review when deadline reached
+	// +optional
+	AutoRevoke bool `json:"autoRevoke,omitempty"`
+
+	// RecurEvery creates next campaign with the same spec when the period
+	// passed since campaign created, campaign is one-off if not set
+	// +optional
+	RecurEvery *metav1.Duration `json:"recurEvery,omitempty"`
+}
+
+// ReviewItem records decision on a scope binding of user
+type ReviewItem struct {
+	User      string           `json:"user"`
+	ScopeType BindingScopeType `json:"scopeType"`
+	ScopeName string           `json:"scopeName"`
+	Role      string           `json:"role"`
+
+	// Decision one of Pending, Approved and Revoked
+	Decision ReviewDecision `json:"decision"`
+
+	// Reviewer the user made the decision
+	// +optional
+	Reviewer string `json:"reviewer,omitempty"`
+
+	// +optional
+	ReviewedAt *metav1.Time `json:"reviewedAt,omitempty"`
+
+	// +optional
+	Comment string `json:"comment,omitempty"`
+
+	// Enforced is true when revoked binding was removed from user
+	// +optional
+	Enforced bool `json:"enforced,omitempty"`
+}
+
+// AccessReviewStatus defines the observed state of AccessReview
+type AccessReviewStatus struct {
+	// Phase one of InProgress and Completed
+	// +optional
+	Phase string `json:"phase,omitempty"`
+
+	// SnapshotTime the time scope bindings were snapshotted
+	// +optional
+	SnapshotTime *metav1.Time `json:"snapshotTime,omitempty"`
+
+	// Deadline the time after which decisions are not accepted
+	// +optional
+	Deadline *metav1.Time `json:"deadline,omitempty"`
+
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Reviewers the resolved reviewers of campaign
+	// +optional
+	Reviewers []string `json:"reviewers,omitempty"`
+
+	// Items the snapshotted scope bindings and decisions on them
+	// +optional
+	Items []ReviewItem `json:"items,omitempty"`
+
+	// +optional
+	Approved int `json:"approved,omitempty"`
+	// +optional
+	Revoked int `json:"revoked,omitempty"`
+	// +optional
+	Pending int `json:"pending,omitempty"`
+
+	// NextReview the name of campaign created after this one
+	// +optional
+	NextReview string `json:"nextReview,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// AccessReview is a campaign to certify scope bindings of tenant or project
+// +kubebuilder:resource:categories="kubeworkz",scope="Cluster"
+// +kubebuilder:printcolumn:name="Tenant",type="string",JSONPath=".spec.tenant"
+// +kubebuilder:printcolumn:name="Project",type="string",JSONPath=".spec.project"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Pending",type="integer",JSONPath=".status.pending"
+// +kubebuilder:printcolumn:name="Deadline",type="string",JSONPath=".status.deadline"
+type AccessReview struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AccessReviewSpec   `json:"spec,omitempty"`
+	Status AccessReviewStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// AccessReviewList contains a list of AccessReview
+type AccessReviewList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AccessReview `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AccessReview{}, &AccessReviewList{})
+}
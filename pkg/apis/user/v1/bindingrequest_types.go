@@ -0,0 +1,96 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	BindingRequestPending  = "Pending"
+	BindingRequestApproved = "Approved"
+	BindingRequestRejected = "Rejected"
+)
+
+// BindingRequestSpec defines the desired state of BindingRequest
+type BindingRequestSpec struct {
+	// User the user requesting the role
+	User string `json:"user"`
+
+	ScopeType BindingScopeType `json:"scopeType"`
+	ScopeName string           `json:"scopeName"`
+	Role      string           `json:"role"`
+
+	// Duration how long the role is granted for once approved
+	Duration metav1.Duration `json:"duration"`
+
+	// Reason why the role is needed, such as the incident being handled
+	Reason string `json:"reason"`
+}
+
+// BindingRequestStatus defines the observed state of BindingRequest
+type BindingRequestStatus struct {
+	// Phase one of Pending, Approved and Rejected
+	// +optional
+	Phase string `json:"phase,omitempty"`
+
+	// Approver the user approved or rejected the request
+	// +optional
+	Approver string `json:"approver,omitempty"`
+
+	// DecidedAt the time request was approved or rejected
+	// +optional
+	DecidedAt *metav1.Time `json:"decidedAt,omitempty"`
+
+	// ExpiresAt the time granted scope binding expires
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// BindingRequest is a request for temporary scope binding of user
+// +kubebuilder:resource:categories="kubeworkz",scope="Cluster"
+// +kubebuilder:printcolumn:name="User",type="string",JSONPath=".spec.user"
+// +kubebuilder:printcolumn:name="Scope",type="string",JSONPath=".spec.scopeName"
+// +kubebuilder:printcolumn:name="Role",type="string",JSONPath=".spec.role"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="ExpiresAt",type="string",JSONPath=".status.expiresAt"
+type BindingRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BindingRequestSpec   `json:"spec,omitempty"`
+	Status BindingRequestStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// BindingRequestList contains a list of BindingRequest
+type BindingRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BindingRequest `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BindingRequest{}, &BindingRequestList{})
+}
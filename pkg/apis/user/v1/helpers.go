@@ -16,6 +16,8 @@ limitations under the License.
 
 package v1
 
+import "time"
+
 func IsPlatformAdmin(user *User) bool {
 	return user.Status.PlatformAdmin
 }
@@ -37,3 +39,55 @@ func BelongsToProject(user *User, project string) bool {
 	}
 	return false
 }
+
+// Summarize counts review items by decision
+func (s *AccessReviewStatus) Summarize() {
+	s.Approved, s.Revoked, s.Pending = 0, 0, 0
+	for _, item := range s.Items {
+		switch item.Decision {
+		case DecisionApproved:
+			s.Approved++
+		case DecisionRevoked:
+			s.Revoked++
+		default:
+			s.Pending++
+		}
+	}
+}
+
+// Matches returns true if item reviews the given scope binding of user
+func (i *ReviewItem) Matches(user string, scopeType BindingScopeType, scopeName, role string) bool {
+	return i.User == user && i.ScopeType == scopeType && i.ScopeName == scopeName && i.Role == role
+}
+
+// Expired returns true if binding has expired at given time
+func (b *ScopeBinding) Expired(now time.Time) bool {
+	return b.ExpiresAt != nil && !now.Before(b.ExpiresAt.Time)
+}
+
+// ActiveScopeBindings returns scope bindings not expired at given time
+func ActiveScopeBindings(bindings []ScopeBinding, now time.Time) []ScopeBinding {
+	active := make([]ScopeBinding, 0, len(bindings))
+	for i := range bindings {
+		if !bindings[i].Expired(now) {
+			active = append(active, bindings[i])
+		}
+	}
+	return active
+}
+
+// NextExpiration returns the duration until the earliest active binding
+// expires, false if no active binding expires
+func NextExpiration(bindings []ScopeBinding, now time.Time) (time.Duration, bool) {
+	var next time.Duration
+	found := false
+	for i := range bindings {
+		if bindings[i].ExpiresAt == nil || bindings[i].Expired(now) {
+			continue
+		}
+		if d := bindings[i].ExpiresAt.Sub(now); !found || d < next {
+			next, found = d, true
+		}
+	}
+	return next, found
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package v1
 
 import (
+	"time"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -72,6 +74,15 @@ type ScopeBinding struct {
 
 	// Role the rbac role name.
 	Role string `json:"role"`
+
+	// ExpiresAt the time after which binding takes no effect and generated
+	// RoleBindings or ClusterRoleBindings are removed, permanent if not set
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+
+	// Reason why binding was granted, such as incident of temporary elevation
+	// +optional
+	Reason string `json:"reason,omitempty"`
 }
 
 // UserStatus defines the observed state of User
@@ -133,7 +144,7 @@ func init() {
 
 func (u *User) IsUserPlatformScope() bool {
 	platformScope := false
-	for _, scope := range u.Spec.ScopeBindings {
+	for _, scope := range ActiveScopeBindings(u.Spec.ScopeBindings, time.Now()) {
 		if scope.ScopeType == PlatformScope {
 			platformScope = true
 			break
@@ -24,13 +24,18 @@ import (
 
 	_ "github.com/saashqdev/kubeworkz/docs"
 	_ "github.com/saashqdev/kubeworkz/pkg/apis/user/v1"
+	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/accessreview"
 	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/authorization"
+	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/bindingrequest"
 	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/cluster"
 	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/healthz"
+	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/hotplug"
 	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/k8s"
 	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/key"
 	resourcemanage "github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/handle"
 	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/scout"
+	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/tenant"
+	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/terminal"
 	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/user"
 	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/yamldeploy"
 	"github.com/saashqdev/kubeworkz/pkg/apiserver/middlewares"
@@ -71,6 +76,21 @@ func registerKubeAPI(cfg *Config) http.Handler {
 	// authZ apis handler
 	authorization.NewHandler().AddApisTo(router)
 
+	// tenant lifecycle apis handler
+	tenant.NewHandler().AddApisTo(router)
+
+	// hotplug dry-run apis handler
+	hotplug.NewHandler().AddApisTo(router)
+
+	// access review campaign apis handler
+	accessreview.NewHandler().AddApisTo(router)
+
+	// temporary role request apis handler
+	bindingrequest.NewHandler().AddApisTo(router)
+
+	// recorded exec and port-forward apis handler
+	terminal.NewHandler(cfg.Gi18nManagers).AddApisTo(router)
+
 	router.POST(constants.ApiPathRoot+"/login", user.Login)
 	router.GET(constants.ApiPathRoot+"/oauth/redirect", user.GitHubLogin)
 
@@ -108,10 +128,13 @@ func registerKubeAPI(cfg *Config) http.Handler {
 
 		k8sApiExtend.GET("/feature-config", resourcemanage.GetFeatureConfig)
 		k8sApiExtend.Any("/clusters/:cluster/resources/:resourceType", extendHandler.ExtendHandle)
+		k8sApiExtend.Any("/clusters/:cluster/resources/:resourceType/:resourceName", extendHandler.ExtendHandle)
 		k8sApiExtend.Any("/clusters/:cluster/namespaces/:namespace/:resourceType/:resourceName", extendHandler.ExtendHandle)
 		k8sApiExtend.Any("/clusters/:cluster/namespaces/:namespace/:resourceType", extendHandler.ExtendHandle)
 		k8sApiExtend.GET("/clusters/:cluster/namespaces/:namespace/logs/:resourceName", resourcemanage.GetPodContainerLog)
 		k8sApiExtend.GET("/clusters/:cluster/namespaces/:namespace/proxy/logs/:resourceName", resourcemanage.GetProxyPodContainerLog)
+		k8sApiExtend.GET("/clusters/:cluster/namespaces/:namespace/logstream", resourcemanage.GetPodLogStream)
+		k8sApiExtend.GET("/clusters/:cluster/namespaces/:namespace/logarchive", resourcemanage.GetPodLogArchive)
 		k8sApiExtend.POST("/clusters/:cluster/yaml/deploy", yamlDeployHandler.Deploy)
 		k8sApiExtend.GET("/ingressDomainSuffix", resourcemanage.IngressDomainSuffix)
 	}
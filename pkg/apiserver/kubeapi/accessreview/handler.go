@@ -0,0 +1,377 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package accessreview
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+
+	tenantv1 "github.com/saashqdev/kubeworkz/pkg/apis/tenant/v1"
+	userv1 "github.com/saashqdev/kubeworkz/pkg/apis/user/v1"
+	"github.com/saashqdev/kubeworkz/pkg/clients"
+	mgrclient "github.com/saashqdev/kubeworkz/pkg/multicluster/client"
+	"github.com/saashqdev/kubeworkz/pkg/utils/access"
+	"github.com/saashqdev/kubeworkz/pkg/utils/audit"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+	"github.com/saashqdev/kubeworkz/pkg/utils/errcode"
+	"github.com/saashqdev/kubeworkz/pkg/utils/response"
+)
+
+const subPath = "/accessreviews"
+
+type handler struct {
+	mgrclient.Client
+}
+
+func NewHandler() *handler {
+	h := new(handler)
+	h.Client = clients.Interface().Kubernetes(constants.LocalCluster)
+	return h
+}
+
+func (h *handler) AddApisTo(root *gin.Engine) {
+	r := root.Group(constants.ApiPathRoot + subPath)
+	r.POST("", h.create)
+	r.POST("/:name/decisions", h.decide)
+	r.GET("/:name/report", h.report)
+}
+
+// Decision is the decision of reviewer on a scope binding of user
+type Decision struct {
+	User      string                  `json:"user"`
+	ScopeType userv1.BindingScopeType `json:"scopeType"`
+	ScopeName string                  `json:"scopeName"`
+	Role      string                  `json:"role"`
+	Decision  userv1.ReviewDecision   `json:"decision"`
+	Comment   string                  `json:"comment,omitempty"`
+}
+
+type decisionsRequest struct {
+	Decisions []Decision `json:"decisions"`
+}
+
+// Report is the exported result of access review
+type Report struct {
+	Name           string              `json:"name"`
+	Tenant         string              `json:"tenant"`
+	Project        string              `json:"project,omitempty"`
+	Phase          string              `json:"phase"`
+	SnapshotTime   *metav1.Time        `json:"snapshotTime,omitempty"`
+	Deadline       *metav1.Time        `json:"deadline,omitempty"`
+	CompletionTime *metav1.Time        `json:"completionTime,omitempty"`
+	Reviewers      []string            `json:"reviewers"`
+	Approved       int                 `json:"approved"`
+	Revoked        int                 `json:"revoked"`
+	Pending        int                 `json:"pending"`
+	Items          []userv1.ReviewItem `json:"items"`
+}
+
+// create starts access review campaign
+// @Summary create access review
+// @Description start campaign to review scope bindings of tenant or project, users allowed to create accessreviews can start any campaign, admins of tenant or project can start campaign of their own scope with admins of the scope as reviewers
+// @Tags accessreview
+// @Param accessReview body userv1.AccessReview true "access review"
+// @Success 200 {object} userv1.AccessReview
+// @Failure 400 {object} errcode.ErrorInfo
+// @Failure 403 {object} errcode.ErrorInfo
+// @Router /api/v1/kube/accessreviews  [post]
+func (h *handler) create(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	review := &userv1.AccessReview{}
+	if err := c.ShouldBindJSON(review); err != nil || len(review.Spec.Tenant) == 0 {
+		response.FailReturn(c, errcode.InvalidBodyFormat)
+		return
+	}
+	review.Status = userv1.AccessReviewStatus{}
+	if len(review.Name) == 0 && len(review.GenerateName) == 0 {
+		review.GenerateName = review.Spec.Tenant + "-"
+	}
+	c = audit.SetAuditInfo(c, audit.CreateAccessReview, review.Spec.Tenant, review.Spec)
+
+	tenant := &tenantv1.Tenant{}
+	if err := h.Direct().Get(ctx, types.NamespacedName{Name: review.Spec.Tenant}, tenant); err != nil {
+		response.FailReturn(c, errcode.BadRequest(err))
+		return
+	}
+	if len(review.Spec.Project) > 0 {
+		project := &tenantv1.Project{}
+		if err := h.Direct().Get(ctx, types.NamespacedName{Name: review.Spec.Project}, project); err != nil {
+			response.FailReturn(c, errcode.BadRequest(err))
+			return
+		}
+		if project.Labels[constants.TenantLabel] != review.Spec.Tenant {
+			response.FailReturn(c, errcode.CustomReturn(http.StatusBadRequest, "project %v does not belong to tenant %v", review.Spec.Project, review.Spec.Tenant))
+			return
+		}
+	}
+
+	if !access.AllowAccess(constants.LocalCluster, c.Request, constants.CreateVerb, review) {
+		if errInfo := h.checkScopeAdmins(ctx, c.GetString(constants.UserName), review.Spec); errInfo != nil {
+			response.FailReturn(c, errInfo)
+			return
+		}
+	}
+
+	if err := h.Direct().Create(ctx, review); err != nil {
+		response.FailReturn(c, errcode.BadRequest(err))
+		return
+	}
+	response.SuccessReturn(c, review)
+}
+
+// checkScopeAdmins checks that creator and reviewers of access review are
+// admins of the reviewed scope, so that campaign never reaches bindings out
+// of scope the creator manages
+func (h *handler) checkScopeAdmins(ctx context.Context, creator string, spec userv1.AccessReviewSpec) *errcode.ErrorInfo {
+	now := time.Now()
+	for _, name := range append([]string{creator}, spec.Reviewers...) {
+		u := &userv1.User{}
+		if err := h.Direct().Get(ctx, types.NamespacedName{Name: name}, u); err != nil {
+			if errors.IsNotFound(err) {
+				return errcode.CustomReturn(http.StatusBadRequest, "user %v not found", name)
+			}
+			return errcode.CustomReturn(http.StatusInternalServerError, err.Error())
+		}
+		if !IsScopeAdmin(u, spec.Tenant, spec.Project, now) {
+			if name == creator {
+				return errcode.ForbiddenErr
+			}
+			return errcode.CustomReturn(http.StatusForbidden, "reviewer %v is not admin of reviewed scope", name)
+		}
+	}
+	return nil
+}
+
+// IsScopeAdmin tells whether user is admin of tenant, or admin of project
+// when project is given
+func IsScopeAdmin(u *userv1.User, tenant, project string, now time.Time) bool {
+	for _, b := range userv1.ActiveScopeBindings(u.Spec.ScopeBindings, now) {
+		if b.ScopeType == userv1.TenantScope && b.ScopeName == tenant && b.Role == constants.TenantAdmin {
+			return true
+		}
+		if len(project) > 0 && b.ScopeType == userv1.ProjectScope && b.ScopeName == project && b.Role == constants.ProjectAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// decide approves or revokes scope bindings of access review
+// @Summary decide on scope bindings under review
+// @Description approve or revoke scope bindings snapshotted by access review, revoked bindings are removed from users by controller
+// @Tags accessreview
+// @Param name path string true "access review name"
+// @Param decisions body decisionsRequest true "decisions on bindings"
+// @Success 200 {object} Report
+// @Failure 400 {object} errcode.ErrorInfo
+// @Failure 403 {object} errcode.ErrorInfo
+// @Router /api/v1/kube/accessreviews/{name}/decisions  [post]
+func (h *handler) decide(c *gin.Context) {
+	ctx := c.Request.Context()
+	name := c.Param("name")
+	reviewer := c.GetString(constants.UserName)
+
+	body := decisionsRequest{}
+	if err := c.ShouldBindJSON(&body); err != nil || len(body.Decisions) == 0 {
+		response.FailReturn(c, errcode.InvalidBodyFormat)
+		return
+	}
+	c = audit.SetAuditInfo(c, audit.ReviewAccess, name, body)
+
+	review := &userv1.AccessReview{}
+	if err := h.Direct().Get(ctx, types.NamespacedName{Name: name}, review); err != nil {
+		response.FailReturn(c, errcode.BadRequest(err))
+		return
+	}
+	if !isReviewer(review, reviewer) && !access.AllowAccess(constants.LocalCluster, c.Request, constants.UpdateVerb, review) {
+		response.FailReturn(c, errcode.ForbiddenErr)
+		return
+	}
+
+	var errInfo *errcode.ErrorInfo
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		errInfo = nil
+		r := &userv1.AccessReview{}
+		err := h.Direct().Get(ctx, types.NamespacedName{Name: name}, r)
+		if err != nil {
+			return err
+		}
+		if errInfo = ApplyDecisions(r, reviewer, body.Decisions, time.Now()); errInfo != nil {
+			return nil
+		}
+		if err = h.Direct().Status().Update(ctx, r); err != nil {
+			return err
+		}
+		review = r
+		return nil
+	})
+	if errInfo != nil {
+		response.FailReturn(c, errInfo)
+		return
+	}
+	if err != nil {
+		if errors.IsNotFound(err) {
+			response.FailReturn(c, errcode.BadRequest(err))
+			return
+		}
+		response.FailReturn(c, errcode.CustomReturn(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	response.SuccessReturn(c, reportOf(review))
+}
+
+// report exports the result of access review as json or csv
+// @Summary export access review report
+// @Description export decisions on scope bindings of access review
+// @Tags accessreview
+// @Param name path string true "access review name"
+// @Param format query string false "json or csv, defaults to json"
+// @Success 200 {object} Report
+// @Failure 400 {object} errcode.ErrorInfo
+// @Failure 403 {object} errcode.ErrorInfo
+// @Router /api/v1/kube/accessreviews/{name}/report  [get]
+func (h *handler) report(c *gin.Context) {
+	ctx := c.Request.Context()
+	name := c.Param("name")
+	format := c.DefaultQuery("format", "json")
+
+	review := &userv1.AccessReview{}
+	if err := h.Direct().Get(ctx, types.NamespacedName{Name: name}, review); err != nil {
+		response.FailReturn(c, errcode.BadRequest(err))
+		return
+	}
+	if !isReviewer(review, c.GetString(constants.UserName)) && !access.AllowAccess(constants.LocalCluster, c.Request, constants.GetVerb, review) {
+		response.FailReturn(c, errcode.ForbiddenErr)
+		return
+	}
+
+	switch format {
+	case "json":
+		response.SuccessReturn(c, reportOf(review))
+	case "csv":
+		data, err := CSVReport(review)
+		if err != nil {
+			response.FailReturn(c, errcode.CustomReturn(http.StatusInternalServerError, err.Error()))
+			return
+		}
+		c.Writer.Header().Set(constants.HttpHeaderContentDisposition, fmt.Sprintf("attachment;filename=%s.csv", name))
+		c.Data(http.StatusOK, "text/csv", data)
+	default:
+		response.FailReturn(c, errcode.CustomReturn(http.StatusBadRequest, "unsupported report format %v", format))
+	}
+}
+
+// ApplyDecisions records decisions of reviewer into status of review,
+// decisions can not be made after deadline, on bindings of reviewer
+// itself, or on bindings already revoked
+func ApplyDecisions(review *userv1.AccessReview, reviewer string, decisions []Decision, now time.Time) *errcode.ErrorInfo {
+	status := &review.Status
+	if status.Phase != userv1.AccessReviewInProgress || (status.Deadline != nil && !now.Before(status.Deadline.Time)) {
+		return errcode.CustomReturn(http.StatusBadRequest, "access review %v is not in progress", review.Name)
+	}
+
+	reviewedAt := metav1.NewTime(now)
+	for _, d := range decisions {
+		if d.Decision != userv1.DecisionApproved && d.Decision != userv1.DecisionRevoked {
+			return errcode.CustomReturn(http.StatusBadRequest, "invalid decision %v", d.Decision)
+		}
+		if d.User == reviewer {
+			return errcode.CustomReturn(http.StatusForbidden, "can not review bindings of yourself")
+		}
+
+		found := false
+		for i := range status.Items {
+			item := &status.Items[i]
+			if !item.Matches(d.User, d.ScopeType, d.ScopeName, d.Role) {
+				continue
+			}
+			if item.Decision == userv1.DecisionRevoked && item.Enforced {
+				return errcode.CustomReturn(http.StatusBadRequest, "binding %v of user %v in %v %v was already revoked", d.Role, d.User, d.ScopeType, d.ScopeName)
+			}
+			item.Decision = d.Decision
+			item.Reviewer = reviewer
+			item.ReviewedAt = &reviewedAt
+			item.Comment = d.Comment
+			found = true
+			break
+		}
+		if !found {
+			return errcode.CustomReturn(http.StatusBadRequest, "binding %v of user %v in %v %v is not under review", d.Role, d.User, d.ScopeType, d.ScopeName)
+		}
+	}
+	status.Summarize()
+
+	return nil
+}
+
+// CSVReport renders items of review as csv with header line
+func CSVReport(review *userv1.AccessReview) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	w := csv.NewWriter(buf)
+	rows := [][]string{{"user", "scopeType", "scopeName", "role", "decision", "reviewer", "reviewedAt", "comment", "enforced"}}
+	for _, item := range review.Status.Items {
+		reviewedAt := ""
+		if item.ReviewedAt != nil {
+			reviewedAt = item.ReviewedAt.UTC().Format(time.RFC3339)
+		}
+		rows = append(rows, []string{item.User, string(item.ScopeType), item.ScopeName, item.Role,
+			string(item.Decision), item.Reviewer, reviewedAt, item.Comment, strconv.FormatBool(item.Enforced)})
+	}
+	if err := w.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func reportOf(review *userv1.AccessReview) Report {
+	return Report{
+		Name:           review.Name,
+		Tenant:         review.Spec.Tenant,
+		Project:        review.Spec.Project,
+		Phase:          review.Status.Phase,
+		SnapshotTime:   review.Status.SnapshotTime,
+		Deadline:       review.Status.Deadline,
+		CompletionTime: review.Status.CompletionTime,
+		Reviewers:      review.Status.Reviewers,
+		Approved:       review.Status.Approved,
+		Revoked:        review.Status.Revoked,
+		Pending:        review.Status.Pending,
+		Items:          review.Status.Items,
+	}
+}
+
+func isReviewer(review *userv1.AccessReview, user string) bool {
+	for _, r := range review.Status.Reviewers {
+		if r == user {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package accessreview
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	userv1 "github.com/saashqdev/kubeworkz/pkg/apis/user/v1"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+)
+
+func TestIsScopeAdmin(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Now()
+	expired := metav1.NewTime(now.Add(-time.Minute))
+
+	tenantAdmin := &userv1.User{Spec: userv1.UserSpec{ScopeBindings: []userv1.ScopeBinding{
+		{ScopeType: userv1.TenantScope, ScopeName: "t1", Role: constants.TenantAdmin},
+	}}}
+	assert.True(IsScopeAdmin(tenantAdmin, "t1", "", now))
+	assert.True(IsScopeAdmin(tenantAdmin, "t1", "p1", now))
+	assert.False(IsScopeAdmin(tenantAdmin, "t2", "", now), "admin of other tenant")
+
+	projectAdmin := &userv1.User{Spec: userv1.UserSpec{ScopeBindings: []userv1.ScopeBinding{
+		{ScopeType: userv1.ProjectScope, ScopeName: "p1", Role: constants.ProjectAdmin},
+		{ScopeType: userv1.TenantScope, ScopeName: "t1", Role: constants.TenantAdmin, ExpiresAt: &expired},
+	}}}
+	assert.True(IsScopeAdmin(projectAdmin, "t1", "p1", now))
+	assert.False(IsScopeAdmin(projectAdmin, "t1", "", now), "expired tenant admin")
+	assert.False(IsScopeAdmin(projectAdmin, "t1", "p2", now), "admin of other project")
+}
@@ -0,0 +1,339 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package authorization
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/saashqdev/kubeworkz/pkg/authorizer/mapping"
+	"github.com/saashqdev/kubeworkz/pkg/clients"
+	"github.com/saashqdev/kubeworkz/pkg/clog"
+	"github.com/saashqdev/kubeworkz/pkg/multicluster"
+	"github.com/saashqdev/kubeworkz/pkg/utils/access"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+	"github.com/saashqdev/kubeworkz/pkg/utils/errcode"
+	"github.com/saashqdev/kubeworkz/pkg/utils/response"
+)
+
+// roleClustersAnnotation records clusters custom role distributed to
+const roleClustersAnnotation = "kubeworkz.io/role-clusters"
+
+type rolePreview struct {
+	ClusterRole *rbacv1.ClusterRole `json:"clusterRole"`
+	// Problems found by validating rules against discovery of each cluster
+	Problems map[string][]string `json:"problems,omitempty"`
+	// Results of distributing role to each cluster
+	Results []clusterResult `json:"results,omitempty"`
+}
+
+type clusterResult struct {
+	Cluster string `json:"cluster"`
+	// Action apply or delete
+	Action string `json:"action"`
+	Error  string `json:"error,omitempty"`
+}
+
+func (r *clusterResult) status() string {
+	if len(r.Error) > 0 {
+		return r.Error
+	}
+	return "success"
+}
+
+// getCustomRole get ClusterRole in authoring form
+// @Summary Get custom role
+// @Description get rules of ClusterRole in authoring form
+// @Tags authorization
+// @Param name path string true "ClusterRole name"
+// @Success 200 {object} mapping.RoleSpec
+// @Failure 400 {object} errcode.ErrorInfo
+// @Router /api/v1/kube/authorization/customroles/{name} [get]
+func (h *handler) getCustomRole(c *gin.Context) {
+	role := &rbacv1.ClusterRole{}
+	err := h.Direct().Get(c.Request.Context(), types.NamespacedName{Name: c.Param("name")}, role)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			response.FailReturn(c, errcode.CustomReturn(http.StatusNotFound, err.Error()))
+			return
+		}
+		response.FailReturn(c, errcode.BadRequest(err))
+		return
+	}
+
+	spec := mapping.RoleSpecOf(role)
+	if v, ok := role.Annotations[roleClustersAnnotation]; ok && len(v) > 0 {
+		spec.Clusters = strings.Split(v, ",")
+	}
+
+	response.SuccessReturn(c, spec)
+}
+
+// previewCustomRole generate ClusterRole of role spec and validate it
+// @Summary Preview custom role
+// @Description generate ClusterRole of role spec and validate rules against discovery of target clusters
+// @Tags authorization
+// @Param role body mapping.RoleSpec true "role spec"
+// @Success 200 {object} rolePreview
+// @Failure 400 {object} errcode.ErrorInfo
+// @Router /api/v1/kube/authorization/customroles/preview [post]
+func (h *handler) previewCustomRole(c *gin.Context) {
+	preview, errInfo := h.buildCustomRole(c)
+	if errInfo != nil {
+		response.FailReturn(c, errInfo)
+		return
+	}
+	response.SuccessReturn(c, preview)
+}
+
+// setCustomRole create or update ClusterRole of role spec in control plane
+// and selected clusters
+// @Summary Create or update custom role
+// @Description validate role spec and distribute generated ClusterRole to control plane and selected clusters
+// @Tags authorization
+// @Param role body mapping.RoleSpec true "role spec"
+// @Success 200 {object} rolePreview
+// @Failure 400 {object} errcode.ErrorInfo
+// @Failure 403 {object} errcode.ErrorInfo
+// @Router /api/v1/kube/authorization/customroles [post]
+func (h *handler) setCustomRole(c *gin.Context) {
+	preview, errInfo := h.buildCustomRole(c)
+	if errInfo != nil {
+		response.FailReturn(c, errInfo)
+		return
+	}
+	if len(preview.Problems) > 0 {
+		response.FailReturn(c, errcode.CustomReturn(http.StatusBadRequest, "rules not served by clusters: %v", preview.Problems))
+		return
+	}
+
+	ctx := c.Request.Context()
+	clusters := targetClusters(preview.ClusterRole)
+	removed, err := removedClusters(ctx, preview.ClusterRole)
+	if err != nil {
+		response.FailReturn(c, errcode.BadRequest(err))
+		return
+	}
+	for _, cluster := range clusters {
+		if !access.AllowAccess(cluster, c.Request, constants.UpdateVerb, preview.ClusterRole) {
+			response.FailReturn(c, errcode.ForbiddenErr)
+			return
+		}
+	}
+	for _, cluster := range removed {
+		if !access.AllowAccess(cluster, c.Request, constants.DeleteVerb, preview.ClusterRole) {
+			response.FailReturn(c, errcode.ForbiddenErr)
+			return
+		}
+	}
+
+	// control plane holds the distribution list, so it must succeed first
+	for _, cluster := range clusters {
+		result := clusterResult{Cluster: cluster, Action: "apply"}
+		cli := clients.Interface().Kubernetes(cluster)
+		if cli == nil {
+			result.Error = fmt.Sprintf("cluster %v not found", cluster)
+		} else {
+			role := preview.ClusterRole.DeepCopy()
+			if cluster != constants.LocalCluster {
+				delete(role.Annotations, roleClustersAnnotation)
+			}
+			if err = applyClusterRole(ctx, cli.Direct(), role); err != nil {
+				result.Error = err.Error()
+			}
+		}
+		if len(result.Error) > 0 && cluster == constants.LocalCluster {
+			response.FailReturn(c, errcode.CustomReturn(http.StatusInternalServerError, "apply ClusterRole %v to cluster %v failed: %v", preview.ClusterRole.Name, cluster, result.Error))
+			return
+		}
+		preview.Results = append(preview.Results, result)
+		clog.Info("custom ClusterRole %v applied to cluster %v: %v", preview.ClusterRole.Name, cluster, result.status())
+	}
+
+	for _, cluster := range removed {
+		result := clusterResult{Cluster: cluster, Action: "delete"}
+		cli := clients.Interface().Kubernetes(cluster)
+		if cli == nil {
+			result.Error = fmt.Sprintf("cluster %v not found", cluster)
+		} else if err = deleteClusterRole(ctx, cli.Direct(), preview.ClusterRole.Name); err != nil {
+			result.Error = err.Error()
+		}
+		preview.Results = append(preview.Results, result)
+		clog.Info("custom ClusterRole %v removed from cluster %v: %v", preview.ClusterRole.Name, cluster, result.status())
+	}
+
+	response.SuccessReturn(c, preview)
+}
+
+// buildCustomRole generates ClusterRole of role spec in request body and
+// validates it against discovery of target clusters
+func (h *handler) buildCustomRole(c *gin.Context) (*rolePreview, *errcode.ErrorInfo) {
+	spec := &mapping.RoleSpec{}
+	if err := c.ShouldBindJSON(spec); err != nil {
+		return nil, errcode.InvalidBodyFormat
+	}
+
+	role, err := mapping.BuildClusterRole(spec)
+	if err != nil {
+		return nil, errcode.BadRequest(err)
+	}
+	if len(spec.Clusters) > 0 {
+		role.Annotations = map[string]string{roleClustersAnnotation: strings.Join(spec.Clusters, ",")}
+	}
+
+	preview := &rolePreview{ClusterRole: role}
+	for _, cluster := range targetClusters(role) {
+		cli := clients.Interface().Kubernetes(cluster)
+		if cli == nil {
+			return nil, errcode.ClusterNotFoundError(cluster)
+		}
+		problems, err := validateRole(cli.CacheDiscovery(), role)
+		if err != nil {
+			return nil, errcode.CustomReturn(http.StatusInternalServerError, "discover resources of cluster %v failed: %v", cluster, err)
+		}
+		if len(problems) > 0 {
+			if preview.Problems == nil {
+				preview.Problems = make(map[string][]string)
+			}
+			preview.Problems[cluster] = problems
+		}
+	}
+
+	return preview, nil
+}
+
+// targetClusters returns control plane and clusters selected for role
+func targetClusters(role *rbacv1.ClusterRole) []string {
+	clusters := []string{constants.LocalCluster}
+	v := role.Annotations[roleClustersAnnotation]
+	if len(v) == 0 {
+		return clusters
+	}
+	for _, cluster := range strings.Split(v, ",") {
+		if cluster != constants.LocalCluster {
+			clusters = append(clusters, cluster)
+		}
+	}
+	return clusters
+}
+
+func validateRole(d discovery.DiscoveryInterface, role *rbacv1.ClusterRole) ([]string, error) {
+	if d == nil {
+		return nil, fmt.Errorf("discovery not available")
+	}
+	_, served, err := d.ServerGroupsAndResources()
+	if err != nil && len(served) == 0 {
+		return nil, err
+	}
+	return mapping.ValidateRules(role.Rules, served), nil
+}
+
+// removedClusters returns clusters role was distributed to but not selected
+// any more, all member clusters if role was synced by warden before
+func removedClusters(ctx context.Context, role *rbacv1.ClusterRole) ([]string, error) {
+	if len(role.Annotations[roleClustersAnnotation]) == 0 {
+		// role is synced to all clusters by warden
+		return nil, nil
+	}
+
+	cli := clients.Interface().Kubernetes(constants.LocalCluster)
+	if cli == nil {
+		return nil, fmt.Errorf("cluster %v not found", constants.LocalCluster)
+	}
+	existing := &rbacv1.ClusterRole{}
+	err := cli.Direct().Get(ctx, types.NamespacedName{Name: role.Name}, existing)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var previous []string
+	if len(existing.Annotations[roleClustersAnnotation]) == 0 {
+		previous = multicluster.Interface().ListClustersNameByType(multicluster.AllCluster)
+	} else {
+		previous = targetClusters(existing)
+	}
+	selected := sets.NewString(targetClusters(role)...)
+	removed := []string{}
+	for _, cluster := range previous {
+		if !selected.Has(cluster) {
+			selected.Insert(cluster)
+			removed = append(removed, cluster)
+		}
+	}
+	return removed, nil
+}
+
+// applyClusterRole creates or updates rules of ClusterRole, labels and
+// annotations not managed here are kept, ClusterRoles not created by
+// kubeworkz are never updated
+func applyClusterRole(ctx context.Context, cli client.Client, role *rbacv1.ClusterRole) error {
+	obj := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: role.Name}}
+	_, err := controllerruntime.CreateOrUpdate(ctx, cli, obj, func() error {
+		if len(obj.ResourceVersion) > 0 && obj.Labels[constants.RbacLabel] != constants.TrueStr {
+			return fmt.Errorf("ClusterRole %v is not managed by kubeworkz", obj.Name)
+		}
+		if obj.AggregationRule != nil {
+			return fmt.Errorf("rules of aggregated ClusterRole can not be set")
+		}
+		if obj.Labels == nil {
+			obj.Labels = make(map[string]string)
+		}
+		for k, v := range role.Labels {
+			obj.Labels[k] = v
+		}
+		if obj.Annotations == nil {
+			obj.Annotations = make(map[string]string)
+		}
+		delete(obj.Annotations, constants.SyncAnnotation)
+		delete(obj.Annotations, roleClustersAnnotation)
+		for k, v := range role.Annotations {
+			obj.Annotations[k] = v
+		}
+		obj.Rules = role.Rules
+		return nil
+	})
+	return err
+}
+
+// deleteClusterRole deletes ClusterRole created by kubeworkz, it is fine
+// if ClusterRole does not exist
+func deleteClusterRole(ctx context.Context, cli client.Client, name string) error {
+	obj := &rbacv1.ClusterRole{}
+	err := cli.Get(ctx, types.NamespacedName{Name: name}, obj)
+	if err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if obj.Labels[constants.RbacLabel] != constants.TrueStr {
+		return fmt.Errorf("ClusterRole %v is not managed by kubeworkz", name)
+	}
+	return client.IgnoreNotFound(cli.Delete(ctx, obj))
+}
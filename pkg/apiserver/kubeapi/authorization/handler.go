@@ -63,10 +63,15 @@ func (h *handler) AddApisTo(root *gin.Engine) {
 	r.DELETE("bindings", h.deleteBinds)
 	r.POST("access", h.authorization)
 	r.POST("resources", h.resourcesGate)
+	r.GET("whocan", h.whoCan)
+	r.GET("whatcan", h.whatCan)
 	r.GET("authitems/:clusterrole", h.getAuthItems)
 	r.GET("authitems", h.getAuthItemsByLabelSelector)
 	r.POST("authitems", h.setAuthItems)
 	r.POST("authitems/permissions", h.getPermissions)
+	r.GET("customroles/:name", h.getCustomRole)
+	r.POST("customroles/preview", h.previewCustomRole)
+	r.POST("customroles", h.setCustomRole)
 	r.GET("deamonsets/level", h.getDaemonSetsLevel)
 }
 
@@ -914,3 +919,165 @@ func (h *handler) resourcesGate(c *gin.Context) {
 
 	response.SuccessReturn(c, result)
 }
+
+// whoCan tells which users, groups and service accounts can perform verb on
+// resource in namespace of cluster, and the binding path granting each of them
+// @Summary Who can perform action
+// @Description list subjects allowed to perform verb on resource with the bindings granting them
+// @Tags authorization
+// @Param cluster query string false "cluster name, pivot cluster if empty"
+// @Param verb query string true "verb"
+// @Param resource query string false "resource"
+// @Param subresource query string false "subresource"
+// @Param apiGroup query string false "api group"
+// @Param name query string false "resource name"
+// @Param namespace query string false "namespace, only cluster wide bindings checked if empty"
+// @Param path query string false "non resource url, used when resource is empty"
+// @Success 200 {object} result
+// @Failure 400 {object} errcode.ErrorInfo
+// @Failure 403 {object} errcode.ErrorInfo
+// @Router /api/v1/kube/authorization/whocan [get]
+func (h *handler) whoCan(c *gin.Context) {
+	cluster := c.DefaultQuery("cluster", constants.LocalCluster)
+	record := &authorizer.AttributesRecord{
+		Verb:            c.Query("verb"),
+		Namespace:       c.Query("namespace"),
+		APIGroup:        c.Query("apiGroup"),
+		Resource:        c.Query("resource"),
+		Subresource:     c.Query("subresource"),
+		Name:            c.Query("name"),
+		ResourceRequest: len(c.Query("resource")) > 0,
+		Path:            c.Query("path"),
+	}
+	if len(record.Verb) == 0 || (len(record.Resource) == 0 && len(record.Path) == 0) {
+		response.FailReturn(c, errcode.CustomReturn(http.StatusBadRequest, "verb and one of resource and path are required"))
+		return
+	}
+
+	r, clusterWide, ok := reviewResolver(c, cluster, record.Namespace)
+	if !ok {
+		return
+	}
+
+	accesses, err := r.SubjectsFor(record)
+	if err != nil {
+		response.FailReturn(c, errcode.BadRequest(err))
+		return
+	}
+
+	// subjects of cluster role bindings are hidden from namespace reviewers
+	if !clusterWide {
+		namespaced := []rbac.SubjectAccess{}
+		for _, a := range accesses {
+			if !a.Path.ClusterWide() {
+				namespaced = append(namespaced, a)
+			}
+		}
+		accesses = namespaced
+	}
+
+	response.SuccessReturn(c, result{Total: len(accesses), Items: accesses})
+}
+
+// whatCan lists every effective permission of user in namespace of cluster
+// and the binding path granting each rule
+// @Summary What can user do
+// @Description list effective rules of user with the bindings granting them
+// @Tags authorization
+// @Param cluster query string false "cluster name, pivot cluster if empty"
+// @Param user query string false "user name, current user if empty"
+// @Param namespace query string false "namespace, only cluster wide rules returned if empty"
+// @Success 200 {object} result
+// @Failure 400 {object} errcode.ErrorInfo
+// @Failure 403 {object} errcode.ErrorInfo
+// @Router /api/v1/kube/authorization/whatcan [get]
+func (h *handler) whatCan(c *gin.Context) {
+	cluster := c.DefaultQuery("cluster", constants.LocalCluster)
+	namespace := c.Query("namespace")
+	userName := c.Query("user")
+
+	if len(userName) == 0 {
+		userName = c.GetString(constants.UserName)
+	}
+
+	var (
+		r           *rbac.DefaultResolver
+		clusterWide = true
+		ok          bool
+	)
+	if userName == c.GetString(constants.UserName) {
+		// everyone can review permissions of oneself
+		r, ok = resolverOf(c, cluster)
+	} else {
+		r, clusterWide, ok = reviewResolver(c, cluster, namespace)
+	}
+	if !ok {
+		return
+	}
+
+	accesses, err := r.RulesFor(rbac.User2UserInfo(userName), namespace)
+	if err != nil {
+		response.FailReturn(c, errcode.BadRequest(err))
+		return
+	}
+
+	// rules of cluster role bindings are hidden from namespace reviewers
+	if !clusterWide {
+		namespaced := []rbac.RuleAccess{}
+		for _, a := range accesses {
+			if !a.Path.ClusterWide() {
+				namespaced = append(namespaced, a)
+			}
+		}
+		accesses = namespaced
+	}
+
+	response.SuccessReturn(c, result{Total: len(accesses), Items: accesses})
+}
+
+// resolverOf returns rbac resolver of cluster
+func resolverOf(c *gin.Context, cluster string) (*rbac.DefaultResolver, bool) {
+	cli := clients.Interface().Kubernetes(cluster)
+	if cli == nil {
+		response.FailReturn(c, errcode.ClusterNotFoundError(cluster))
+		return nil, false
+	}
+	return &rbac.DefaultResolver{Cache: cli.Cache()}, true
+}
+
+// reviewResolver returns rbac resolver of cluster if current user is allowed
+// to list bindings in namespace, or cluster wide when namespace is empty. It
+// also tells if current user is allowed to list cluster role bindings, the
+// subjects and rules granted by which should be hidden if not allowed.
+func reviewResolver(c *gin.Context, cluster, namespace string) (*rbac.DefaultResolver, bool, bool) {
+	r, ok := resolverOf(c, cluster)
+	if !ok {
+		return nil, false, false
+	}
+
+	userName := c.GetString(constants.UserName)
+	clusterWide, err := rbac.IsAllowResourceAccess(r, userName, "clusterrolebindings", constants.ListVerb, "")
+	if err != nil {
+		response.FailReturn(c, errcode.BadRequest(err))
+		return nil, false, false
+	}
+	if len(namespace) == 0 {
+		if !clusterWide {
+			response.FailReturn(c, errcode.ForbiddenErr)
+			return nil, false, false
+		}
+		return r, true, true
+	}
+
+	allowed, err := rbac.IsAllowResourceAccess(r, userName, "rolebindings", constants.ListVerb, namespace)
+	if err != nil {
+		response.FailReturn(c, errcode.BadRequest(err))
+		return nil, false, false
+	}
+	if !allowed {
+		response.FailReturn(c, errcode.ForbiddenErr)
+		return nil, false, false
+	}
+
+	return r, clusterWide, true
+}
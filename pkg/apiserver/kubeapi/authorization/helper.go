@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
@@ -188,7 +189,7 @@ func isPlatformAdmin(user *userv1.User) bool {
 
 func isTenantAdmin(user *userv1.User) bool {
 	var res bool
-	for _, binding := range user.Spec.ScopeBindings {
+	for _, binding := range userv1.ActiveScopeBindings(user.Spec.ScopeBindings, time.Now()) {
 		if binding.Role == constants.TenantAdmin {
 			res = true
 		}
@@ -198,7 +199,7 @@ func isTenantAdmin(user *userv1.User) bool {
 
 func isProjectAdmin(user *userv1.User) bool {
 	var res bool
-	for _, binding := range user.Spec.ScopeBindings {
+	for _, binding := range userv1.ActiveScopeBindings(user.Spec.ScopeBindings, time.Now()) {
 		if binding.Role == constants.ProjectAdmin {
 			res = true
 		}
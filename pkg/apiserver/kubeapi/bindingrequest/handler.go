@@ -0,0 +1,346 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bindingrequest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+
+	tenantv1 "github.com/saashqdev/kubeworkz/pkg/apis/tenant/v1"
+	userv1 "github.com/saashqdev/kubeworkz/pkg/apis/user/v1"
+	"github.com/saashqdev/kubeworkz/pkg/authorizer/rbac"
+	"github.com/saashqdev/kubeworkz/pkg/clients"
+	"github.com/saashqdev/kubeworkz/pkg/clog"
+	mgrclient "github.com/saashqdev/kubeworkz/pkg/multicluster/client"
+	"github.com/saashqdev/kubeworkz/pkg/utils/audit"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+	"github.com/saashqdev/kubeworkz/pkg/utils/env"
+	"github.com/saashqdev/kubeworkz/pkg/utils/errcode"
+	"github.com/saashqdev/kubeworkz/pkg/utils/response"
+	"github.com/saashqdev/kubeworkz/pkg/utils/transition"
+)
+
+const subPath = "/bindingrequests"
+
+type handler struct {
+	mgrclient.Client
+}
+
+func NewHandler() *handler {
+	h := new(handler)
+	h.Client = clients.Interface().Kubernetes(constants.LocalCluster)
+	return h
+}
+
+func (h *handler) AddApisTo(root *gin.Engine) {
+	r := root.Group(constants.ApiPathRoot + subPath)
+	r.POST("", h.requestBinding)
+	r.POST("/:name/approve", h.approveBinding)
+	r.POST("/:name/reject", h.rejectBinding)
+}
+
+type bindingRequestBody struct {
+	ScopeType userv1.BindingScopeType `json:"scopeType"`
+	ScopeName string                  `json:"scopeName"`
+	Role      string                  `json:"role"`
+	// Duration such as 2h or 30m
+	Duration string `json:"duration"`
+	Reason   string `json:"reason"`
+}
+
+type decisionBody struct {
+	Message string `json:"message,omitempty"`
+}
+
+// requestBinding requests temporary scope binding for current user
+// @Summary request temporary role
+// @Description request a scope binding for current user which expires after duration once approved
+// @Tags bindingrequest
+// @Param request body bindingRequestBody true "requested binding"
+// @Success 200 {object} userv1.BindingRequest
+// @Failure 400 {object} errcode.ErrorInfo
+// @Router /api/v1/kube/bindingrequests  [post]
+func (h *handler) requestBinding(c *gin.Context) {
+	ctx := c.Request.Context()
+	user := c.GetString(constants.UserName)
+
+	body := bindingRequestBody{}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.FailReturn(c, errcode.InvalidBodyFormat)
+		return
+	}
+	c = audit.SetAuditInfo(c, audit.RequestBinding, user, body)
+
+	duration, err := time.ParseDuration(body.Duration)
+	if err != nil || duration <= 0 {
+		response.FailReturn(c, errcode.CustomReturn(http.StatusBadRequest, "invalid duration %v", body.Duration))
+		return
+	}
+	if limit := env.MaxBindingRequestDuration(); duration > limit {
+		response.FailReturn(c, errcode.CustomReturn(http.StatusBadRequest, "duration %v exceeds the max %v", duration, limit))
+		return
+	}
+	if len(body.Reason) == 0 {
+		response.FailReturn(c, errcode.CustomReturn(http.StatusBadRequest, "reason is required"))
+		return
+	}
+	if _, err = h.tenantOf(ctx, body.ScopeType, body.ScopeName); err != nil {
+		response.FailReturn(c, errcode.BadRequest(err))
+		return
+	}
+	role := &rbacv1.ClusterRole{}
+	if err = h.Direct().Get(ctx, types.NamespacedName{Name: body.Role}, role); err != nil {
+		response.FailReturn(c, errcode.BadRequest(err))
+		return
+	}
+	// only kubeworkz roles of requested scope level can be requested
+	if role.Labels[constants.RoleLabel] != string(body.ScopeType) {
+		response.FailReturn(c, errcode.CustomReturn(http.StatusBadRequest, "role %v can not be bound to %v scope", body.Role, body.ScopeType))
+		return
+	}
+
+	req := &userv1.BindingRequest{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: user + "-"},
+		Spec: userv1.BindingRequestSpec{
+			User:      user,
+			ScopeType: body.ScopeType,
+			ScopeName: body.ScopeName,
+			Role:      body.Role,
+			Duration:  metav1.Duration{Duration: duration},
+			Reason:    body.Reason,
+		},
+	}
+	if err = h.Direct().Create(ctx, req); err != nil {
+		response.FailReturn(c, errcode.BadRequest(err))
+		return
+	}
+	req.Status.Phase = userv1.BindingRequestPending
+	if err = h.Direct().Status().Update(ctx, req); err != nil {
+		clog.Warn("init status of binding request %v failed: %v", req.Name, err)
+	}
+
+	response.SuccessReturn(c, req)
+}
+
+// approveBinding approves binding request and grants temporary scope binding
+// @Summary approve temporary role request
+// @Description grant requested scope binding to user until duration passed
+// @Tags bindingrequest
+// @Param name path string true "binding request name"
+// @Param decision body decisionBody false "message of approval"
+// @Success 200 {object} userv1.BindingRequest
+// @Failure 400 {object} errcode.ErrorInfo
+// @Failure 403 {object} errcode.ErrorInfo
+// @Router /api/v1/kube/bindingrequests/{name}/approve  [post]
+func (h *handler) approveBinding(c *gin.Context) {
+	h.decide(c, audit.ApproveBinding, userv1.BindingRequestApproved)
+}
+
+// rejectBinding rejects binding request
+// @Summary reject temporary role request
+// @Description reject requested scope binding
+// @Tags bindingrequest
+// @Param name path string true "binding request name"
+// @Param decision body decisionBody false "reason of rejection"
+// @Success 200 {object} userv1.BindingRequest
+// @Failure 400 {object} errcode.ErrorInfo
+// @Failure 403 {object} errcode.ErrorInfo
+// @Router /api/v1/kube/bindingrequests/{name}/reject  [post]
+func (h *handler) rejectBinding(c *gin.Context) {
+	h.decide(c, audit.RejectBinding, userv1.BindingRequestRejected)
+}
+
+func (h *handler) decide(c *gin.Context, event *audit.EventInfo, phase string) {
+	ctx := c.Request.Context()
+	name := c.Param("name")
+	approver := c.GetString(constants.UserName)
+
+	body := decisionBody{}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			response.FailReturn(c, errcode.InvalidBodyFormat)
+			return
+		}
+	}
+	c = audit.SetAuditInfo(c, event, name, body)
+
+	req := &userv1.BindingRequest{}
+	if err := h.Direct().Get(ctx, types.NamespacedName{Name: name}, req); err != nil {
+		response.FailReturn(c, errcode.BadRequest(err))
+		return
+	}
+	if req.Status.Phase == userv1.BindingRequestApproved || req.Status.Phase == userv1.BindingRequestRejected {
+		response.FailReturn(c, errcode.CustomReturn(http.StatusBadRequest, "binding request %v was already %v", name, req.Status.Phase))
+		return
+	}
+	if req.Spec.User == approver {
+		response.FailReturn(c, errcode.CustomReturn(http.StatusForbidden, "can not decide on request of yourself"))
+		return
+	}
+
+	u := &userv1.User{}
+	if err := h.Direct().Get(ctx, types.NamespacedName{Name: approver}, u); err != nil {
+		response.FailReturn(c, errcode.ForbiddenErr)
+		return
+	}
+	tenant, err := h.tenantOf(ctx, req.Spec.ScopeType, req.Spec.ScopeName)
+	if err != nil {
+		response.FailReturn(c, errcode.BadRequest(err))
+		return
+	}
+	role := &rbacv1.ClusterRole{}
+	if err = h.Direct().Get(ctx, types.NamespacedName{Name: req.Spec.Role}, role); err != nil {
+		response.FailReturn(c, errcode.BadRequest(err))
+		return
+	}
+	if !CanApprove(u, req.Spec.ScopeType, req.Spec.ScopeName, tenant, time.Now(), role.Rules, h.rulesOf(ctx)) {
+		response.FailReturn(c, errcode.ForbiddenErr)
+		return
+	}
+
+	// request is decided by updating its status first, conflicting decisions
+	// re-read it and find it decided, so scope binding is granted at most once
+	now := metav1.Now()
+	decided := false
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := h.Direct().Get(ctx, types.NamespacedName{Name: name}, req); err != nil {
+			return err
+		}
+		if req.Status.Phase == userv1.BindingRequestApproved || req.Status.Phase == userv1.BindingRequestRejected {
+			decided = true
+			return nil
+		}
+		req.Status.Phase = phase
+		req.Status.Approver = approver
+		req.Status.DecidedAt = &now
+		req.Status.Message = body.Message
+		if phase == userv1.BindingRequestApproved {
+			expiresAt := metav1.NewTime(now.Add(req.Spec.Duration.Duration))
+			req.Status.ExpiresAt = &expiresAt
+		}
+		return h.Direct().Status().Update(ctx, req)
+	})
+	if err != nil {
+		response.FailReturn(c, errcode.CustomReturn(http.StatusInternalServerError, err.Error()))
+		return
+	}
+	if decided {
+		response.FailReturn(c, errcode.CustomReturn(http.StatusBadRequest, "binding request %v was already %v", name, req.Status.Phase))
+		return
+	}
+
+	if phase == userv1.BindingRequestApproved {
+		if err = h.grant(ctx, req, *req.Status.ExpiresAt); err != nil {
+			// request is pending again so that it can be approved later
+			if resetErr := h.resetDecision(ctx, name); resetErr != nil {
+				clog.Error("reset decision of binding request %v failed: %v", name, resetErr)
+			}
+			response.FailReturn(c, errcode.BadRequest(err))
+			return
+		}
+		clog.Info("binding request %v of user %v approved by %v until %v", name, req.Spec.User, approver, req.Status.ExpiresAt)
+	}
+
+	response.SuccessReturn(c, req)
+}
+
+// resetDecision sets binding request back to pending
+func (h *handler) resetDecision(ctx context.Context, name string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		r := &userv1.BindingRequest{}
+		if err := h.Direct().Get(ctx, types.NamespacedName{Name: name}, r); err != nil {
+			return err
+		}
+		r.Status.Phase = userv1.BindingRequestPending
+		r.Status.Approver = ""
+		r.Status.DecidedAt = nil
+		r.Status.ExpiresAt = nil
+		r.Status.Message = ""
+		return h.Direct().Status().Update(ctx, r)
+	})
+}
+
+// grant adds requested scope binding to user with expiration
+func (h *handler) grant(ctx context.Context, req *userv1.BindingRequest, expiresAt metav1.Time) error {
+	u := &userv1.User{}
+	if err := h.Direct().Get(ctx, types.NamespacedName{Name: req.Spec.User}, u); err != nil {
+		return err
+	}
+	reason := fmt.Sprintf("%v (request %v)", req.Spec.Reason, req.Name)
+	transition.GrantTemporaryScopeBinding(u, string(req.Spec.ScopeType), req.Spec.ScopeName, req.Spec.Role, expiresAt, reason)
+	return transition.UpdateUserSpec(ctx, h.Direct(), u)
+}
+
+// tenantOf returns tenant the scope belongs to, empty for platform scope
+func (h *handler) tenantOf(ctx context.Context, scopeType userv1.BindingScopeType, scopeName string) (string, error) {
+	switch scopeType {
+	case userv1.PlatformScope:
+		return "", nil
+	case userv1.TenantScope:
+		return scopeName, h.Direct().Get(ctx, types.NamespacedName{Name: scopeName}, &tenantv1.Tenant{})
+	case userv1.ProjectScope:
+		p := &tenantv1.Project{}
+		if err := h.Direct().Get(ctx, types.NamespacedName{Name: scopeName}, p); err != nil {
+			return "", err
+		}
+		return p.Labels[constants.TenantLabel], nil
+	}
+	return "", errors.NewBadRequest(fmt.Sprintf("unknown scope type %v", scopeType))
+}
+
+// rulesOf returns rules of cluster role by name, nil if not found
+func (h *handler) rulesOf(ctx context.Context) func(role string) []rbacv1.PolicyRule {
+	return func(role string) []rbacv1.PolicyRule {
+		r := &rbacv1.ClusterRole{}
+		if err := h.Direct().Get(ctx, types.NamespacedName{Name: role}, r); err != nil {
+			clog.Warn("get cluster role %v failed: %v", role, err)
+			return nil
+		}
+		return r.Rules
+	}
+}
+
+// CanApprove returns true if user is admin of the scope and the admin role
+// covers rules of requested role, admins of tenant are able to approve
+// requests of projects of tenant and platform admins are able to approve
+// all requests
+func CanApprove(u *userv1.User, scopeType userv1.BindingScopeType, scopeName, tenant string, now time.Time,
+	requested []rbacv1.PolicyRule, rulesOf func(role string) []rbacv1.PolicyRule) bool {
+	for _, b := range userv1.ActiveScopeBindings(u.Spec.ScopeBindings, now) {
+		switch {
+		case b.ScopeType == userv1.PlatformScope && b.Role == constants.PlatformAdmin:
+			return true
+		case scopeType == userv1.PlatformScope:
+			continue
+		case b.ScopeType == userv1.TenantScope && b.Role == constants.TenantAdmin && b.ScopeName == tenant,
+			scopeType == userv1.ProjectScope && b.ScopeType == userv1.ProjectScope && b.Role == constants.ProjectAdmin && b.ScopeName == scopeName:
+			if rbac.Covers(rulesOf(b.Role), requested) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -18,6 +18,7 @@ package cluster
 
 import (
 	"encoding/base64"
+	"fmt"
 	"net/http"
 	"sort"
 	"strconv"
@@ -32,6 +33,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	userinfo "k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	clusterv1 "github.com/saashqdev/kubeworkz/pkg/apis/cluster/v1"
@@ -45,8 +47,10 @@ import (
 	"github.com/saashqdev/kubeworkz/pkg/quota"
 	"github.com/saashqdev/kubeworkz/pkg/utils/access"
 	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+	"github.com/saashqdev/kubeworkz/pkg/utils/env"
 	"github.com/saashqdev/kubeworkz/pkg/utils/errcode"
 	"github.com/saashqdev/kubeworkz/pkg/utils/kubeconfig"
+	"github.com/saashqdev/kubeworkz/pkg/utils/lifecycle"
 	"github.com/saashqdev/kubeworkz/pkg/utils/response"
 	"github.com/saashqdev/kubeworkz/pkg/utils/transition"
 )
@@ -64,6 +68,8 @@ func (h *handler) AddApisTo(root *gin.Engine) {
 	r.POST("register", h.registerCluster)
 	r.POST("add", h.addCluster)
 	r.POST("nsquota", h.createNsAndQuota)
+	r.GET("/:cluster/namespaces/:namespace/lifecycle", h.getNamespaceLifecycle)
+	r.PUT("/:cluster/namespaces/:namespace/ttl", h.extendNamespaceTTL)
 	r.GET("kuberesourcequotas", h.getKubeResourceQuota)
 }
 
@@ -619,13 +625,17 @@ type nsAndQuota struct {
 	Cluster            string                         `json:"cluster"`
 	SubNamespaceAnchor *transition.SubnamespaceAnchor `json:"subNamespaceAnchor"`
 	ResourceQuota      *v1.ResourceQuota              `json:"resourceQuota"`
+	// TTLSeconds namespace will be deleted after ttl, never expire if not set
+	TTLSeconds *int64 `json:"ttlSeconds,omitempty"`
 }
 
 // createNsAndQuota create quota when rbac was spread to new namespace
 // @Summary create subNamespace and resourceQuota
-// @Description create subNamespace and resourceQuota
+// @Description create subNamespace and resourceQuota, returns "success" after both created. With async=true, returns lifecycle
+// @Description status once namespace created, resourceQuota will be created by warden after rbac spread, poll lifecycle api for status
 // @Tags cluster
 // @Param nsAndQuota body nsAndQuota true "ns and quota data"
+// @Param async query bool false "provision namespace asynchronously"
 // @Success 200 {string} string "success"
 // @Failure 400 {object} errcode.ErrorInfo
 // @Failure 500 {object} errcode.ErrorInfo
@@ -637,9 +647,17 @@ func (h *handler) createNsAndQuota(c *gin.Context) {
 		response.FailReturn(c, errcode.CustomReturn(http.StatusBadRequest, err.Error()))
 		return
 	}
+	if data.SubNamespaceAnchor == nil {
+		response.FailReturn(c, errcode.InvalidBodyFormat)
+		return
+	}
 
 	username := c.GetString(constants.UserName)
 	cli := clients.Interface().Kubernetes(data.Cluster)
+	if cli == nil {
+		response.FailReturn(c, errcode.ClusterNotFoundError(data.Cluster))
+		return
+	}
 	ctx := c.Request.Context()
 
 	ns := transition.SubNs2Ns(data.SubNamespaceAnchor)
@@ -648,28 +666,17 @@ func (h *handler) createNsAndQuota(c *gin.Context) {
 		return
 	}
 
-	// create namespace directly
-	err = cli.Direct().Create(ctx, ns)
-	if err != nil {
-		response.FailReturn(c, errcode.BadRequest(err))
-		return
-	}
-
-	rollback := func() {
-		err := cli.Direct().Delete(ctx, ns)
-		if err != nil {
-			clog.Error(err.Error())
+	if data.TTLSeconds != nil {
+		if err := checkNamespaceTTL(*data.TTLSeconds); err != nil {
+			response.FailReturn(c, err)
+			return
 		}
+		expireAt := time.Now().Add(time.Duration(*data.TTLSeconds) * time.Second)
+		lifecycle.SetExpireAt(ns, &expireAt)
 	}
 
-	const (
-		retryCount    = 20
-		retryInterval = 100 * time.Millisecond
-	)
-
 	_, clusterRoles, err := h.Interface.RolesFor(&userinfo.DefaultInfo{Name: username}, "")
 	if err != nil {
-		rollback()
 		response.FailReturn(c, errcode.CustomReturn(http.StatusBadRequest, err.Error()))
 		return
 	}
@@ -684,6 +691,49 @@ func (h *handler) createNsAndQuota(c *gin.Context) {
 		}
 	}
 
+	async := c.Query("async") == "true"
+	if async && toWait {
+		// resource quota will be created by warden after rbac spread
+		lifecycle.SetPhase(ns, lifecycle.PhasePending, "")
+		if data.ResourceQuota != nil {
+			if err = lifecycle.SetPendingQuota(ns, data.ResourceQuota); err != nil {
+				response.FailReturn(c, errcode.BadRequest(err))
+				return
+			}
+		}
+
+		err = cli.Direct().Create(ctx, ns)
+		if err != nil {
+			response.FailReturn(c, errcode.BadRequest(err))
+			return
+		}
+
+		clog.Debug("user %v create ns %v in cluster %v success, waiting for provisioned",
+			username, data.SubNamespaceAnchor.Name, data.Cluster)
+
+		response.SuccessReturn(c, lifecycle.StatusOf(ns))
+		return
+	}
+
+	// create namespace directly
+	err = cli.Direct().Create(ctx, ns)
+	if err != nil {
+		response.FailReturn(c, errcode.BadRequest(err))
+		return
+	}
+
+	rollback := func() {
+		err := cli.Direct().Delete(ctx, ns)
+		if err != nil {
+			clog.Error(err.Error())
+		}
+	}
+
+	const (
+		retryCount    = 20
+		retryInterval = 100 * time.Millisecond
+	)
+
 	// wait for rbac resources spread
 	count := 0
 	for toWait {
@@ -707,17 +757,139 @@ func (h *handler) createNsAndQuota(c *gin.Context) {
 	}
 
 	// final action failed would rollback whole action
-	err = cli.Direct().Create(ctx, data.ResourceQuota)
+	if data.ResourceQuota != nil {
+		err = cli.Direct().Create(ctx, data.ResourceQuota)
+		if err != nil {
+			rollback()
+			response.FailReturn(c, errcode.CustomReturn(http.StatusBadRequest, err.Error()))
+			return
+		}
+	}
+
+	clog.Debug("user %v create ns %v and resourceQuota in cluster %v success",
+		username, data.SubNamespaceAnchor.Name, data.Cluster)
+
+	if async {
+		response.SuccessReturn(c, lifecycle.StatusOf(ns))
+		return
+	}
+	response.SuccessJsonReturn(c, "success")
+}
+
+// getNamespaceLifecycle returns lifecycle status of namespace
+// @Summary get lifecycle of namespace
+// @Description get provision phase and expire time of namespace
+// @Tags cluster
+// @Param cluster path string true "cluster name"
+// @Param namespace path string true "namespace name"
+// @Success 200 {object} lifecycle.Status
+// @Failure 400 {object} errcode.ErrorInfo
+// @Failure 403 {object} errcode.ErrorInfo
+// @Router /api/v1/kube/clusters/{cluster}/namespaces/{namespace}/lifecycle  [get]
+func (h *handler) getNamespaceLifecycle(c *gin.Context) {
+	cluster, namespace := c.Param("cluster"), c.Param("namespace")
+	cli := clients.Interface().Kubernetes(cluster)
+	if cli == nil {
+		response.FailReturn(c, errcode.ClusterNotFoundError(cluster))
+		return
+	}
+
+	ns := &v1.Namespace{}
+	err := cli.Direct().Get(c.Request.Context(), types.NamespacedName{Name: namespace}, ns)
 	if err != nil {
-		rollback()
-		response.FailReturn(c, errcode.CustomReturn(http.StatusBadRequest, err.Error()))
+		response.FailReturn(c, errcode.BadRequest(err))
+		return
+	}
+
+	if !access.AllowAccess(cluster, c.Request, constants.GetVerb, ns) {
+		response.FailReturn(c, errcode.ForbiddenErr)
 		return
 	}
 
-	clog.Debug("user %v create ns %v and resourceQuota %v in cluster %v success",
-		username, data.SubNamespaceAnchor.Name, data.ResourceQuota.Name, data.Cluster)
+	response.SuccessReturn(c, lifecycle.StatusOf(ns))
+}
 
-	response.SuccessJsonReturn(c, "success")
+// checkNamespaceTTL ensures ttl of namespace is positive and not longer than
+// the max ttl configured
+func checkNamespaceTTL(ttlSeconds int64) *errcode.ErrorInfo {
+	if ttlSeconds <= 0 {
+		return errcode.CustomReturn(http.StatusBadRequest, "ttlSeconds must be positive")
+	}
+	maxTTLSeconds := int64(env.NamespaceMaxTTL() / time.Second)
+	if ttlSeconds > maxTTLSeconds {
+		return errcode.CustomReturn(http.StatusBadRequest, "ttlSeconds must not be greater than %v", maxTTLSeconds)
+	}
+	return nil
+}
+
+type namespaceTTL struct {
+	// TTLSeconds namespace will be deleted after ttl from now on
+	TTLSeconds int64 `json:"ttlSeconds"`
+}
+
+// extendNamespaceTTL resets expire time of namespace, members who can update
+// the namespace are allowed
+// @Summary extend ttl of namespace
+// @Description reset expire time of namespace to now plus ttl
+// @Tags cluster
+// @Param cluster path string true "cluster name"
+// @Param namespace path string true "namespace name"
+// @Param namespaceTTL body namespaceTTL true "ttl of namespace"
+// @Success 200 {object} lifecycle.Status
+// @Failure 400 {object} errcode.ErrorInfo
+// @Failure 403 {object} errcode.ErrorInfo
+// @Router /api/v1/kube/clusters/{cluster}/namespaces/{namespace}/ttl  [put]
+func (h *handler) extendNamespaceTTL(c *gin.Context) {
+	cluster, namespace := c.Param("cluster"), c.Param("namespace")
+	data := &namespaceTTL{}
+	err := c.ShouldBindJSON(data)
+	if err != nil {
+		response.FailReturn(c, errcode.InvalidBodyFormat)
+		return
+	}
+	if errInfo := checkNamespaceTTL(data.TTLSeconds); errInfo != nil {
+		response.FailReturn(c, errInfo)
+		return
+	}
+
+	cli := clients.Interface().Kubernetes(cluster)
+	if cli == nil {
+		response.FailReturn(c, errcode.ClusterNotFoundError(cluster))
+		return
+	}
+
+	// namespace is authorized within itself, same as kube-apiserver does
+	target := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace, Namespace: namespace}}
+	if !access.AllowAccess(cluster, c.Request, constants.UpdateVerb, target) {
+		response.FailReturn(c, errcode.ForbiddenErr)
+		return
+	}
+
+	ctx := c.Request.Context()
+	ns := &v1.Namespace{}
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		err := cli.Direct().Get(ctx, types.NamespacedName{Name: namespace}, ns)
+		if err != nil {
+			return err
+		}
+		if lifecycle.ExpireAt(ns) == nil {
+			return fmt.Errorf("namespace %v never expires", namespace)
+		}
+		expireAt := time.Now().Add(time.Duration(data.TTLSeconds) * time.Second)
+		lifecycle.SetExpireAt(ns, &expireAt)
+		if lifecycle.StatusOf(ns).Phase == lifecycle.PhaseExpiring {
+			lifecycle.SetPhase(ns, lifecycle.PhaseReady, "")
+		}
+		return cli.Direct().Update(ctx, ns)
+	})
+	if err != nil {
+		response.FailReturn(c, errcode.BadRequest(err))
+		return
+	}
+
+	clog.Info("user %v extend ttl of namespace %v in cluster %v to %v", c.GetString(constants.UserName), namespace, cluster, ns.Annotations[lifecycle.ExpireAtAnnotation])
+
+	response.SuccessReturn(c, lifecycle.StatusOf(ns))
 }
 
 type getKubeResourceQuotaResp struct {
@@ -0,0 +1,121 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hotplug
+
+import (
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	hotplugv1 "github.com/saashqdev/kubeworkz/pkg/apis/hotplug/v1"
+	"github.com/saashqdev/kubeworkz/pkg/clients"
+	"github.com/saashqdev/kubeworkz/pkg/multicluster"
+	mgrclient "github.com/saashqdev/kubeworkz/pkg/multicluster/client"
+	"github.com/saashqdev/kubeworkz/pkg/utils/access"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+	"github.com/saashqdev/kubeworkz/pkg/utils/errcode"
+	"github.com/saashqdev/kubeworkz/pkg/utils/hotplug"
+	"github.com/saashqdev/kubeworkz/pkg/utils/response"
+)
+
+const (
+	subPath = "/hotplugs"
+
+	// commonHotplug is the name of hotplug shared by all clusters
+	commonHotplug = "common"
+)
+
+type handler struct {
+	mgrclient.Client
+}
+
+func NewHandler() *handler {
+	h := new(handler)
+	h.Client = clients.Interface().Kubernetes(constants.LocalCluster)
+	return h
+}
+
+func (h *handler) AddApisTo(root *gin.Engine) {
+	r := root.Group(constants.ApiPathRoot + subPath)
+	r.POST("/:cluster/dryrun", h.dryRun)
+}
+
+// dryRun renders components of cluster merged from common and cluster hotplug
+// and diffs them with releases in cluster without changing anything
+// @Summary dry-run hotplug of cluster
+// @Description merge common hotplug with proposed or current cluster hotplug, render charts by helm dry-run and diff manifests with current releases
+// @Tags hotplug
+// @Param cluster path string true "cluster name"
+// @Param component query string false "only preview given component"
+// @Param spec body hotplugv1.HotplugSpec false "proposed spec of cluster hotplug, current one is used if empty"
+// @Success 200 {array} hotplug.ComponentPreview
+// @Failure 400 {object} errcode.ErrorInfo
+// @Failure 403 {object} errcode.ErrorInfo
+// @Router /api/v1/kube/hotplugs/{cluster}/dryrun  [post]
+func (h *handler) dryRun(c *gin.Context) {
+	ctx := c.Request.Context()
+	cluster := c.Param("cluster")
+
+	commonConfig := hotplugv1.Hotplug{}
+	if err := h.Direct().Get(ctx, types.NamespacedName{Name: commonHotplug}, &commonConfig); err != nil {
+		response.FailReturn(c, errcode.BadRequest(err))
+		return
+	}
+
+	if !access.AllowAccess(constants.LocalCluster, c.Request, constants.UpdateVerb, &commonConfig) {
+		response.FailReturn(c, errcode.ForbiddenErr)
+		return
+	}
+
+	clusterConfig := hotplugv1.Hotplug{}
+	err := h.Direct().Get(ctx, types.NamespacedName{Name: cluster}, &clusterConfig)
+	if err != nil && !errors.IsNotFound(err) {
+		response.FailReturn(c, errcode.BadRequest(err))
+		return
+	}
+
+	// proposed spec overrides the stored one of cluster
+	if c.Request.ContentLength != 0 {
+		spec := hotplugv1.HotplugSpec{}
+		if err = c.ShouldBindJSON(&spec); err != nil {
+			response.FailReturn(c, errcode.InvalidBodyFormat)
+			return
+		}
+		clusterConfig.Spec = spec
+	}
+
+	internalCluster, err := multicluster.Interface().Get(cluster)
+	if err != nil {
+		response.FailReturn(c, errcode.ClusterNotFoundError(cluster))
+		return
+	}
+
+	merged := hotplug.MergeHotplugForCluster(commonConfig, clusterConfig, cluster)
+	components := merged.Spec.Component
+	if name := c.Query("component"); len(name) > 0 {
+		components = nil
+		for _, component := range merged.Spec.Component {
+			if component.Name == name {
+				components = append(components, component)
+			}
+		}
+	}
+
+	helm := hotplug.NewHelmForConfig(internalCluster.Config)
+	charts := hotplug.NewChartLoader(internalCluster.Client.Direct())
+	response.SuccessReturn(c, hotplug.Preview(ctx, helm, charts, components))
+}
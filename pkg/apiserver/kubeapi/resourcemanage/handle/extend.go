@@ -40,6 +40,8 @@ import (
 
 var (
 	extendFuncMap = make(map[string]ExtendFunc)
+	// defaultExtendFunc handles resource type which has no handler registered
+	defaultExtendFunc ExtendFunc
 )
 
 type ExtendHandler struct {
@@ -64,6 +66,12 @@ func SetExtendHandler(resource enum.ResourceTypeEnum, extendFunc ExtendFunc) {
 	extendFuncMap[string(resource)] = extendFunc
 }
 
+// SetDefaultExtendHandler the func to register handler func for resource
+// types without real handler func
+func SetDefaultExtendHandler(extendFunc ExtendFunc) {
+	defaultExtendFunc = extendFunc
+}
+
 // ExtendHandle api/v1/kube/extend/clusters/{cluster}/namespaces/{namespace}/{resourceType}
 func (e *ExtendHandler) ExtendHandle(c *gin.Context) {
 	// request param
@@ -71,7 +79,11 @@ func (e *ExtendHandler) ExtendHandle(c *gin.Context) {
 	namespace := c.Param("namespace")
 	resourceType := c.Param("resourceType")
 	resourceName := c.Param("resourceName")
-	condition := parseQueryParams(c)
+	condition, err := parseQueryParams(c)
+	if err != nil {
+		response.FailReturn(c, errcode.BadRequest(err))
+		return
+	}
 	httpMethod := c.Request.Method
 
 	// k8s client
@@ -89,6 +101,7 @@ func (e *ExtendHandler) ExtendHandle(c *gin.Context) {
 		ResourceName:             resourceName,
 		FilterCondition:          condition,
 		Action:                   httpMethod,
+		ResourceType:             resourceType,
 		Username:                 username,
 		NginxNamespace:           e.NginxNamespace,
 		NginxTcpServiceConfigMap: e.NginxTcpServiceConfigMap,
@@ -99,8 +112,13 @@ func (e *ExtendHandler) ExtendHandle(c *gin.Context) {
 
 	clog.Debug("request extend api with method (%v), cluster (%v), namespace (%v), resource type (%v), resource name (%v)", httpMethod, cluster, namespace, resourceType, resourceName)
 
-	// get real handler func and work, if not found, return not support error
-	if extendFunc, ok := extendFuncMap[resourceType]; ok {
+	// get real handler func and work, if not found, fall back to default
+	// handler func, return not support error if neither exists
+	extendFunc, ok := extendFuncMap[resourceType]
+	if !ok {
+		extendFunc = defaultExtendFunc
+	}
+	if extendFunc != nil {
 		result, errInfo := extendFunc(extendCtx)
 		if errInfo != nil {
 			clog.Error("get extend res err, resourceType: %s, error: %s", resourceType, errInfo.Message)
@@ -119,7 +137,11 @@ func (e *ExtendHandler) ExtendHandle(c *gin.Context) {
 func GetPodContainerLog(c *gin.Context) {
 	cluster := c.Param("cluster")
 	namespace := c.Param("namespace")
-	condition := parseQueryParams(c)
+	condition, err := parseQueryParams(c)
+	if err != nil {
+		response.FailReturn(c, errcode.BadRequest(err))
+		return
+	}
 	// k8s client
 	client := clients.Interface().Kubernetes(cluster)
 	if client == nil {
@@ -141,7 +163,11 @@ func GetPodContainerLog(c *gin.Context) {
 func GetProxyPodContainerLog(c *gin.Context) {
 	cluster := c.Param("cluster")
 	namespace := c.Param("namespace")
-	condition := parseQueryParams(c)
+	condition, err := parseQueryParams(c)
+	if err != nil {
+		response.FailReturn(c, errcode.BadRequest(err))
+		return
+	}
 	// k8s client
 	client := clients.Interface().Kubernetes(cluster)
 	if client == nil {
@@ -0,0 +1,304 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcemanage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources"
+	"github.com/saashqdev/kubeworkz/pkg/clients"
+	"github.com/saashqdev/kubeworkz/pkg/clog"
+	"github.com/saashqdev/kubeworkz/pkg/multicluster/client"
+	"github.com/saashqdev/kubeworkz/pkg/utils/archive"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+	"github.com/saashqdev/kubeworkz/pkg/utils/errcode"
+	"github.com/saashqdev/kubeworkz/pkg/utils/response"
+)
+
+const (
+	// defaultLogLimitBytes the default size limit of log of each container
+	defaultLogLimitBytes = 10 << 20
+	// maxLogArchiveBytes the size limit of all logs in archive
+	maxLogArchiveBytes = 200 << 20
+)
+
+// LogArchive collects logs of pods into tar.gz archive
+type LogArchive struct {
+	client     client.Client
+	namespace  string
+	container  string
+	previous   bool
+	sinceTime  *metav1.Time
+	limitBytes int64
+	// remain the bytes of logs can still be added into archive
+	remain int64
+}
+
+// logFile a log of container to be archived
+type logFile struct {
+	pod       string
+	container string
+	previous  bool
+}
+
+func (f logFile) name() string {
+	if f.previous {
+		return path.Join(f.pod, f.container+".previous.log")
+	}
+	return path.Join(f.pod, f.container+".log")
+}
+
+// files returns logs of all containers of pods, previous logs are included
+// only for containers restarted
+func (a *LogArchive) files(pods []v1.Pod) []logFile {
+	var files []logFile
+	for _, pod := range pods {
+		restarts := make(map[string]int32)
+		for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+			restarts[status.Name] = status.RestartCount
+		}
+		for _, c := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+			if len(a.container) > 0 && c.Name != a.container {
+				continue
+			}
+			if a.previous && restarts[c.Name] > 0 {
+				files = append(files, logFile{pod: pod.Name, container: c.Name, previous: true})
+			}
+			files = append(files, logFile{pod: pod.Name, container: c.Name})
+		}
+	}
+	return files
+}
+
+// Write writes logs of pods into w, logs are skipped once size limit of
+// archive is reached
+func (a *LogArchive) Write(ctx context.Context, pods []v1.Pod, w *archive.Writer) error {
+	var skipped []string
+	for _, f := range a.files(pods) {
+		if a.remain <= 0 {
+			skipped = append(skipped, f.name())
+			continue
+		}
+		if err := a.add(ctx, f, w); err != nil {
+			return err
+		}
+	}
+	if len(skipped) > 0 {
+		note := fmt.Sprintf("logs exceed size limit of archive %v bytes, skipped:\n", maxLogArchiveBytes)
+		for _, name := range skipped {
+			note += name + "\n"
+		}
+		return w.AddFile("SKIPPED.txt", []byte(note))
+	}
+	return nil
+}
+
+// add spools log of container into temp file so that size of it is known
+// before written into archive, partial log is kept with error appended if
+// reading log failed
+func (a *LogArchive) add(ctx context.Context, f logFile, w *archive.Writer) error {
+	tmp, err := os.CreateTemp("", "kubeworkz-log-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+
+	size, err := a.read(ctx, f, tmp)
+	a.remain -= size
+	if err != nil {
+		// container may be not started yet, the error is kept in archive
+		clog.Debug("read log of %v failed: %v", f.name(), err)
+		note := fmt.Sprintf("read log failed: %v\n", err)
+		if size > 0 {
+			note = "\n" + note
+		}
+		n, err := tmp.WriteString(note)
+		if err != nil {
+			return err
+		}
+		size += int64(n)
+	}
+	if _, err = tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return w.AddReader(f.name(), tmp, size)
+}
+
+// read copies log of container into dst, returns bytes copied
+func (a *LogArchive) read(ctx context.Context, f logFile, dst io.Writer) (int64, error) {
+	limit := a.limitBytes
+	if limit > a.remain {
+		limit = a.remain
+	}
+	opts := &v1.PodLogOptions{
+		Container:  f.container,
+		Previous:   f.previous,
+		Timestamps: true,
+		SinceTime:  a.sinceTime,
+		LimitBytes: &limit,
+	}
+	stream, err := a.client.ClientSet().CoreV1().Pods(a.namespace).GetLogs(f.pod, opts).Stream(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer stream.Close()
+	// kubelet may not honor limit exactly, so read no more than limit anyway
+	return io.Copy(dst, io.LimitReader(stream, limit))
+}
+
+// GetPodLogArchive downloads logs of pod or all pods of workload
+// @Summary Download pod logs
+// @Description download current and previous logs of all containers of pod, or pods matching selector or owned by workload, as tar.gz archive with one file per container
+// @Tags extend
+// @Param cluster path string true "cluster name"
+// @Param namespace path string true "namespace"
+// @Param pod query string false "pod name"
+// @Param workload query string false "workload like deployments/{name} or jobs/{name}"
+// @Param labelSelector query string false "label selector of pods"
+// @Param container query string false "container name"
+// @Param previous query bool false "include logs of previous instance of restarted containers, default true"
+// @Param sinceSeconds query int false "only logs newer than seconds"
+// @Param sinceTime query string false "only logs newer than RFC3339 time"
+// @Param limitBytes query int false "size limit of log of each container, default 10MiB"
+// @Success 200 {file} file "tar.gz archive"
+// @Failure 400 {object} errcode.ErrorInfo
+// @Failure 403 {object} errcode.ErrorInfo
+// @Router /api/v1/kube/extend/clusters/{cluster}/namespaces/{namespace}/logarchive [get]
+func GetPodLogArchive(c *gin.Context) {
+	cluster := c.Param("cluster")
+	namespace := c.Param("namespace")
+	cli := clients.Interface().Kubernetes(cluster)
+	if cli == nil {
+		response.FailReturn(c, errcode.ClusterNotFoundError(cluster))
+		return
+	}
+	username := c.GetString(constants.UserName)
+	access := resources.NewSimpleAccess(cluster, username, namespace)
+	if allow := access.AccessAllow("", "pods", "list"); !allow {
+		response.FailReturn(c, errcode.ForbiddenErr)
+		return
+	}
+
+	a, err := newLogArchive(c, cli, namespace)
+	if err != nil {
+		response.FailReturn(c, errcode.BadRequest(err))
+		return
+	}
+	pods, fileName, err := archivedPods(c, cli, namespace)
+	if err != nil {
+		response.FailReturn(c, errcode.BadRequest(err))
+		return
+	}
+
+	c.Header(constants.HttpHeaderContentType, "application/gzip")
+	c.Header(constants.HttpHeaderContentDisposition, fmt.Sprintf("attachment; filename=%v-logs.tar.gz", fileName))
+	c.Status(http.StatusOK)
+
+	// headers already sent, errors can only be logged from now on
+	w := archive.NewWriter(c.Writer)
+	if err = a.Write(c.Request.Context(), pods, w); err != nil {
+		clog.Error("archive logs of %v failed: %v", fileName, err)
+	}
+	if err = w.Close(); err != nil {
+		clog.Error("close log archive of %v failed: %v", fileName, err)
+	}
+}
+
+func newLogArchive(c *gin.Context, cli client.Client, namespace string) (*LogArchive, error) {
+	a := &LogArchive{
+		client:     cli,
+		namespace:  namespace,
+		container:  c.Query("container"),
+		previous:   c.Query("previous") != "false",
+		limitBytes: defaultLogLimitBytes,
+		remain:     maxLogArchiveBytes,
+	}
+	if v := c.Query("limitBytes"); len(v) > 0 {
+		limit, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || limit <= 0 || limit > maxLogArchiveBytes {
+			return nil, fmt.Errorf("invalid limitBytes %v, should be in (0, %v]", v, maxLogArchiveBytes)
+		}
+		a.limitBytes = limit
+	}
+	sinceSeconds, sinceTime := c.Query("sinceSeconds"), c.Query("sinceTime")
+	switch {
+	case len(sinceSeconds) > 0 && len(sinceTime) > 0:
+		return nil, fmt.Errorf("only one of sinceSeconds and sinceTime can be given")
+	case len(sinceSeconds) > 0:
+		seconds, err := strconv.ParseInt(sinceSeconds, 10, 64)
+		if err != nil || seconds <= 0 {
+			return nil, fmt.Errorf("invalid sinceSeconds %v", sinceSeconds)
+		}
+		a.sinceTime = &metav1.Time{Time: time.Now().Add(-time.Duration(seconds) * time.Second)}
+	case len(sinceTime) > 0:
+		t, err := time.Parse(time.RFC3339, sinceTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sinceTime %v: %v", sinceTime, err)
+		}
+		a.sinceTime = &metav1.Time{Time: t}
+	}
+	return a, nil
+}
+
+// archivedPods returns pods given by pod, workload or labelSelector query,
+// and the name of archive
+func archivedPods(c *gin.Context, cli client.Client, namespace string) ([]v1.Pod, string, error) {
+	ctx := c.Request.Context()
+	if name := c.Query("pod"); len(name) > 0 {
+		pod := v1.Pod{}
+		if err := cli.Cache().Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &pod); err != nil {
+			return nil, "", err
+		}
+		return []v1.Pod{pod}, name, nil
+	}
+
+	selector, err := podSelector(c, cli, namespace)
+	if err != nil {
+		return nil, "", err
+	}
+	pods := v1.PodList{}
+	err = cli.Cache().List(ctx, &pods, ctrlclient.InNamespace(namespace), ctrlclient.MatchingLabelsSelector{Selector: selector})
+	if err != nil {
+		return nil, "", err
+	}
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[i].Name < pods.Items[j].Name
+	})
+	fileName := namespace
+	if _, name, found := strings.Cut(c.Query("workload"), "/"); found {
+		fileName = name
+	}
+	return pods.Items, fileName, nil
+}
@@ -0,0 +1,455 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcemanage
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources"
+	"github.com/saashqdev/kubeworkz/pkg/clients"
+	"github.com/saashqdev/kubeworkz/pkg/clog"
+	"github.com/saashqdev/kubeworkz/pkg/multicluster/client"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+	"github.com/saashqdev/kubeworkz/pkg/utils/errcode"
+	"github.com/saashqdev/kubeworkz/pkg/utils/response"
+)
+
+const (
+	// maxLogStreams limits containers followed by one log stream
+	maxLogStreams = 50
+	// logResolvePeriod the period to pick up new pods and restarted containers
+	logResolvePeriod = 10 * time.Second
+	defaultTailLines = 100
+)
+
+// LogLine a line of container log
+type LogLine struct {
+	Pod       string `json:"pod"`
+	Container string `json:"container"`
+	Timestamp string `json:"timestamp"`
+	Line      string `json:"line"`
+}
+
+// String formats line with pod and container prefix
+func (l LogLine) String() string {
+	return fmt.Sprintf("[%s/%s] %s %s", l.Pod, l.Container, l.Timestamp, l.Line)
+}
+
+// LogEvent a notice of log stream sent to client besides lines
+type LogEvent struct {
+	Message string `json:"message"`
+}
+
+// parseLogLine splits the timestamp prefixed by kubelet from log line
+func parseLogLine(pod, container, raw string) LogLine {
+	l := LogLine{Pod: pod, Container: container, Line: strings.TrimRight(raw, "\r\n")}
+	if ts, line, found := strings.Cut(l.Line, " "); found {
+		if _, err := time.Parse(time.RFC3339Nano, ts); err == nil {
+			l.Timestamp, l.Line = ts, line
+		}
+	}
+	return l
+}
+
+// LogStream follows logs of all containers of pods matching selector
+type LogStream struct {
+	client       client.Client
+	namespace    string
+	selector     labels.Selector
+	container    string
+	grep         *regexp.Regexp
+	tailLines    int64
+	sinceSeconds *int64
+
+	mu sync.Mutex
+	// followed containers of pods, key is pod/container, value is the
+	// restart count of container when it was followed
+	followed map[string]int32
+	// done containers whose logs ended, they are followed again once restarted
+	done map[string]time.Time
+	// streaming the number of containers whose logs are being read
+	streaming int
+	// limited is true if containers were skipped for limit in last round
+	limited bool
+}
+
+type logTarget struct {
+	pod       string
+	container string
+	restarts  int32
+}
+
+// resolve returns containers of pods matching selector which are not followed
+// and the containers skipped for limit, containers of pods gone are forgotten
+func (s *LogStream) resolve(ctx context.Context) ([]logTarget, []string, error) {
+	pods := v1.PodList{}
+	err := s.client.Cache().List(ctx, &pods, ctrlclient.InNamespace(s.namespace), ctrlclient.MatchingLabelsSelector{Selector: s.selector})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	present := make(map[string]bool)
+	for _, pod := range pods.Items {
+		for _, c := range pod.Spec.Containers {
+			present[pod.Name+"/"+c.Name] = true
+		}
+	}
+	for key := range s.followed {
+		if !present[key] {
+			delete(s.followed, key)
+			delete(s.done, key)
+		}
+	}
+
+	var (
+		targets []logTarget
+		skipped []string
+	)
+	for _, pod := range pods.Items {
+		restarts := make(map[string]int32)
+		for _, status := range pod.Status.ContainerStatuses {
+			restarts[status.Name] = status.RestartCount
+		}
+		for _, c := range pod.Spec.Containers {
+			if len(s.container) > 0 && c.Name != s.container {
+				continue
+			}
+			key := pod.Name + "/" + c.Name
+			if n, ok := s.followed[key]; ok && n == restarts[c.Name] {
+				continue
+			}
+			if s.streaming >= maxLogStreams {
+				skipped = append(skipped, key)
+				continue
+			}
+			s.followed[key] = restarts[c.Name]
+			s.streaming++
+			targets = append(targets, logTarget{pod: pod.Name, container: c.Name, restarts: restarts[c.Name]})
+		}
+	}
+	if len(skipped) > 0 {
+		clog.Warn("containers of log stream exceed %v, skip %v", maxLogStreams, strings.Join(skipped, ","))
+	}
+	return targets, skipped, nil
+}
+
+// run follows logs until ctx done, lines are sent to out, and client is
+// told by events once containers are skipped for limit
+func (s *LogStream) run(ctx context.Context, out chan<- LogLine, events chan<- LogEvent) {
+	ticker := time.NewTicker(logResolvePeriod)
+	defer ticker.Stop()
+	for {
+		targets, skipped, err := s.resolve(ctx)
+		if err != nil {
+			clog.Warn("resolve pods of log stream failed: %v", err)
+		}
+		for _, t := range targets {
+			go s.follow(ctx, t, out)
+		}
+		if len(skipped) > 0 && !s.limited {
+			e := LogEvent{Message: fmt.Sprintf("log stream follows at most %v containers, %v containers are skipped: %v",
+				maxLogStreams, len(skipped), strings.Join(skipped, ","))}
+			select {
+			case events <- e:
+			case <-ctx.Done():
+				return
+			}
+		}
+		s.limited = len(skipped) > 0
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *LogStream) follow(ctx context.Context, t logTarget, out chan<- LogLine) {
+	key := t.pod + "/" + t.container
+	defer func() {
+		s.mu.Lock()
+		s.streaming--
+		s.mu.Unlock()
+	}()
+	opts := &v1.PodLogOptions{Container: t.container, Follow: true, Timestamps: true}
+	s.mu.Lock()
+	ended, restarted := s.done[key]
+	s.mu.Unlock()
+	switch {
+	case restarted:
+		// the new instance of container is read from where the old one ended
+		opts.SinceTime = &metav1.Time{Time: ended}
+	default:
+		opts.TailLines = &s.tailLines
+		opts.SinceSeconds = s.sinceSeconds
+	}
+
+	stream, err := s.client.ClientSet().CoreV1().Pods(s.namespace).GetLogs(t.pod, opts).Stream(ctx)
+	if err != nil {
+		// container may be not started yet, try again in next round
+		clog.Debug("open log stream of %v failed: %v", key, err)
+		s.mu.Lock()
+		delete(s.followed, key)
+		s.mu.Unlock()
+		return
+	}
+	defer stream.Close()
+
+	r := bufio.NewReader(stream)
+	for {
+		raw, err := r.ReadString('\n')
+		if len(raw) > 0 {
+			l := parseLogLine(t.pod, t.container, raw)
+			if s.grep == nil || s.grep.MatchString(l.Line) {
+				select {
+				case out <- l:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	s.mu.Lock()
+	// container is forgotten if its pod was gone meanwhile
+	if _, ok := s.followed[key]; ok {
+		s.done[key] = time.Now()
+	}
+	s.mu.Unlock()
+}
+
+// GetPodLogStream follows logs of all pods of workload or selector
+// @Summary Stream pod logs
+// @Description follow logs of all containers of pods matching selector or owned by workload over WebSocket or Server-Sent Events, a warning is sent once containers exceed the limit of a stream
+// @Tags extend
+// @Param cluster path string true "cluster name"
+// @Param namespace path string true "namespace"
+// @Param workload query string false "workload like deployments/{name} or jobs/{name}"
+// @Param labelSelector query string false "label selector of pods"
+// @Param container query string false "container name"
+// @Param grep query string false "regular expression lines must match"
+// @Param tailLines query int false "lines of each container to begin with, default 100"
+// @Param sinceSeconds query int false "only logs newer than seconds"
+// @Param format query string false "text (default) or json"
+// @Success 200 {object} LogLine
+// @Failure 400 {object} errcode.ErrorInfo
+// @Failure 403 {object} errcode.ErrorInfo
+// @Router /api/v1/kube/extend/clusters/{cluster}/namespaces/{namespace}/logstream [get]
+func GetPodLogStream(c *gin.Context) {
+	cluster := c.Param("cluster")
+	namespace := c.Param("namespace")
+	cli := clients.Interface().Kubernetes(cluster)
+	if cli == nil {
+		response.FailReturn(c, errcode.ClusterNotFoundError(cluster))
+		return
+	}
+	username := c.GetString(constants.UserName)
+	access := resources.NewSimpleAccess(cluster, username, namespace)
+	if allow := access.AccessAllow("", "pods", "list"); !allow {
+		response.FailReturn(c, errcode.ForbiddenErr)
+		return
+	}
+
+	s, err := newLogStream(c, cli, namespace)
+	if err != nil {
+		response.FailReturn(c, errcode.BadRequest(err))
+		return
+	}
+	asJson := c.Query("format") == "json"
+
+	if strings.EqualFold(c.GetHeader("Upgrade"), "websocket") {
+		server := websocket.Server{
+			Handshake: checkSameOrigin,
+			Handler: func(ws *websocket.Conn) {
+				ctx, cancel := context.WithCancel(c.Request.Context())
+				defer cancel()
+				// client closes stream by closing connection
+				go func() {
+					var msg string
+					for websocket.Message.Receive(ws, &msg) == nil {
+					}
+					cancel()
+				}()
+				lines, events := make(chan LogLine), make(chan LogEvent)
+				go s.run(ctx, lines, events)
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case l := <-lines:
+						if asJson {
+							err = websocket.JSON.Send(ws, l)
+						} else {
+							err = websocket.Message.Send(ws, l.String())
+						}
+					case e := <-events:
+						if asJson {
+							err = websocket.JSON.Send(ws, e)
+						} else {
+							err = websocket.Message.Send(ws, "[warning] "+e.Message)
+						}
+					}
+					if err != nil {
+						return
+					}
+				}
+			},
+		}
+		server.ServeHTTP(c.Writer, c.Request)
+		return
+	}
+
+	c.Header(constants.HttpHeaderContentType, "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	lines, events := make(chan LogLine), make(chan LogEvent)
+	go s.run(c.Request.Context(), lines, events)
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case l := <-lines:
+			if asJson {
+				c.SSEvent("log", l)
+			} else {
+				c.SSEvent("log", l.String())
+			}
+		case e := <-events:
+			if asJson {
+				c.SSEvent("warning", e)
+			} else {
+				c.SSEvent("warning", e.Message)
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+// checkSameOrigin rejects cross site websocket requests from browsers
+func checkSameOrigin(config *websocket.Config, req *http.Request) error {
+	origin := req.Header.Get("Origin")
+	if len(origin) == 0 {
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host != req.Host {
+		return fmt.Errorf("cross origin request %v is not allowed", origin)
+	}
+	return nil
+}
+
+func newLogStream(c *gin.Context, cli client.Client, namespace string) (*LogStream, error) {
+	s := &LogStream{
+		client:    cli,
+		namespace: namespace,
+		container: c.Query("container"),
+		tailLines: defaultTailLines,
+		followed:  make(map[string]int32),
+		done:      make(map[string]time.Time),
+	}
+
+	var err error
+	if s.selector, err = podSelector(c, cli, namespace); err != nil {
+		return nil, err
+	}
+
+	if grep := c.Query("grep"); len(grep) > 0 {
+		if s.grep, err = regexp.Compile(grep); err != nil {
+			return nil, fmt.Errorf("invalid grep: %v", err)
+		}
+	}
+	if v := c.Query("tailLines"); len(v) > 0 {
+		if s.tailLines, err = strconv.ParseInt(v, 10, 64); err != nil || s.tailLines < 0 {
+			return nil, fmt.Errorf("invalid tailLines %v", v)
+		}
+	}
+	if v := c.Query("sinceSeconds"); len(v) > 0 {
+		seconds, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || seconds <= 0 {
+			return nil, fmt.Errorf("invalid sinceSeconds %v", v)
+		}
+		s.sinceSeconds = &seconds
+	}
+	return s, nil
+}
+
+// podSelector returns pod selector given by workload or labelSelector query
+func podSelector(c *gin.Context, cli client.Client, namespace string) (labels.Selector, error) {
+	workload, selector := c.Query("workload"), c.Query("labelSelector")
+	switch {
+	case len(workload) > 0:
+		return workloadSelector(c.Request.Context(), cli, namespace, workload)
+	case len(selector) > 0:
+		return labels.Parse(selector)
+	}
+	return nil, fmt.Errorf("either workload or labelSelector is required")
+}
+
+// workloadSelector returns pod selector of workload like deployments/{name}
+func workloadSelector(ctx context.Context, cli client.Client, namespace, workload string) (labels.Selector, error) {
+	kind, name, found := strings.Cut(workload, "/")
+	if !found || len(name) == 0 {
+		return nil, fmt.Errorf("invalid workload %v", workload)
+	}
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+	var selector *metav1.LabelSelector
+	switch kind {
+	case "deployments":
+		d := &appsv1.Deployment{}
+		if err := cli.Cache().Get(ctx, key, d); err != nil {
+			return nil, err
+		}
+		selector = d.Spec.Selector
+	case "jobs":
+		j := &batchv1.Job{}
+		if err := cli.Cache().Get(ctx, key, j); err != nil {
+			return nil, err
+		}
+		selector = j.Spec.Selector
+	default:
+		return nil, fmt.Errorf("workload kind %v is not supported", kind)
+	}
+	if selector == nil {
+		return nil, fmt.Errorf("workload %v has no selector", workload)
+	}
+	return metav1.LabelSelectorAsSelector(selector)
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcemanage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/saashqdev/kubeworkz/pkg/apis"
+	"github.com/saashqdev/kubeworkz/pkg/clients"
+	"github.com/saashqdev/kubeworkz/pkg/multicluster"
+	"github.com/saashqdev/kubeworkz/pkg/multicluster/client/fake"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+)
+
+var _ = Describe("LogStreamLimit", func() {
+	var s *LogStream
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		apis.AddToScheme(scheme)
+		corev1.AddToScheme(scheme)
+		containers := make([]corev1.Container, maxLogStreams+2)
+		for i := range containers {
+			containers[i].Name = fmt.Sprintf("c%d", i)
+		}
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "big", Namespace: "ns1", Labels: map[string]string{"app": "big"}},
+			Spec:       corev1.PodSpec{Containers: containers},
+		}
+		multicluster.InitFakeMultiClusterMgrWithOpts(&fake.Options{Scheme: scheme, Objs: []client.Object{pod}})
+		clients.InitKubeClientSetWithOpts(nil)
+
+		s = &LogStream{
+			client:    clients.Interface().Kubernetes(constants.LocalCluster),
+			namespace: "ns1",
+			selector:  labels.SelectorFromSet(labels.Set{"app": "big"}),
+			followed:  make(map[string]int32),
+			done:      make(map[string]time.Time),
+		}
+	})
+
+	It("skips containers over limit and warns client once", func() {
+		targets, skipped, err := s.resolve(context.Background())
+		Expect(err).To(BeNil())
+		Expect(targets).To(HaveLen(maxLogStreams))
+		Expect(skipped).To(HaveLen(2))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		events := make(chan LogEvent, 2)
+		go s.run(ctx, make(chan LogLine), events)
+		var e LogEvent
+		Eventually(events).Should(Receive(&e))
+		Expect(e.Message).To(ContainSubstring("at most 50 containers"))
+	})
+
+	It("forgets containers of pods gone", func() {
+		_, _, err := s.resolve(context.Background())
+		Expect(err).To(BeNil())
+		Expect(s.followed).To(HaveLen(maxLogStreams))
+
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "big", Namespace: "ns1"}}
+		Expect(s.client.Direct().Delete(context.Background(), pod)).To(BeNil())
+		s.done["big/c0"] = time.Now()
+		targets, skipped, err := s.resolve(context.Background())
+		Expect(err).To(BeNil())
+		Expect(targets).To(BeEmpty())
+		Expect(skipped).To(BeEmpty())
+		Expect(s.followed).To(BeEmpty())
+		Expect(s.done).To(BeEmpty())
+	})
+})
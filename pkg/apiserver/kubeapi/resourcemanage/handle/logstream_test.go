@@ -0,0 +1,153 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcemanage_test
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/saashqdev/kubeworkz/pkg/apis"
+	extend "github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/handle"
+	"github.com/saashqdev/kubeworkz/pkg/clients"
+	"github.com/saashqdev/kubeworkz/pkg/multicluster"
+	"github.com/saashqdev/kubeworkz/pkg/multicluster/client/fake"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+)
+
+var _ = Describe("PodLogs", func() {
+
+	JustBeforeEach(func() {
+		scheme := runtime.NewScheme()
+		apis.AddToScheme(scheme)
+		corev1.AddToScheme(scheme)
+		appsv1.AddToScheme(scheme)
+		rbacv1.AddToScheme(scheme)
+		labels := map[string]string{"app": "web"}
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "ns1", Labels: labels},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "nginx"}}},
+			Status:     corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{Name: "nginx", RestartCount: 1}}},
+		}
+		opts := &fake.Options{
+			Scheme: scheme,
+			Objs: []client.Object{
+				&appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "ns1"},
+					Spec:       appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: labels}},
+				},
+				pod,
+				&rbacv1.ClusterRole{
+					ObjectMeta: metav1.ObjectMeta{Name: "pod-reader"},
+					Rules:      []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"list"}}},
+				},
+				&rbacv1.ClusterRoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "pod-reader"},
+					RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "pod-reader"},
+					Subjects:   []rbacv1.Subject{{Kind: rbacv1.UserKind, Name: "reader"}},
+				},
+			},
+			ClientSetRuntimeObjs: []runtime.Object{pod},
+			Lists:                []client.ObjectList{},
+		}
+		multicluster.InitFakeMultiClusterMgrWithOpts(opts)
+		clients.InitKubeClientSetWithOpts(nil)
+	})
+
+	newContext := func(ctx context.Context, query, user string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		u, _ := url.Parse("https://example.org?" + query)
+		c.Request = (&http.Request{URL: u, Header: http.Header{}}).WithContext(ctx)
+		c.Params = []gin.Param{{Key: "cluster", Value: constants.LocalCluster}, {Key: "namespace", Value: "ns1"}}
+		c.Set(constants.UserName, user)
+		return c, w
+	}
+
+	It("rejects user not allowed to list pods", func() {
+		c, w := newContext(context.Background(), "workload=deployments/web", "nobody")
+		extend.GetPodLogStream(c)
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+	})
+
+	It("rejects invalid params", func() {
+		for _, query := range []string{"", "workload=statefulsets/web", "labelSelector=app=web&grep=(", "labelSelector=app=web&tailLines=-1"} {
+			c, w := newContext(context.Background(), query, "reader")
+			extend.GetPodLogStream(c)
+			Expect(w.Code).To(Equal(http.StatusBadRequest), query)
+		}
+	})
+
+	It("streams logs of pods of workload as server-sent events", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+		c, w := newContext(ctx, "workload=deployments/web&grep=fake", "reader")
+		extend.GetPodLogStream(c)
+		Expect(w.Header().Get(constants.HttpHeaderContentType)).To(Equal("text/event-stream"))
+		Expect(w.Body.String()).To(ContainSubstring("event:log"))
+		Expect(w.Body.String()).To(ContainSubstring("[web-1/nginx]  fake logs"))
+	})
+
+	It("archives current and previous logs of pods of workload", func() {
+		c, w := newContext(context.Background(), "workload=deployments/web&sinceSeconds=60", "reader")
+		extend.GetPodLogArchive(c)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get(constants.HttpHeaderContentDisposition)).To(Equal("attachment; filename=web-logs.tar.gz"))
+
+		gr, err := gzip.NewReader(w.Body)
+		Expect(err).To(BeNil())
+		tr := tar.NewReader(gr)
+		files := make(map[string]string)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			Expect(err).To(BeNil())
+			content, err := io.ReadAll(tr)
+			Expect(err).To(BeNil())
+			files[hdr.Name] = string(content)
+		}
+		Expect(files).To(Equal(map[string]string{
+			"web-1/nginx.previous.log": "fake logs",
+			"web-1/nginx.log":          "fake logs",
+		}))
+	})
+
+	It("rejects invalid archive params", func() {
+		for _, query := range []string{"pod=web-1&limitBytes=0", "pod=web-1&sinceSeconds=1&sinceTime=2024-01-01T00:00:00Z", "pod=web-2"} {
+			c, w := newContext(context.Background(), query, "reader")
+			extend.GetPodLogArchive(c)
+			Expect(w.Code).To(Equal(http.StatusBadRequest), query)
+		}
+	})
+})
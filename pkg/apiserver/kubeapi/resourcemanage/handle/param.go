@@ -27,6 +27,7 @@ type ExtendContext struct {
 	Namespace    string
 	Username     string
 	Action       string
+	ResourceType string
 	ResourceName string
 
 	// todo: remove this customize field to suitable place
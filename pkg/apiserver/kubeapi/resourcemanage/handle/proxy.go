@@ -146,7 +146,11 @@ func (h *ProxyHandler) ProxyHandle(c *gin.Context) {
 	if len(username) == 0 {
 		clog.Warn("username is empty")
 	}
-	condition := parseQueryParams(c)
+	condition, err := parseQueryParams(c)
+	if err != nil {
+		response.FailReturn(c, errcode.BadRequest(err))
+		return
+	}
 	converterContext := filter.ConverterContext{}
 	c.Request.Header.Set(constants.ImpersonateUserKey, username)
 	internalCluster, err := multicluster.Interface().Get(cluster)
@@ -179,6 +183,41 @@ func (h *ProxyHandler) ProxyHandle(c *gin.Context) {
 		return
 	}
 
+	needModifyResponse := needModifyResponse(proxyUrl, c)
+	// paginate filtered list by platform continue token if asked, list is
+	// requested from apiserver chunk by chunk so that large list never be
+	// loaded at once. continue and limit of apiserver are passed through as is
+	if token, ok := c.GetQuery(filter.ContinueParam); ok && needModifyResponse {
+		if len(c.Query("sortName")) > 0 {
+			response.FailReturn(c, errcode.BadRequest(fmt.Errorf("sort is not supported when paginating by continue token")))
+			return
+		}
+		if c.Request.URL.Query().Has("continue") {
+			response.FailReturn(c, errcode.BadRequest(fmt.Errorf("continue can not be used together with %v", filter.ContinueParam)))
+			return
+		}
+		condition.Continue, err = filter.ParseContinueToken(token)
+		if err != nil {
+			response.FailReturn(c, errcode.BadRequest(err))
+			return
+		}
+		condition.SortName, condition.SortFunc = "", ""
+		query := c.Request.URL.Query()
+		query.Del(filter.ContinueParam)
+		if !query.Has("limit") {
+			query.Set("limit", strconv.Itoa(filter.ChunkSize))
+		}
+		if len(condition.Continue.Continue) > 0 {
+			query.Set("continue", condition.Continue.Continue)
+		}
+		c.Request.URL.RawQuery = query.Encode()
+	}
+
+	// answer list from indexed cache if enabled
+	if !needConvert && needModifyResponse && serveFromCache(c, cluster, proxyUrl, username, condition) {
+		return
+	}
+
 	// create director
 	director := directerFunc(c, internalCluster, proxyUrl, username, convertedUrl, needConvert, convertedObj)
 
@@ -209,8 +248,8 @@ func (h *ProxyHandler) ProxyHandle(c *gin.Context) {
 	filter := ResponseFilter{
 		Condition:        condition,
 		ConverterContext: &converterContext,
+		Transport:        transport,
 	}
-	needModifyResponse := needModifyResponse(proxyUrl, c)
 	// trim auth token here
 	c.Request.Header.Del(constants.AuthorizationHeader)
 	requestProxy := &httputil.ReverseProxy{Director: director, Transport: transport, ModifyResponse: nil, ErrorHandler: errorHandler}
@@ -312,7 +351,10 @@ func convertsLabelSelectorForReq(req *http.Request, labelSelector map[string][]s
 
 // product match/sort/page to other function
 func Filter(c *gin.Context, object runtime.Object) (*int, error) {
-	condition := parseQueryParams(c)
+	condition, err := parseQueryParams(c)
+	if err != nil {
+		return nil, err
+	}
 	total, err := filter.GetEmptyFilter().FilterObjectList(object, condition)
 	if err != nil {
 		clog.Error("filterCondition userList error, err: %s", err.Error())
@@ -323,20 +365,25 @@ func Filter(c *gin.Context, object runtime.Object) (*int, error) {
 
 // parse request params, include selector, sort and page
 
-func parseQueryParams(c *gin.Context) *filter.Condition {
-	exact, fuzzy := selector.ParseSelector(c.Query("selector"))
+func parseQueryParams(c *gin.Context) (*filter.Condition, error) {
+	s, err := selector.Compile(c.Query("selector"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector: %v", err)
+	}
+	exact, fuzzy, rest := s.Split()
 	limit, offset := page.ParsePage(c.Query("pageSize"), c.Query("pageNum"))
 	sortName, sortOrder, sortFunc := sort.ParseSort(c.Query("sortName"), c.Query("sortOrder"), c.Query("sortFunc"))
 	condition := filter.Condition{
 		Exact:     exact,
 		Fuzzy:     fuzzy,
+		Selector:  rest,
 		Limit:     limit,
 		Offset:    offset,
 		SortName:  sortName,
 		SortOrder: sortOrder,
 		SortFunc:  sortFunc,
 	}
-	return &condition
+	return &condition, nil
 }
 
 // if the request has a watch, it should not be filtered
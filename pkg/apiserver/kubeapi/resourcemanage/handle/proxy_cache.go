@@ -0,0 +1,98 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcemanage
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources"
+	"github.com/saashqdev/kubeworkz/pkg/clients"
+	"github.com/saashqdev/kubeworkz/pkg/clog"
+	"github.com/saashqdev/kubeworkz/pkg/multicluster/listcache"
+	"github.com/saashqdev/kubeworkz/pkg/utils/errcode"
+	"github.com/saashqdev/kubeworkz/pkg/utils/filter"
+	"github.com/saashqdev/kubeworkz/pkg/utils/response"
+)
+
+// serveFromCache answers list request from indexed list cache of cluster
+// if enabled, returns false if request should be proxied to apiserver.
+// Requests which cache can not answer exactly, such as watch, chunked list,
+// field selector, exact resource version or consistent read without resource
+// version, always go to apiserver.
+func serveFromCache(c *gin.Context, cluster, proxyUrl, username string, condition *filter.Condition) bool {
+	if c.Request.Method != http.MethodGet || condition.Continue != nil {
+		return false
+	}
+	query := c.Request.URL.Query()
+	for _, key := range []string{"watch", "limit", "continue", "fieldSelector"} {
+		if query.Has(key) {
+			return false
+		}
+	}
+	if query.Get("resourceVersionMatch") == string(metav1.ResourceVersionMatchExact) {
+		return false
+	}
+	gvr, namespace, ok := listcache.ParseListURL(proxyUrl)
+	if !ok {
+		return false
+	}
+	cli := clients.Interface().Kubernetes(cluster)
+	if cli == nil || cli.ListCache() == nil {
+		return false
+	}
+	labelSelector, err := labels.Parse(query.Get("labelSelector"))
+	if err != nil {
+		return false
+	}
+	// cache is read with privilege of kubeworkz, so let apiserver deny the
+	// request if user is not allowed to list
+	if !resources.NewSimpleAccess(cluster, username, namespace).AccessAllow(gvr.Group, gvr.Resource, "list") {
+		return false
+	}
+
+	list, resourceVersion, ok, err := cli.ListCache().List(c.Request.Context(), gvr, namespace, condition.Exact, labelSelector, query.Get("resourceVersion"))
+	if err != nil {
+		clog.Warn("list %v of cluster %v from cache failed: %v", gvr.Resource, cluster, err)
+		return false
+	}
+	if !ok {
+		return false
+	}
+
+	gvk := list.GetObjectKind().GroupVersionKind()
+	total, err := filter.GetEmptyFilter().FilterObjectList(list, condition)
+	if err != nil {
+		response.FailReturn(c, errcode.BadRequest(err))
+		return true
+	}
+	result, err := runtime.DefaultUnstructuredConverter.ToUnstructured(list)
+	if err != nil {
+		response.FailReturn(c, errcode.BadRequest(err))
+		return true
+	}
+	result["apiVersion"], result["kind"] = gvk.GroupVersion().String(), gvk.Kind
+	result["metadata"] = map[string]interface{}{"resourceVersion": resourceVersion}
+	result["total"] = total
+	c.JSON(http.StatusOK, result)
+	c.Abort()
+	return true
+}
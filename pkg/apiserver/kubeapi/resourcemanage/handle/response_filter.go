@@ -25,8 +25,13 @@ import (
 type ResponseFilter struct {
 	Condition        *filter.Condition
 	ConverterContext *filter.ConverterContext
+	// Transport requests the following chunks when paginating by continue token
+	Transport http.RoundTripper
 }
 
 func (f *ResponseFilter) filterResponse(r *http.Response) error {
+	if f.Condition.Continue != nil {
+		return filter.NewFilter(f.ConverterContext).ModifyChunkedResponse(r, f.Condition, f.Transport)
+	}
 	return filter.NewFilter(f.ConverterContext).ModifyResponse(r, f.Condition)
 }
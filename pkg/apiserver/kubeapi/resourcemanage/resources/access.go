@@ -18,6 +18,7 @@ package resources
 
 import (
 	"context"
+	"strings"
 
 	"github.com/saashqdev/kubeworkz/pkg/authorizer/rbac"
 	"github.com/saashqdev/kubeworkz/pkg/clog"
@@ -53,14 +54,17 @@ func NewAccess(clusterName string, userName string, operator string, apiGroup st
 	return a
 }
 
+// AccessAllow checks if user is allowed to operate resource, subresource is
+// given after slash as rbac rules do, such as deployments/scale
 func (a *Access) AccessAllow(apiGroup string, resource string, operator string) bool {
-
+	resource, subresource, _ := strings.Cut(resource, "/")
 	auth := authorizer.AttributesRecord{
 		User:            &userinfo.DefaultInfo{Name: a.Name},
 		Verb:            operator,
 		APIGroup:        apiGroup,
 		Namespace:       a.Namespace,
 		Resource:        resource,
+		Subresource:     subresource,
 		ResourceRequest: true,
 	}
 	r := rbac.NewDefaultResolver(a.Cluster)
@@ -0,0 +1,156 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cronjob
+
+import (
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apiserver/pkg/storage/names"
+	"k8s.io/client-go/util/retry"
+
+	resourcemanage "github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/handle"
+	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources"
+	"github.com/saashqdev/kubeworkz/pkg/clients"
+	"github.com/saashqdev/kubeworkz/pkg/clog"
+	"github.com/saashqdev/kubeworkz/pkg/conversion"
+	"github.com/saashqdev/kubeworkz/pkg/utils/audit"
+	"github.com/saashqdev/kubeworkz/pkg/utils/errcode"
+)
+
+// Actions of cronjob, given by query param "action" of POST request
+const (
+	ActionSuspend = "suspend"
+	ActionResume  = "resume"
+	ActionTrigger = "trigger"
+)
+
+// instantiateAnnotation marks jobs created manually from cronjob, the same as
+// kubectl create job --from
+const instantiateAnnotation = "cronjob.kubernetes.io/instantiate"
+
+// handleAction handles POST api/v1/kube/extend/clusters/{cluster}/namespaces/{namespace}/cronjobs/{name}?action={action}
+func handleAction(param resourcemanage.ExtendContext) (interface{}, *errcode.ErrorInfo) {
+	c := param.GinContext
+	if len(param.ResourceName) == 0 {
+		return nil, errcode.ParamsMissing("resourceName")
+	}
+	action := c.Query("action")
+	access := resources.NewSimpleAccess(param.Cluster, param.Username, param.Namespace)
+	switch action {
+	case ActionSuspend, ActionResume:
+		if allow := access.AccessAllow("batch", "cronjobs", "patch"); !allow {
+			return nil, errcode.ForbiddenErr
+		}
+	case ActionTrigger:
+		// trigger reads the cronjob to copy its job template, so user must
+		// be able to get it as well
+		if !access.AccessAllow("batch", "cronjobs", "get") || !access.AccessAllow("batch", "jobs", "create") {
+			return nil, errcode.ForbiddenErr
+		}
+	default:
+		return nil, errcode.BadRequest(fmt.Errorf("unsupported action %q of cronjob", action))
+	}
+	kubernetes := clients.Interface().Kubernetes(param.Cluster)
+	if kubernetes == nil {
+		return nil, errcode.ClusterNotFoundError(param.Cluster)
+	}
+	convertor, err := conversion.NewVersionConvertor(kubernetes.CacheDiscovery(), kubernetes.RESTMapper())
+	if err != nil {
+		return nil, errcode.BadRequest(err)
+	}
+	cli := conversion.WrapClient(kubernetes.Direct(), convertor, true)
+
+	ctx := c.Request.Context()
+	key := types.NamespacedName{Namespace: param.Namespace, Name: param.ResourceName}
+	cronJob := &batchv1beta1.CronJob{}
+	body := map[string]string{"action": action}
+
+	if action == ActionTrigger {
+		if err = cli.Get(ctx, key, cronJob); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, errcode.NotFoundErr
+			}
+			return nil, errcode.BadRequest(err)
+		}
+		// owner reference must be of the version served by cluster, or the
+		// job would be collected as garbage
+		gvk := batchv1.SchemeGroupVersion.WithKind("CronJob")
+		if greetBack, _, recommend, err := convertor.GvkGreeting(&gvk); err == nil && greetBack == conversion.IsNeedConvert {
+			gvk = *recommend
+		}
+		job := newJobFromCronJob(cronJob, gvk)
+		if err = kubernetes.Direct().Create(ctx, job); err != nil {
+			clog.Warn("trigger cronjob %v/%v of cluster %v failed: %v", param.Namespace, param.ResourceName, param.Cluster, err)
+			return nil, errcode.BadRequest(err)
+		}
+		body["job"] = job.Name
+		audit.SetAuditInfo(c, audit.TriggerCronJob, generateName(cronJob.Name, cronJob.Namespace, param.Cluster, string(cronJob.UID)), body)
+		return job, nil
+	}
+
+	suspend := action == ActionSuspend
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := cli.Get(ctx, key, cronJob); err != nil {
+			return err
+		}
+		cronJob.Spec.Suspend = &suspend
+		return cli.Update(ctx, cronJob)
+	})
+	if err != nil {
+		clog.Warn("%v cronjob %v/%v of cluster %v failed: %v", action, param.Namespace, param.ResourceName, param.Cluster, err)
+		if apierrors.IsNotFound(err) {
+			return nil, errcode.NotFoundErr
+		}
+		return nil, errcode.BadRequest(err)
+	}
+	eventInfo := audit.ResumeCronJob
+	if suspend {
+		eventInfo = audit.SuspendCronJob
+	}
+	audit.SetAuditInfo(c, eventInfo, generateName(cronJob.Name, cronJob.Namespace, param.Cluster, string(cronJob.UID)), body)
+	return cronJob, nil
+}
+
+// newJobFromCronJob creates job from job template of cronjob, it is owned by
+// the cronjob
+func newJobFromCronJob(cronJob *batchv1beta1.CronJob, gvk schema.GroupVersionKind) *batchv1.Job {
+	annotations := map[string]string{instantiateAnnotation: "manual"}
+	for k, v := range cronJob.Spec.JobTemplate.Annotations {
+		annotations[k] = v
+	}
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            names.SimpleNameGenerator.GenerateName(cronJob.Name + "-manual-"),
+			Namespace:       cronJob.Namespace,
+			Labels:          cronJob.Spec.JobTemplate.Labels,
+			Annotations:     annotations,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(cronJob, gvk)},
+		},
+		Spec: cronJob.Spec.JobTemplate.Spec,
+	}
+}
+
+// generateName will generate cronjob name by {name}/{namespace}/{cluster}/{uid}
+func generateName(name, namespace, cluster, uid string) string {
+	return name + "/" + namespace + "/" + cluster + "/" + uid
+}
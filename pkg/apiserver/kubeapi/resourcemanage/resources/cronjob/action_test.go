@@ -0,0 +1,176 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cronjob
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/saashqdev/kubeworkz/pkg/apis"
+	resourcemanage "github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/handle"
+	"github.com/saashqdev/kubeworkz/pkg/clients"
+	"github.com/saashqdev/kubeworkz/pkg/multicluster"
+	"github.com/saashqdev/kubeworkz/pkg/multicluster/client/fake"
+	"github.com/saashqdev/kubeworkz/pkg/utils/audit"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+)
+
+var _ = Describe("CronJobAction", func() {
+	var ns = "ns1"
+
+	JustBeforeEach(func() {
+		scheme := runtime.NewScheme()
+		apis.AddToScheme(scheme)
+		corev1.AddToScheme(scheme)
+		batchv1.AddToScheme(scheme)
+		rbacv1.AddToScheme(scheme)
+		cronJob := &batchv1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: ns, UID: "cjid"},
+			Spec: batchv1.CronJobSpec{
+				Schedule: "0 * * * *",
+				JobTemplate: batchv1.JobTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "backup"}},
+					Spec: batchv1.JobSpec{
+						Template: corev1.PodTemplateSpec{
+							Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "backup", Image: "busybox"}}},
+						},
+					},
+				},
+			},
+		}
+		newRole := func(name string, rules ...rbacv1.PolicyRule) []client.Object {
+			return []client.Object{
+				&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: name}, Rules: rules},
+				&rbacv1.ClusterRoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: name},
+					RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: name},
+					Subjects:   []rbacv1.Subject{{Kind: rbacv1.UserKind, Name: name}},
+				},
+			}
+		}
+		objs := []client.Object{cronJob}
+		objs = append(objs, newRole("editor",
+			rbacv1.PolicyRule{APIGroups: []string{"batch"}, Resources: []string{"cronjobs"}, Verbs: []string{"get", "patch"}},
+			rbacv1.PolicyRule{APIGroups: []string{"batch"}, Resources: []string{"jobs"}, Verbs: []string{"create"}})...)
+		objs = append(objs, newRole("jobcreator",
+			rbacv1.PolicyRule{APIGroups: []string{"batch"}, Resources: []string{"jobs"}, Verbs: []string{"create"}})...)
+		opts := &fake.Options{
+			Scheme:               scheme,
+			Objs:                 objs,
+			ClientSetRuntimeObjs: []runtime.Object{},
+			Lists:                []client.ObjectList{},
+			Resources: []*metav1.APIResourceList{{
+				GroupVersion: batchv1.SchemeGroupVersion.String(),
+				APIResources: []metav1.APIResource{
+					{Name: "cronjobs", Namespaced: true, Kind: "CronJob"},
+					{Name: "jobs", Namespaced: true, Kind: "Job"},
+				},
+			}},
+		}
+		multicluster.InitFakeMultiClusterMgrWithOpts(opts)
+		clients.InitKubeClientSetWithOpts(nil)
+	})
+
+	doAction := func(user, name, action string) (*gin.Context, interface{}, int) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, "/?action="+action, nil)
+		ret, errInfo := handle(resourcemanage.ExtendContext{
+			Cluster:      constants.LocalCluster,
+			Namespace:    ns,
+			Username:     user,
+			Action:       http.MethodPost,
+			ResourceName: name,
+			GinContext:   c,
+		})
+		if errInfo != nil {
+			return c, nil, errInfo.Code
+		}
+		return c, ret, http.StatusOK
+	}
+
+	getCronJob := func() *batchv1.CronJob {
+		cronJob := &batchv1.CronJob{}
+		cli := clients.Interface().Kubernetes(constants.LocalCluster)
+		Expect(cli.Direct().Get(context.Background(), types.NamespacedName{Namespace: ns, Name: "backup"}, cronJob)).To(BeNil())
+		return cronJob
+	}
+
+	It("rejects user not allowed to patch cronjobs", func() {
+		_, _, code := doAction("nobody", "backup", ActionSuspend)
+		Expect(code).To(Equal(http.StatusForbidden))
+	})
+
+	It("rejects invalid actions", func() {
+		_, _, code := doAction("editor", "backup", "delete")
+		Expect(code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("suspends and resumes cronjob", func() {
+		c, _, code := doAction("editor", "backup", ActionSuspend)
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(*getCronJob().Spec.Suspend).To(BeTrue())
+		Expect(c.GetString(audit.EventName)).To(Equal(audit.SuspendCronJob.EventName))
+		Expect(c.GetString(audit.EventResourceName)).To(Equal("backup/ns1/" + constants.LocalCluster + "/cjid"))
+
+		c, _, code = doAction("editor", "backup", ActionResume)
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(*getCronJob().Spec.Suspend).To(BeFalse())
+		Expect(c.GetString(audit.EventName)).To(Equal(audit.ResumeCronJob.EventName))
+	})
+
+	It("triggers job owned by cronjob", func() {
+		c, ret, code := doAction("editor", "backup", ActionTrigger)
+		Expect(code).To(Equal(http.StatusOK))
+		job, ok := ret.(*batchv1.Job)
+		Expect(ok).To(BeTrue())
+		Expect(c.GetString(audit.EventName)).To(Equal(audit.TriggerCronJob.EventName))
+
+		created := &batchv1.Job{}
+		cli := clients.Interface().Kubernetes(constants.LocalCluster)
+		Expect(cli.Direct().Get(context.Background(), types.NamespacedName{Namespace: ns, Name: job.Name}, created)).To(BeNil())
+		Expect(created.Annotations).To(HaveKeyWithValue(instantiateAnnotation, "manual"))
+		Expect(created.Labels).To(HaveKeyWithValue("app", "backup"))
+		Expect(created.OwnerReferences).To(HaveLen(1))
+		Expect(created.OwnerReferences[0].Kind).To(Equal("CronJob"))
+		Expect(created.OwnerReferences[0].APIVersion).To(Equal(batchv1.SchemeGroupVersion.String()))
+		Expect(created.OwnerReferences[0].UID).To(Equal(types.UID("cjid")))
+	})
+
+	It("rejects trigger by user not allowed to get cronjobs", func() {
+		_, _, code := doAction("jobcreator", "backup", ActionTrigger)
+		Expect(code).To(Equal(http.StatusForbidden))
+	})
+
+	It("returns not found for missing cronjob", func() {
+		for _, action := range []string{ActionSuspend, ActionTrigger} {
+			_, _, code := doAction("editor", "missing", action)
+			Expect(code).To(Equal(http.StatusNotFound), action)
+		}
+	})
+})
@@ -19,6 +19,7 @@ package cronjob
 import (
 	"context"
 	"fmt"
+	"net/http"
 
 	batchv1 "k8s.io/api/batch/v1"
 	batchv1beta1 "k8s.io/api/batch/v1beta1"
@@ -51,6 +52,9 @@ func init() {
 }
 
 func handle(param resourcemanage.ExtendContext) (interface{}, *errcode.ErrorInfo) {
+	if param.Action == http.MethodPost {
+		return handleAction(param)
+	}
 	access := resources.NewSimpleAccess(param.Cluster, param.Username, param.Namespace)
 	if allow := access.AccessAllow("batch", "cronjobs", "list"); !allow {
 		return nil, errcode.ForbiddenErr
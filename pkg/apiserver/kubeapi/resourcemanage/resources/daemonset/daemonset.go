@@ -0,0 +1,159 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package daemonset
+
+import (
+	"context"
+	"sync"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	resourcemanage "github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/handle"
+	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources"
+	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources/deployment"
+	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources/enum"
+	"github.com/saashqdev/kubeworkz/pkg/clients"
+	"github.com/saashqdev/kubeworkz/pkg/clog"
+	mgrclient "github.com/saashqdev/kubeworkz/pkg/multicluster/client"
+	"github.com/saashqdev/kubeworkz/pkg/utils/errcode"
+	"github.com/saashqdev/kubeworkz/pkg/utils/filter"
+)
+
+type DaemonSet struct {
+	ctx             context.Context
+	client          mgrclient.Client
+	namespace       string
+	filterCondition *filter.Condition
+}
+
+type ExtendDaemonSet struct {
+	deployment.PodStatus `json:"podStatus,omitempty"`
+	appsv1.DaemonSet
+}
+
+func init() {
+	resourcemanage.SetExtendHandler(enum.DaemonSetResourceType, handle)
+}
+
+func handle(param resourcemanage.ExtendContext) (interface{}, *errcode.ErrorInfo) {
+	access := resources.NewSimpleAccess(param.Cluster, param.Username, param.Namespace)
+	if allow := access.AccessAllow("apps", "daemonsets", "list"); !allow {
+		return nil, errcode.ForbiddenErr
+	}
+	kubernetes := clients.Interface().Kubernetes(param.Cluster)
+	if kubernetes == nil {
+		return nil, errcode.ClusterNotFoundError(param.Cluster)
+	}
+	daemonSet := NewDaemonSet(kubernetes, param.Namespace, param.FilterCondition)
+	return daemonSet.getExtendDaemonSets()
+}
+
+func NewDaemonSet(client mgrclient.Client, namespace string, condition *filter.Condition) DaemonSet {
+	ctx := context.Background()
+	return DaemonSet{
+		ctx:             ctx,
+		client:          client,
+		namespace:       namespace,
+		filterCondition: condition,
+	}
+}
+
+// getExtendDaemonSets get extend daemonsets
+func (d *DaemonSet) getExtendDaemonSets() (*unstructured.Unstructured, *errcode.ErrorInfo) {
+	resultMap := make(map[string]interface{})
+	// get daemonset list from k8s cluster
+	var daemonSetList appsv1.DaemonSetList
+	err := d.client.Cache().List(d.ctx, &daemonSetList, client.InNamespace(d.namespace))
+	if err != nil {
+		clog.Error("can not find daemonset in %s from cluster, %v", d.namespace, err)
+		return nil, errcode.BadRequest(err)
+	}
+	// filterCondition list by selector/sort/page
+	total, err := filter.GetEmptyFilter().FilterObjectList(&daemonSetList, d.filterCondition)
+	if err != nil {
+		clog.Error("filterCondition daemonSetList error, err: %s", err.Error())
+		return nil, errcode.BadRequest(err)
+	}
+
+	resultList := make([]ExtendDaemonSet, len(daemonSetList.Items))
+	wg := &sync.WaitGroup{}
+	for i, daemonSet := range daemonSetList.Items {
+		wg.Add(1)
+		go func(i int, ds appsv1.DaemonSet) {
+			resultList[i] = d.getDaemonSetExtendInfo(ds)
+			wg.Done()
+		}(i, daemonSet)
+	}
+	wg.Wait()
+	resultMap["total"] = total
+	resultMap["items"] = resultList
+
+	return &unstructured.Unstructured{
+		Object: resultMap,
+	}, nil
+}
+
+func (d *DaemonSet) getDaemonSetExtendInfo(ds appsv1.DaemonSet) ExtendDaemonSet {
+	result := ExtendDaemonSet{DaemonSet: ds}
+	podList, err := d.getPodsByDaemonSet(ds)
+	if err != nil {
+		clog.Info("add extend pods info to daemonset %s fail, %v", ds.Name, err)
+		return result
+	}
+
+	// get warning event list by podList
+	warningEventList, err := deployment.GetWarningEventsByPodList(d.ctx, d.client, d.namespace, &podList)
+	if err != nil {
+		clog.Info("add extend warning events info to daemonset %s fail, %v", ds.Name, err)
+	}
+
+	// pods of daemonset are desired on every scheduled node
+	desired := ds.Status.DesiredNumberScheduled
+	result.Current = ds.Status.CurrentNumberScheduled
+	result.Desired = &desired
+	result.CountPhases(podList.Items)
+	result.Warning = warningEventList
+	return result
+}
+
+// getPodsByDaemonSet get pods controlled by daemonset
+func (d *DaemonSet) getPodsByDaemonSet(ds appsv1.DaemonSet) (corev1.PodList, error) {
+	if ds.Spec.Selector == nil {
+		return corev1.PodList{}, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(ds.Spec.Selector)
+	if err != nil {
+		return corev1.PodList{}, err
+	}
+	podList := corev1.PodList{}
+	err = d.client.Cache().List(d.ctx, &podList, &client.ListOptions{Namespace: d.namespace, LabelSelector: selector})
+	if err != nil {
+		return corev1.PodList{}, err
+	}
+	pods := podList.Items[:0]
+	for _, pod := range podList.Items {
+		if metav1.IsControlledBy(&pod, &ds) {
+			pods = append(pods, pod)
+		}
+	}
+	podList.Items = pods
+	return podList, nil
+}
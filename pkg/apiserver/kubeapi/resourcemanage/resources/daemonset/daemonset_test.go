@@ -0,0 +1,91 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package daemonset
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/saashqdev/kubeworkz/pkg/apis"
+	"github.com/saashqdev/kubeworkz/pkg/clients"
+	"github.com/saashqdev/kubeworkz/pkg/multicluster"
+	"github.com/saashqdev/kubeworkz/pkg/multicluster/client/fake"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+	"github.com/saashqdev/kubeworkz/pkg/utils/filter"
+)
+
+var _ = Describe("DaemonSet", func() {
+	var ns = "namespace-test"
+
+	JustBeforeEach(func() {
+		scheme := runtime.NewScheme()
+		apis.AddToScheme(scheme)
+		corev1.AddToScheme(scheme)
+		appsv1.AddToScheme(scheme)
+		labels := map[string]string{"app": "agent"}
+		ds := &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: ns, UID: "dsid"},
+			Spec: appsv1.DaemonSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+			},
+			Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, CurrentNumberScheduled: 2},
+		}
+		newPod := func(name string, phase corev1.PodPhase, owned bool) *corev1.Pod {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns, Labels: labels},
+				Status:     corev1.PodStatus{Phase: phase},
+			}
+			if owned {
+				pod.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(ds, appsv1.SchemeGroupVersion.WithKind("DaemonSet"))}
+			}
+			return pod
+		}
+		opts := &fake.Options{
+			Scheme: scheme,
+			Objs: []client.Object{
+				ds,
+				newPod("agent-a", corev1.PodRunning, true),
+				newPod("agent-b", corev1.PodPending, true),
+				newPod("agent-debug", corev1.PodRunning, false),
+			},
+			ClientSetRuntimeObjs: []runtime.Object{},
+			Lists:                []client.ObjectList{},
+		}
+		multicluster.InitFakeMultiClusterMgrWithOpts(opts)
+		clients.InitKubeClientSetWithOpts(nil)
+	})
+
+	It("test get daemonset extend info", func() {
+		client := clients.Interface().Kubernetes(constants.LocalCluster)
+		Expect(client).NotTo(BeNil())
+		daemonSet := NewDaemonSet(client, ns, &filter.Condition{Limit: 10, Offset: 0})
+		ret, err := daemonSet.getExtendDaemonSets()
+		Expect(err).To(BeNil())
+		Expect(ret.Object["total"]).To(Equal(1))
+		info := ret.Object["items"].([]ExtendDaemonSet)[0]
+		Expect(info.Name).To(Equal("agent"))
+		Expect(info.Current).To(Equal(int32(2)))
+		Expect(*info.Desired).To(Equal(int32(3)))
+		Expect(info.Running).To(Equal(int32(1)))
+		Expect(info.Pending).To(Equal(int32(1)))
+	})
+})
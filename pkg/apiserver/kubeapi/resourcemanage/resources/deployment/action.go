@@ -0,0 +1,258 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	resourcemanage "github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/handle"
+	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources"
+	"github.com/saashqdev/kubeworkz/pkg/clients"
+	"github.com/saashqdev/kubeworkz/pkg/clog"
+	mgrclient "github.com/saashqdev/kubeworkz/pkg/multicluster/client"
+	"github.com/saashqdev/kubeworkz/pkg/utils/audit"
+	"github.com/saashqdev/kubeworkz/pkg/utils/errcode"
+)
+
+// Actions of deployment, given by query param "action" of POST request
+const (
+	ActionRestart  = "restart"
+	ActionScale    = "scale"
+	ActionRollback = "rollback"
+	ActionPause    = "pause"
+	ActionResume   = "resume"
+)
+
+const (
+	// restartedAtAnnotation the annotation of pod template patched by kubectl rollout restart
+	restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+	// revisionAnnotation the annotation of revision of replicaset and deployment
+	revisionAnnotation = "deployment.kubernetes.io/revision"
+)
+
+var actionEvents = map[string]*audit.EventInfo{
+	ActionRestart:  audit.RestartDeployment,
+	ActionScale:    audit.ScaleDeployment,
+	ActionRollback: audit.RollbackDeployment,
+	ActionPause:    audit.PauseDeployment,
+	ActionResume:   audit.ResumeDeployment,
+}
+
+// ActionBody the request body of deployment action, it is recorded in audit
+type ActionBody struct {
+	Action string `json:"action"`
+	// Replicas the desired replicas of scale
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Revision the revision of replicaset to rollback to, 0 means the
+	// previous revision
+	Revision int64 `json:"revision,omitempty"`
+}
+
+type actionPerReq struct {
+	ginContext *gin.Context
+	client     mgrclient.Client
+	cluster    string
+	namespace  string
+	name       string
+	body       ActionBody
+}
+
+// handleAction handles POST api/v1/kube/extend/clusters/{cluster}/namespaces/{namespace}/deployments/{name}?action={action}
+func handleAction(extendCtx resourcemanage.ExtendContext) (interface{}, *errcode.ErrorInfo) {
+	c := extendCtx.GinContext
+	if len(extendCtx.ResourceName) == 0 {
+		return nil, errcode.ParamsMissing("resourceName")
+	}
+	action := c.Query("action")
+	eventInfo, ok := actionEvents[action]
+	if !ok {
+		return nil, errcode.BadRequest(fmt.Errorf("unsupported action %q of deployment", action))
+	}
+	access := resources.NewSimpleAccess(extendCtx.Cluster, extendCtx.Username, extendCtx.Namespace)
+	// scale is authorized on scale subresource as kubectl scale does
+	resource, verb := "deployments", "patch"
+	if action == ActionScale {
+		resource, verb = "deployments/scale", "update"
+	}
+	if allow := access.AccessAllow("apps", resource, verb); !allow {
+		return nil, errcode.ForbiddenErr
+	}
+	cli := clients.Interface().Kubernetes(extendCtx.Cluster)
+	if cli == nil {
+		return nil, errcode.ClusterNotFoundError(extendCtx.Cluster)
+	}
+
+	a := actionPerReq{
+		ginContext: c,
+		client:     cli,
+		cluster:    extendCtx.Cluster,
+		namespace:  extendCtx.Namespace,
+		name:       extendCtx.ResourceName,
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&a.body); err != nil && !errors.Is(err, io.EOF) {
+			return nil, errcode.InvalidBodyFormat
+		}
+	}
+	a.body.Action = action
+
+	var mutate func(deploy *appsv1.Deployment) error
+	switch action {
+	case ActionRestart:
+		mutate = a.restart
+	case ActionScale:
+		if a.body.Replicas == nil || *a.body.Replicas < 0 {
+			return nil, errcode.BadRequest(fmt.Errorf("replicas must be given and not negative"))
+		}
+		mutate = a.scale
+	case ActionRollback:
+		if a.body.Revision < 0 {
+			return nil, errcode.BadRequest(fmt.Errorf("revision must not be negative"))
+		}
+		mutate = a.rollback
+	case ActionPause, ActionResume:
+		mutate = a.pause
+	}
+
+	deploy, err := a.update(mutate)
+	if err != nil {
+		clog.Warn("%v deployment %v/%v of cluster %v failed: %v", action, a.namespace, a.name, a.cluster, err)
+		if apierrors.IsNotFound(err) {
+			return nil, errcode.NotFoundErr
+		}
+		return nil, errcode.BadRequest(err)
+	}
+	audit.SetAuditInfo(c, eventInfo, generateName(deploy.Name, deploy.Namespace, a.cluster, string(deploy.UID)), a.body)
+	return deploy, nil
+}
+
+// update gets the latest deployment and updates it by mutate, retried on conflict
+func (a *actionPerReq) update(mutate func(deploy *appsv1.Deployment) error) (*appsv1.Deployment, error) {
+	ctx := a.ginContext.Request.Context()
+	deploy := &appsv1.Deployment{}
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := a.client.Direct().Get(ctx, types.NamespacedName{Namespace: a.namespace, Name: a.name}, deploy); err != nil {
+			return err
+		}
+		if err := mutate(deploy); err != nil {
+			return err
+		}
+		return a.client.Direct().Update(ctx, deploy)
+	})
+	return deploy, err
+}
+
+// restart triggers rolling restart the same way as kubectl rollout restart
+func (a *actionPerReq) restart(deploy *appsv1.Deployment) error {
+	if deploy.Spec.Paused {
+		return fmt.Errorf("can not restart paused deployment %v, resume it first", deploy.Name)
+	}
+	if deploy.Spec.Template.Annotations == nil {
+		deploy.Spec.Template.Annotations = make(map[string]string)
+	}
+	deploy.Spec.Template.Annotations[restartedAtAnnotation] = time.Now().Format(time.RFC3339)
+	return nil
+}
+
+func (a *actionPerReq) scale(deploy *appsv1.Deployment) error {
+	deploy.Spec.Replicas = a.body.Replicas
+	return nil
+}
+
+func (a *actionPerReq) pause(deploy *appsv1.Deployment) error {
+	deploy.Spec.Paused = a.body.Action == ActionPause
+	return nil
+}
+
+// rollback replaces pod template of deployment with the one of replicaset of
+// given revision
+func (a *actionPerReq) rollback(deploy *appsv1.Deployment) error {
+	if deploy.Spec.Paused {
+		return fmt.Errorf("can not rollback paused deployment %v, resume it first", deploy.Name)
+	}
+	rs, err := a.revision(deploy)
+	if err != nil {
+		return err
+	}
+	template := rs.Spec.Template.DeepCopy()
+	delete(template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	deploy.Spec.Template = *template
+	return nil
+}
+
+// revision finds replicaset of deployment with given revision, or the one of
+// previous revision if revision is 0
+func (a *actionPerReq) revision(deploy *appsv1.Deployment) (*appsv1.ReplicaSet, error) {
+	if deploy.Spec.Selector == nil {
+		return nil, fmt.Errorf("deployment %v has no selector", deploy.Name)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(deploy.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	rsList := appsv1.ReplicaSetList{}
+	err = a.client.Direct().List(a.ginContext.Request.Context(), &rsList, &client.ListOptions{Namespace: a.namespace, LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+
+	current, _ := strconv.ParseInt(deploy.Annotations[revisionAnnotation], 10, 64)
+	var target *appsv1.ReplicaSet
+	var targetRevision int64
+	for i := range rsList.Items {
+		rs := &rsList.Items[i]
+		if !metav1.IsControlledBy(rs, deploy) {
+			continue
+		}
+		revision, err := strconv.ParseInt(rs.Annotations[revisionAnnotation], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch {
+		case a.body.Revision > 0:
+			if revision == a.body.Revision {
+				return rs, nil
+			}
+		case revision < current && revision > targetRevision:
+			target, targetRevision = rs, revision
+		}
+	}
+	if target == nil {
+		if a.body.Revision > 0 {
+			return nil, fmt.Errorf("revision %v of deployment %v not found", a.body.Revision, deploy.Name)
+		}
+		return nil, fmt.Errorf("no previous revision of deployment %v", deploy.Name)
+	}
+	return target, nil
+}
+
+// generateName will generate deployment name by {name}/{namespace}/{cluster}/{uid}
+func generateName(name, namespace, cluster, uid string) string {
+	return name + "/" + namespace + "/" + cluster + "/" + uid
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/saashqdev/kubeworkz/pkg/apis"
+	resourcemanage "github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/handle"
+	"github.com/saashqdev/kubeworkz/pkg/clients"
+	"github.com/saashqdev/kubeworkz/pkg/multicluster"
+	"github.com/saashqdev/kubeworkz/pkg/multicluster/client/fake"
+	"github.com/saashqdev/kubeworkz/pkg/utils/audit"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+)
+
+var _ = Describe("DeploymentAction", func() {
+	var ns = "ns1"
+
+	JustBeforeEach(func() {
+		scheme := runtime.NewScheme()
+		apis.AddToScheme(scheme)
+		corev1.AddToScheme(scheme)
+		appsv1.AddToScheme(scheme)
+		rbacv1.AddToScheme(scheme)
+		labels := map[string]string{"app": "web"}
+		dp := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: ns, UID: "dpid",
+				Annotations: map[string]string{revisionAnnotation: "2"}},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: pointer.Int32(1),
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels},
+					Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "nginx", Image: "nginx:2"}}},
+				},
+			},
+		}
+		newRs := func(revision, image string) *appsv1.ReplicaSet {
+			rsLabels := map[string]string{"app": "web", appsv1.DefaultDeploymentUniqueLabelKey: revision}
+			return &appsv1.ReplicaSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "web-" + revision,
+					Namespace:       ns,
+					Labels:          rsLabels,
+					Annotations:     map[string]string{revisionAnnotation: revision},
+					OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(dp, appsv1.SchemeGroupVersion.WithKind("Deployment"))},
+				},
+				Spec: appsv1.ReplicaSetSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: rsLabels},
+						Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "nginx", Image: image}}},
+					},
+				},
+			}
+		}
+		opts := &fake.Options{
+			Scheme: scheme,
+			Objs: []client.Object{
+				dp,
+				newRs("1", "nginx:1"),
+				newRs("2", "nginx:2"),
+				&rbacv1.ClusterRole{
+					ObjectMeta: metav1.ObjectMeta{Name: "deployment-editor"},
+					Rules: []rbacv1.PolicyRule{
+						{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"patch"}},
+						{APIGroups: []string{"apps"}, Resources: []string{"deployments/scale"}, Verbs: []string{"update"}},
+					},
+				},
+				&rbacv1.ClusterRoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "deployment-editor"},
+					RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "deployment-editor"},
+					Subjects:   []rbacv1.Subject{{Kind: rbacv1.UserKind, Name: "editor"}},
+				},
+				&rbacv1.ClusterRole{
+					ObjectMeta: metav1.ObjectMeta{Name: "deployment-patcher"},
+					Rules:      []rbacv1.PolicyRule{{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"patch"}}},
+				},
+				&rbacv1.ClusterRoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "deployment-patcher"},
+					RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "deployment-patcher"},
+					Subjects:   []rbacv1.Subject{{Kind: rbacv1.UserKind, Name: "patcher"}},
+				},
+			},
+			ClientSetRuntimeObjs: []runtime.Object{},
+			Lists:                []client.ObjectList{},
+		}
+		multicluster.InitFakeMultiClusterMgrWithOpts(opts)
+		clients.InitKubeClientSetWithOpts(nil)
+	})
+
+	doActionOn := func(name, user, action, body string) (*gin.Context, interface{}, int) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, "/?action="+action, strings.NewReader(body))
+		ret, errInfo := handle(resourcemanage.ExtendContext{
+			Cluster:      constants.LocalCluster,
+			Namespace:    ns,
+			Username:     user,
+			Action:       http.MethodPost,
+			ResourceName: name,
+			GinContext:   c,
+		})
+		if errInfo != nil {
+			return c, nil, errInfo.Code
+		}
+		return c, ret, http.StatusOK
+	}
+
+	doAction := func(user, action, body string) (*gin.Context, interface{}, int) {
+		return doActionOn("web", user, action, body)
+	}
+
+	getDeployment := func() *appsv1.Deployment {
+		dp := &appsv1.Deployment{}
+		cli := clients.Interface().Kubernetes(constants.LocalCluster)
+		Expect(cli.Direct().Get(context.Background(), types.NamespacedName{Namespace: ns, Name: "web"}, dp)).To(BeNil())
+		return dp
+	}
+
+	It("rejects user not allowed to patch deployments", func() {
+		_, _, code := doAction("nobody", ActionRestart, "")
+		Expect(code).To(Equal(http.StatusForbidden))
+	})
+
+	It("rejects invalid actions", func() {
+		for _, action := range []string{"delete", ActionScale} {
+			_, _, code := doAction("editor", action, "")
+			Expect(code).To(Equal(http.StatusBadRequest), action)
+		}
+	})
+
+	It("returns not found for missing deployment", func() {
+		_, _, code := doActionOn("missing", "editor", ActionRestart, "")
+		Expect(code).To(Equal(http.StatusNotFound))
+	})
+
+	It("restarts deployment and sets audit info", func() {
+		c, _, code := doAction("editor", ActionRestart, "")
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(getDeployment().Spec.Template.Annotations).To(HaveKey(restartedAtAnnotation))
+		Expect(c.GetString(audit.EventName)).To(Equal(audit.RestartDeployment.EventName))
+		Expect(c.GetString(audit.EventResourceName)).To(Equal("web/ns1/" + constants.LocalCluster + "/dpid"))
+		Expect(c.GetString(audit.EventRequestBody)).To(Equal(`{"action":"restart"}`))
+	})
+
+	It("scales deployment", func() {
+		_, _, code := doAction("editor", ActionScale, `{"replicas":3}`)
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(*getDeployment().Spec.Replicas).To(Equal(int32(3)))
+	})
+
+	It("rejects scale by user not allowed to update deployments/scale", func() {
+		_, _, code := doAction("patcher", ActionScale, `{"replicas":3}`)
+		Expect(code).To(Equal(http.StatusForbidden))
+		_, _, code = doAction("patcher", ActionRestart, "")
+		Expect(code).To(Equal(http.StatusOK))
+	})
+
+	It("pauses deployment and refuses to rollback it", func() {
+		_, _, code := doAction("editor", ActionPause, "")
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(getDeployment().Spec.Paused).To(BeTrue())
+		_, _, code = doAction("editor", ActionRollback, "")
+		Expect(code).To(Equal(http.StatusBadRequest))
+		_, _, code = doAction("editor", ActionResume, "")
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(getDeployment().Spec.Paused).To(BeFalse())
+	})
+
+	It("rolls back deployment to previous revision", func() {
+		_, _, code := doAction("editor", ActionRollback, "")
+		Expect(code).To(Equal(http.StatusOK))
+		template := getDeployment().Spec.Template
+		Expect(template.Spec.Containers[0].Image).To(Equal("nginx:1"))
+		Expect(template.Labels).NotTo(HaveKey(appsv1.DefaultDeploymentUniqueLabelKey))
+
+		_, _, code = doAction("editor", ActionRollback, `{"revision":2}`)
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(getDeployment().Spec.Template.Spec.Containers[0].Image).To(Equal("nginx:2"))
+
+		_, _, code = doAction("editor", ActionRollback, `{"revision":5}`)
+		Expect(code).To(Equal(http.StatusBadRequest))
+	})
+})
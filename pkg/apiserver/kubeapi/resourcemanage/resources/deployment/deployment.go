@@ -18,6 +18,7 @@ package deployment
 
 import (
 	"context"
+	"net/http"
 	"sync"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -48,6 +49,9 @@ func init() {
 }
 
 func handle(extendCtx resourcemanage.ExtendContext) (interface{}, *errcode.ErrorInfo) {
+	if extendCtx.Action == http.MethodPost {
+		return handleAction(extendCtx)
+	}
 	access := resources.NewSimpleAccess(extendCtx.Cluster, extendCtx.Username, extendCtx.Namespace)
 	if allow := access.AccessAllow("apps", "deployments", "list"); !allow {
 		return nil, errcode.ForbiddenErr
@@ -133,26 +137,7 @@ func (d *Deployment) getDeployExtendInfo(deployment appsv1.Deployment) ExtentDep
 	podsStatus := PodStatus{}
 	podsStatus.Current = deployment.Status.Replicas
 	podsStatus.Desired = deployment.Spec.Replicas
-	var succeeded, running, pending, failed, unknown int32
-	for _, p := range realPodList.Items {
-		switch p.Status.Phase {
-		case corev1.PodSucceeded:
-			succeeded++
-		case corev1.PodRunning:
-			running++
-		case corev1.PodPending:
-			pending++
-		case corev1.PodFailed:
-			failed++
-		default:
-			unknown++
-		}
-	}
-	podsStatus.Succeeded = succeeded
-	podsStatus.Running = running
-	podsStatus.Pending = pending
-	podsStatus.Failed = failed
-	podsStatus.Unknown = unknown
+	podsStatus.CountPhases(realPodList.Items)
 	podsStatus.Warning = warningEventList
 
 	// create result map
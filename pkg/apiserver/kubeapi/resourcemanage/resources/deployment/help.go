@@ -24,6 +24,8 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mgrclient "github.com/saashqdev/kubeworkz/pkg/multicluster/client"
 )
 
 type ExtendEvent struct {
@@ -72,10 +74,33 @@ func isPodReadyOrSucceed(pod *corev1.Pod) bool {
 	return false
 }
 
+// CountPhases counts pods by phase
+func (s *PodStatus) CountPhases(pods []corev1.Pod) {
+	for _, p := range pods {
+		switch p.Status.Phase {
+		case corev1.PodSucceeded:
+			s.Succeeded++
+		case corev1.PodRunning:
+			s.Running++
+		case corev1.PodPending:
+			s.Pending++
+		case corev1.PodFailed:
+			s.Failed++
+		default:
+			s.Unknown++
+		}
+	}
+}
+
 func (d *Deployment) getWarningEventsByPodList(podList *corev1.PodList) ([]ExtendEvent, error) {
+	return GetWarningEventsByPodList(d.ctx, d.client, d.namespace, podList)
+}
+
+// GetWarningEventsByPodList gets warning events of pods which are not ready
+func GetWarningEventsByPodList(ctx context.Context, cli mgrclient.Client, namespace string, podList *corev1.PodList) ([]ExtendEvent, error) {
 	// kubectl get ev --field-selector="involvedObject.uid=1a58441c-3c03-4267-85d1-a81f0c268d62,type=Warning"
 	resultEventList := make([]ExtendEvent, 0)
-	ctx, cancelFunc := context.WithTimeout(d.ctx, time.Second*10)
+	ctx, cancelFunc := context.WithTimeout(ctx, time.Second*10)
 	defer cancelFunc()
 	for _, pod := range podList.Items {
 		if isPodReadyOrSucceed(&pod) {
@@ -85,12 +110,12 @@ func (d *Deployment) getWarningEventsByPodList(podList *corev1.PodList) ([]Exten
 		fieldSelector["involvedObject.uid"] = string(pod.GetUID())
 		fieldSelector["type"] = "Warning"
 		listOptions := &client.ListOptions{
-			Namespace:     d.namespace,
+			Namespace:     namespace,
 			FieldSelector: fields.SelectorFromSet(fieldSelector),
 		}
 
 		eventList := corev1.EventList{}
-		err := d.client.Direct().List(ctx, &eventList, listOptions)
+		err := cli.Direct().List(ctx, &eventList, listOptions)
 		if err != nil {
 			return nil, err
 		}
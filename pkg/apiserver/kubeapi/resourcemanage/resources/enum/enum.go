@@ -32,4 +32,9 @@ const (
 	NodeResourceType                  ResourceTypeEnum = "nodes"
 	NodeExportResourceType            ResourceTypeEnum = "nodeExport"
 	ReplicasetType                    ResourceTypeEnum = "replicasets"
+	StatefulSetResourceType           ResourceTypeEnum = "statefulsets"
+	DaemonSetResourceType             ResourceTypeEnum = "daemonsets"
+	IngressResourceType               ResourceTypeEnum = "ingresses"
+	HpaResourceType                   ResourceTypeEnum = "horizontalpodautoscalers"
+	TopologyResourceType              ResourceTypeEnum = "topology"
 )
@@ -0,0 +1,373 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package externalresource
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	extensionv1 "github.com/saashqdev/kubeworkz/pkg/apis/extension/v1"
+	resourcemanage "github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/handle"
+	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources"
+	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources/topology"
+	"github.com/saashqdev/kubeworkz/pkg/clients"
+	"github.com/saashqdev/kubeworkz/pkg/clog"
+	mgrclient "github.com/saashqdev/kubeworkz/pkg/multicluster/client"
+	"github.com/saashqdev/kubeworkz/pkg/utils/errcode"
+	"github.com/saashqdev/kubeworkz/pkg/utils/filter"
+)
+
+// CustomResource serves list and detail view of custom resource by display
+// metadata of ExternalResource
+type CustomResource struct {
+	ctx             context.Context
+	client          mgrclient.Client
+	namespace       string
+	display         *display
+	access          *resources.Access
+	filterCondition *filter.Condition
+}
+
+type ExtendObject struct {
+	Columns []Column                   `json:"columns"`
+	Health  Health                     `json:"health"`
+	Links   []Link                     `json:"links,omitempty"`
+	Object  *unstructured.Unstructured `json:"object"`
+}
+
+type Column struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type Health struct {
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// Link the objects related to object, they are resolved in detail view only
+type Link struct {
+	Name       string `json:"name"`
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// Allowed whether user is allowed to read related objects, existence of
+	// objects is unknown when not allowed
+	Allowed bool         `json:"allowed"`
+	Objects []LinkObject `json:"objects"`
+}
+
+type LinkObject struct {
+	Name   string `json:"name"`
+	Exists bool   `json:"exists"`
+}
+
+// display the parsed display metadata of ExternalResource
+type display struct {
+	resource    string
+	gvk         schema.GroupVersionKind
+	columns     []column
+	healthRules []healthRule
+	links       []link
+}
+
+type column struct {
+	name string
+	path *path
+}
+
+type healthRule struct {
+	extensionv1.HealthRule
+	path    *path
+	message *path
+}
+
+type link struct {
+	extensionv1.RelatedLink
+	gvk      schema.GroupVersionKind
+	resource string
+	name     *path
+}
+
+func init() {
+	resourcemanage.SetDefaultExtendHandler(handle)
+}
+
+// handle api/v1/kube/extend/clusters/{cluster}/namespaces/{namespace}/{resourceType}/{resourceName}
+// and api/v1/kube/extend/clusters/{cluster}/resources/{resourceType}/{resourceName} for resource
+// type which is the name of ExternalResource with display metadata
+func handle(param resourcemanage.ExtendContext) (interface{}, *errcode.ErrorInfo) {
+	kubernetes := clients.Interface().Kubernetes(param.Cluster)
+	if kubernetes == nil {
+		return nil, errcode.ClusterNotFoundError(param.Cluster)
+	}
+	externalResource := &extensionv1.ExternalResource{}
+	err := kubernetes.Cache().Get(context.Background(), types.NamespacedName{Name: param.ResourceType}, externalResource)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, errcode.InvalidResourceTypeErr
+		}
+		clog.Error("get external resource %s fail, %v", param.ResourceType, err)
+		return nil, errcode.BadRequest(err)
+	}
+	if externalResource.Spec.Display == nil {
+		return nil, errcode.InvalidResourceTypeErr
+	}
+	if param.Action != http.MethodGet {
+		return nil, errcode.InvalidHttpMethod
+	}
+	if !externalResource.Spec.Namespaced && len(param.Namespace) > 0 {
+		return nil, errcode.BadRequest(fmt.Errorf("resource %s is cluster scoped", param.ResourceType))
+	}
+	if externalResource.Spec.Namespaced && len(param.Namespace) == 0 && len(param.ResourceName) > 0 {
+		return nil, errcode.ParamsMissing("namespace")
+	}
+	d, err := newDisplay(externalResource)
+	if err != nil {
+		return nil, errcode.BadRequest(err)
+	}
+	verb := "list"
+	if len(param.ResourceName) > 0 {
+		verb = "get"
+	}
+	access := resources.NewSimpleAccess(param.Cluster, param.Username, param.Namespace)
+	if allow := access.AccessAllow(d.gvk.Group, d.resource, verb); !allow {
+		return nil, errcode.ForbiddenErr
+	}
+	customResource := NewCustomResource(kubernetes, param.Namespace, d, access, param.FilterCondition)
+	if len(param.ResourceName) > 0 {
+		return customResource.getExtendObject(param.ResourceName)
+	}
+	return customResource.getExtendObjects()
+}
+
+func NewCustomResource(client mgrclient.Client, namespace string, d *display, access *resources.Access, condition *filter.Condition) CustomResource {
+	ctx := context.Background()
+	return CustomResource{
+		ctx:             ctx,
+		client:          client,
+		namespace:       namespace,
+		display:         d,
+		access:          access,
+		filterCondition: condition,
+	}
+}
+
+// newDisplay parses JSONPaths of display metadata of ExternalResource
+func newDisplay(externalResource *extensionv1.ExternalResource) (*display, error) {
+	spec := externalResource.Spec.Display
+	if len(spec.Version) == 0 || len(spec.Kind) == 0 {
+		return nil, fmt.Errorf("version and kind of external resource %s are required", externalResource.Name)
+	}
+	d := &display{
+		resource: externalResource.Name,
+		gvk:      schema.GroupVersionKind{Group: spec.Group, Version: spec.Version, Kind: spec.Kind},
+	}
+	for _, c := range spec.PrinterColumns {
+		p, err := parsePath("column "+c.Name, c.JSONPath)
+		if err != nil {
+			return nil, err
+		}
+		d.columns = append(d.columns, column{name: c.Name, path: p})
+	}
+	for i, rule := range spec.HealthRules {
+		r := healthRule{HealthRule: rule}
+		var err error
+		if r.path, err = parsePath(fmt.Sprintf("health rule %d", i), rule.JSONPath); err != nil {
+			return nil, err
+		}
+		if len(rule.MessageJSONPath) > 0 {
+			if r.message, err = parsePath(fmt.Sprintf("message of health rule %d", i), rule.MessageJSONPath); err != nil {
+				return nil, err
+			}
+		}
+		d.healthRules = append(d.healthRules, r)
+	}
+	for _, l := range spec.RelatedLinks {
+		if len(l.Version) == 0 || len(l.Kind) == 0 {
+			return nil, fmt.Errorf("version and kind of link %s are required", l.Name)
+		}
+		r := link{RelatedLink: l, gvk: schema.GroupVersionKind{Group: l.Group, Version: l.Version, Kind: l.Kind}, resource: l.Resource}
+		if len(r.resource) == 0 {
+			plural, _ := meta.UnsafeGuessKindToResource(r.gvk)
+			r.resource = plural.Resource
+		}
+		if !l.Owned && len(l.NameJSONPath) > 0 {
+			var err error
+			if r.name, err = parsePath("link "+l.Name, l.NameJSONPath); err != nil {
+				return nil, err
+			}
+		}
+		d.links = append(d.links, r)
+	}
+	return d, nil
+}
+
+// getExtendObjects get custom resources with columns and health
+func (c *CustomResource) getExtendObjects() (*unstructured.Unstructured, *errcode.ErrorInfo) {
+	resultMap := make(map[string]interface{})
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(c.display.gvk.GroupVersion().WithKind(c.display.gvk.Kind + "List"))
+	err := c.client.Direct().List(c.ctx, list, client.InNamespace(c.namespace))
+	if err != nil {
+		clog.Error("can not find %s in %s from cluster, %v", c.display.resource, c.namespace, err)
+		return nil, errcode.BadRequest(err)
+	}
+	// filterCondition list by selector/sort/page
+	total, err := filter.GetEmptyFilter().FilterObjectList(list, c.filterCondition)
+	if err != nil {
+		clog.Error("filterCondition %s list error, err: %s", c.display.resource, err.Error())
+		return nil, errcode.BadRequest(err)
+	}
+
+	resultList := make([]ExtendObject, 0, len(list.Items))
+	for i := range list.Items {
+		resultList = append(resultList, c.extend(&list.Items[i]))
+	}
+	resultMap["total"] = total
+	resultMap["items"] = resultList
+
+	return &unstructured.Unstructured{
+		Object: resultMap,
+	}, nil
+}
+
+// getExtendObject get custom resource with columns, health and related links
+func (c *CustomResource) getExtendObject(name string) (*ExtendObject, *errcode.ErrorInfo) {
+	object := &unstructured.Unstructured{}
+	object.SetGroupVersionKind(c.display.gvk)
+	err := c.client.Direct().Get(c.ctx, types.NamespacedName{Namespace: c.namespace, Name: name}, object)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, errcode.NotFoundErr
+		}
+		clog.Error("get %s %s fail, %v", c.display.resource, name, err)
+		return nil, errcode.BadRequest(err)
+	}
+	result := c.extend(object)
+	result.Links = make([]Link, 0, len(c.display.links))
+	for i := range c.display.links {
+		result.Links = append(result.Links, c.getLink(object, &c.display.links[i]))
+	}
+	return &result, nil
+}
+
+func (c *CustomResource) extend(object *unstructured.Unstructured) ExtendObject {
+	columns := make([]Column, 0, len(c.display.columns))
+	for _, col := range c.display.columns {
+		columns = append(columns, Column{Name: col.name, Value: col.path.value(object.Object)})
+	}
+	return ExtendObject{
+		Columns: columns,
+		Health:  c.display.health(object.Object),
+		Object:  object,
+	}
+}
+
+// health get health of object by the first matched rule
+func (d *display) health(object map[string]interface{}) Health {
+	for _, rule := range d.healthRules {
+		if !rule.match(rule.path.values(object)) {
+			continue
+		}
+		health := Health{Status: rule.Health}
+		if rule.message != nil {
+			health.Message = rule.message.value(object)
+		}
+		return health
+	}
+	return Health{Status: topology.HealthUnknown}
+}
+
+func (r *healthRule) match(values []string) bool {
+	if len(r.Values) == 0 {
+		return len(values) > 0
+	}
+	for _, value := range values {
+		for _, expected := range r.Values {
+			if value == expected {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getLink resolve related objects of object in its namespace
+func (c *CustomResource) getLink(object *unstructured.Unstructured, l *link) Link {
+	result := Link{
+		Name:       l.Name,
+		APIVersion: l.gvk.GroupVersion().String(),
+		Kind:       l.gvk.Kind,
+		Objects:    []LinkObject{},
+	}
+	namespace := object.GetNamespace()
+	if l.Owned {
+		if result.Allowed = c.access.AccessAllow(l.gvk.Group, l.resource, "list"); !result.Allowed {
+			return result
+		}
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(l.gvk.GroupVersion().WithKind(l.gvk.Kind + "List"))
+		if err := c.client.Direct().List(c.ctx, list, client.InNamespace(namespace)); err != nil {
+			clog.Info("list %s owned by %s fail, %v", l.resource, object.GetName(), err)
+			return result
+		}
+		for _, item := range list.Items {
+			for _, ref := range item.GetOwnerReferences() {
+				if ref.UID == object.GetUID() {
+					result.Objects = append(result.Objects, LinkObject{Name: item.GetName(), Exists: true})
+					break
+				}
+			}
+		}
+		sort.Slice(result.Objects, func(i, j int) bool {
+			return result.Objects[i].Name < result.Objects[j].Name
+		})
+		return result
+	}
+	if l.name == nil {
+		return result
+	}
+	result.Allowed = c.access.AccessAllow(l.gvk.Group, l.resource, "get")
+	seen := make(map[string]bool)
+	for _, name := range l.name.values(object.Object) {
+		if len(name) == 0 || seen[name] {
+			continue
+		}
+		seen[name] = true
+		linkObject := LinkObject{Name: name}
+		if result.Allowed {
+			related := &unstructured.Unstructured{}
+			related.SetGroupVersionKind(l.gvk)
+			err := c.client.Direct().Get(c.ctx, types.NamespacedName{Namespace: namespace, Name: name}, related)
+			if err != nil && !errors.IsNotFound(err) {
+				clog.Info("get %s %s related to %s fail, %v", l.resource, name, object.GetName(), err)
+			}
+			linkObject.Exists = err == nil
+		}
+		result.Objects = append(result.Objects, linkObject)
+	}
+	return result
+}
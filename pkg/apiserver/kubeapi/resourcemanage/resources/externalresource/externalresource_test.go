@@ -0,0 +1,197 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package externalresource
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/saashqdev/kubeworkz/pkg/apis"
+	extensionv1 "github.com/saashqdev/kubeworkz/pkg/apis/extension/v1"
+	resourcemanage "github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/handle"
+	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources/topology"
+	"github.com/saashqdev/kubeworkz/pkg/clients"
+	"github.com/saashqdev/kubeworkz/pkg/multicluster"
+	"github.com/saashqdev/kubeworkz/pkg/multicluster/client/fake"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+	"github.com/saashqdev/kubeworkz/pkg/utils/errcode"
+	"github.com/saashqdev/kubeworkz/pkg/utils/filter"
+)
+
+var _ = Describe("ExternalResource", func() {
+	var ns = "namespace-test"
+
+	JustBeforeEach(func() {
+		scheme := runtime.NewScheme()
+		apis.AddToScheme(scheme)
+		corev1.AddToScheme(scheme)
+		rbacv1.AddToScheme(scheme)
+		newDatabase := func(name string, uid string, ready string) *unstructured.Unstructured {
+			db := &unstructured.Unstructured{Object: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"engine":     "postgres",
+					"replicas":   int64(2),
+					"secretName": name + "-credentials",
+				},
+			}}
+			if len(ready) > 0 {
+				db.Object["status"] = map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Ready", "status": ready, "message": "replica lagging"},
+					},
+				}
+			}
+			db.SetAPIVersion("example.io/v1")
+			db.SetKind("Database")
+			db.SetName(name)
+			db.SetNamespace(ns)
+			db.SetUID(types.UID(uid))
+			return db
+		}
+		backup := &unstructured.Unstructured{}
+		backup.SetAPIVersion("example.io/v1")
+		backup.SetKind("Backup")
+		backup.SetName("orders-daily")
+		backup.SetNamespace(ns)
+		backup.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "example.io/v1", Kind: "Database", Name: "orders", UID: "ordersid"}})
+		opts := &fake.Options{
+			Scheme: scheme,
+			Objs: []client.Object{
+				&extensionv1.ExternalResource{
+					ObjectMeta: metav1.ObjectMeta{Name: "databases"},
+					Spec: extensionv1.ExternalResourceSpec{
+						Namespaced: true,
+						Display: &extensionv1.DisplaySpec{
+							Group:   "example.io",
+							Version: "v1",
+							Kind:    "Database",
+							PrinterColumns: []extensionv1.PrinterColumn{
+								{Name: "Engine", JSONPath: ".spec.engine"},
+								{Name: "Replicas", JSONPath: "{.spec.replicas}"},
+								{Name: "Version", JSONPath: ".status.version"},
+							},
+							HealthRules: []extensionv1.HealthRule{
+								{JSONPath: `.status.conditions[?(@.type=="Ready")].status`, Values: []string{"True"}, Health: topology.HealthHealthy},
+								{JSONPath: `.status.conditions[?(@.type=="Ready")].status`, Values: []string{"False"}, Health: topology.HealthDegraded,
+									MessageJSONPath: `.status.conditions[?(@.type=="Ready")].message`},
+							},
+							RelatedLinks: []extensionv1.RelatedLink{
+								{Name: "credentials", Version: "v1", Kind: "Secret", NameJSONPath: ".spec.secretName"},
+								{Name: "config", Version: "v1", Kind: "ConfigMap", NameJSONPath: ".spec.secretName"},
+								{Name: "backups", Group: "example.io", Version: "v1", Kind: "Backup", Owned: true},
+							},
+						},
+					},
+				},
+				&extensionv1.ExternalResource{ObjectMeta: metav1.ObjectMeta{Name: "bookinfo"}},
+				newDatabase("orders", "ordersid", "False"),
+				newDatabase("users", "usersid", "True"),
+				newDatabase("legacy", "legacyid", ""),
+				backup,
+				&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "orders-credentials", Namespace: ns}},
+				&rbacv1.ClusterRole{
+					ObjectMeta: metav1.ObjectMeta{Name: "viewer"},
+					Rules: []rbacv1.PolicyRule{{
+						APIGroups: []string{"", "example.io"},
+						Resources: []string{"databases", "backups", "secrets"},
+						Verbs:     []string{"get", "list"},
+					}},
+				},
+				&rbacv1.ClusterRoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "viewer"},
+					RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "viewer"},
+					Subjects:   []rbacv1.Subject{{Kind: rbacv1.UserKind, Name: "viewer"}},
+				},
+			},
+			ClientSetRuntimeObjs: []runtime.Object{},
+			Lists:                []client.ObjectList{},
+		}
+		multicluster.InitFakeMultiClusterMgrWithOpts(opts)
+		clients.InitKubeClientSetWithOpts(nil)
+	})
+
+	newContext := func(user string, resourceType string, resourceName string) resourcemanage.ExtendContext {
+		return resourcemanage.ExtendContext{
+			Cluster:         constants.LocalCluster,
+			Namespace:       ns,
+			Username:        user,
+			Action:          http.MethodGet,
+			ResourceType:    resourceType,
+			ResourceName:    resourceName,
+			FilterCondition: &filter.Condition{Limit: 10, Offset: 0},
+		}
+	}
+
+	It("test unknown resource type", func() {
+		_, err := handle(newContext("viewer", "unknown", ""))
+		Expect(err).To(Equal(errcode.InvalidResourceTypeErr))
+		_, err = handle(newContext("viewer", "bookinfo", ""))
+		Expect(err).To(Equal(errcode.InvalidResourceTypeErr))
+	})
+
+	It("test forbidden", func() {
+		_, err := handle(newContext("guest", "databases", ""))
+		Expect(err).To(Equal(errcode.ForbiddenErr))
+	})
+
+	It("test list custom resources", func() {
+		ret, err := handle(newContext("viewer", "databases", ""))
+		Expect(err).To(BeNil())
+		list := ret.(*unstructured.Unstructured)
+		Expect(list.Object["total"]).To(Equal(3))
+		result := make(map[string]ExtendObject)
+		for _, item := range list.Object["items"].([]ExtendObject) {
+			result[item.Object.GetName()] = item
+		}
+		Expect(result["orders"].Columns).To(Equal([]Column{
+			{Name: "Engine", Value: "postgres"},
+			{Name: "Replicas", Value: "2"},
+			{Name: "Version", Value: ""},
+		}))
+		Expect(result["orders"].Health).To(Equal(Health{Status: topology.HealthDegraded, Message: "replica lagging"}))
+		Expect(result["users"].Health).To(Equal(Health{Status: topology.HealthHealthy}))
+		Expect(result["legacy"].Health).To(Equal(Health{Status: topology.HealthUnknown}))
+		Expect(result["orders"].Links).To(BeNil())
+	})
+
+	It("test get custom resource with links", func() {
+		ret, err := handle(newContext("viewer", "databases", "orders"))
+		Expect(err).To(BeNil())
+		object := ret.(*ExtendObject)
+		Expect(object.Object.GetName()).To(Equal("orders"))
+		Expect(object.Links).To(Equal([]Link{
+			{Name: "credentials", APIVersion: "v1", Kind: "Secret", Allowed: true,
+				Objects: []LinkObject{{Name: "orders-credentials", Exists: true}}},
+			{Name: "config", APIVersion: "v1", Kind: "ConfigMap",
+				Objects: []LinkObject{{Name: "orders-credentials"}}},
+			{Name: "backups", APIVersion: "example.io/v1", Kind: "Backup", Allowed: true,
+				Objects: []LinkObject{{Name: "orders-daily", Exists: true}}},
+		}))
+
+		_, err = handle(newContext("viewer", "databases", "none"))
+		Expect(err).To(Equal(errcode.NotFoundErr))
+	})
+})
@@ -0,0 +1,81 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package externalresource
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// path a parsed JSONPath of display metadata
+type path struct {
+	*jsonpath.JSONPath
+}
+
+// parsePath parses simple JSONPath such as .status.phase, template form
+// such as {.status.phase} is accepted too
+func parsePath(name string, expr string) (*path, error) {
+	if !strings.HasPrefix(expr, "{") {
+		expr = "{" + expr + "}"
+	}
+	p := jsonpath.New(name).AllowMissingKeys(true)
+	if err := p.Parse(expr); err != nil {
+		return nil, fmt.Errorf("invalid JSONPath %q of %s: %v", expr, name, err)
+	}
+	return &path{JSONPath: p}, nil
+}
+
+// values returns all values found in object by path, they are formatted as
+// json except for strings
+func (p *path) values(object map[string]interface{}) []string {
+	results, err := p.FindResults(object)
+	if err != nil {
+		return nil
+	}
+	var values []string
+	for _, result := range results {
+		for _, value := range result {
+			if !value.IsValid() || !value.CanInterface() {
+				continue
+			}
+			values = append(values, format(value.Interface()))
+		}
+	}
+	return values
+}
+
+// value returns values found in object by path joined by comma
+func (p *path) value(object map[string]interface{}) string {
+	return strings.Join(p.values(object), ",")
+}
+
+func format(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
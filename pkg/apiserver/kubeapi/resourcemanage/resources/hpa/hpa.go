@@ -0,0 +1,179 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hpa
+
+import (
+	"context"
+	"time"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	resourcemanage "github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/handle"
+	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources"
+	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources/deployment"
+	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources/enum"
+	"github.com/saashqdev/kubeworkz/pkg/clients"
+	"github.com/saashqdev/kubeworkz/pkg/clog"
+	"github.com/saashqdev/kubeworkz/pkg/conversion"
+	"github.com/saashqdev/kubeworkz/pkg/utils/errcode"
+	"github.com/saashqdev/kubeworkz/pkg/utils/filter"
+)
+
+type Hpa struct {
+	ctx             context.Context
+	client          client.Client
+	cache           cache.Cache
+	namespace       string
+	filterCondition *filter.Condition
+}
+
+type ExtendHpa struct {
+	Target  Target                   `json:"target"`
+	Warning []deployment.ExtendEvent `json:"warning,omitempty"`
+	autoscalingv2.HorizontalPodAutoscaler
+}
+
+// Target the status of workload scaled by hpa
+type Target struct {
+	// Exists whether the workload exists
+	Exists  bool   `json:"exists"`
+	Current int64  `json:"current,omitempty"`
+	Desired *int64 `json:"desired,omitempty"`
+}
+
+func init() {
+	resourcemanage.SetExtendHandler(enum.HpaResourceType, handle)
+}
+
+func handle(param resourcemanage.ExtendContext) (interface{}, *errcode.ErrorInfo) {
+	access := resources.NewSimpleAccess(param.Cluster, param.Username, param.Namespace)
+	if allow := access.AccessAllow("autoscaling", "horizontalpodautoscalers", "list"); !allow {
+		return nil, errcode.ForbiddenErr
+	}
+	kubernetes := clients.Interface().Kubernetes(param.Cluster)
+	if kubernetes == nil {
+		return nil, errcode.ClusterNotFoundError(param.Cluster)
+	}
+	convertor, err := conversion.NewVersionConvertor(kubernetes.CacheDiscovery(), kubernetes.RESTMapper())
+	if err != nil {
+		return nil, errcode.BadRequest(err)
+	}
+	client := conversion.WrapClient(kubernetes.Direct(), convertor, true)
+	cache := conversion.WrapCache(kubernetes.Cache(), convertor)
+	hpa := NewHpa(client, cache, param.Namespace, param.FilterCondition)
+	return hpa.getExtendHpas()
+}
+
+func NewHpa(client client.Client, cache cache.Cache, namespace string, condition *filter.Condition) Hpa {
+	ctx := context.Background()
+	return Hpa{
+		ctx:             ctx,
+		client:          client,
+		cache:           cache,
+		namespace:       namespace,
+		filterCondition: condition,
+	}
+}
+
+// getExtendHpas get extend horizontal pod autoscalers
+func (h *Hpa) getExtendHpas() (*unstructured.Unstructured, *errcode.ErrorInfo) {
+	resultMap := make(map[string]interface{})
+	// get hpa list from k8s cluster
+	var hpaList autoscalingv2.HorizontalPodAutoscalerList
+	err := h.cache.List(h.ctx, &hpaList, client.InNamespace(h.namespace))
+	if err != nil {
+		clog.Error("can not find hpa in %s from cluster, %v", h.namespace, err)
+		return nil, errcode.BadRequest(err)
+	}
+	// filterCondition list by selector/sort/page
+	total, err := filter.GetEmptyFilter().FilterObjectList(&hpaList, h.filterCondition)
+	if err != nil {
+		clog.Error("filterCondition hpaList error, err: %s", err.Error())
+		return nil, errcode.BadRequest(err)
+	}
+
+	resultList := make([]ExtendHpa, 0, len(hpaList.Items))
+	for _, hpa := range hpaList.Items {
+		warningEventList, err := h.getWarningEvents(hpa)
+		if err != nil {
+			clog.Info("add extend warning events info to hpa %s fail, %v", hpa.Name, err)
+		}
+		resultList = append(resultList, ExtendHpa{
+			Target:                  h.getTarget(hpa),
+			Warning:                 warningEventList,
+			HorizontalPodAutoscaler: hpa,
+		})
+	}
+	resultMap["total"] = total
+	resultMap["items"] = resultList
+
+	return &unstructured.Unstructured{
+		Object: resultMap,
+	}, nil
+}
+
+// getTarget get replicas of workload referred by scale target of hpa
+func (h *Hpa) getTarget(hpa autoscalingv2.HorizontalPodAutoscaler) Target {
+	ref := hpa.Spec.ScaleTargetRef
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		clog.Info("invalid scale target of hpa %s, %v", hpa.Name, err)
+		return Target{}
+	}
+	workload := &unstructured.Unstructured{}
+	workload.SetGroupVersionKind(gv.WithKind(ref.Kind))
+	err = h.client.Get(h.ctx, types.NamespacedName{Namespace: h.namespace, Name: ref.Name}, workload)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			clog.Info("get scale target of hpa %s fail, %v", hpa.Name, err)
+		}
+		return Target{}
+	}
+	target := Target{Exists: true}
+	target.Current, _, _ = unstructured.NestedInt64(workload.Object, "status", "replicas")
+	if desired, found, _ := unstructured.NestedInt64(workload.Object, "spec", "replicas"); found {
+		target.Desired = &desired
+	}
+	return target
+}
+
+// getWarningEvents get warning events of hpa, such as failures of getting metrics
+func (h *Hpa) getWarningEvents(hpa autoscalingv2.HorizontalPodAutoscaler) ([]deployment.ExtendEvent, error) {
+	ctx, cancelFunc := context.WithTimeout(h.ctx, time.Second*10)
+	defer cancelFunc()
+	listOptions := &client.ListOptions{
+		Namespace:     h.namespace,
+		FieldSelector: fields.SelectorFromSet(fields.Set{"involvedObject.uid": string(hpa.UID), "type": corev1.EventTypeWarning}),
+	}
+	eventList := corev1.EventList{}
+	if err := h.client.List(ctx, &eventList, listOptions); err != nil {
+		return nil, err
+	}
+	resultEventList := make([]deployment.ExtendEvent, 0, len(eventList.Items))
+	for _, event := range eventList.Items {
+		resultEventList = append(resultEventList, deployment.ExtendEvent{Type: corev1.EventTypeWarning, Event: event})
+	}
+	return resultEventList, nil
+}
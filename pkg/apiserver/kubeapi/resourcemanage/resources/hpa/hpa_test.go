@@ -0,0 +1,100 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hpa
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/saashqdev/kubeworkz/pkg/apis"
+	"github.com/saashqdev/kubeworkz/pkg/clients"
+	"github.com/saashqdev/kubeworkz/pkg/conversion"
+	"github.com/saashqdev/kubeworkz/pkg/multicluster"
+	"github.com/saashqdev/kubeworkz/pkg/multicluster/client/fake"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+	"github.com/saashqdev/kubeworkz/pkg/utils/filter"
+)
+
+var _ = Describe("Hpa", func() {
+	var ns = "namespace-test"
+
+	JustBeforeEach(func() {
+		scheme := runtime.NewScheme()
+		apis.AddToScheme(scheme)
+		corev1.AddToScheme(scheme)
+		appsv1.AddToScheme(scheme)
+		autoscalingv2beta2.AddToScheme(scheme)
+		newHpa := func(name, target string) *autoscalingv2beta2.HorizontalPodAutoscaler {
+			return &autoscalingv2beta2.HorizontalPodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+				Spec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+					ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: target},
+					MinReplicas:    pointer.Int32(1),
+					MaxReplicas:    5,
+				},
+			}
+		}
+		opts := &fake.Options{
+			Scheme: scheme,
+			Objs: []client.Object{
+				newHpa("web", "web"),
+				newHpa("gone", "gone"),
+				&appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: ns},
+					Spec:       appsv1.DeploymentSpec{Replicas: pointer.Int32(3)},
+					Status:     appsv1.DeploymentStatus{Replicas: 2},
+				},
+			},
+			ClientSetRuntimeObjs: []runtime.Object{},
+			Lists:                []client.ObjectList{},
+			// cluster serves autoscaling/v2beta2 only, hpa must be converted
+			Resources: []*metav1.APIResourceList{{
+				GroupVersion: autoscalingv2beta2.SchemeGroupVersion.String(),
+				APIResources: []metav1.APIResource{{Name: "horizontalpodautoscalers", Namespaced: true, Kind: "HorizontalPodAutoscaler"}},
+			}},
+		}
+		multicluster.InitFakeMultiClusterMgrWithOpts(opts)
+		clients.InitKubeClientSetWithOpts(nil)
+	})
+
+	It("test get hpa extend info", func() {
+		kubernetes := clients.Interface().Kubernetes(constants.LocalCluster)
+		Expect(kubernetes).NotTo(BeNil())
+		convertor, err := conversion.NewVersionConvertor(kubernetes.CacheDiscovery(), kubernetes.RESTMapper())
+		Expect(err).To(BeNil())
+		hpa := NewHpa(conversion.WrapClient(kubernetes.Direct(), convertor, true), conversion.WrapCache(kubernetes.Cache(), convertor),
+			ns, &filter.Condition{Limit: 10, Offset: 0, SortName: "metadata.name"})
+		ret, errInfo := hpa.getExtendHpas()
+		Expect(errInfo).To(BeNil())
+		Expect(ret.Object["total"]).To(Equal(2))
+		items := ret.Object["items"].([]ExtendHpa)
+		Expect(items[0].Name).To(Equal("gone"))
+		Expect(items[0].Target).To(Equal(Target{}))
+		Expect(items[1].Name).To(Equal("web"))
+		Expect(items[1].Spec.MaxReplicas).To(Equal(int32(5)))
+		Expect(items[1].Target.Exists).To(BeTrue())
+		Expect(items[1].Target.Current).To(Equal(int64(2)))
+		Expect(*items[1].Target.Desired).To(Equal(int64(3)))
+	})
+})
@@ -0,0 +1,196 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	resourcemanage "github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/handle"
+	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources"
+	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources/enum"
+	"github.com/saashqdev/kubeworkz/pkg/clients"
+	"github.com/saashqdev/kubeworkz/pkg/clog"
+	"github.com/saashqdev/kubeworkz/pkg/conversion"
+	"github.com/saashqdev/kubeworkz/pkg/utils/errcode"
+	"github.com/saashqdev/kubeworkz/pkg/utils/filter"
+)
+
+type Ingress struct {
+	ctx             context.Context
+	cache           cache.Cache
+	namespace       string
+	filterCondition *filter.Condition
+}
+
+type ExtendIngress struct {
+	Backends []Backend `json:"backends,omitempty"`
+	networkingv1.Ingress
+}
+
+// Backend the health of service backend of ingress rule
+type Backend struct {
+	Host    string `json:"host,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Service string `json:"service"`
+	Port    string `json:"port,omitempty"`
+	// Exists whether the backend service exists
+	Exists bool `json:"exists"`
+	// Ready the count of ready endpoints of backend service
+	Ready int `json:"ready"`
+	// NotReady the count of not ready endpoints of backend service
+	NotReady int `json:"notReady"`
+}
+
+func init() {
+	resourcemanage.SetExtendHandler(enum.IngressResourceType, handle)
+}
+
+func handle(param resourcemanage.ExtendContext) (interface{}, *errcode.ErrorInfo) {
+	access := resources.NewSimpleAccess(param.Cluster, param.Username, param.Namespace)
+	if allow := access.AccessAllow("networking.k8s.io", "ingresses", "list"); !allow {
+		return nil, errcode.ForbiddenErr
+	}
+	kubernetes := clients.Interface().Kubernetes(param.Cluster)
+	if kubernetes == nil {
+		return nil, errcode.ClusterNotFoundError(param.Cluster)
+	}
+	convertor, err := conversion.NewVersionConvertor(kubernetes.CacheDiscovery(), kubernetes.RESTMapper())
+	if err != nil {
+		return nil, errcode.BadRequest(err)
+	}
+	cache := conversion.WrapCache(kubernetes.Cache(), convertor)
+	ingress := NewIngress(cache, param.Namespace, param.FilterCondition)
+	return ingress.getExtendIngresses()
+}
+
+func NewIngress(cache cache.Cache, namespace string, condition *filter.Condition) Ingress {
+	ctx := context.Background()
+	return Ingress{
+		ctx:             ctx,
+		cache:           cache,
+		namespace:       namespace,
+		filterCondition: condition,
+	}
+}
+
+// getExtendIngresses get extend ingresses
+func (i *Ingress) getExtendIngresses() (*unstructured.Unstructured, *errcode.ErrorInfo) {
+	resultMap := make(map[string]interface{})
+	// get ingress list from k8s cluster
+	var ingressList networkingv1.IngressList
+	err := i.cache.List(i.ctx, &ingressList, client.InNamespace(i.namespace))
+	if err != nil {
+		clog.Error("can not find ingress in %s from cluster, %v", i.namespace, err)
+		return nil, errcode.BadRequest(err)
+	}
+	// filterCondition list by selector/sort/page
+	total, err := filter.GetEmptyFilter().FilterObjectList(&ingressList, i.filterCondition)
+	if err != nil {
+		clog.Error("filterCondition ingressList error, err: %s", err.Error())
+		return nil, errcode.BadRequest(err)
+	}
+
+	resultList := make([]ExtendIngress, 0, len(ingressList.Items))
+	for _, ingress := range ingressList.Items {
+		resultList = append(resultList, ExtendIngress{
+			Backends: i.getBackends(ingress),
+			Ingress:  ingress,
+		})
+	}
+	resultMap["total"] = total
+	resultMap["items"] = resultList
+
+	return &unstructured.Unstructured{
+		Object: resultMap,
+	}, nil
+}
+
+// getBackends get health of service backends of default backend and rules
+func (i *Ingress) getBackends(ingress networkingv1.Ingress) []Backend {
+	var backends []Backend
+	if b := ingress.Spec.DefaultBackend; b != nil && b.Service != nil {
+		backends = append(backends, i.getBackend("", "", b.Service))
+	}
+	for _, rule := range ingress.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			if path.Backend.Service == nil {
+				continue
+			}
+			backends = append(backends, i.getBackend(rule.Host, path.Path, path.Backend.Service))
+		}
+	}
+	return backends
+}
+
+func (i *Ingress) getBackend(host, path string, svc *networkingv1.IngressServiceBackend) Backend {
+	backend := Backend{Host: host, Path: path, Service: svc.Name, Port: svc.Port.Name}
+	if svc.Port.Number > 0 {
+		backend.Port = strconv.Itoa(int(svc.Port.Number))
+	}
+
+	service := corev1.Service{}
+	err := i.cache.Get(i.ctx, types.NamespacedName{Namespace: i.namespace, Name: svc.Name}, &service)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			clog.Info("get backend service %s/%s fail, %v", i.namespace, svc.Name, err)
+		}
+		return backend
+	}
+	backend.Exists = true
+
+	// endpoint ports are named after service ports
+	portName, found := "", false
+	for _, port := range service.Spec.Ports {
+		if (len(svc.Port.Name) > 0 && port.Name == svc.Port.Name) || (svc.Port.Number > 0 && port.Port == svc.Port.Number) {
+			portName, found = port.Name, true
+			break
+		}
+	}
+	if !found {
+		return backend
+	}
+	endpoints := corev1.Endpoints{}
+	err = i.cache.Get(i.ctx, types.NamespacedName{Namespace: i.namespace, Name: svc.Name}, &endpoints)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			clog.Info("get endpoints of backend service %s/%s fail, %v", i.namespace, svc.Name, err)
+		}
+		return backend
+	}
+	for _, subset := range endpoints.Subsets {
+		for _, port := range subset.Ports {
+			if port.Name == portName {
+				backend.Ready += len(subset.Addresses)
+				backend.NotReady += len(subset.NotReadyAddresses)
+				break
+			}
+		}
+	}
+	return backend
+}
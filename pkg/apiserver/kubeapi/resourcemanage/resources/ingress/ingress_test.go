@@ -0,0 +1,116 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/saashqdev/kubeworkz/pkg/apis"
+	"github.com/saashqdev/kubeworkz/pkg/clients"
+	"github.com/saashqdev/kubeworkz/pkg/conversion"
+	"github.com/saashqdev/kubeworkz/pkg/multicluster"
+	"github.com/saashqdev/kubeworkz/pkg/multicluster/client/fake"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+	"github.com/saashqdev/kubeworkz/pkg/utils/filter"
+)
+
+var _ = Describe("Ingress", func() {
+	var ns = "namespace-test"
+
+	JustBeforeEach(func() {
+		scheme := runtime.NewScheme()
+		apis.AddToScheme(scheme)
+		corev1.AddToScheme(scheme)
+		networkingv1.AddToScheme(scheme)
+		pathType := networkingv1.PathTypePrefix
+		newPath := func(path, service string, port networkingv1.ServiceBackendPort) networkingv1.HTTPIngressPath {
+			return networkingv1.HTTPIngressPath{
+				Path:     path,
+				PathType: &pathType,
+				Backend:  networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: service, Port: port}},
+			}
+		}
+		ingress := &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: ns},
+			Spec: networkingv1.IngressSpec{
+				Rules: []networkingv1.IngressRule{{
+					Host: "web.example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{
+							newPath("/", "web", networkingv1.ServiceBackendPort{Number: 80}),
+							newPath("/api", "api", networkingv1.ServiceBackendPort{Name: "http"}),
+						},
+					}},
+				}},
+			},
+		}
+		opts := &fake.Options{
+			Scheme: scheme,
+			Objs: []client.Object{
+				ingress,
+				&corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: ns},
+					Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Port: 80}, {Name: "metrics", Port: 9090}}},
+				},
+				&corev1.Endpoints{
+					ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: ns},
+					Subsets: []corev1.EndpointSubset{
+						{
+							Addresses:         []corev1.EndpointAddress{{IP: "10.0.0.1"}, {IP: "10.0.0.2"}},
+							NotReadyAddresses: []corev1.EndpointAddress{{IP: "10.0.0.3"}},
+							Ports:             []corev1.EndpointPort{{Name: "http", Port: 8080}},
+						},
+						{
+							Addresses: []corev1.EndpointAddress{{IP: "10.0.0.4"}},
+							Ports:     []corev1.EndpointPort{{Name: "metrics", Port: 9090}},
+						},
+					},
+				},
+			},
+			ClientSetRuntimeObjs: []runtime.Object{},
+			Lists:                []client.ObjectList{},
+			Resources: []*metav1.APIResourceList{{
+				GroupVersion: networkingv1.SchemeGroupVersion.String(),
+				APIResources: []metav1.APIResource{{Name: "ingresses", Namespaced: true, Kind: "Ingress"}},
+			}},
+		}
+		multicluster.InitFakeMultiClusterMgrWithOpts(opts)
+		clients.InitKubeClientSetWithOpts(nil)
+	})
+
+	It("test get ingress extend info", func() {
+		client := clients.Interface().Kubernetes(constants.LocalCluster)
+		Expect(client).NotTo(BeNil())
+		convertor, err := conversion.NewVersionConvertor(client.CacheDiscovery(), client.RESTMapper())
+		Expect(err).To(BeNil())
+		ingress := NewIngress(conversion.WrapCache(client.Cache(), convertor), ns, &filter.Condition{Limit: 10, Offset: 0})
+		ret, errInfo := ingress.getExtendIngresses()
+		Expect(errInfo).To(BeNil())
+		Expect(ret.Object["total"]).To(Equal(1))
+		info := ret.Object["items"].([]ExtendIngress)[0]
+		Expect(info.Backends).To(Equal([]Backend{
+			{Host: "web.example.com", Path: "/", Service: "web", Port: "80", Exists: true, Ready: 2, NotReady: 1},
+			{Host: "web.example.com", Path: "/api", Service: "api", Port: "http"},
+		}))
+	})
+})
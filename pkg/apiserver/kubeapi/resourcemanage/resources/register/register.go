@@ -19,11 +19,17 @@ package register
 import (
 	_ "github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources/configmap"
 	_ "github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources/cronjob"
+	_ "github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources/daemonset"
 	_ "github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources/deployment"
+	_ "github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources/externalresource"
+	_ "github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources/hpa"
+	_ "github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources/ingress"
 	_ "github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources/job"
 	_ "github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources/node"
 	_ "github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources/pod"
 	_ "github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources/pvc"
 	_ "github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources/replicaset"
 	_ "github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources/service"
+	_ "github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources/statefulset"
+	_ "github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources/topology"
 )
@@ -0,0 +1,194 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statefulset
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	resourcemanage "github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/handle"
+	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources"
+	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources/deployment"
+	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources/enum"
+	"github.com/saashqdev/kubeworkz/pkg/clients"
+	"github.com/saashqdev/kubeworkz/pkg/clog"
+	mgrclient "github.com/saashqdev/kubeworkz/pkg/multicluster/client"
+	"github.com/saashqdev/kubeworkz/pkg/utils/errcode"
+	"github.com/saashqdev/kubeworkz/pkg/utils/filter"
+)
+
+type StatefulSet struct {
+	ctx             context.Context
+	client          mgrclient.Client
+	namespace       string
+	filterCondition *filter.Condition
+	// pvcAllowed is true when user is allowed to list pvcs, pvcs of
+	// statefulsets are omitted otherwise
+	pvcAllowed bool
+}
+
+type ExtendStatefulSet struct {
+	deployment.PodStatus `json:"podStatus,omitempty"`
+	// Pvcs the pvcs created from volume claim templates of statefulset
+	Pvcs []corev1.PersistentVolumeClaim `json:"pvcs,omitempty"`
+	appsv1.StatefulSet
+}
+
+func init() {
+	resourcemanage.SetExtendHandler(enum.StatefulSetResourceType, handle)
+}
+
+func handle(param resourcemanage.ExtendContext) (interface{}, *errcode.ErrorInfo) {
+	access := resources.NewSimpleAccess(param.Cluster, param.Username, param.Namespace)
+	if allow := access.AccessAllow("apps", "statefulsets", "list"); !allow {
+		return nil, errcode.ForbiddenErr
+	}
+	kubernetes := clients.Interface().Kubernetes(param.Cluster)
+	if kubernetes == nil {
+		return nil, errcode.ClusterNotFoundError(param.Cluster)
+	}
+	statefulSet := NewStatefulSet(kubernetes, param.Namespace, param.FilterCondition)
+	statefulSet.pvcAllowed = access.AccessAllow("", "persistentvolumeclaims", "list")
+	return statefulSet.getExtendStatefulSets()
+}
+
+func NewStatefulSet(client mgrclient.Client, namespace string, condition *filter.Condition) StatefulSet {
+	ctx := context.Background()
+	return StatefulSet{
+		ctx:             ctx,
+		client:          client,
+		namespace:       namespace,
+		filterCondition: condition,
+	}
+}
+
+// getExtendStatefulSets get extend statefulsets
+func (s *StatefulSet) getExtendStatefulSets() (*unstructured.Unstructured, *errcode.ErrorInfo) {
+	resultMap := make(map[string]interface{})
+	// get statefulset list from k8s cluster
+	var statefulSetList appsv1.StatefulSetList
+	err := s.client.Cache().List(s.ctx, &statefulSetList, client.InNamespace(s.namespace))
+	if err != nil {
+		clog.Error("can not find statefulset in %s from cluster, %v", s.namespace, err)
+		return nil, errcode.BadRequest(err)
+	}
+	// filterCondition list by selector/sort/page
+	total, err := filter.GetEmptyFilter().FilterObjectList(&statefulSetList, s.filterCondition)
+	if err != nil {
+		clog.Error("filterCondition statefulSetList error, err: %s", err.Error())
+		return nil, errcode.BadRequest(err)
+	}
+	// pvcs are listed once and shared by statefulsets
+	var pvcList corev1.PersistentVolumeClaimList
+	if s.pvcAllowed {
+		if err = s.client.Cache().List(s.ctx, &pvcList, client.InNamespace(s.namespace)); err != nil {
+			clog.Info("list pvcs in %s fail, %v", s.namespace, err)
+		}
+	}
+
+	resultList := make([]ExtendStatefulSet, len(statefulSetList.Items))
+	wg := &sync.WaitGroup{}
+	for i, statefulSet := range statefulSetList.Items {
+		wg.Add(1)
+		go func(i int, sts appsv1.StatefulSet) {
+			resultList[i] = s.getStatefulSetExtendInfo(sts, pvcList.Items)
+			wg.Done()
+		}(i, statefulSet)
+	}
+	wg.Wait()
+	resultMap["total"] = total
+	resultMap["items"] = resultList
+
+	return &unstructured.Unstructured{
+		Object: resultMap,
+	}, nil
+}
+
+func (s *StatefulSet) getStatefulSetExtendInfo(sts appsv1.StatefulSet, pvcs []corev1.PersistentVolumeClaim) ExtendStatefulSet {
+	result := ExtendStatefulSet{StatefulSet: sts}
+	if s.pvcAllowed {
+		result.Pvcs = ownedPvcs(sts, pvcs)
+	}
+	podList, err := s.getPodsByStatefulSet(sts)
+	if err != nil {
+		clog.Info("add extend pods info to statefulset %s fail, %v", sts.Name, err)
+		return result
+	}
+
+	// get warning event list by podList
+	warningEventList, err := deployment.GetWarningEventsByPodList(s.ctx, s.client, s.namespace, &podList)
+	if err != nil {
+		clog.Info("add extend warning events info to statefulset %s fail, %v", sts.Name, err)
+	}
+
+	result.Current = sts.Status.Replicas
+	result.Desired = sts.Spec.Replicas
+	result.CountPhases(podList.Items)
+	result.Warning = warningEventList
+	return result
+}
+
+// getPodsByStatefulSet get pods controlled by statefulset
+func (s *StatefulSet) getPodsByStatefulSet(sts appsv1.StatefulSet) (corev1.PodList, error) {
+	if sts.Spec.Selector == nil {
+		return corev1.PodList{}, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(sts.Spec.Selector)
+	if err != nil {
+		return corev1.PodList{}, err
+	}
+	podList := corev1.PodList{}
+	err = s.client.Cache().List(s.ctx, &podList, &client.ListOptions{Namespace: s.namespace, LabelSelector: selector})
+	if err != nil {
+		return corev1.PodList{}, err
+	}
+	pods := podList.Items[:0]
+	for _, pod := range podList.Items {
+		if metav1.IsControlledBy(&pod, &sts) {
+			pods = append(pods, pod)
+		}
+	}
+	podList.Items = pods
+	return podList, nil
+}
+
+// ownedPvcs returns pvcs named {template}-{statefulset}-{ordinal} by
+// statefulset controller from volume claim templates
+func ownedPvcs(sts appsv1.StatefulSet, pvcs []corev1.PersistentVolumeClaim) []corev1.PersistentVolumeClaim {
+	var result []corev1.PersistentVolumeClaim
+	for _, pvc := range pvcs {
+		for _, template := range sts.Spec.VolumeClaimTemplates {
+			ordinal, ok := strings.CutPrefix(pvc.Name, template.Name+"-"+sts.Name+"-")
+			if !ok {
+				continue
+			}
+			if _, err := strconv.Atoi(ordinal); err == nil {
+				result = append(result, pvc)
+				break
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statefulset
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/saashqdev/kubeworkz/pkg/apis"
+	"github.com/saashqdev/kubeworkz/pkg/clients"
+	"github.com/saashqdev/kubeworkz/pkg/multicluster"
+	"github.com/saashqdev/kubeworkz/pkg/multicluster/client/fake"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+	"github.com/saashqdev/kubeworkz/pkg/utils/filter"
+)
+
+var _ = Describe("StatefulSet", func() {
+	var ns = "namespace-test"
+
+	JustBeforeEach(func() {
+		scheme := runtime.NewScheme()
+		apis.AddToScheme(scheme)
+		corev1.AddToScheme(scheme)
+		appsv1.AddToScheme(scheme)
+		labels := map[string]string{"app": "db"}
+		sts := &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: ns, UID: "stsid"},
+			Spec: appsv1.StatefulSetSpec{
+				Replicas:             pointer.Int32(2),
+				Selector:             &metav1.LabelSelector{MatchLabels: labels},
+				VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{ObjectMeta: metav1.ObjectMeta{Name: "data"}}},
+			},
+			Status: appsv1.StatefulSetStatus{Replicas: 2},
+		}
+		newPod := func(name string, phase corev1.PodPhase, owned bool) *corev1.Pod {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns, Labels: labels},
+				Status:     corev1.PodStatus{Phase: phase},
+			}
+			if owned {
+				pod.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(sts, appsv1.SchemeGroupVersion.WithKind("StatefulSet"))}
+			}
+			return pod
+		}
+		newPvc := func(name string) *corev1.PersistentVolumeClaim {
+			return &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns}}
+		}
+		opts := &fake.Options{
+			Scheme: scheme,
+			Objs: []client.Object{
+				sts,
+				newPod("db-0", corev1.PodRunning, true),
+				newPod("db-1", corev1.PodSucceeded, true),
+				newPod("db-debug", corev1.PodRunning, false),
+				newPvc("data-db-0"),
+				newPvc("data-db-1"),
+				newPvc("data-db-backup"),
+				newPvc("data-dbx-0"),
+			},
+			ClientSetRuntimeObjs: []runtime.Object{},
+			Lists:                []client.ObjectList{},
+		}
+		multicluster.InitFakeMultiClusterMgrWithOpts(opts)
+		clients.InitKubeClientSetWithOpts(nil)
+	})
+
+	It("test get statefulset extend info", func() {
+		client := clients.Interface().Kubernetes(constants.LocalCluster)
+		Expect(client).NotTo(BeNil())
+		statefulSet := NewStatefulSet(client, ns, &filter.Condition{Limit: 10, Offset: 0})
+		statefulSet.pvcAllowed = true
+		ret, err := statefulSet.getExtendStatefulSets()
+		Expect(err).To(BeNil())
+		Expect(ret.Object["total"]).To(Equal(1))
+		info := ret.Object["items"].([]ExtendStatefulSet)[0]
+		Expect(info.Name).To(Equal("db"))
+		Expect(info.Current).To(Equal(int32(2)))
+		Expect(*info.Desired).To(Equal(int32(2)))
+		Expect(info.Running).To(Equal(int32(1)))
+		Expect(info.Succeeded).To(Equal(int32(1)))
+		var pvcs []string
+		for _, pvc := range info.Pvcs {
+			pvcs = append(pvcs, pvc.Name)
+		}
+		Expect(pvcs).To(ConsistOf("data-db-0", "data-db-1"))
+	})
+
+	It("omits pvcs when user is not allowed to list pvcs", func() {
+		client := clients.Interface().Kubernetes(constants.LocalCluster)
+		statefulSet := NewStatefulSet(client, ns, &filter.Condition{Limit: 10, Offset: 0})
+		ret, err := statefulSet.getExtendStatefulSets()
+		Expect(err).To(BeNil())
+		info := ret.Object["items"].([]ExtendStatefulSet)[0]
+		Expect(info.Pvcs).To(BeEmpty())
+	})
+})
@@ -0,0 +1,173 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topology
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources/pod"
+)
+
+// Health of nodes of graph
+const (
+	HealthHealthy     = "Healthy"
+	HealthProgressing = "Progressing"
+	HealthDegraded    = "Degraded"
+	HealthSuspended   = "Suspended"
+	HealthUnknown     = "Unknown"
+	// HealthMissing the object is referred but not found
+	HealthMissing = "Missing"
+)
+
+func replicasHealth(desired, ready int32) (string, string) {
+	message := fmt.Sprintf("%d/%d ready", ready, desired)
+	if ready < desired {
+		return HealthProgressing, message
+	}
+	return HealthHealthy, message
+}
+
+func deploymentHealth(deploy *appsv1.Deployment) (string, string) {
+	if deploy.Spec.Paused {
+		return HealthSuspended, "rollout is paused"
+	}
+	for _, cond := range deploy.Status.Conditions {
+		if cond.Type == appsv1.DeploymentProgressing && cond.Status == corev1.ConditionFalse {
+			return HealthDegraded, cond.Message
+		}
+	}
+	desired := int32(1)
+	if deploy.Spec.Replicas != nil {
+		desired = *deploy.Spec.Replicas
+	}
+	return replicasHealth(desired, deploy.Status.AvailableReplicas)
+}
+
+func statefulSetHealth(sts *appsv1.StatefulSet) (string, string) {
+	desired := int32(1)
+	if sts.Spec.Replicas != nil {
+		desired = *sts.Spec.Replicas
+	}
+	return replicasHealth(desired, sts.Status.ReadyReplicas)
+}
+
+func daemonSetHealth(ds *appsv1.DaemonSet) (string, string) {
+	return replicasHealth(ds.Status.DesiredNumberScheduled, ds.Status.NumberReady)
+}
+
+func replicaSetHealth(rs *appsv1.ReplicaSet) (string, string) {
+	desired := int32(1)
+	if rs.Spec.Replicas != nil {
+		desired = *rs.Spec.Replicas
+	}
+	return replicasHealth(desired, rs.Status.ReadyReplicas)
+}
+
+func jobHealth(job *batchv1.Job) (string, string) {
+	for _, cond := range job.Status.Conditions {
+		if cond.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch cond.Type {
+		case batchv1.JobFailed:
+			return HealthDegraded, cond.Message
+		case batchv1.JobComplete:
+			return HealthHealthy, "complete"
+		case batchv1.JobSuspended:
+			return HealthSuspended, cond.Message
+		}
+	}
+	return HealthProgressing, fmt.Sprintf("%d active", job.Status.Active)
+}
+
+func podHealth(p *corev1.Pod) (string, string) {
+	reason := pod.GetPodReason(*p)
+	switch p.Status.Phase {
+	case corev1.PodSucceeded:
+		return HealthHealthy, reason
+	case corev1.PodFailed:
+		return HealthDegraded, reason
+	case corev1.PodPending:
+		for _, status := range p.Status.ContainerStatuses {
+			if status.State.Waiting != nil && status.State.Waiting.Reason != "ContainerCreating" {
+				return HealthDegraded, reason
+			}
+		}
+		return HealthProgressing, reason
+	case corev1.PodRunning:
+		for _, cond := range p.Status.Conditions {
+			if cond.Type == corev1.PodReady && cond.Status == corev1.ConditionTrue {
+				return HealthHealthy, reason
+			}
+		}
+		for _, status := range p.Status.ContainerStatuses {
+			if status.State.Waiting != nil {
+				return HealthDegraded, reason
+			}
+		}
+		return HealthProgressing, reason
+	}
+	return HealthUnknown, reason
+}
+
+// serviceHealth tells whether service with selector has ready endpoints
+func serviceHealth(svc *corev1.Service, endpoints *corev1.Endpoints) (string, string) {
+	if len(svc.Spec.Selector) == 0 {
+		return HealthUnknown, "no selector"
+	}
+	ready, notReady := 0, 0
+	if endpoints != nil {
+		for _, subset := range endpoints.Subsets {
+			ready += len(subset.Addresses)
+			notReady += len(subset.NotReadyAddresses)
+		}
+	}
+	message := fmt.Sprintf("%d ready endpoints", ready)
+	switch {
+	case ready > 0:
+		return HealthHealthy, message
+	case notReady > 0:
+		return HealthProgressing, message
+	}
+	return HealthDegraded, message
+}
+
+func hpaHealth(hpa *autoscalingv2.HorizontalPodAutoscaler) (string, string) {
+	for _, cond := range hpa.Status.Conditions {
+		if (cond.Type == autoscalingv2.AbleToScale || cond.Type == autoscalingv2.ScalingActive) && cond.Status == corev1.ConditionFalse {
+			return HealthDegraded, cond.Message
+		}
+	}
+	return HealthHealthy, fmt.Sprintf("%d/%d replicas", hpa.Status.CurrentReplicas, hpa.Status.DesiredReplicas)
+}
+
+func pvcHealth(pvc *corev1.PersistentVolumeClaim) (string, string) {
+	switch pvc.Status.Phase {
+	case corev1.ClaimBound:
+		return HealthHealthy, string(pvc.Status.Phase)
+	case corev1.ClaimPending:
+		return HealthProgressing, string(pvc.Status.Phase)
+	case corev1.ClaimLost:
+		return HealthDegraded, string(pvc.Status.Phase)
+	}
+	return HealthUnknown, string(pvc.Status.Phase)
+}
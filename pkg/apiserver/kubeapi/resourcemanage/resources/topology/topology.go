@@ -0,0 +1,559 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topology
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	resourcemanage "github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/handle"
+	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources"
+	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources/enum"
+	"github.com/saashqdev/kubeworkz/pkg/clients"
+	"github.com/saashqdev/kubeworkz/pkg/clog"
+	mgrclient "github.com/saashqdev/kubeworkz/pkg/multicluster/client"
+	"github.com/saashqdev/kubeworkz/pkg/utils/errcode"
+)
+
+// Types of edges of graph
+const (
+	EdgeOwns    = "owns"
+	EdgeSelects = "selects"
+	EdgeScales  = "scales"
+	EdgeMounts  = "mounts"
+	EdgeEnv     = "env"
+	EdgeRoutes  = "routes"
+)
+
+// rootCAConfigMap is projected into every pod by cluster, it is not a
+// reference of workload
+const rootCAConfigMap = "kube-root-ca.crt"
+
+// groups the api groups of resources loaded into graph
+var groups = map[string]string{
+	"deployments":              appsv1.GroupName,
+	"replicasets":              appsv1.GroupName,
+	"statefulsets":             appsv1.GroupName,
+	"daemonsets":               appsv1.GroupName,
+	"jobs":                     batchv1.GroupName,
+	"pods":                     corev1.GroupName,
+	"services":                 corev1.GroupName,
+	"ingresses":                networkingv1.GroupName,
+	"horizontalpodautoscalers": autoscalingv2.GroupName,
+	"configmaps":               corev1.GroupName,
+	"secrets":                  corev1.GroupName,
+	"persistentvolumeclaims":   corev1.GroupName,
+}
+
+// kinds maps kinds referred by owner references and scale targets to resources
+var kinds = map[string]string{
+	"Deployment":              "deployments",
+	"ReplicaSet":              "replicasets",
+	"StatefulSet":             "statefulsets",
+	"DaemonSet":               "daemonsets",
+	"Job":                     "jobs",
+	"CronJob":                 "cronjobs",
+	"Pod":                     "pods",
+	"Service":                 "services",
+	"Ingress":                 "ingresses",
+	"HorizontalPodAutoscaler": "horizontalpodautoscalers",
+	"ConfigMap":               "configmaps",
+	"Secret":                  "secrets",
+	"PersistentVolumeClaim":   "persistentvolumeclaims",
+}
+
+// leaves are shared by unrelated workloads, so they are not expanded when
+// traversing unless they are the root
+var leaves = map[string]bool{"configmaps": true, "secrets": true, "persistentvolumeclaims": true}
+
+type Node struct {
+	// ID the id of node as {resource}/{name}
+	ID      string `json:"id"`
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+	Health  string `json:"health"`
+	Message string `json:"message,omitempty"`
+}
+
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Type string `json:"type"`
+}
+
+type Graph struct {
+	Root  string  `json:"root"`
+	Nodes []*Node `json:"nodes"`
+	Edges []Edge  `json:"edges"`
+}
+
+type Topology struct {
+	ctx       context.Context
+	client    mgrclient.Client
+	namespace string
+	access    *resources.Access
+
+	nodes map[string]*Node
+	edges map[Edge]bool
+	// loaded the resources listed, objects of them not found are missing
+	loaded map[string]bool
+	// failed the errors of resources allowed but failed to be listed
+	failed map[string]error
+	uids   map[types.UID]string
+	owners map[string][]metav1.OwnerReference
+	// podSpecs the pod specs of objects whose references are collected
+	podSpecs map[string]*corev1.PodSpec
+
+	pods         []corev1.Pod
+	services     []corev1.Service
+	endpoints    map[string]*corev1.Endpoints
+	ingresses    []networkingv1.Ingress
+	hpas         []autoscalingv2.HorizontalPodAutoscaler
+	statefulSets []appsv1.StatefulSet
+}
+
+func init() {
+	resourcemanage.SetExtendHandler(enum.TopologyResourceType, handle)
+}
+
+// handle api/v1/kube/extend/clusters/{cluster}/namespaces/{namespace}/topology/{name}?kind={resource}
+func handle(param resourcemanage.ExtendContext) (interface{}, *errcode.ErrorInfo) {
+	kind := param.GinContext.Query("kind")
+	if len(kind) == 0 {
+		return nil, errcode.ParamsMissing("kind")
+	}
+	if len(param.ResourceName) == 0 {
+		return nil, errcode.ParamsMissing("resourceName")
+	}
+	group, ok := groups[kind]
+	if !ok {
+		return nil, errcode.BadRequest(fmt.Errorf("unsupported kind %q of topology", kind))
+	}
+	access := resources.NewSimpleAccess(param.Cluster, param.Username, param.Namespace)
+	if allow := access.AccessAllow(group, kind, "list"); !allow {
+		return nil, errcode.ForbiddenErr
+	}
+	kubernetes := clients.Interface().Kubernetes(param.Cluster)
+	if kubernetes == nil {
+		return nil, errcode.ClusterNotFoundError(param.Cluster)
+	}
+	topology := NewTopology(kubernetes, param.Namespace, access)
+	return topology.getGraph(kind + "/" + param.ResourceName)
+}
+
+func NewTopology(client mgrclient.Client, namespace string, access *resources.Access) *Topology {
+	return &Topology{
+		ctx:       context.Background(),
+		client:    client,
+		namespace: namespace,
+		access:    access,
+		nodes:     make(map[string]*Node),
+		edges:     make(map[Edge]bool),
+		loaded:    make(map[string]bool),
+		failed:    make(map[string]error),
+		uids:      make(map[types.UID]string),
+		owners:    make(map[string][]metav1.OwnerReference),
+		podSpecs:  make(map[string]*corev1.PodSpec),
+		endpoints: make(map[string]*corev1.Endpoints),
+	}
+}
+
+// getGraph get graph of objects connected to root
+func (t *Topology) getGraph(root string) (*Graph, *errcode.ErrorInfo) {
+	t.load()
+	if _, ok := t.nodes[root]; !ok {
+		if err, failed := t.failed[strings.Split(root, "/")[0]]; failed {
+			return nil, errcode.BadRequest(err)
+		}
+		return nil, errcode.NotFoundErr
+	}
+	t.link()
+	return t.traverse(root), nil
+}
+
+// load lists objects of namespace allowed to be listed by user as nodes
+func (t *Topology) load() {
+	if list := new(appsv1.DeploymentList); t.list("deployments", list) {
+		for i := range list.Items {
+			item := &list.Items[i]
+			health, message := deploymentHealth(item)
+			id := t.addObject("deployments", "Deployment", item, health, message)
+			t.podSpecs[id] = &item.Spec.Template.Spec
+		}
+	}
+	if list := new(appsv1.ReplicaSetList); t.list("replicasets", list) {
+		for i := range list.Items {
+			item := &list.Items[i]
+			health, message := replicaSetHealth(item)
+			id := t.addObject("replicasets", "ReplicaSet", item, health, message)
+			t.podSpecs[id] = &item.Spec.Template.Spec
+		}
+	}
+	if list := new(appsv1.StatefulSetList); t.list("statefulsets", list) {
+		for i := range list.Items {
+			item := &list.Items[i]
+			health, message := statefulSetHealth(item)
+			id := t.addObject("statefulsets", "StatefulSet", item, health, message)
+			t.podSpecs[id] = &item.Spec.Template.Spec
+		}
+		t.statefulSets = list.Items
+	}
+	if list := new(appsv1.DaemonSetList); t.list("daemonsets", list) {
+		for i := range list.Items {
+			item := &list.Items[i]
+			health, message := daemonSetHealth(item)
+			id := t.addObject("daemonsets", "DaemonSet", item, health, message)
+			t.podSpecs[id] = &item.Spec.Template.Spec
+		}
+	}
+	if list := new(batchv1.JobList); t.list("jobs", list) {
+		for i := range list.Items {
+			item := &list.Items[i]
+			health, message := jobHealth(item)
+			id := t.addObject("jobs", "Job", item, health, message)
+			t.podSpecs[id] = &item.Spec.Template.Spec
+		}
+	}
+	if list := new(corev1.PodList); t.list("pods", list) {
+		for i := range list.Items {
+			item := &list.Items[i]
+			health, message := podHealth(item)
+			id := t.addObject("pods", "Pod", item, health, message)
+			t.podSpecs[id] = &item.Spec
+		}
+		t.pods = list.Items
+	}
+	if list := new(corev1.ServiceList); t.list("services", list) {
+		// health of services is unknown if their endpoints can not be read
+		endpointsList := corev1.EndpointsList{}
+		readable := t.access.AccessAllow(corev1.GroupName, "endpoints", "list")
+		if readable {
+			if err := t.client.Cache().List(t.ctx, &endpointsList, client.InNamespace(t.namespace)); err != nil {
+				clog.Warn("list endpoints of namespace %s fail, %v", t.namespace, err)
+				readable = false
+			}
+		}
+		for i := range endpointsList.Items {
+			t.endpoints[endpointsList.Items[i].Name] = &endpointsList.Items[i]
+		}
+		for i := range list.Items {
+			item := &list.Items[i]
+			health, message := HealthUnknown, "endpoints not readable"
+			if readable {
+				health, message = serviceHealth(item, t.endpoints[item.Name])
+			}
+			t.addObject("services", "Service", item, health, message)
+		}
+		t.services = list.Items
+	}
+	if list := new(networkingv1.IngressList); t.list("ingresses", list) {
+		for i := range list.Items {
+			t.addObject("ingresses", "Ingress", &list.Items[i], HealthHealthy, "")
+		}
+		t.ingresses = list.Items
+	}
+	if list := new(autoscalingv2.HorizontalPodAutoscalerList); t.list("horizontalpodautoscalers", list) {
+		for i := range list.Items {
+			item := &list.Items[i]
+			health, message := hpaHealth(item)
+			t.addObject("horizontalpodautoscalers", "HorizontalPodAutoscaler", item, health, message)
+		}
+		t.hpas = list.Items
+	}
+	if list := new(corev1.PersistentVolumeClaimList); t.list("persistentvolumeclaims", list) {
+		for i := range list.Items {
+			item := &list.Items[i]
+			health, message := pvcHealth(item)
+			t.addObject("persistentvolumeclaims", "PersistentVolumeClaim", item, health, message)
+		}
+	}
+	// data of configmaps and secrets is never needed, only metadata is listed
+	for _, kind := range []string{"ConfigMap", "Secret"} {
+		resource := kinds[kind]
+		list := &metav1.PartialObjectMetadataList{}
+		list.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind(kind + "List"))
+		if !t.listFrom(t.client.Direct(), resource, list) {
+			continue
+		}
+		for i := range list.Items {
+			t.addObject(resource, kind, &list.Items[i], HealthHealthy, "")
+		}
+	}
+}
+
+// list lists objects of resource from cache, see listFrom
+func (t *Topology) list(resource string, list client.ObjectList) bool {
+	return t.listFrom(t.client.Cache(), resource, list)
+}
+
+// listFrom lists objects of resource if user is allowed to. A resource failed
+// to be listed, such as not served by cluster, is skipped rather than failing
+// the graph, objects referred of it are unknown as it is not loaded.
+func (t *Topology) listFrom(reader client.Reader, resource string, list client.ObjectList) bool {
+	if !t.access.AccessAllow(groups[resource], resource, "list") {
+		return false
+	}
+	if err := reader.List(t.ctx, list, client.InNamespace(t.namespace)); err != nil {
+		if meta.IsNoMatchError(err) {
+			clog.Debug("%s is not served by cluster, %v", resource, err)
+		} else {
+			clog.Warn("list %s of namespace %s fail, %v", resource, t.namespace, err)
+		}
+		t.failed[resource] = err
+		return false
+	}
+	t.loaded[resource] = true
+	return true
+}
+
+func (t *Topology) addObject(resource, kind string, obj metav1.Object, health, message string) string {
+	id := resource + "/" + obj.GetName()
+	t.nodes[id] = &Node{ID: id, Kind: kind, Name: obj.GetName(), Health: health, Message: message}
+	t.uids[obj.GetUID()] = id
+	if refs := obj.GetOwnerReferences(); len(refs) > 0 {
+		t.owners[id] = refs
+	}
+	return id
+}
+
+// ref returns id of referred object, the node is added if the object is not
+// loaded, which is missing if its resource is loaded or unknown otherwise
+func (t *Topology) ref(resource, kind, name string) string {
+	id := resource + "/" + name
+	if _, ok := t.nodes[id]; ok {
+		return id
+	}
+	health := HealthUnknown
+	if t.loaded[resource] {
+		health = HealthMissing
+	}
+	t.nodes[id] = &Node{ID: id, Kind: kind, Name: name, Health: health}
+	return id
+}
+
+// resourceOf returns resource of kind referred by owner references and scale
+// targets
+func (t *Topology) resourceOf(apiVersion, kind string) string {
+	if resource, ok := kinds[kind]; ok {
+		return resource
+	}
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err == nil {
+		mapping, err := t.client.Direct().RESTMapper().RESTMapping(gv.WithKind(kind).GroupKind(), gv.Version)
+		if err == nil {
+			return mapping.Resource.Resource
+		}
+	}
+	return strings.ToLower(kind) + "s"
+}
+
+func (t *Topology) addEdge(from, to, edgeType string) {
+	t.edges[Edge{From: from, To: to, Type: edgeType}] = true
+}
+
+// link adds edges between nodes
+func (t *Topology) link() {
+	controlled := make(map[string]bool)
+	for id, refs := range t.owners {
+		for _, ref := range refs {
+			owner, ok := t.uids[ref.UID]
+			if !ok {
+				owner = t.ref(t.resourceOf(ref.APIVersion, ref.Kind), ref.Kind, ref.Name)
+			}
+			t.addEdge(owner, id, EdgeOwns)
+			if ref.Controller != nil && *ref.Controller && ok {
+				controlled[id] = true
+			}
+		}
+	}
+
+	// references are collected from top level objects only, as pod specs of
+	// controlled objects are the same as their controllers
+	for id, spec := range t.podSpecs {
+		if !controlled[id] {
+			t.linkPodSpec(id, spec)
+		}
+	}
+
+	for _, sts := range t.statefulSets {
+		for _, template := range sts.Spec.VolumeClaimTemplates {
+			prefix := "persistentvolumeclaims/" + template.Name + "-" + sts.Name + "-"
+			for id := range t.nodes {
+				if strings.HasPrefix(id, prefix) {
+					t.addEdge("statefulsets/"+sts.Name, id, EdgeOwns)
+				}
+			}
+		}
+	}
+
+	for _, svc := range t.services {
+		if len(svc.Spec.Selector) == 0 {
+			continue
+		}
+		selector := labels.SelectorFromSet(svc.Spec.Selector)
+		for _, pod := range t.pods {
+			if selector.Matches(labels.Set(pod.Labels)) {
+				t.addEdge("services/"+svc.Name, "pods/"+pod.Name, EdgeSelects)
+			}
+		}
+	}
+
+	for _, hpa := range t.hpas {
+		ref := hpa.Spec.ScaleTargetRef
+		target := t.ref(t.resourceOf(ref.APIVersion, ref.Kind), ref.Kind, ref.Name)
+		t.addEdge("horizontalpodautoscalers/"+hpa.Name, target, EdgeScales)
+	}
+
+	for _, ingress := range t.ingresses {
+		id := "ingresses/" + ingress.Name
+		var backends []*networkingv1.IngressServiceBackend
+		if ingress.Spec.DefaultBackend != nil && ingress.Spec.DefaultBackend.Service != nil {
+			backends = append(backends, ingress.Spec.DefaultBackend.Service)
+		}
+		for _, rule := range ingress.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, path := range rule.HTTP.Paths {
+				if path.Backend.Service != nil {
+					backends = append(backends, path.Backend.Service)
+				}
+			}
+		}
+		for _, backend := range backends {
+			svc := t.ref("services", "Service", backend.Name)
+			t.addEdge(id, svc, EdgeRoutes)
+			if t.nodes[svc].Health == HealthMissing {
+				t.nodes[id].Health = HealthDegraded
+				t.nodes[id].Message = fmt.Sprintf("backend service %s not found", backend.Name)
+			}
+		}
+	}
+}
+
+// linkPodSpec adds edges to configmaps, secrets and pvcs referred by volumes
+// and envs of pod spec
+func (t *Topology) linkPodSpec(id string, spec *corev1.PodSpec) {
+	link := func(kind, name string, optional *bool, edgeType string) {
+		resource := kinds[kind]
+		if _, ok := t.nodes[resource+"/"+name]; !ok && optional != nil && *optional {
+			return
+		}
+		t.addEdge(id, t.ref(resource, kind, name), edgeType)
+	}
+	for _, volume := range spec.Volumes {
+		switch {
+		case volume.ConfigMap != nil:
+			link("ConfigMap", volume.ConfigMap.Name, volume.ConfigMap.Optional, EdgeMounts)
+		case volume.Secret != nil:
+			link("Secret", volume.Secret.SecretName, volume.Secret.Optional, EdgeMounts)
+		case volume.PersistentVolumeClaim != nil:
+			link("PersistentVolumeClaim", volume.PersistentVolumeClaim.ClaimName, nil, EdgeMounts)
+		case volume.Projected != nil:
+			for _, source := range volume.Projected.Sources {
+				if source.ConfigMap != nil && source.ConfigMap.Name != rootCAConfigMap {
+					link("ConfigMap", source.ConfigMap.Name, source.ConfigMap.Optional, EdgeMounts)
+				}
+				if source.Secret != nil {
+					link("Secret", source.Secret.Name, source.Secret.Optional, EdgeMounts)
+				}
+			}
+		}
+	}
+	containers := append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, container := range containers {
+		for _, env := range container.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if ref := env.ValueFrom.ConfigMapKeyRef; ref != nil {
+				link("ConfigMap", ref.Name, ref.Optional, EdgeEnv)
+			}
+			if ref := env.ValueFrom.SecretKeyRef; ref != nil {
+				link("Secret", ref.Name, ref.Optional, EdgeEnv)
+			}
+		}
+		for _, envFrom := range container.EnvFrom {
+			if ref := envFrom.ConfigMapRef; ref != nil {
+				link("ConfigMap", ref.Name, ref.Optional, EdgeEnv)
+			}
+			if ref := envFrom.SecretRef; ref != nil {
+				link("Secret", ref.Name, ref.Optional, EdgeEnv)
+			}
+		}
+	}
+}
+
+// traverse returns the graph of nodes connected to root
+func (t *Topology) traverse(root string) *Graph {
+	adjacent := make(map[string][]string)
+	for edge := range t.edges {
+		adjacent[edge.From] = append(adjacent[edge.From], edge.To)
+		adjacent[edge.To] = append(adjacent[edge.To], edge.From)
+	}
+	visited := map[string]bool{root: true}
+	queue := []string{root}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if id != root && leaves[strings.Split(id, "/")[0]] {
+			continue
+		}
+		for _, next := range adjacent[id] {
+			if !visited[next] {
+				visited[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	graph := &Graph{Root: root, Nodes: make([]*Node, 0, len(visited)), Edges: make([]Edge, 0)}
+	for id := range visited {
+		graph.Nodes = append(graph.Nodes, t.nodes[id])
+	}
+	for edge := range t.edges {
+		if visited[edge.From] && visited[edge.To] {
+			graph.Edges = append(graph.Edges, edge)
+		}
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool { return graph.Nodes[i].ID < graph.Nodes[j].ID })
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		a, b := graph.Edges[i], graph.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.Type < b.Type
+	})
+	return graph
+}
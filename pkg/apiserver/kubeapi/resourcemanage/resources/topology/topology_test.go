@@ -0,0 +1,254 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topology
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/saashqdev/kubeworkz/pkg/apis"
+	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources"
+	"github.com/saashqdev/kubeworkz/pkg/clients"
+	"github.com/saashqdev/kubeworkz/pkg/multicluster"
+	"github.com/saashqdev/kubeworkz/pkg/multicluster/client/fake"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+)
+
+var _ = Describe("Topology", func() {
+	var ns = "namespace-test"
+
+	JustBeforeEach(func() {
+		scheme := runtime.NewScheme()
+		apis.AddToScheme(scheme)
+		corev1.AddToScheme(scheme)
+		appsv1.AddToScheme(scheme)
+		autoscalingv2.AddToScheme(scheme)
+		networkingv1.AddToScheme(scheme)
+		rbacv1.AddToScheme(scheme)
+		labels := map[string]string{"app": "web"}
+		podSpec := corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name: "nginx",
+				Env: []corev1.EnvVar{{Name: "TOKEN", ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "web-secret"}, Key: "token"},
+				}}},
+			}},
+			Volumes: []corev1.Volume{
+				{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "web-config"}}}},
+				{Name: "data", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data"}}},
+				{Name: "token", VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{Sources: []corev1.VolumeProjection{
+					{ConfigMap: &corev1.ConfigMapProjection{LocalObjectReference: corev1.LocalObjectReference{Name: rootCAConfigMap}}},
+				}}}},
+			},
+		}
+		dp := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: ns, UID: "dpid"},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: pointer.Int32(1),
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: labels}, Spec: podSpec},
+			},
+			Status: appsv1.DeploymentStatus{AvailableReplicas: 1},
+		}
+		rs := &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: ns, UID: "rsid",
+				OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(dp, appsv1.SchemeGroupVersion.WithKind("Deployment"))}},
+			Spec:   appsv1.ReplicaSetSpec{Replicas: pointer.Int32(1), Template: dp.Spec.Template},
+			Status: appsv1.ReplicaSetStatus{ReadyReplicas: 1},
+		}
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-1-a", Namespace: ns, Labels: labels,
+				OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(rs, appsv1.SchemeGroupVersion.WithKind("ReplicaSet"))}},
+			Spec: podSpec,
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		}
+		// other deployment shares configmap, it is not connected to web
+		other := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: ns, UID: "otherid"},
+			Spec: appsv1.DeploymentSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "other"}},
+				Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Volumes: podSpec.Volumes[:1]}},
+			},
+		}
+		pathType := networkingv1.PathTypePrefix
+		newPath := func(service string) networkingv1.HTTPIngressPath {
+			return networkingv1.HTTPIngressPath{Path: "/" + service, PathType: &pathType, Backend: networkingv1.IngressBackend{
+				Service: &networkingv1.IngressServiceBackend{Name: service, Port: networkingv1.ServiceBackendPort{Number: 80}},
+			}}
+		}
+		opts := &fake.Options{
+			Scheme: scheme,
+			Objs: []client.Object{
+				dp, rs, pod, other,
+				&corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: ns},
+					Spec:       corev1.ServiceSpec{Selector: labels},
+				},
+				&corev1.Endpoints{
+					ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: ns},
+					Subsets:    []corev1.EndpointSubset{{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}}},
+				},
+				&networkingv1.Ingress{
+					ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: ns},
+					Spec: networkingv1.IngressSpec{Rules: []networkingv1.IngressRule{{
+						IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{newPath("web"), newPath("api")},
+						}},
+					}}},
+				},
+				&autoscalingv2.HorizontalPodAutoscaler{
+					ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: ns},
+					Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+						ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"},
+					},
+				},
+				&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "web-config", Namespace: ns}},
+				&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "web-secret", Namespace: ns}},
+				&corev1.PersistentVolumeClaim{
+					ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: ns},
+					Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+				},
+				// job of batch is not registered in scheme, listing jobs fails
+				&corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: "batch-a", Namespace: ns,
+						OwnerReferences: []metav1.OwnerReference{{APIVersion: "batch/v1", Kind: "Job", Name: "batch", UID: "jobid", Controller: pointer.Bool(true)}}},
+				},
+			},
+			ClientSetRuntimeObjs: []runtime.Object{},
+			Lists:                []client.ObjectList{},
+		}
+		resourceNames := []string{"deployments", "replicasets", "jobs", "pods", "services", "ingresses", "horizontalpodautoscalers", "configmaps", "persistentvolumeclaims"}
+		for user, names := range map[string][]string{"viewer": append(resourceNames, "endpoints"), "noendpoints": resourceNames} {
+			opts.Objs = append(opts.Objs,
+				&rbacv1.ClusterRole{
+					ObjectMeta: metav1.ObjectMeta{Name: user},
+					Rules: []rbacv1.PolicyRule{{
+						APIGroups: []string{"", "apps", "batch", "networking.k8s.io", "autoscaling"},
+						Resources: names,
+						Verbs:     []string{"list"},
+					}},
+				},
+				&rbacv1.ClusterRoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: user},
+					RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: user},
+					Subjects:   []rbacv1.Subject{{Kind: rbacv1.UserKind, Name: user}},
+				},
+			)
+		}
+		multicluster.InitFakeMultiClusterMgrWithOpts(opts)
+		clients.InitKubeClientSetWithOpts(nil)
+	})
+
+	getGraphOf := func(user, root string) *Graph {
+		client := clients.Interface().Kubernetes(constants.LocalCluster)
+		Expect(client).NotTo(BeNil())
+		topology := NewTopology(client, ns, resources.NewSimpleAccess(constants.LocalCluster, user, ns))
+		graph, err := topology.getGraph(root)
+		Expect(err).To(BeNil())
+		return graph
+	}
+
+	getGraph := func(root string) *Graph {
+		return getGraphOf("viewer", root)
+	}
+
+	health := func(graph *Graph) map[string]string {
+		result := make(map[string]string)
+		for _, node := range graph.Nodes {
+			result[node.ID] = node.Health
+		}
+		return result
+	}
+
+	It("test get graph of pod", func() {
+		graph := getGraph("pods/web-1-a")
+		Expect(graph.Root).To(Equal("pods/web-1-a"))
+		Expect(health(graph)).To(Equal(map[string]string{
+			"configmaps/web-config":        HealthHealthy,
+			"deployments/web":              HealthHealthy,
+			"horizontalpodautoscalers/web": HealthHealthy,
+			"ingresses/web":                HealthDegraded,
+			"persistentvolumeclaims/data":  HealthProgressing,
+			"pods/web-1-a":                 HealthHealthy,
+			"replicasets/web-1":            HealthHealthy,
+			"secrets/web-secret":           HealthUnknown,
+			"services/api":                 HealthMissing,
+			"services/web":                 HealthHealthy,
+		}))
+		Expect(graph.Edges).To(Equal([]Edge{
+			{From: "deployments/web", To: "configmaps/web-config", Type: EdgeMounts},
+			{From: "deployments/web", To: "persistentvolumeclaims/data", Type: EdgeMounts},
+			{From: "deployments/web", To: "replicasets/web-1", Type: EdgeOwns},
+			{From: "deployments/web", To: "secrets/web-secret", Type: EdgeEnv},
+			{From: "horizontalpodautoscalers/web", To: "deployments/web", Type: EdgeScales},
+			{From: "ingresses/web", To: "services/api", Type: EdgeRoutes},
+			{From: "ingresses/web", To: "services/web", Type: EdgeRoutes},
+			{From: "replicasets/web-1", To: "pods/web-1-a", Type: EdgeOwns},
+			{From: "services/web", To: "pods/web-1-a", Type: EdgeSelects},
+		}))
+	})
+
+	It("test get graph of shared configmap", func() {
+		graph := getGraph("configmaps/web-config")
+		var ids []string
+		for _, node := range graph.Nodes {
+			ids = append(ids, node.ID)
+		}
+		Expect(ids).To(ContainElements("deployments/web", "deployments/other", "pods/web-1-a"))
+	})
+
+	It("test get graph with resource failed to be listed", func() {
+		graph := getGraph("pods/batch-a")
+		Expect(health(graph)).To(Equal(map[string]string{
+			"jobs/batch":   HealthUnknown,
+			"pods/batch-a": HealthUnknown,
+		}))
+
+		client := clients.Interface().Kubernetes(constants.LocalCluster)
+		topology := NewTopology(client, ns, resources.NewSimpleAccess(constants.LocalCluster, "viewer", ns))
+		_, err := topology.getGraph("jobs/batch")
+		Expect(err).NotTo(BeNil())
+		Expect(err.Code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("test get graph without access to endpoints", func() {
+		graph := getGraphOf("noendpoints", "services/web")
+		Expect(health(graph)).To(HaveKeyWithValue("services/web", HealthUnknown))
+	})
+
+	It("test get graph of missing root", func() {
+		client := clients.Interface().Kubernetes(constants.LocalCluster)
+		topology := NewTopology(client, ns, resources.NewSimpleAccess(constants.LocalCluster, "viewer", ns))
+		_, err := topology.getGraph("deployments/none")
+		Expect(err).NotTo(BeNil())
+		Expect(err.Code).To(Equal(http.StatusNotFound))
+	})
+})
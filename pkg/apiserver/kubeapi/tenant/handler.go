@@ -0,0 +1,238 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tenant
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	tenantv1 "github.com/saashqdev/kubeworkz/pkg/apis/tenant/v1"
+	"github.com/saashqdev/kubeworkz/pkg/apiserver/kubeapi/resourcemanage/resources"
+	"github.com/saashqdev/kubeworkz/pkg/authentication/authenticators/token"
+	"github.com/saashqdev/kubeworkz/pkg/clients"
+	"github.com/saashqdev/kubeworkz/pkg/clog"
+	mgrclient "github.com/saashqdev/kubeworkz/pkg/multicluster/client"
+	"github.com/saashqdev/kubeworkz/pkg/utils/access"
+	"github.com/saashqdev/kubeworkz/pkg/utils/archive"
+	"github.com/saashqdev/kubeworkz/pkg/utils/audit"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+	"github.com/saashqdev/kubeworkz/pkg/utils/errcode"
+	"github.com/saashqdev/kubeworkz/pkg/utils/response"
+)
+
+const subPath = "/tenants"
+
+type handler struct {
+	mgrclient.Client
+}
+
+func NewHandler() *handler {
+	h := new(handler)
+	h.Client = clients.Interface().Kubernetes(constants.LocalCluster)
+	return h
+}
+
+func (h *handler) AddApisTo(root *gin.Engine) {
+	r := root.Group(constants.ApiPathRoot + subPath)
+	r.POST("/:tenant/suspend", h.suspendTenant)
+	r.POST("/:tenant/resume", h.resumeTenant)
+	r.POST("/:tenant/delete", h.deleteTenant)
+	r.POST("/:tenant/restore", h.restoreTenant)
+	r.GET("/:tenant/archive", h.archiveTenant)
+}
+
+// suspendTenant scales workloads of tenant to zero and blocks new writes
+// @Summary suspend tenant
+// @Description scale workloads in namespaces of tenant to zero and deny writes into them
+// @Tags tenant
+// @Param tenant path string true "tenant name"
+// @Success 200 {object} response.SuccessInfo
+// @Failure 400 {object} errcode.ErrorInfo
+// @Failure 403 {object} errcode.ErrorInfo
+// @Router /api/v1/kube/tenants/{tenant}/suspend  [post]
+func (h *handler) suspendTenant(c *gin.Context) {
+	h.updateTenant(c, audit.SuspendTenant, constants.UpdateVerb, func(tenant *tenantv1.Tenant) {
+		tenant.Spec.Suspended = true
+	})
+}
+
+// resumeTenant restores workloads of suspended tenant
+// @Summary resume tenant
+// @Description restore workloads in namespaces of suspended tenant
+// @Tags tenant
+// @Param tenant path string true "tenant name"
+// @Success 200 {object} response.SuccessInfo
+// @Failure 400 {object} errcode.ErrorInfo
+// @Failure 403 {object} errcode.ErrorInfo
+// @Router /api/v1/kube/tenants/{tenant}/resume  [post]
+func (h *handler) resumeTenant(c *gin.Context) {
+	h.updateTenant(c, audit.ResumeTenant, constants.UpdateVerb, func(tenant *tenantv1.Tenant) {
+		tenant.Spec.Suspended = false
+	})
+}
+
+// deleteTenant requests deletion of tenant, tenant will be suspended and
+// deleted after grace period
+// @Summary delete tenant with grace period
+// @Description suspend tenant and delete it after grace period, tenant can be restored before that
+// @Tags tenant
+// @Param tenant path string true "tenant name"
+// @Success 200 {object} response.SuccessInfo
+// @Failure 400 {object} errcode.ErrorInfo
+// @Failure 403 {object} errcode.ErrorInfo
+// @Router /api/v1/kube/tenants/{tenant}/delete  [post]
+func (h *handler) deleteTenant(c *gin.Context) {
+	h.updateTenant(c, audit.DeleteTenant, constants.DeleteVerb, func(tenant *tenantv1.Tenant) {
+		if tenant.Annotations == nil {
+			tenant.Annotations = make(map[string]string)
+		}
+		if _, ok := tenant.Annotations[constants.DeletionRequestedAnnotation]; !ok {
+			tenant.Annotations[constants.DeletionRequestedAnnotation] = time.Now().Format(time.RFC3339)
+		}
+	})
+}
+
+// restoreTenant cancels deletion of tenant during grace period
+// @Summary restore tenant
+// @Description cancel deletion of tenant during grace period
+// @Tags tenant
+// @Param tenant path string true "tenant name"
+// @Success 200 {object} response.SuccessInfo
+// @Failure 400 {object} errcode.ErrorInfo
+// @Failure 403 {object} errcode.ErrorInfo
+// @Router /api/v1/kube/tenants/{tenant}/restore  [post]
+func (h *handler) restoreTenant(c *gin.Context) {
+	h.updateTenant(c, audit.RestoreTenant, constants.DeleteVerb, func(tenant *tenantv1.Tenant) {
+		delete(tenant.Annotations, constants.DeletionRequestedAnnotation)
+	})
+}
+
+// archiveTenant exports all namespaced resources of tenant or project as tar.gz bundle
+// @Summary archive tenant
+// @Description export all namespaced resources in namespaces of tenant or project of all clusters,
+// @Description user must be able to update the tenant or project, data of secrets is only exported
+// @Description in namespaces where user can list secrets
+// @Tags tenant
+// @Param tenant path string true "tenant name"
+// @Param project query string false "only archive namespaces of given project"
+// @Success 200 {file} file "tar.gz bundle"
+// @Failure 400 {object} errcode.ErrorInfo
+// @Failure 403 {object} errcode.ErrorInfo
+// @Router /api/v1/kube/tenants/{tenant}/archive  [get]
+func (h *handler) archiveTenant(c *gin.Context) {
+	ctx := c.Request.Context()
+	name := c.Param("tenant")
+	project := c.Query("project")
+
+	var (
+		obj      client.Object
+		nsLabels client.MatchingLabels
+		fileName string
+	)
+	if len(project) > 0 {
+		p := &tenantv1.Project{}
+		if err := h.Direct().Get(ctx, types.NamespacedName{Name: project}, p); err != nil {
+			response.FailReturn(c, errcode.BadRequest(err))
+			return
+		}
+		if p.Labels[constants.TenantLabel] != name {
+			response.FailReturn(c, errcode.CustomReturn(http.StatusBadRequest, "project %v does not belong to tenant %v", project, name))
+			return
+		}
+		obj, nsLabels, fileName = p, client.MatchingLabels{constants.HncProjectLabel: project}, project
+	} else {
+		t := &tenantv1.Tenant{}
+		if err := h.Direct().Get(ctx, types.NamespacedName{Name: name}, t); err != nil {
+			response.FailReturn(c, errcode.BadRequest(err))
+			return
+		}
+		obj, nsLabels, fileName = t, client.MatchingLabels{constants.HncTenantLabel: name}, name
+	}
+
+	// archive holds everything in namespaces, which is as sensitive as
+	// managing the tenant or project
+	if !access.AllowAccess(constants.LocalCluster, c.Request, constants.UpdateVerb, obj) {
+		response.FailReturn(c, errcode.ForbiddenErr)
+		return
+	}
+	user, err := token.GetUserFromReq(c.Request)
+	if err != nil {
+		response.FailReturn(c, errcode.AuthenticateError)
+		return
+	}
+	secretAccess := func(cluster, namespace string) bool {
+		return resources.NewSimpleAccess(cluster, user.Username, namespace).AccessAllow("", "secrets", "list")
+	}
+
+	c.Header("Content-Type", "application/gzip")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%v.tar.gz", fileName))
+	c.Status(http.StatusOK)
+
+	// headers already sent, errors can only be logged from now on
+	w := archive.NewWriter(c.Writer)
+	if err := archive.ExportClusters(ctx, nsLabels, w, secretAccess); err != nil {
+		clog.Error("archive %v failed: %v", fileName, err)
+	}
+	if err := w.Close(); err != nil {
+		clog.Error("close archive of %v failed: %v", fileName, err)
+	}
+}
+
+func (h *handler) updateTenant(c *gin.Context, event *audit.EventInfo, verb string, mutate func(tenant *tenantv1.Tenant)) {
+	ctx := c.Request.Context()
+	name := c.Param("tenant")
+	c = audit.SetAuditInfo(c, event, name, nil)
+
+	tenant := &tenantv1.Tenant{}
+	err := h.Direct().Get(ctx, types.NamespacedName{Name: name}, tenant)
+	if err != nil {
+		response.FailReturn(c, errcode.BadRequest(err))
+		return
+	}
+
+	if !access.AllowAccess(constants.LocalCluster, c.Request, verb, tenant) {
+		response.FailReturn(c, errcode.ForbiddenErr)
+		return
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		t := &tenantv1.Tenant{}
+		err := h.Direct().Get(ctx, types.NamespacedName{Name: name}, t)
+		if err != nil {
+			return err
+		}
+		mutate(t)
+		return h.Direct().Update(ctx, t)
+	})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			response.FailReturn(c, errcode.BadRequest(err))
+			return
+		}
+		response.FailReturn(c, errcode.CustomReturn(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	response.SuccessReturn(c, nil)
+}
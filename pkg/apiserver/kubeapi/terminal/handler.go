@@ -0,0 +1,313 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terminal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	userinfo "k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+
+	"github.com/saashqdev/kubeworkz/pkg/apiserver/middlewares/audit"
+	"github.com/saashqdev/kubeworkz/pkg/authorizer/rbac"
+	"github.com/saashqdev/kubeworkz/pkg/clog"
+	"github.com/saashqdev/kubeworkz/pkg/multicluster"
+	auditutil "github.com/saashqdev/kubeworkz/pkg/utils/audit"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+	"github.com/saashqdev/kubeworkz/pkg/utils/env"
+	"github.com/saashqdev/kubeworkz/pkg/utils/errcode"
+	"github.com/saashqdev/kubeworkz/pkg/utils/international"
+	"github.com/saashqdev/kubeworkz/pkg/utils/response"
+	"github.com/saashqdev/kubeworkz/pkg/warden/server/authproxy"
+)
+
+const subPath = "/terminal"
+
+// Kinds of sessions, they are the subresources of pod as well
+const (
+	KindExec        = "exec"
+	KindPortForward = "portforward"
+)
+
+var defaultCommand = []string{"/bin/sh"}
+
+type handler struct {
+	store        *Store
+	auditHandler audit.Handler
+}
+
+func NewHandler(managers *international.Gi18nManagers) *handler {
+	store := NewStore(env.SessionRecordDir())
+	go store.RunCleanup(context.Background(), env.SessionRecordRetention())
+	return &handler{
+		store:        store,
+		auditHandler: audit.NewHandler(managers),
+	}
+}
+
+func (h *handler) AddApisTo(root *gin.Engine) {
+	r := root.Group(constants.ApiPathRoot + subPath)
+	r.GET("/clusters/:cluster/namespaces/:namespace/pods/:pod/exec", h.exec)
+	r.GET("/clusters/:cluster/namespaces/:namespace/pods/:pod/portforward", h.portForward)
+	r.GET("/sessions/:session", h.getSession)
+}
+
+// exec opens web terminal of container over websocket
+// @Summary exec in container
+// @Description open interactive session of container over websocket with channel protocols of apiserver, the session is recorded and audited
+// @Tags terminal
+// @Param cluster path string true "cluster name"
+// @Param namespace path string true "namespace"
+// @Param pod path string true "pod name"
+// @Param container query string false "container name"
+// @Param command query []string false "command to run, default /bin/sh" collectionFormat(multi)
+// @Param tty query bool false "allocate tty, default true"
+// @Success 101
+// @Failure 400 {object} errcode.ErrorInfo
+// @Failure 403 {object} errcode.ErrorInfo
+// @Router /api/v1/kube/terminal/clusters/{cluster}/namespaces/{namespace}/pods/{pod}/exec [get]
+func (h *handler) exec(c *gin.Context) {
+	command := c.QueryArray("command")
+	if len(command) == 0 {
+		command = defaultCommand
+	}
+	tty := c.Query("tty") != "false"
+
+	query := url.Values{}
+	query["command"] = command
+	query.Set("stdin", "true")
+	query.Set("stdout", "true")
+	// stderr is merged into stdout by tty
+	query.Set("stderr", fmt.Sprint(!tty))
+	query.Set("tty", fmt.Sprint(tty))
+	if container := c.Query("container"); len(container) > 0 {
+		query.Set("container", container)
+	}
+
+	h.serve(c, &Session{
+		Kind:      KindExec,
+		Container: c.Query("container"),
+		Command:   strings.Join(command, " "),
+	}, query)
+}
+
+// portForward forwards ports of pod over websocket
+// @Summary port-forward to pod
+// @Description forward ports of pod over websocket with port-forward protocols of apiserver, the session is recorded and audited
+// @Tags terminal
+// @Param cluster path string true "cluster name"
+// @Param namespace path string true "namespace"
+// @Param pod path string true "pod name"
+// @Param ports query []string true "ports of pod" collectionFormat(multi)
+// @Success 101
+// @Failure 400 {object} errcode.ErrorInfo
+// @Failure 403 {object} errcode.ErrorInfo
+// @Router /api/v1/kube/terminal/clusters/{cluster}/namespaces/{namespace}/pods/{pod}/portforward [get]
+func (h *handler) portForward(c *gin.Context) {
+	ports := c.QueryArray("ports")
+	if len(ports) == 0 {
+		response.FailReturn(c, errcode.ParamsMissing("ports"))
+		return
+	}
+	h.serve(c, &Session{
+		Kind:  KindPortForward,
+		Ports: strings.Join(ports, ","),
+	}, url.Values{"ports": ports})
+}
+
+// serve proxies upgraded stream of session to subresource of pod in
+// apiserver, and records the traffic
+func (h *handler) serve(c *gin.Context, session *Session, query url.Values) {
+	session.User = c.GetString(constants.UserName)
+	session.Cluster = c.Param("cluster")
+	session.Namespace = c.Param("namespace")
+	session.Pod = c.Param("pod")
+
+	if !httpstream.IsUpgradeRequest(c.Request) {
+		response.FailReturn(c, errcode.BadRequest(fmt.Errorf("%v requires websocket upgrade", session.Kind)))
+		return
+	}
+	// token may be read from cookie, so cross site upgrade from browsers
+	// is rejected, otherwise any site could open session as user
+	if err := checkSameOrigin(c.Request); err != nil {
+		response.FailReturn(c, errcode.CustomReturn(http.StatusForbidden, "%v", err))
+		return
+	}
+	if !allowed(session.Cluster, session.User, session.Namespace, session.Pod, session.Kind) {
+		response.FailReturn(c, errcode.ForbiddenErr)
+		return
+	}
+	cluster, err := multicluster.Interface().Get(session.Cluster)
+	if err != nil {
+		response.FailReturn(c, errcode.BadRequest(err))
+		return
+	}
+	target, err := url.Parse(cluster.Config.Host)
+	if err != nil {
+		response.FailReturn(c, errcode.BadRequest(err))
+		return
+	}
+	proxy, err := authproxy.NewUpgradeAwareHandler(target, cluster.Config)
+	if err != nil {
+		response.FailReturn(c, errcode.BadRequest(err))
+		return
+	}
+
+	// sessions are never opened without being recorded
+	session.ID = uuid.New().String()
+	session.StartTime = time.Now()
+	f, err := h.store.Create(session.ID)
+	if err != nil {
+		clog.Error("create recording of session %v failed: %v", session.ID, err)
+		response.FailReturn(c, errcode.CustomReturn(http.StatusInternalServerError, "session can not be recorded"))
+		return
+	}
+	recorder, err := NewRecorder(f, session)
+	if err != nil {
+		_ = f.Close()
+		clog.Error("create recording of session %v failed: %v", session.ID, err)
+		response.FailReturn(c, errcode.CustomReturn(http.StatusInternalServerError, "session can not be recorded"))
+		return
+	}
+
+	req := c.Request.Clone(c.Request.Context())
+	req.URL.Path = path.Join("/api/v1/namespaces", session.Namespace, "pods", session.Pod, session.Kind)
+	req.URL.RawQuery = query.Encode()
+	// impersonate given user to access k8s-apiserver
+	req.Header.Set(constants.ImpersonateUserKey, session.User)
+	req.Header.Del(constants.AuthorizationHeader)
+	req.Header.Del("Cookie")
+
+	clog.Info("user %v starts %v session %v of %v/%v/%v", session.User, session.Kind, session.ID, session.Cluster, session.Namespace, session.Pod)
+	w := newRecordingWriter(c.Writer, recorder, session.Kind)
+	proxy.ServeHTTP(w, req)
+
+	if err = recorder.Close(); err != nil {
+		clog.Error("recording of session %v is incomplete: %v", session.ID, err)
+	}
+	if n := recorder.Truncated(); n > 0 {
+		clog.Warn("%v messages of session %v are too large to be recorded", n, session.ID)
+	}
+	status := w.status
+	if status == 0 {
+		status = c.Writer.Status()
+	}
+	h.sendAuditEvent(c, session, recorder, status)
+}
+
+// checkSameOrigin rejects cross site requests from browsers, requests
+// without origin are not sent by browsers and allowed
+func checkSameOrigin(req *http.Request) error {
+	origin := req.Header.Get("Origin")
+	if len(origin) == 0 {
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host != req.Host {
+		return fmt.Errorf("cross origin request %v is not allowed", origin)
+	}
+	return nil
+}
+
+// allowed tells whether user can create subresource of pod in namespace
+func allowed(cluster, user, namespace, pod, subresource string) bool {
+	attrs := authorizer.AttributesRecord{
+		User:            &userinfo.DefaultInfo{Name: user},
+		Verb:            "create",
+		Namespace:       namespace,
+		Resource:        "pods",
+		Subresource:     subresource,
+		Name:            pod,
+		ResourceRequest: true,
+	}
+	d, _, err := rbac.NewDefaultResolver(cluster).Authorize(context.Background(), attrs)
+	if err != nil {
+		clog.Warn("authorize %v of %v failed: %v", subresource, user, err)
+	}
+	return d == authorizer.DecisionAllow
+}
+
+func (h *handler) sendAuditEvent(c *gin.Context, session *Session, recorder *Recorder, status int) {
+	eventInfo := auditutil.ExecPod
+	if session.Kind == KindPortForward {
+		eventInfo = auditutil.PortForwardPod
+	}
+	event := &audit.Event{
+		EventName:   eventInfo.EventName,
+		Description: eventInfo.Description,
+		ResourceReports: []audit.Resource{{
+			ResourceType: eventInfo.ResourceType,
+			ResourceId:   session.ID,
+			ResourceName: fmt.Sprintf("%s/%s/%s", session.Pod, session.Namespace, session.Cluster),
+		}},
+		RequestMethod:  c.Request.Method,
+		ResponseStatus: status,
+		Url:            c.Request.URL.String(),
+		ResponseElements: fmt.Sprintf("session %v lasted %v, input %v bytes, output %v bytes, %v messages truncated, recording %v",
+			session.ID, time.Since(session.StartTime).Round(time.Second), recorder.Bytes("i"), recorder.Bytes("o"),
+			recorder.Truncated(), constants.ApiPathRoot+subPath+"/sessions/"+session.ID),
+		RequestParameters: audit.ConsistParameters(c, nil),
+	}
+	go h.auditHandler.SendEvent(c.Copy(), event, &audit.Options{Translate: false})
+}
+
+// getSession downloads recording of session in asciicast v2 format
+// @Summary download recording of session
+// @Description download recording of exec or port-forward session, which is allowed for the user of session and users allowed to start the same kind of session in namespace
+// @Tags terminal
+// @Param session path string true "session id"
+// @Success 200 {file} file "asciicast v2 recording"
+// @Failure 400 {object} errcode.ErrorInfo
+// @Failure 403 {object} errcode.ErrorInfo
+// @Router /api/v1/kube/terminal/sessions/{session} [get]
+func (h *handler) getSession(c *gin.Context) {
+	id := c.Param("session")
+	session, f, err := h.store.Open(id)
+	if err != nil {
+		if os.IsNotExist(err) {
+			response.FailReturn(c, errcode.CustomReturn(http.StatusNotFound, "session %v not found", id))
+			return
+		}
+		response.FailReturn(c, errcode.BadRequest(err))
+		return
+	}
+	defer f.Close()
+
+	user := c.GetString(constants.UserName)
+	if user != session.User && !allowed(session.Cluster, user, session.Namespace, session.Pod, session.Kind) {
+		response.FailReturn(c, errcode.ForbiddenErr)
+		return
+	}
+
+	c.Header(constants.HttpHeaderContentType, "application/x-asciicast")
+	c.Header(constants.HttpHeaderContentDisposition, fmt.Sprintf("attachment; filename=%v.cast", id))
+	c.Status(http.StatusOK)
+	if _, err = io.Copy(c.Writer, f); err != nil {
+		clog.Error("download recording of session %v failed: %v", id, err)
+	}
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terminal
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckSameOrigin(t *testing.T) {
+	cases := []struct {
+		origin  string
+		allowed bool
+	}{
+		{"", true},
+		{"https://kubeworkz.example.com", true},
+		{"https://evil.example.com", false},
+		{"://bad", false},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest("GET", "https://kubeworkz.example.com/api/v1/kube/terminal", nil)
+		if len(c.origin) > 0 {
+			req.Header.Set("Origin", c.origin)
+		}
+		if err := checkSameOrigin(req); (err == nil) != c.allowed {
+			t.Errorf("origin %q: expected allowed %v, got error %v", c.origin, c.allowed, err)
+		}
+	}
+}
@@ -0,0 +1,457 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terminal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Channels of streams multiplexed in websocket messages by apiserver, the
+// first byte of message is the channel
+const (
+	channelStdin  = 0
+	channelStdout = 1
+	channelStderr = 2
+	channelError  = 3
+	channelResize = 4
+)
+
+// maxFrameSize messages larger than it are not recorded to bound memory,
+// a truncation marker is recorded instead
+const maxFrameSize = 16 << 20
+
+// Session describes an exec or port-forward session, it is kept in header
+// of recording
+type Session struct {
+	ID        string    `json:"id"`
+	Kind      string    `json:"kind"`
+	User      string    `json:"user"`
+	Cluster   string    `json:"cluster"`
+	Namespace string    `json:"namespace"`
+	Pod       string    `json:"pod"`
+	Container string    `json:"container,omitempty"`
+	Command   string    `json:"command,omitempty"`
+	Ports     string    `json:"ports,omitempty"`
+	StartTime time.Time `json:"startTime"`
+}
+
+// header the header line of asciicast v2 file, session is an extra field
+// ignored by players
+type header struct {
+	Version   int      `json:"version"`
+	Width     int      `json:"width"`
+	Height    int      `json:"height"`
+	Timestamp int64    `json:"timestamp"`
+	Title     string   `json:"title,omitempty"`
+	Command   string   `json:"command,omitempty"`
+	Session   *Session `json:"session"`
+}
+
+// Recorder writes session transcript in asciicast v2 format
+type Recorder struct {
+	mu      sync.Mutex
+	w       io.WriteCloser
+	start   time.Time
+	session *Session
+	err     error
+	// bytes the bytes of data recorded for input and output
+	bytes map[string]int64
+	// truncated the number of messages too large to be recorded
+	truncated int
+}
+
+// NewRecorder writes header of session into w
+func NewRecorder(w io.WriteCloser, session *Session) (*Recorder, error) {
+	r := &Recorder{w: w, start: session.StartTime, session: session, bytes: make(map[string]int64)}
+	h := header{
+		Version:   2,
+		Width:     80,
+		Height:    24,
+		Timestamp: session.StartTime.Unix(),
+		Title:     fmt.Sprintf("%v %v/%v/%v", session.Kind, session.Cluster, session.Namespace, session.Pod),
+		Command:   session.Command,
+		Session:   session,
+	}
+	if err := r.writeLine(h); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Recorder) writeLine(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = r.w.Write(append(b, '\n'))
+	return err
+}
+
+// Event records data of given event type: "o" for output, "i" for input
+// and "r" for resize
+func (r *Recorder) Event(code string, data string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return
+	}
+	r.bytes[code] += int64(len(data))
+	elapsed := time.Since(r.start).Seconds()
+	r.err = r.writeLine([]interface{}{elapsed, code, data})
+}
+
+// Count counts bytes of data of given event type which is not recorded,
+// such as binary data of port-forward
+func (r *Recorder) Count(code string, n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bytes[code] += int64(n)
+}
+
+// Truncate records a visible marker in place of message too large to be
+// recorded
+func (r *Recorder) Truncate(size uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.truncated++
+	if r.err != nil {
+		return
+	}
+	elapsed := time.Since(r.start).Seconds()
+	marker := fmt.Sprintf("[recording truncated: message of %v bytes exceeds %v bytes]", size, maxFrameSize)
+	r.err = r.writeLine([]interface{}{elapsed, "m", marker})
+}
+
+// Truncated returns the number of messages not recorded for size
+func (r *Recorder) Truncated() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.truncated
+}
+
+// Close closes the recording, the first error of writing is returned
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.w.Close(); err != nil && r.err == nil {
+		r.err = err
+	}
+	return r.err
+}
+
+// Bytes returns the bytes recorded of event type
+func (r *Recorder) Bytes(code string) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.bytes[code]
+}
+
+// frameParser decodes websocket frames of one direction of connection and
+// hands complete messages to onMessage
+type frameParser struct {
+	buf []byte
+	// skipHeader the stream begins with http response which is skipped
+	skipHeader bool
+	onHeader   func(resp *http.Response)
+	message    []byte
+	// discard the bytes of payload of oversized frame still to be dropped
+	discard uint64
+	// truncated the message being assembled is dropped until its last frame
+	truncated  bool
+	onMessage  func(message []byte)
+	onTruncate func(size uint64)
+}
+
+func (p *frameParser) Write(b []byte) {
+	p.buf = append(p.buf, b...)
+	if p.skipHeader {
+		i := bytes.Index(p.buf, []byte("\r\n\r\n"))
+		if i < 0 {
+			return
+		}
+		if p.onHeader != nil {
+			resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(p.buf[:i+4])), nil)
+			if err == nil {
+				p.onHeader(resp)
+			}
+		}
+		p.buf = p.buf[i+4:]
+		p.skipHeader = false
+	}
+
+	for {
+		if p.discard > 0 {
+			n := uint64(len(p.buf))
+			if n > p.discard {
+				n = p.discard
+			}
+			p.buf, p.discard = p.buf[n:], p.discard-n
+			if p.discard > 0 {
+				break
+			}
+		}
+		n, ok := p.next()
+		if !ok {
+			break
+		}
+		p.buf = p.buf[n:]
+	}
+}
+
+// truncate drops the message being assembled
+func (p *frameParser) truncate(size uint64, fin bool) {
+	if !p.truncated && p.onTruncate != nil {
+		p.onTruncate(size)
+	}
+	p.message, p.truncated = nil, !fin
+}
+
+// next parses one frame at the beginning of buf, returns its length
+func (p *frameParser) next() (int, bool) {
+	b := p.buf
+	if len(b) < 2 {
+		return 0, false
+	}
+	fin, opcode := b[0]&0x80 != 0, b[0]&0x0f
+	masked, length := b[1]&0x80 != 0, uint64(b[1]&0x7f)
+	pos := 2
+	switch length {
+	case 126:
+		if len(b) < pos+2 {
+			return 0, false
+		}
+		length = uint64(binary.BigEndian.Uint16(b[pos:]))
+		pos += 2
+	case 127:
+		if len(b) < pos+8 {
+			return 0, false
+		}
+		length = binary.BigEndian.Uint64(b[pos:])
+		pos += 8
+	}
+	var mask []byte
+	if masked {
+		if len(b) < pos+4 {
+			return 0, false
+		}
+		mask = b[pos : pos+4]
+		pos += 4
+	}
+	if length > maxFrameSize {
+		// payload is dropped as it arrives instead of being buffered
+		p.discard = length
+		if opcode < 8 {
+			p.truncate(length, fin)
+		}
+		return pos, true
+	}
+	if uint64(len(b)-pos) < length {
+		return 0, false
+	}
+	payload := make([]byte, length)
+	copy(payload, b[pos:pos+int(length)])
+	for i := range mask {
+		for j := i; j < len(payload); j += 4 {
+			payload[j] ^= mask[i]
+		}
+	}
+
+	switch {
+	case opcode >= 8:
+		// control frames such as ping, pong and close
+		return pos + int(length), true
+	case opcode == 0 && p.truncated:
+		p.truncated = !fin
+		return pos + int(length), true
+	case opcode == 0 && len(p.message)+len(payload) > maxFrameSize:
+		p.truncate(uint64(len(p.message)+len(payload)), fin)
+		return pos + int(length), true
+	case opcode == 0:
+		p.message = append(p.message, payload...)
+	default:
+		p.message, p.truncated = payload, false
+	}
+	if fin {
+		p.onMessage(p.message)
+		p.message = nil
+	}
+	return pos + int(length), true
+}
+
+// channelDecoder turns websocket messages of channel protocols of apiserver
+// into recorded events
+type channelDecoder struct {
+	recorder *Recorder
+	kind     string
+	// base64 whether payload of messages is base64 encoded, it is set when
+	// server responds and read by both directions concurrently
+	base64 atomic.Bool
+	// seen channels of port-forward, the first message of each channel from
+	// server is the port number
+	seen map[byte]bool
+}
+
+func (d *channelDecoder) setProtocol(protocol string) {
+	d.base64.Store(strings.Contains(protocol, "base64"))
+}
+
+func (d *channelDecoder) decode(message []byte) (byte, []byte, bool) {
+	if len(message) == 0 {
+		return 0, nil, false
+	}
+	if !d.base64.Load() {
+		return message[0], message[1:], true
+	}
+	data, err := base64.StdEncoding.DecodeString(string(message[1:]))
+	if err != nil || message[0] < '0' {
+		return 0, nil, false
+	}
+	return message[0] - '0', data, true
+}
+
+// fromClient records message sent by client to pod
+func (d *channelDecoder) fromClient(message []byte) {
+	channel, data, ok := d.decode(message)
+	if !ok || len(data) == 0 {
+		return
+	}
+	switch {
+	case d.kind == KindPortForward:
+		// forwarded traffic is binary, only its size is recorded
+		if channel%2 == 0 {
+			d.recorder.Count("i", len(data))
+		}
+	case channel == channelStdin:
+		d.recorder.Event("i", string(data))
+	case channel == channelResize:
+		size := struct{ Width, Height uint16 }{}
+		if json.Unmarshal(data, &size) == nil {
+			d.recorder.Event("r", fmt.Sprintf("%dx%d", size.Width, size.Height))
+		}
+	}
+}
+
+// fromServer records message sent by pod to client
+func (d *channelDecoder) fromServer(message []byte) {
+	channel, data, ok := d.decode(message)
+	if !ok {
+		return
+	}
+	switch {
+	case d.kind == KindPortForward:
+		if !d.seen[channel] {
+			d.seen[channel] = true
+			if channel%2 == 0 && len(data) >= 2 {
+				d.recorder.Event("m", fmt.Sprintf("forwarding port %d", binary.LittleEndian.Uint16(data)))
+			}
+			return
+		}
+		switch {
+		case channel%2 == 0:
+			// forwarded traffic is binary, only its size is recorded
+			d.recorder.Count("o", len(data))
+		case len(data) > 0:
+			// errors of port-forward are text
+			d.recorder.Event("m", string(data))
+		}
+	case channel == channelStdout || channel == channelStderr:
+		if len(data) > 0 {
+			d.recorder.Event("o", string(data))
+		}
+	case channel == channelError:
+		// status of exec is delivered on error channel when it exits
+		if len(data) > 0 {
+			d.recorder.Event("m", string(data))
+		}
+	}
+}
+
+// recordingConn copies traffic of hijacked connection to recorder
+type recordingConn struct {
+	net.Conn
+	in  *frameParser
+	out *frameParser
+}
+
+func (c *recordingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		c.in.Write(b[:n])
+	}
+	return n, err
+}
+
+func (c *recordingConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	if n > 0 {
+		c.out.Write(b[:n])
+	}
+	return n, err
+}
+
+// recordingWriter wraps ResponseWriter so that the connection hijacked by
+// upgrade aware proxy is recorded
+type recordingWriter struct {
+	http.ResponseWriter
+	decoder *channelDecoder
+	// status the status of response of apiserver once hijacked
+	status int
+}
+
+func newRecordingWriter(w http.ResponseWriter, recorder *Recorder, kind string) *recordingWriter {
+	return &recordingWriter{
+		ResponseWriter: w,
+		decoder:        &channelDecoder{recorder: recorder, kind: kind, seen: make(map[byte]bool)},
+	}
+}
+
+func (w *recordingWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer %T can not be hijacked", w.ResponseWriter)
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	recorder := w.decoder.recorder
+	return &recordingConn{
+		Conn: conn,
+		in:   &frameParser{onMessage: w.decoder.fromClient, onTruncate: recorder.Truncate},
+		out: &frameParser{
+			skipHeader: true,
+			onHeader: func(resp *http.Response) {
+				w.status = resp.StatusCode
+				w.decoder.setProtocol(resp.Header.Get("Sec-WebSocket-Protocol"))
+			},
+			onMessage:  w.decoder.fromServer,
+			onTruncate: recorder.Truncate,
+		},
+	}, rw, nil
+}
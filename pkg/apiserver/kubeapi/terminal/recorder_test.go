@@ -0,0 +1,263 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terminal
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// frame encodes websocket frame, client frames are masked
+func frame(opcode byte, fin bool, payload []byte, masked bool) []byte {
+	b := []byte{opcode}
+	if fin {
+		b[0] |= 0x80
+	}
+	var maskBit byte
+	if masked {
+		maskBit = 0x80
+	}
+	switch {
+	case len(payload) < 126:
+		b = append(b, maskBit|byte(len(payload)))
+	default:
+		b = append(b, maskBit|126, byte(len(payload)>>8), byte(len(payload)))
+	}
+	if !masked {
+		return append(b, payload...)
+	}
+	mask := []byte{1, 2, 3, 4}
+	b = append(b, mask...)
+	for i, c := range payload {
+		b = append(b, c^mask[i%4])
+	}
+	return b
+}
+
+func TestRecordingConn(t *testing.T) {
+	store := NewStore(t.TempDir())
+	session := &Session{ID: "7c4b5e0a-6f1e-4d55-9a8e-3f0a1c2b3d4e", Kind: KindExec, User: "admin", Cluster: "pivot",
+		Namespace: "ns1", Pod: "web-1", Command: "/bin/sh", StartTime: time.Now()}
+	f, err := store.Create(session.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder, err := NewRecorder(f, session)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server, client := net.Pipe()
+	defer client.Close()
+	w := newRecordingWriter(hijackable{httptest.NewRecorder(), server}, recorder, KindExec)
+	conn, _, err := w.Hijack()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// client types a command in two fragments with ping between, and
+	// resizes terminal
+	var frames []byte
+	frames = append(frames, frame(0x2, false, []byte("\x00l"), true)...)
+	frames = append(frames, frame(0x9, true, nil, true)...)
+	frames = append(frames, frame(0x0, true, []byte("s\n"), true)...)
+	frames = append(frames, frame(0x2, true, append([]byte{channelResize}, `{"Width":120,"Height":40}`...), true)...)
+	go func() {
+		_, _ = client.Write(frames)
+		_, _ = io.Copy(io.Discard, client)
+	}()
+	buf := make([]byte, 1024)
+	for read := 0; read < len(frames); {
+		n, err := conn.Read(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		read += n
+	}
+
+	// apiserver answers with base64 protocol
+	output := base64.StdEncoding.EncodeToString([]byte("bin  etc\n"))
+	response := "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Protocol: base64.channel.k8s.io\r\n\r\n"
+	_, _ = conn.Write([]byte(response))
+	_, _ = conn.Write(frame(0x2, true, []byte("1"+output), false))
+	_ = conn.Close()
+	if err = recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if w.status != 101 {
+		t.Errorf("expect status 101, got %v", w.status)
+	}
+
+	got, r, err := store.Open(session.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if got.User != "admin" || got.Pod != "web-1" {
+		t.Errorf("unexpected session of header: %+v", got)
+	}
+	var events [][]interface{}
+	scanner := bufio.NewScanner(r)
+	scanner.Scan()
+	for scanner.Scan() {
+		var e []interface{}
+		if err = json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, e[1:])
+	}
+	expected := [][]interface{}{{"i", "ls\n"}, {"r", "120x40"}, {"o", "bin  etc\n"}}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("expect events %v, got %v", expected, events)
+	}
+}
+
+func TestFrameParserTruncate(t *testing.T) {
+	var (
+		messages  []string
+		truncated []uint64
+	)
+	p := &frameParser{
+		onMessage:  func(message []byte) { messages = append(messages, string(message)) },
+		onTruncate: func(size uint64) { truncated = append(truncated, size) },
+	}
+
+	// oversized frame is dropped as it arrives and parsing goes on after it
+	size := uint64(maxFrameSize + 1)
+	header := []byte{0x82, 127, 0, 0, 0, 0, 0, 0, 0, 0}
+	binary.BigEndian.PutUint64(header[2:], size)
+	p.Write(header)
+	chunk := make([]byte, 1<<20)
+	for left := size; left > 0; {
+		n := uint64(len(chunk))
+		if n > left {
+			n = left
+		}
+		p.Write(chunk[:n])
+		left -= n
+	}
+	p.Write(frame(0x2, true, []byte("\x01ok"), false))
+
+	if !reflect.DeepEqual(truncated, []uint64{size}) {
+		t.Errorf("expect truncation of %v bytes, got %v", size, truncated)
+	}
+	if !reflect.DeepEqual(messages, []string{"\x01ok"}) {
+		t.Errorf("expect message after oversized frame parsed, got %q", messages)
+	}
+	if len(p.buf) != 0 {
+		t.Errorf("expect nothing buffered, got %v bytes", len(p.buf))
+	}
+}
+
+func TestPortForwardRecordsMetadata(t *testing.T) {
+	store := NewStore(t.TempDir())
+	session := &Session{ID: "0b6d1c8e-2a7f-4f3e-9c5d-7e8f9a0b1c2d", Kind: KindPortForward, StartTime: time.Now()}
+	f, err := store.Create(session.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder, err := NewRecorder(f, session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &channelDecoder{recorder: recorder, kind: KindPortForward, seen: make(map[byte]bool)}
+	d.fromServer([]byte{0, 0x50, 0x00})
+	d.fromServer([]byte{1, 0x50, 0x00})
+	d.fromClient([]byte{0, 0xff, 0x00, 0x01})
+	d.fromServer([]byte{0, 0xde, 0xad})
+	if err = recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if recorder.Bytes("i") != 3 || recorder.Bytes("o") != 2 {
+		t.Errorf("unexpected bytes counted, input %v output %v", recorder.Bytes("i"), recorder.Bytes("o"))
+	}
+
+	_, r, err := store.Open(session.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], `"m","forwarding port 80"`) {
+		t.Errorf("expect only port recorded, got %v", lines[1:])
+	}
+}
+
+func TestStoreCleanup(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(dir)
+	ids := []string{"7c4b5e0a-6f1e-4d55-9a8e-3f0a1c2b3d4e", "0b6d1c8e-2a7f-4f3e-9c5d-7e8f9a0b1c2d"}
+	for _, id := range ids {
+		f, err := store.Create(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = f.Close()
+	}
+	old := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, ids[0]+".cast"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Cleanup(time.Now().Add(-24 * time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := store.Open(ids[0]); !os.IsNotExist(err) {
+		t.Errorf("expect expired recording removed, got %v", err)
+	}
+	if _, r, err := store.Open(ids[1]); err == nil {
+		_ = r.Close()
+	} else if os.IsNotExist(err) {
+		t.Errorf("expect recent recording kept")
+	}
+}
+
+func TestStoreInvalidID(t *testing.T) {
+	store := NewStore(t.TempDir())
+	for _, id := range []string{"../etc/passwd", "", strings.Repeat("a", 36)} {
+		if _, err := store.Create(id); err == nil {
+			t.Errorf("expect error of creating session %q", id)
+		}
+		if _, _, err := store.Open(id); err == nil {
+			t.Errorf("expect error of opening session %q", id)
+		}
+	}
+}
+
+type hijackable struct {
+	*httptest.ResponseRecorder
+	conn net.Conn
+}
+
+func (h hijackable) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return h.conn, nil, nil
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terminal
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/saashqdev/kubeworkz/pkg/clog"
+)
+
+// cleanupPeriod the period to remove expired recordings
+const cleanupPeriod = time.Hour
+
+// Store keeps recordings of sessions as asciicast files in local dir
+type Store struct {
+	dir string
+}
+
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+func (s *Store) path(id string) (string, error) {
+	// id is given by client when reading, so it must be checked before
+	// joined into path
+	if _, err := uuid.Parse(id); err != nil {
+		return "", fmt.Errorf("invalid session id %v", id)
+	}
+	return filepath.Join(s.dir, id+".cast"), nil
+}
+
+// Create creates recording of session
+func (s *Store) Create(id string) (io.WriteCloser, error) {
+	p, err := s.path(id)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(s.dir, 0700); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(p, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+}
+
+// Open opens recording of session and returns the session recorded in header
+func (s *Store) Open(id string) (*Session, io.ReadCloser, error) {
+	p, err := s.path(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, nil, err
+	}
+	line, err := bufio.NewReader(f).ReadBytes('\n')
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, fmt.Errorf("read header of session %v failed: %v", id, err)
+	}
+	h := header{}
+	if err = json.Unmarshal(line, &h); err != nil || h.Session == nil {
+		_ = f.Close()
+		return nil, nil, fmt.Errorf("invalid header of session %v", id)
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		_ = f.Close()
+		return nil, nil, err
+	}
+	return h.Session, f, nil
+}
+
+// Cleanup removes recordings last written before given time
+func (s *Store) Cleanup(before time.Time) error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".cast") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || !info.ModTime().Before(before) {
+			continue
+		}
+		if err = os.Remove(filepath.Join(s.dir, e.Name())); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		clog.Debug("recording %v expired and removed", e.Name())
+	}
+	return nil
+}
+
+// RunCleanup removes recordings older than retention periodically until ctx
+// done, nothing is removed if retention is not positive
+func (s *Store) RunCleanup(ctx context.Context, retention time.Duration) {
+	if retention <= 0 {
+		return
+	}
+	ticker := time.NewTicker(cleanupPeriod)
+	defer ticker.Stop()
+	for {
+		if err := s.Cleanup(time.Now().Add(-retention)); err != nil {
+			clog.Warn("clean up recordings of sessions failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -40,6 +40,7 @@ import (
 	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
 	"github.com/saashqdev/kubeworkz/pkg/utils/env"
 	"github.com/saashqdev/kubeworkz/pkg/utils/international"
+	"github.com/saashqdev/kubeworkz/pkg/utils/redact"
 )
 
 var (
@@ -220,6 +221,7 @@ func (h *Handler) SendEvent(c *gin.Context, event *Event, options *Options) {
 
 func sendEvent(e *Event) {
 	clog.Debug("[audit] send event to audit service")
+	redactSecretEvent(e)
 	jsonstr, err := json.Marshal(e)
 	if err != nil {
 		clog.Error("[audit] json marshal event error: %v", err)
@@ -324,6 +326,43 @@ func processUrlStr(urlstrs []string) (objectType string, objectName string) {
 	return objectType, objectName
 }
 
+func isSecretEvent(e *Event) bool {
+	for _, r := range e.ResourceReports {
+		if r.ResourceType == "secret" {
+			return true
+		}
+	}
+	return false
+}
+
+// redactSecretEvent masks data of secrets in request and response of event,
+// they are never sent to audit service
+func redactSecretEvent(e *Event) {
+	if !isSecretEvent(e) {
+		return
+	}
+	e.ResponseElements = redact.SecretJSON(e.ResponseElements)
+	e.ErrorMessage = redact.SecretJSON(e.ErrorMessage)
+
+	if len(e.RequestParameters) == 0 {
+		return
+	}
+	var params parameters
+	if err := json.Unmarshal([]byte(e.RequestParameters), &params); err != nil {
+		clog.Warn("[audit] unmarshal request parameters error: %s", err)
+		e.RequestParameters = ""
+		return
+	}
+	params.Body = redact.SecretJSON(params.Body)
+	paramJson, err := json.Marshal(params)
+	if err != nil {
+		clog.Warn("[audit] marshal request parameters error: %s", err)
+		e.RequestParameters = ""
+		return
+	}
+	e.RequestParameters = string(paramJson)
+}
+
 func isProxyApi(requestURI string) bool {
 	if strings.HasPrefix(requestURI, constants.ApiPathRoot+"/proxy") {
 		return true
@@ -31,6 +31,7 @@ import (
 	"github.com/gogf/gf/v2/i18n/gi18n"
 	"github.com/google/uuid"
 	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+	"github.com/saashqdev/kubeworkz/pkg/utils/redact"
 )
 
 type header struct {
@@ -108,3 +109,27 @@ func TestGetEventName(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestRedactSecretEvent(t *testing.T) {
+	h := Handler{gi18n.Instance(), gi18n.Instance()}
+	body := []byte(`{"kind":"Secret","metadata":{"name":"secretA"},"data":{"password":"cGFzcw=="},"stringData":{"token":"plain"}}`)
+
+	e := &Event{}
+	router := gin.New()
+	router.POST("/api/v1/kube/proxy/clusters/:cluster/api/v1/namespaces/:namespace/secrets", func(c *gin.Context) {
+		e = h.handleProxyApi(context.Background(), c, *e)
+		e.RequestParameters = ConsistParameters(c, body)
+		e.ResponseElements = string(body)
+	})
+	_ = performRequest(router, http.MethodPost, "/api/v1/kube/proxy/clusters/pivot-cluster/api/v1/namespaces/dev/secrets", body)
+
+	redactSecretEvent(e)
+	for _, s := range []string{e.RequestParameters, e.ResponseElements} {
+		if strings.Contains(s, "cGFzcw==") || strings.Contains(s, "plain") || !strings.Contains(s, redact.Mask) {
+			t.Errorf("data of secret is not redacted: %s", s)
+		}
+	}
+	if !strings.Contains(e.RequestParameters, "secretA") {
+		t.Errorf("request parameters are lost: %s", e.RequestParameters)
+	}
+}
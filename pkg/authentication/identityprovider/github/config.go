@@ -27,7 +27,7 @@ import (
 	"github.com/saashqdev/kubeworkz/pkg/clog"
 	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
 	"github.com/saashqdev/kubeworkz/pkg/utils/env"
-	"github.com/saashqdev/kubeworkz/pkg/warden/localmgr/controllers/hotplug"
+	"github.com/saashqdev/kubeworkz/pkg/utils/hotplug"
 )
 
 const configMapName = "kubeworkz-auth-config"
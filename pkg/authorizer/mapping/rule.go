@@ -0,0 +1,249 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mapping
+
+import (
+	"fmt"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+)
+
+// RoleSpec the authoring form of ClusterRole which supports what auth items
+// can not express, such as subresources, resourceNames and non-resource urls.
+type RoleSpec struct {
+	Name  string `json:"name"`
+	Scope string `json:"scope"`
+	Rules []Rule `json:"rules"`
+
+	// Clusters the clusters the role distributed to besides control plane,
+	// role is synced to all clusters by warden if empty
+	Clusters []string `json:"clusters,omitempty"`
+}
+
+// Rule grants verbs on resources of one api group, or on non-resource urls
+type Rule struct {
+	// APIGroup the api group of resources, empty for core group
+	APIGroup string `json:"apiGroup,omitempty"`
+
+	// Resources such as pods, or pods/log for subresource
+	Resources     []string `json:"resources,omitempty"`
+	ResourceNames []string `json:"resourceNames,omitempty"`
+
+	// NonResourceURLs such as /healthz, only for platform roles as they are
+	// not effective in RoleBindings
+	NonResourceURLs []string `json:"nonResourceURLs,omitempty"`
+
+	Verbs []string `json:"verbs,omitempty"`
+
+	// VerbGroup is expanded into verbs of read, write or all and merged with Verbs
+	VerbGroup VerbRepresent `json:"verbGroup,omitempty"`
+}
+
+// verbs the request verbs which are reported by discovery
+var discoveryVerbs = sets.NewString("get", "list", "watch", "create", "update", "patch", "delete", "deletecollection")
+
+// reservedRoles the roles of kubernetes and kubeworkz which can not be
+// authored as custom roles
+var reservedRoles = sets.NewString(
+	"cluster-admin", "admin", "edit", "view",
+	constants.PlatformAdmin, constants.TenantAdmin, constants.ProjectAdmin, constants.Reviewer,
+	constants.TenantAdminCluster, constants.ProjectAdminCluster, constants.ReviewerCluster,
+	constants.AggPlatformAdmin, constants.AggReviewer, constants.AggProjectAdminCluster,
+	constants.AggTenantAdminCluster, constants.AggProjectAdmin, constants.AggTenantAdmin,
+)
+
+// IsReservedRole returns true if role is built in kubernetes or kubeworkz
+func IsReservedRole(name string) bool {
+	return reservedRoles.Has(name) || strings.HasPrefix(name, "system:")
+}
+
+// BuildClusterRole validates role spec and generates ClusterRole of it
+func BuildClusterRole(spec *RoleSpec) (*rbacv1.ClusterRole, error) {
+	if IsReservedRole(spec.Name) {
+		return nil, fmt.Errorf("role name %q is reserved", spec.Name)
+	}
+	if errs := validation.IsDNS1123Subdomain(spec.Name); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid role name %q: %v", spec.Name, strings.Join(errs, ","))
+	}
+	if spec.Scope != constants.ClusterRolePlatform && spec.Scope != constants.ClusterRoleTenant && spec.Scope != constants.ClusterRoleProject {
+		return nil, fmt.Errorf("invalid role scope %q", spec.Scope)
+	}
+	if len(spec.Rules) == 0 {
+		return nil, fmt.Errorf("role has no rules")
+	}
+
+	role := &rbacv1.ClusterRole{
+		ObjectMeta: v1.ObjectMeta{
+			Name:   spec.Name,
+			Labels: map[string]string{constants.RbacLabel: constants.TrueStr, constants.RoleLabel: spec.Scope},
+		},
+	}
+	if len(spec.Clusters) == 0 {
+		role.Annotations = map[string]string{constants.SyncAnnotation: constants.TrueStr}
+	}
+
+	for i, r := range spec.Rules {
+		verbs, err := expandVerbs(r)
+		if err != nil {
+			return nil, fmt.Errorf("rule %v: %v", i, err)
+		}
+
+		if len(r.NonResourceURLs) > 0 {
+			if len(r.Resources) > 0 || len(r.ResourceNames) > 0 || len(r.APIGroup) > 0 {
+				return nil, fmt.Errorf("rule %v: non-resource urls can not be mixed with resources", i)
+			}
+			if spec.Scope != constants.ClusterRolePlatform {
+				return nil, fmt.Errorf("rule %v: non-resource urls are only allowed in platform roles", i)
+			}
+			for _, url := range r.NonResourceURLs {
+				if !strings.HasPrefix(url, "/") {
+					return nil, fmt.Errorf("rule %v: non-resource url %q must start with /", i, url)
+				}
+			}
+			role.Rules = append(role.Rules, rbacv1.PolicyRule{NonResourceURLs: r.NonResourceURLs, Verbs: verbs})
+			continue
+		}
+
+		if len(r.Resources) == 0 {
+			return nil, fmt.Errorf("rule %v: either resources or non-resource urls required", i)
+		}
+		if len(r.ResourceNames) > 0 {
+			for _, v := range verbs {
+				if v == "create" || v == "deletecollection" || v == rbacv1.VerbAll {
+					return nil, fmt.Errorf("rule %v: verb %v can not be restricted by resource names", i, v)
+				}
+			}
+		}
+		role.Rules = append(role.Rules, rbacv1.PolicyRule{
+			APIGroups:     []string{r.APIGroup},
+			Resources:     r.Resources,
+			ResourceNames: r.ResourceNames,
+			Verbs:         verbs,
+		})
+	}
+
+	return role, nil
+}
+
+func expandVerbs(r Rule) ([]string, error) {
+	verbs := sets.NewString(r.Verbs...)
+	switch r.VerbGroup {
+	case "", Null:
+	case Read:
+		verbs.Insert(readVerbs.List()...)
+	case Write:
+		verbs.Insert(writeVerbs.List()...)
+	case All:
+		verbs.Insert(bothVerbs.List()...)
+	default:
+		return nil, fmt.Errorf("unknown verb group %q", r.VerbGroup)
+	}
+	if verbs.Len() == 0 {
+		return nil, fmt.Errorf("no verbs")
+	}
+	if verbs.Has(rbacv1.VerbAll) {
+		return []string{rbacv1.VerbAll}, nil
+	}
+	return verbs.List(), nil
+}
+
+// RoleSpecOf transforms ClusterRole back into role spec for editing
+func RoleSpecOf(role *rbacv1.ClusterRole) *RoleSpec {
+	spec := &RoleSpec{Name: role.Name, Scope: role.Labels[constants.RoleLabel], Rules: []Rule{}}
+	for _, r := range role.Rules {
+		if len(r.NonResourceURLs) > 0 {
+			spec.Rules = append(spec.Rules, Rule{NonResourceURLs: r.NonResourceURLs, Verbs: r.Verbs})
+			continue
+		}
+		for _, group := range r.APIGroups {
+			spec.Rules = append(spec.Rules, Rule{
+				APIGroup:      group,
+				Resources:     r.Resources,
+				ResourceNames: r.ResourceNames,
+				Verbs:         r.Verbs,
+			})
+		}
+	}
+	return spec
+}
+
+// ValidateRules checks that api groups, resources, subresources and verbs
+// of rules are served by cluster, returns the problems found
+func ValidateRules(rules []rbacv1.PolicyRule, served []*v1.APIResourceList) []string {
+	// api group -> resource -> verbs
+	groups := make(map[string]map[string]sets.String)
+	for _, list := range served {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		resources, ok := groups[gv.Group]
+		if !ok {
+			resources = make(map[string]sets.String)
+			groups[gv.Group] = resources
+		}
+		for _, r := range list.APIResources {
+			if _, ok := resources[r.Name]; !ok {
+				resources[r.Name] = sets.NewString()
+			}
+			resources[r.Name].Insert(r.Verbs...)
+		}
+	}
+
+	problems := []string{}
+	for i, rule := range rules {
+		for _, group := range rule.APIGroups {
+			if group == rbacv1.APIGroupAll {
+				continue
+			}
+			resources, ok := groups[group]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("rule %v: api group %q is not served", i, group))
+				continue
+			}
+			for _, resource := range rule.Resources {
+				if resource == rbacv1.ResourceAll {
+					continue
+				}
+				if parent, sub, found := strings.Cut(resource, "/"); found && sub == "*" {
+					if _, ok := resources[parent]; !ok {
+						problems = append(problems, fmt.Sprintf("rule %v: resource %q is not served in api group %q", i, parent, group))
+					}
+					continue
+				}
+				verbs, ok := resources[resource]
+				if !ok {
+					problems = append(problems, fmt.Sprintf("rule %v: resource %q is not served in api group %q", i, resource, group))
+					continue
+				}
+				for _, verb := range rule.Verbs {
+					if discoveryVerbs.Has(verb) && verbs.Len() > 0 && !verbs.Has(verb) {
+						problems = append(problems, fmt.Sprintf("rule %v: verb %v is not supported by resource %q in api group %q", i, verb, resource, group))
+					}
+				}
+			}
+		}
+	}
+	return problems
+}
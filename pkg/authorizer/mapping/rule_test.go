@@ -0,0 +1,96 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mapping
+
+import (
+	"reflect"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+)
+
+func TestBuildClusterRole(t *testing.T) {
+	spec := &RoleSpec{
+		Name:  "log-reader",
+		Scope: constants.ClusterRoleProject,
+		Rules: []Rule{
+			{Resources: []string{"pods", "pods/log"}, VerbGroup: Read},
+			{APIGroup: "apps", Resources: []string{"deployments"}, ResourceNames: []string{"web"}, Verbs: []string{"get", "patch"}},
+		},
+	}
+	role, err := BuildClusterRole(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"pods", "pods/log"}, Verbs: []string{"get", "list", "watch"}},
+		{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, ResourceNames: []string{"web"}, Verbs: []string{"get", "patch"}},
+	}
+	if !reflect.DeepEqual(role.Rules, want) {
+		t.Fatalf("expect rules %v, got %v", want, role.Rules)
+	}
+	if role.Labels[constants.RoleLabel] != constants.ClusterRoleProject || role.Annotations[constants.SyncAnnotation] != constants.TrueStr {
+		t.Fatalf("unexpected metadata %v %v", role.Labels, role.Annotations)
+	}
+
+	if got := RoleSpecOf(role); !reflect.DeepEqual(got.Rules[1].ResourceNames, []string{"web"}) || got.Scope != spec.Scope {
+		t.Fatalf("unexpected spec of role %+v", got)
+	}
+
+	invalid := []RoleSpec{
+		{Name: "a", Scope: constants.ClusterRoleTenant, Rules: []Rule{{NonResourceURLs: []string{"/healthz"}, Verbs: []string{"get"}}}},
+		{Name: "a", Scope: constants.ClusterRolePlatform, Rules: []Rule{{NonResourceURLs: []string{"/healthz"}, Resources: []string{"pods"}, Verbs: []string{"get"}}}},
+		{Name: "a", Scope: constants.ClusterRoleTenant, Rules: []Rule{{Resources: []string{"pods"}, ResourceNames: []string{"x"}, Verbs: []string{"create"}}}},
+		{Name: "a", Scope: constants.ClusterRoleTenant, Rules: []Rule{{Resources: []string{"pods"}}}},
+		{Name: "A_b", Scope: constants.ClusterRoleTenant, Rules: []Rule{{Resources: []string{"pods"}, Verbs: []string{"get"}}}},
+		{Name: "a", Scope: "cluster", Rules: []Rule{{Resources: []string{"pods"}, Verbs: []string{"get"}}}},
+		{Name: "cluster-admin", Scope: constants.ClusterRolePlatform, Rules: []Rule{{Resources: []string{"pods"}, Verbs: []string{"get"}}}},
+		{Name: constants.TenantAdmin, Scope: constants.ClusterRoleTenant, Rules: []Rule{{Resources: []string{"pods"}, Verbs: []string{"get"}}}},
+		{Name: "system:node", Scope: constants.ClusterRolePlatform, Rules: []Rule{{Resources: []string{"pods"}, Verbs: []string{"get"}}}},
+	}
+	for i := range invalid {
+		if _, err = BuildClusterRole(&invalid[i]); err == nil {
+			t.Errorf("expect spec %v invalid", i)
+		}
+	}
+}
+
+func TestValidateRules(t *testing.T) {
+	served := []*v1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []v1.APIResource{
+			{Name: "pods", Verbs: []string{"get", "list", "watch", "create", "delete"}},
+			{Name: "pods/log", Verbs: []string{"get"}},
+		}},
+		{GroupVersion: "apps/v1", APIResources: []v1.APIResource{
+			{Name: "deployments", Verbs: []string{"get", "list", "update"}},
+		}},
+	}
+	rules := []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"pods", "pods/log", "pods/*"}, Verbs: []string{"get"}},
+		{APIGroups: []string{""}, Resources: []string{"pods/log"}, Verbs: []string{"delete"}},
+		{APIGroups: []string{"apps"}, Resources: []string{"statefulsets"}, Verbs: []string{"get"}},
+		{APIGroups: []string{"batch"}, Resources: []string{"jobs"}, Verbs: []string{"get"}},
+		{NonResourceURLs: []string{"/healthz"}, Verbs: []string{"get"}},
+	}
+	problems := ValidateRules(rules, served)
+	if len(problems) != 3 {
+		t.Fatalf("expect 3 problems, got %v", problems)
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+)
+
+// Covers returns true if owner rules allow everything servant rules allow,
+// wildcards of servant are only covered by the same wildcards of owner
+func Covers(owner, servant []rbacv1.PolicyRule) bool {
+	for _, rule := range servant {
+		for _, attributes := range attributesOf(rule) {
+			if !allowedBy(attributes, owner) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func allowedBy(attributes authorizer.Attributes, rules []rbacv1.PolicyRule) bool {
+	for i := range rules {
+		if RuleAllows(attributes, &rules[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// attributesOf breaks rule down into requests it allows
+func attributesOf(rule rbacv1.PolicyRule) []authorizer.Attributes {
+	var attributes []authorizer.Attributes
+	for _, verb := range rule.Verbs {
+		for _, url := range rule.NonResourceURLs {
+			attributes = append(attributes, authorizer.AttributesRecord{Verb: verb, Path: url})
+		}
+		names := rule.ResourceNames
+		if len(names) == 0 {
+			names = []string{""}
+		}
+		for _, group := range rule.APIGroups {
+			for _, resource := range rule.Resources {
+				resource, subresource, _ := strings.Cut(resource, "/")
+				for _, name := range names {
+					attributes = append(attributes, authorizer.AttributesRecord{
+						Verb:            verb,
+						APIGroup:        group,
+						Resource:        resource,
+						Subresource:     subresource,
+						Name:            name,
+						ResourceRequest: true,
+					})
+				}
+			}
+		}
+	}
+	return attributes
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+func TestCovers(t *testing.T) {
+	admin := []rbacv1.PolicyRule{{Verbs: []string{"*"}, APIGroups: []string{"*"}, Resources: []string{"*"}}}
+	podReader := []rbacv1.PolicyRule{{Verbs: []string{"get", "list"}, APIGroups: []string{""}, Resources: []string{"pods", "pods/log"}}}
+	podWriter := []rbacv1.PolicyRule{{Verbs: []string{"get", "list", "delete"}, APIGroups: []string{""}, Resources: []string{"pods"}}}
+	namedPod := []rbacv1.PolicyRule{{Verbs: []string{"get"}, APIGroups: []string{""}, Resources: []string{"pods"}, ResourceNames: []string{"foo"}}}
+	anyVerb := []rbacv1.PolicyRule{{Verbs: []string{"*"}, APIGroups: []string{""}, Resources: []string{"pods"}}}
+	metrics := []rbacv1.PolicyRule{{Verbs: []string{"get"}, NonResourceURLs: []string{"/metrics"}}}
+
+	tests := []struct {
+		name           string
+		owner, servant []rbacv1.PolicyRule
+		expected       bool
+	}{
+		{"admin covers all", admin, podWriter, true},
+		{"same rules", podReader, podReader, true},
+		{"missing verb", podReader, podWriter, false},
+		{"missing subresource", podWriter, podReader, false},
+		{"named resource", podReader, namedPod, true},
+		{"named owner", namedPod, podReader, false},
+		{"wildcard verb", podWriter, anyVerb, false},
+		{"non resource url", admin, metrics, false},
+		{"empty servant", nil, nil, true},
+	}
+	for _, test := range tests {
+		if got := Covers(test.owner, test.servant); got != test.expected {
+			t.Errorf("%v: expected %v, got %v", test.name, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"fmt"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+
+	"github.com/saashqdev/kubeworkz/pkg/clog"
+)
+
+// BindingPath describes the binding and role through which a rule granted
+type BindingPath struct {
+	BindingKind      string `json:"bindingKind"`
+	BindingName      string `json:"bindingName"`
+	BindingNamespace string `json:"bindingNamespace,omitempty"`
+	RoleKind         string `json:"roleKind"`
+	RoleName         string `json:"roleName"`
+}
+
+func (p BindingPath) String() string {
+	if len(p.BindingNamespace) > 0 {
+		return fmt.Sprintf("%s %q of %s %q", p.BindingKind, p.BindingName+"/"+p.BindingNamespace, p.RoleKind, p.RoleName)
+	}
+	return fmt.Sprintf("%s %q of %s %q", p.BindingKind, p.BindingName, p.RoleKind, p.RoleName)
+}
+
+// ClusterWide tells if the path is granted by cluster role binding
+func (p BindingPath) ClusterWide() bool {
+	return p.BindingKind == "ClusterRoleBinding"
+}
+
+// SubjectAccess is a subject allowed to perform an action and the binding
+// path granting it
+type SubjectAccess struct {
+	Subject rbacv1.Subject    `json:"subject"`
+	Path    BindingPath       `json:"path"`
+	Rule    rbacv1.PolicyRule `json:"rule"`
+}
+
+// RuleAccess is an effective rule of user and the binding path granting it
+type RuleAccess struct {
+	Rule rbacv1.PolicyRule `json:"rule"`
+	Path BindingPath       `json:"path"`
+}
+
+func clusterRoleBindingPath(binding *rbacv1.ClusterRoleBinding) BindingPath {
+	return BindingPath{
+		BindingKind: "ClusterRoleBinding",
+		BindingName: binding.Name,
+		RoleKind:    binding.RoleRef.Kind,
+		RoleName:    binding.RoleRef.Name,
+	}
+}
+
+func roleBindingPath(binding *rbacv1.RoleBinding) BindingPath {
+	return BindingPath{
+		BindingKind:      "RoleBinding",
+		BindingName:      binding.Name,
+		BindingNamespace: binding.Namespace,
+		RoleKind:         binding.RoleRef.Kind,
+		RoleName:         binding.RoleRef.Name,
+	}
+}
+
+// SubjectsFor returns users, groups and service accounts allowed to perform
+// the action described by attributes, the user of attributes is ignored.
+// Bindings referring to missing roles are skipped.
+func (r *DefaultResolver) SubjectsFor(attributes authorizer.Attributes) ([]SubjectAccess, error) {
+	accesses := []SubjectAccess{}
+
+	visit := func(path BindingPath, subjects []rbacv1.Subject, bindingNamespace string) {
+		rules, err := r.GetRoleReferenceRules(rbacv1.RoleRef{Kind: path.RoleKind, Name: path.RoleName}, bindingNamespace)
+		if err != nil {
+			clog.Warn("resolve rules of %v failed: %v", path, err)
+			return
+		}
+		for i := range rules {
+			if !RuleAllows(attributes, &rules[i]) {
+				continue
+			}
+			for _, subject := range subjects {
+				if subject.Kind == rbacv1.ServiceAccountKind && len(subject.Namespace) == 0 {
+					subject.Namespace = bindingNamespace
+				}
+				accesses = append(accesses, SubjectAccess{Subject: subject, Path: path, Rule: rules[i]})
+			}
+			// the first allowing rule is enough for binding
+			return
+		}
+	}
+
+	clusterRoleBindings, err := r.ListClusterRoleBindings()
+	if err != nil {
+		return nil, err
+	}
+	for i := range clusterRoleBindings {
+		b := &clusterRoleBindings[i]
+		visit(clusterRoleBindingPath(b), b.Subjects, "")
+	}
+
+	if ns := attributes.GetNamespace(); len(ns) > 0 {
+		roleBindings, err := r.ListRoleBindings(ns)
+		if err != nil {
+			return nil, err
+		}
+		for i := range roleBindings {
+			b := &roleBindings[i]
+			visit(roleBindingPath(b), b.Subjects, ns)
+		}
+	}
+
+	return accesses, nil
+}
+
+// RulesFor returns every effective rule of user in namespace with the binding
+// path granting it, only cluster wide rules returned if namespace is empty.
+// Bindings referring to missing roles are skipped.
+func (r *DefaultResolver) RulesFor(user user.Info, namespace string) ([]RuleAccess, error) {
+	accesses := []RuleAccess{}
+	var listErr error
+
+	r.VisitRulesFor(user, namespace, func(source fmt.Stringer, rule *rbacv1.PolicyRule, err error) bool {
+		if err != nil {
+			clog.Warn("resolve rules of user %v failed: %v", user.GetName(), err)
+			// role referred by binding may be deleted
+			if errors.IsNotFound(err) {
+				return true
+			}
+			listErr = err
+			return false
+		}
+		var path BindingPath
+		switch s := source.(type) {
+		case *clusterRoleBindingDescriber:
+			path = clusterRoleBindingPath(s.binding)
+		case *roleBindingDescriber:
+			path = roleBindingPath(s.binding)
+		default:
+			return true
+		}
+		accesses = append(accesses, RuleAccess{Rule: *rule, Path: path})
+		return true
+	})
+
+	if listErr != nil {
+		return nil, listErr
+	}
+	return accesses, nil
+}
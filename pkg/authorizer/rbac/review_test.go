@@ -0,0 +1,131 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"context"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// fakeCache serves reads of resolver from fake client
+type fakeCache struct {
+	cache.Cache
+	cli client.Client
+}
+
+func (f *fakeCache) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	return f.cli.Get(ctx, key, obj, opts...)
+}
+
+func (f *fakeCache) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return f.cli.List(ctx, list, opts...)
+}
+
+func newReviewResolver() *DefaultResolver {
+	objs := []client.Object{
+		&rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{Name: "reviewer"},
+			Rules:      []rbacv1.PolicyRule{{Verbs: []string{"get", "list"}, APIGroups: []string{"*"}, Resources: []string{"pods"}}},
+		},
+		&rbacv1.Role{
+			ObjectMeta: metav1.ObjectMeta{Name: "editor", Namespace: "ns1"},
+			Rules:      []rbacv1.PolicyRule{{Verbs: []string{"*"}, APIGroups: []string{"*"}, Resources: []string{"pods"}}},
+		},
+		&rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "alice-reviewer"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "reviewer"},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.UserKind, Name: "alice"}, {Kind: rbacv1.GroupKind, Name: "auditors"}},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "bob-editor", Namespace: "ns1"},
+			RoleRef:    rbacv1.RoleRef{Kind: "Role", Name: "editor"},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.UserKind, Name: "bob"}, {Kind: rbacv1.ServiceAccountKind, Name: "ci"}},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "dangling", Namespace: "ns1"},
+			RoleRef:    rbacv1.RoleRef{Kind: "Role", Name: "missing"},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.UserKind, Name: "bob"}},
+		},
+	}
+	cli := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(objs...).Build()
+	return &DefaultResolver{Cache: &fakeCache{cli: cli}}
+}
+
+func TestSubjectsFor(t *testing.T) {
+	r := newReviewResolver()
+
+	accesses, err := r.SubjectsFor(&authorizer.AttributesRecord{Verb: "delete", Namespace: "ns1", Resource: "pods", ResourceRequest: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accesses) != 2 {
+		t.Fatalf("expect bob and service account ci, got %+v", accesses)
+	}
+	for _, a := range accesses {
+		if a.Path.BindingName != "bob-editor" || a.Path.RoleName != "editor" {
+			t.Fatalf("unexpected binding path %+v", a.Path)
+		}
+		if a.Subject.Kind == rbacv1.ServiceAccountKind && a.Subject.Namespace != "ns1" {
+			t.Fatalf("expect namespace of service account defaulted, got %+v", a.Subject)
+		}
+	}
+
+	accesses, err = r.SubjectsFor(&authorizer.AttributesRecord{Verb: "get", Namespace: "ns1", Resource: "pods", ResourceRequest: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accesses) != 4 {
+		t.Fatalf("expect alice, auditors, bob and ci, got %+v", accesses)
+	}
+	clusterWide := 0
+	for _, a := range accesses {
+		if a.Path.ClusterWide() {
+			clusterWide++
+		}
+	}
+	if clusterWide != 2 {
+		t.Fatalf("expect alice and auditors granted cluster wide, got %+v", accesses)
+	}
+}
+
+func TestRulesFor(t *testing.T) {
+	r := newReviewResolver()
+
+	accesses, err := r.RulesFor(User2UserInfo("bob"), "ns1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accesses) != 1 || accesses[0].Path.String() != `RoleBinding "bob-editor/ns1" of Role "editor"` {
+		t.Fatalf("unexpected rules of bob: %+v", accesses)
+	}
+
+	accesses, err = r.RulesFor(User2UserInfo("alice"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accesses) != 1 || accesses[0].Path.BindingKind != "ClusterRoleBinding" {
+		t.Fatalf("unexpected rules of alice: %+v", accesses)
+	}
+}
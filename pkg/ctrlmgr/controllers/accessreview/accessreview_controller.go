@@ -0,0 +1,335 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package accessreview
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	tenantv1 "github.com/saashqdev/kubeworkz/pkg/apis/tenant/v1"
+	userv1 "github.com/saashqdev/kubeworkz/pkg/apis/user/v1"
+	"github.com/saashqdev/kubeworkz/pkg/clog"
+	"github.com/saashqdev/kubeworkz/pkg/ctrlmgr/options"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+	"github.com/saashqdev/kubeworkz/pkg/utils/transition"
+)
+
+const (
+	// defaultDuration is the review window when not specified
+	defaultDuration = 7 * 24 * time.Hour
+
+	// AutoRevoker is recorded as reviewer of bindings revoked at deadline
+	AutoRevoker = "system:auto-revoke"
+)
+
+// AccessReviewReconciler snapshots scope bindings for access review campaigns,
+// enforces revocations and completes campaigns at deadline
+type AccessReviewReconciler struct {
+	client.Client
+}
+
+func newReconciler(mgr manager.Manager) (reconcile.Reconciler, error) {
+	r := &AccessReviewReconciler{
+		Client: mgr.GetClient(),
+	}
+	return r, nil
+}
+
+//+kubebuilder:rbac:groups=user.kubeworkz.io,resources=accessreviews,verbs=get;list;watch;create;update
+//+kubebuilder:rbac:groups=user.kubeworkz.io,resources=accessreviews/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=user.kubeworkz.io,resources=users,verbs=get;list;watch;update
+//+kubebuilder:rbac:groups=tenant.kubeworkz.io,resources=projects,verbs=get;list;watch
+
+// Reconcile snapshots scope bindings when campaign created, removes bindings
+// revoked by reviewers, and completes campaign when deadline reached.
+func (r *AccessReviewReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	review := &userv1.AccessReview{}
+	err := r.Get(ctx, req.NamespacedName, review)
+	if err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if len(review.Status.Phase) == 0 {
+		if err = r.snapshot(ctx, review); err != nil {
+			clog.Error("snapshot bindings for access review %v failed: %v", review.Name, err)
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	if review.Status.Phase == userv1.AccessReviewInProgress {
+		if err = r.enforce(ctx, review.Name); err != nil {
+			clog.Error("enforce decisions of access review %v failed: %v", review.Name, err)
+			return ctrl.Result{}, err
+		}
+		if remain := time.Until(deadlineOf(review).Time); remain > 0 {
+			return ctrl.Result{RequeueAfter: remain}, nil
+		}
+		if err = r.complete(ctx, review.Name); err != nil {
+			clog.Error("complete access review %v failed: %v", review.Name, err)
+			return ctrl.Result{}, err
+		}
+	}
+
+	return r.recur(ctx, review)
+}
+
+// snapshot records scope bindings in scope of campaign as pending items
+func (r *AccessReviewReconciler) snapshot(ctx context.Context, review *userv1.AccessReview) error {
+	scopes, err := r.scopesOf(ctx, review.Spec)
+	if err != nil {
+		return err
+	}
+
+	users := userv1.UserList{}
+	if err = r.List(ctx, &users); err != nil {
+		return err
+	}
+
+	items := []userv1.ReviewItem{}
+	admins := sets.New[string]()
+	now := metav1.Now()
+	for _, u := range users.Items {
+		for _, b := range userv1.ActiveScopeBindings(u.Spec.ScopeBindings, now.Time) {
+			if b.ScopeType == userv1.TenantScope && b.ScopeName == review.Spec.Tenant && b.Role == constants.TenantAdmin {
+				admins.Insert(u.Name)
+			}
+			if !scopes.Has(scopeKey(b.ScopeType, b.ScopeName)) {
+				continue
+			}
+			items = append(items, userv1.ReviewItem{
+				User:      u.Name,
+				ScopeType: b.ScopeType,
+				ScopeName: b.ScopeName,
+				Role:      b.Role,
+				Decision:  userv1.DecisionPending,
+			})
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return itemKey(items[i]) < itemKey(items[j])
+	})
+
+	reviewers := review.Spec.Reviewers
+	if len(reviewers) == 0 {
+		reviewers = sets.List(admins)
+	}
+
+	deadline := deadlineOf(review)
+
+	clog.Info("access review %v snapshotted %v bindings, deadline %v", review.Name, len(items), deadline)
+
+	return r.updateStatus(ctx, review.Name, func(status *userv1.AccessReviewStatus) {
+		status.Phase = userv1.AccessReviewInProgress
+		status.SnapshotTime = &now
+		status.Deadline = &deadline
+		status.Reviewers = reviewers
+		status.Items = items
+		status.Summarize()
+	})
+}
+
+// deadlineOf returns deadline of access review, it is derived from spec if
+// not recorded in status yet
+func deadlineOf(review *userv1.AccessReview) metav1.Time {
+	if review.Status.Deadline != nil {
+		return *review.Status.Deadline
+	}
+	duration := defaultDuration
+	if review.Spec.Duration != nil {
+		duration = review.Spec.Duration.Duration
+	}
+	return metav1.NewTime(review.CreationTimestamp.Add(duration))
+}
+
+// scopesOf returns the scopes whose bindings are reviewed
+func (r *AccessReviewReconciler) scopesOf(ctx context.Context, spec userv1.AccessReviewSpec) (sets.Set[string], error) {
+	if len(spec.Project) > 0 {
+		return sets.New(scopeKey(userv1.ProjectScope, spec.Project)), nil
+	}
+
+	scopes := sets.New(scopeKey(userv1.TenantScope, spec.Tenant))
+	projects := tenantv1.ProjectList{}
+	err := r.List(ctx, &projects, client.MatchingLabels{constants.TenantLabel: spec.Tenant})
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range projects.Items {
+		scopes.Insert(scopeKey(userv1.ProjectScope, p.Name))
+	}
+	return scopes, nil
+}
+
+// enforce removes scope bindings revoked but not yet removed from users
+func (r *AccessReviewReconciler) enforce(ctx context.Context, name string) error {
+	review := &userv1.AccessReview{}
+	if err := r.Get(ctx, types.NamespacedName{Name: name}, review); err != nil {
+		return err
+	}
+
+	enforced := []int{}
+	for i, item := range review.Status.Items {
+		if item.Decision != userv1.DecisionRevoked || item.Enforced {
+			continue
+		}
+		if err := r.revoke(ctx, item); err != nil {
+			return err
+		}
+		enforced = append(enforced, i)
+	}
+	if len(enforced) == 0 {
+		return nil
+	}
+
+	return r.updateStatus(ctx, name, func(status *userv1.AccessReviewStatus) {
+		for _, i := range enforced {
+			status.Items[i].Enforced = true
+		}
+	})
+}
+
+// complete revokes pending bindings if auto revoke enabled and marks
+// campaign completed
+func (r *AccessReviewReconciler) complete(ctx context.Context, name string) error {
+	review := &userv1.AccessReview{}
+	if err := r.Get(ctx, types.NamespacedName{Name: name}, review); err != nil {
+		return err
+	}
+
+	now := metav1.Now()
+	revoked := []int{}
+	if review.Spec.AutoRevoke {
+		for i, item := range review.Status.Items {
+			if item.Decision != userv1.DecisionPending {
+				continue
+			}
+			if err := r.revoke(ctx, item); err != nil {
+				return err
+			}
+			revoked = append(revoked, i)
+		}
+	}
+
+	clog.Info("access review %v completed, %v pending bindings revoked", name, len(revoked))
+
+	return r.updateStatus(ctx, name, func(status *userv1.AccessReviewStatus) {
+		for _, i := range revoked {
+			status.Items[i].Decision = userv1.DecisionRevoked
+			status.Items[i].Reviewer = AutoRevoker
+			status.Items[i].ReviewedAt = &now
+			status.Items[i].Enforced = true
+		}
+		status.Phase = userv1.AccessReviewCompleted
+		status.CompletionTime = &now
+		status.Summarize()
+	})
+}
+
+// revoke removes scope binding of item from user
+func (r *AccessReviewReconciler) revoke(ctx context.Context, item userv1.ReviewItem) error {
+	u := &userv1.User{}
+	err := r.Get(ctx, types.NamespacedName{Name: item.User}, u)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	transition.RemoveUserScopeBindings(u, string(item.ScopeType), item.ScopeName, item.Role)
+	err = transition.UpdateUserSpec(ctx, r.Client, u)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// recur creates next campaign of recurring access review when due
+func (r *AccessReviewReconciler) recur(ctx context.Context, review *userv1.AccessReview) (ctrl.Result, error) {
+	if review.Spec.RecurEvery == nil || len(review.Status.NextReview) > 0 {
+		return ctrl.Result{}, nil
+	}
+
+	due := review.CreationTimestamp.Add(review.Spec.RecurEvery.Duration)
+	if remain := time.Until(due); remain > 0 {
+		return ctrl.Result{RequeueAfter: remain}, nil
+	}
+
+	campaign := review.Name
+	if c, ok := review.Labels[constants.AccessReviewCampaignLabel]; ok {
+		campaign = c
+	}
+	next := &userv1.AccessReview{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   fmt.Sprintf("%v-%v", campaign, due.UTC().Format("20060102-150405")),
+			Labels: map[string]string{constants.AccessReviewCampaignLabel: campaign},
+		},
+		Spec: review.Spec,
+	}
+	err := r.Create(ctx, next)
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return ctrl.Result{}, err
+	}
+	clog.Info("access review %v created following %v", next.Name, review.Name)
+
+	return ctrl.Result{}, r.updateStatus(ctx, review.Name, func(status *userv1.AccessReviewStatus) {
+		status.NextReview = next.Name
+	})
+}
+
+func (r *AccessReviewReconciler) updateStatus(ctx context.Context, name string, mutate func(status *userv1.AccessReviewStatus)) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		review := &userv1.AccessReview{}
+		err := r.Get(ctx, types.NamespacedName{Name: name}, review)
+		if err != nil {
+			return err
+		}
+		mutate(&review.Status)
+		return r.Status().Update(ctx, review)
+	})
+}
+
+func scopeKey(scopeType userv1.BindingScopeType, scopeName string) string {
+	return string(scopeType) + "/" + scopeName
+}
+
+func itemKey(item userv1.ReviewItem) string {
+	return item.User + "/" + scopeKey(item.ScopeType, item.ScopeName) + "/" + item.Role
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func SetupWithManager(mgr ctrl.Manager, _ *options.Options) error {
+	r, err := newReconciler(mgr)
+	if err != nil {
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&userv1.AccessReview{}).
+		Complete(r)
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package accessreview
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	tenantv1 "github.com/saashqdev/kubeworkz/pkg/apis/tenant/v1"
+	userv1 "github.com/saashqdev/kubeworkz/pkg/apis/user/v1"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+)
+
+func newUser(name string, bindings ...userv1.ScopeBinding) *userv1.User {
+	return &userv1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       userv1.UserSpec{ScopeBindings: bindings},
+	}
+}
+
+func newTestReconciler(review *userv1.AccessReview) *AccessReviewReconciler {
+	scheme := runtime.NewScheme()
+	_ = userv1.AddToScheme(scheme)
+	_ = tenantv1.AddToScheme(scheme)
+
+	project := &tenantv1.Project{ObjectMeta: metav1.ObjectMeta{Name: "p1", Labels: map[string]string{constants.TenantLabel: "t1"}}}
+	other := &tenantv1.Project{ObjectMeta: metav1.ObjectMeta{Name: "p2", Labels: map[string]string{constants.TenantLabel: "t2"}}}
+
+	cli := fake.NewClientBuilder().WithScheme(scheme).
+		WithStatusSubresource(&userv1.AccessReview{}).
+		WithObjects(review, project, other,
+			newUser("admin", userv1.ScopeBinding{ScopeType: userv1.TenantScope, ScopeName: "t1", Role: constants.TenantAdmin}),
+			newUser("alice", userv1.ScopeBinding{ScopeType: userv1.ProjectScope, ScopeName: "p1", Role: constants.ProjectAdmin}),
+			newUser("bob",
+				userv1.ScopeBinding{ScopeType: userv1.ProjectScope, ScopeName: "p1", Role: "reviewer"},
+				userv1.ScopeBinding{ScopeType: userv1.ProjectScope, ScopeName: "p2", Role: constants.ProjectAdmin}),
+		).Build()
+	return &AccessReviewReconciler{Client: cli}
+}
+
+func reconcileReview(t *testing.T, r *AccessReviewReconciler, name string) *userv1.AccessReview {
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: name}})
+	if err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	review := &userv1.AccessReview{}
+	if err = r.Get(context.Background(), types.NamespacedName{Name: name}, review); err != nil {
+		t.Fatal(err)
+	}
+	return review
+}
+
+func bindingsOf(t *testing.T, cli client.Client, name string) []userv1.ScopeBinding {
+	u := &userv1.User{}
+	if err := cli.Get(context.Background(), types.NamespacedName{Name: name}, u); err != nil {
+		t.Fatal(err)
+	}
+	return u.Spec.ScopeBindings
+}
+
+func TestSnapshotAndEnforce(t *testing.T) {
+	review := &userv1.AccessReview{
+		ObjectMeta: metav1.ObjectMeta{Name: "r1", CreationTimestamp: metav1.Now()},
+		Spec:       userv1.AccessReviewSpec{Tenant: "t1"},
+	}
+	r := newTestReconciler(review)
+
+	review = reconcileReview(t, r, "r1")
+	if review.Status.Phase != userv1.AccessReviewInProgress {
+		t.Fatalf("expect phase %v, got %v", userv1.AccessReviewInProgress, review.Status.Phase)
+	}
+	if len(review.Status.Items) != 3 || review.Status.Pending != 3 {
+		t.Fatalf("expect 3 pending bindings of tenant t1, got %+v", review.Status.Items)
+	}
+	if len(review.Status.Reviewers) != 1 || review.Status.Reviewers[0] != "admin" {
+		t.Fatalf("expect tenant admin as reviewer, got %v", review.Status.Reviewers)
+	}
+
+	for i := range review.Status.Items {
+		if review.Status.Items[i].User == "bob" {
+			review.Status.Items[i].Decision = userv1.DecisionRevoked
+		}
+	}
+	if err := r.Status().Update(context.Background(), review); err != nil {
+		t.Fatal(err)
+	}
+
+	review = reconcileReview(t, r, "r1")
+	bindings := bindingsOf(t, r.Client, "bob")
+	if len(bindings) != 1 || bindings[0].ScopeName != "p2" {
+		t.Fatalf("expect only binding out of tenant kept, got %v", bindings)
+	}
+	for _, item := range review.Status.Items {
+		if item.User == "bob" && !item.Enforced {
+			t.Fatalf("expect revoked binding enforced")
+		}
+	}
+}
+
+func TestAutoRevokeAndRecur(t *testing.T) {
+	created := metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	review := &userv1.AccessReview{
+		ObjectMeta: metav1.ObjectMeta{Name: "r2", CreationTimestamp: created},
+		Spec: userv1.AccessReviewSpec{
+			Tenant:     "t1",
+			Project:    "p1",
+			Duration:   &metav1.Duration{Duration: time.Hour},
+			AutoRevoke: true,
+			RecurEvery: &metav1.Duration{Duration: time.Hour},
+		},
+	}
+	r := newTestReconciler(review)
+
+	review = reconcileReview(t, r, "r2")
+	if len(review.Status.Items) != 2 {
+		t.Fatalf("expect 2 bindings of project p1, got %+v", review.Status.Items)
+	}
+
+	review = reconcileReview(t, r, "r2")
+	if review.Status.Phase != userv1.AccessReviewCompleted || review.Status.Revoked != 2 {
+		t.Fatalf("expect completed with all bindings revoked, got %+v", review.Status)
+	}
+	if len(bindingsOf(t, r.Client, "alice")) != 0 {
+		t.Fatalf("expect binding of alice revoked")
+	}
+
+	if len(review.Status.NextReview) == 0 {
+		t.Fatalf("expect next review created")
+	}
+	next := &userv1.AccessReview{}
+	if err := r.Get(context.Background(), types.NamespacedName{Name: review.Status.NextReview}, next); err != nil {
+		t.Fatal(err)
+	}
+	if next.Labels[constants.AccessReviewCampaignLabel] != "r2" || next.Spec.Project != "p1" {
+		t.Fatalf("unexpected next review %+v", next)
+	}
+}
+
+func TestInProgressWithoutDeadline(t *testing.T) {
+	review := &userv1.AccessReview{
+		ObjectMeta: metav1.ObjectMeta{Name: "r3", CreationTimestamp: metav1.Now()},
+		Spec:       userv1.AccessReviewSpec{Tenant: "t1", Project: "p1"},
+		Status:     userv1.AccessReviewStatus{Phase: userv1.AccessReviewInProgress},
+	}
+	r := newTestReconciler(review)
+
+	result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "r3"}})
+	if err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	if result.RequeueAfter <= 0 || result.RequeueAfter > defaultDuration {
+		t.Fatalf("expect requeue at deadline derived from spec, got %v", result.RequeueAfter)
+	}
+}
@@ -69,6 +69,12 @@ func (r *ClusterRoleBindingReconciler) syncUserOnCreate(ctx context.Context, clu
 		return ctrl.Result{}, nil
 	}
 
+	// bindings generated by warden for scope bindings of user are labeled
+	// already, adding them back would make temporary scope bindings permanent
+	if _, ok := clusterRoleBinding.Labels[constants.LabelRelationship]; ok {
+		return ctrl.Result{}, nil
+	}
+
 	foundUser := false
 
 	user := &userv1.User{}
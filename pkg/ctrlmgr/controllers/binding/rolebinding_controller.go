@@ -70,6 +70,12 @@ func (r *RoleBindingReconciler) syncUserOnCreate(ctx context.Context, roleBindin
 		return ctrl.Result{}, nil
 	}
 
+	// bindings generated by warden for scope bindings of user are labeled
+	// already, adding them back would make temporary scope bindings permanent
+	if _, ok := roleBinding.Labels[constants.LabelRelationship]; ok {
+		return ctrl.Result{}, nil
+	}
+
 	user := &userv1.User{}
 	err = r.Get(ctx, types.NamespacedName{Name: userName}, user)
 	if err != nil {
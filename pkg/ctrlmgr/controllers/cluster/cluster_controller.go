@@ -119,9 +119,30 @@ func (r *ClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return ctrl.Result{}, nil
 	}
 
+	// only check rolling warden of cluster already initialized
+	if cluster.Status.WardenRollout != nil {
+		if _, err := multicluster.Interface().Get(cluster.Name); err == nil {
+			return r.checkWardenRolloutOf(ctx, cluster)
+		}
+	}
+
 	return r.syncCluster(ctx, cluster)
 }
 
+func (r *ClusterReconciler) checkWardenRolloutOf(ctx context.Context, cluster clusterv1.Cluster) (ctrl.Result, error) {
+	tempClient, err := tryConnectCluster(cluster, r.Scheme)
+	if err != nil {
+		log.Error(err.Error())
+		return ctrl.Result{RequeueAfter: wardenRolloutPollInterval}, nil
+	}
+	requeueAfter, err := r.checkWardenRollout(ctx, tempClient, &cluster)
+	if err != nil {
+		log.Error("check warden rollout of cluster %v failed: %v", cluster.Name, err)
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
 func (r *ClusterReconciler) syncCluster(ctx context.Context, cluster clusterv1.Cluster) (ctrl.Result, error) {
 	// update cluster status to processing
 	err := utils.UpdateClusterStatusByState(ctx, r.Client, &cluster, clusterv1.ClusterProcessing)
@@ -131,7 +152,7 @@ func (r *ClusterReconciler) syncCluster(ctx context.Context, cluster clusterv1.C
 	log.Info("Cluster %v is processing", cluster.Name)
 
 	// try connect to cluster, tempClient will be GC after function down
-	tempClient, err := tryConnectCluster(cluster)
+	tempClient, err := tryConnectCluster(cluster, r.Scheme)
 	if err != nil {
 		// todo: what if kubeconfig is wrong
 		log.Error(err.Error())
@@ -142,7 +163,7 @@ func (r *ClusterReconciler) syncCluster(ctx context.Context, cluster clusterv1.C
 	log.Info("Handshake with cluster %v success", cluster.Name)
 
 	// deploy resources to cluster
-	err = deployResources(ctx, tempClient, &cluster, r.pivotCluster)
+	requeueAfter, err := r.deployResources(ctx, tempClient, &cluster, r.pivotCluster)
 	if err != nil {
 		log.Error("deploy resource failed: %v", err)
 		_ = utils.UpdateClusterStatusByState(ctx, r.Client, &cluster, clusterv1.ClusterInitFailed)
@@ -170,7 +191,8 @@ func (r *ClusterReconciler) syncCluster(ctx context.Context, cluster clusterv1.C
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{}, nil
+	// keep checking rolling warden
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
 }
 
 // It enqueues a cluster for later reconciliation. This occurs in a goroutine
@@ -26,9 +26,11 @@ import (
 	v1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	clusterv1 "github.com/saashqdev/kubeworkz/pkg/apis/cluster/v1"
@@ -61,6 +63,31 @@ func createResource(ctx context.Context, obj client.Object, c client.Client, clu
 	return nil
 }
 
+// applyResource creates or updates obj in cluster by server-side apply,
+// fields owned by other managers are taken over
+func applyResource(ctx context.Context, obj client.Object, c client.Client, cluster string, objKind string) error {
+	if reflect.ValueOf(obj).IsNil() {
+		return fmt.Errorf("object can not be nil")
+	}
+
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return err
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	obj.SetResourceVersion("")
+	obj.SetManagedFields(nil)
+
+	err = c.Patch(ctx, obj, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership)
+	if err != nil {
+		return fmt.Errorf("apply resource %v %v to cluster %v failed: %v", objKind, obj.GetName(), cluster, err)
+	}
+
+	log.Debug("apply %v %v to cluster %v success", objKind, obj.GetName(), cluster)
+
+	return nil
+}
+
 // waitForJobComplete block and wait until job meets completed
 func waitForJobComplete(ctx context.Context, cli client.Client, namespacedName types.NamespacedName) error {
 	isJobCompleted := func(j v1.Job) bool {
@@ -88,13 +115,13 @@ func waitForJobComplete(ctx context.Context, cli client.Client, namespacedName t
 	})
 }
 
-func tryConnectCluster(cluster clusterv1.Cluster) (client.Client, error) {
+func tryConnectCluster(cluster clusterv1.Cluster, scheme *runtime.Scheme) (client.Client, error) {
 	config, err := kubeconfig.LoadKubeConfigFromBytes(cluster.Spec.KubeConfig)
 	if err != nil {
 		return nil, err
 	}
 
-	cli, err := client.New(config, client.Options{})
+	cli, err := client.New(config, client.Options{Scheme: scheme})
 	if err != nil {
 		return nil, err
 	}
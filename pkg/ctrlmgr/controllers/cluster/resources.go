@@ -19,6 +19,7 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"time"
 
 	clusterv1 "github.com/saashqdev/kubeworkz/pkg/apis/cluster/v1"
 	v1 "k8s.io/api/admissionregistration/v1"
@@ -50,108 +51,121 @@ const (
 	mountPki             = "/etc/kubernetes/pki"
 	mountName            = "pki-mount"
 	helmVolumeName       = "helm-pkg"
+	fieldManager         = "kubeworkz-controller-manager"
 )
 
-func deployResources(ctx context.Context, cli client.Client, memberCluster, pivotCluster *clusterv1.Cluster) error {
-	// if target cluster is dating with Kubeworkz
-	// or warden use register mode, return directly
-	if isDatingCluster(ctx, cli, memberCluster.Name) || env.WardenRegisterModeEnable() == "true" {
-		return nil
+// deployResources applies bootstrap resources to member cluster by
+// server-side apply, so that they follow kubeworkz after upgraded, and rolls
+// warden forward when its image skewed from the one kubeworkz wants
+func (r *ClusterReconciler) deployResources(ctx context.Context, cli client.Client, memberCluster, pivotCluster *clusterv1.Cluster) (time.Duration, error) {
+	// if warden use register mode, return directly
+	if env.WardenRegisterModeEnable() == "true" {
+		return 0, nil
 	}
 
 	isMemberCluster := memberCluster.Spec.IsMemberCluster
 
-	// create resource below when cluster is member
+	// warden of pivot cluster is installed by helm along with kubeworkz,
+	// it is deployed only when missing and never applied over
+	if !isMemberCluster && isDatingCluster(ctx, cli, memberCluster.Name) {
+		return 0, nil
+	}
+
+	// apply resource below when cluster is member
 	if isMemberCluster {
 		if pivotCluster == nil {
-			return fmt.Errorf("pivot cluster not ready")
+			return 0, fmt.Errorf("pivot cluster not ready")
 		}
 
-		// create crds to target cluster
+		// dependence only need to be installed before warden first deployed
+		isDating := isDatingCluster(ctx, cli, memberCluster.Name)
+
+		// apply crds to target cluster
 		crds := makeCRDs()
 		for _, crd := range crds {
-			err := createResource(ctx, crd, cli, memberCluster.Name, "crd")
+			err := applyResource(ctx, crd, cli, memberCluster.Name, "crd")
 			if err != nil {
-				return err
+				return 0, err
 			}
 		}
 
-		// create namespace to member cluster
+		// apply namespace to member cluster
 		ns := makeNamespace()
-		err := createResource(ctx, ns, cli, memberCluster.Name, "namespace")
+		err := applyResource(ctx, ns, cli, memberCluster.Name, "namespace")
 		if err != nil {
-			return err
+			return 0, err
 		}
 
 		clusterRole := makeClusterRole()
-		err = createResource(ctx, clusterRole, cli, memberCluster.Name, "ClusterRole")
+		err = applyResource(ctx, clusterRole, cli, memberCluster.Name, "ClusterRole")
 		if err != nil {
-			return err
+			return 0, err
 		}
 
 		clusterRoleBinding := makeClusterRoleBinding()
-		err = createResource(ctx, clusterRoleBinding, cli, memberCluster.Name, "ClusterRoleBinding")
+		err = applyResource(ctx, clusterRoleBinding, cli, memberCluster.Name, "ClusterRoleBinding")
 		if err != nil {
-			return err
+			return 0, err
 		}
 
-		// create tls secret to target cluster
+		// apply tls secret to target cluster
 		tlsSecret := makeTLSSecret()
-		err = createResource(ctx, tlsSecret, cli, memberCluster.Name, "secret")
+		err = applyResource(ctx, tlsSecret, cli, memberCluster.Name, "secret")
 		if err != nil {
-			return err
+			return 0, err
 		}
 
-		// create kubeConfig cm to target cluster
+		// apply kubeConfig cm to target cluster
 		cm := makeKubeConfigCM(pivotCluster)
-		err = createResource(ctx, cm, cli, memberCluster.Name, "configmap")
+		err = applyResource(ctx, cm, cli, memberCluster.Name, "configmap")
 		if err != nil {
-			return err
+			return 0, err
 		}
 
-		// install dependence into target cluster by job
-		prevJob := makePrevJob()
-		err = createResource(ctx, prevJob, cli, memberCluster.Name, "job")
-		if err != nil {
-			return err
-		}
+		if !isDating {
+			// install dependence into target cluster by job
+			prevJob := makePrevJob()
+			err = createResource(ctx, prevJob, cli, memberCluster.Name, "job")
+			if err != nil {
+				return 0, err
+			}
 
-		// wait until job complete
-		err = waitForJobComplete(ctx, cli, types.NamespacedName{Name: prevJob.Name, Namespace: prevJob.Namespace})
-		if err != nil {
-			return err
+			// wait until job complete
+			err = waitForJobComplete(ctx, cli, types.NamespacedName{Name: prevJob.Name, Namespace: prevJob.Namespace})
+			if err != nil {
+				return 0, err
+			}
 		}
 	}
 
-	// create kubeConfig secret to target cluster
+	// apply kubeConfig secret to target cluster
 	secret := makeKubeConfigSecret(pivotCluster, memberCluster)
-	err := createResource(ctx, secret, cli, memberCluster.Name, "secret")
+	err := applyResource(ctx, secret, cli, memberCluster.Name, "secret")
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	// create warden deployment to target cluster
-	deployment := makeDeployment(memberCluster.Name, isMemberCluster)
-	err = createResource(ctx, deployment, cli, memberCluster.Name, "deployment")
+	// apply warden deployment to target cluster and check it healthy later
+	requeueAfter, err := r.rollWarden(ctx, cli, memberCluster)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	// create warden service to target cluster
+	// apply warden service to target cluster
 	npSvc := makeWardenSvc()
-	err = createResource(ctx, npSvc, cli, memberCluster.Name, "service")
+	err = applyResource(ctx, npSvc, cli, memberCluster.Name, "service")
 	if err != nil {
-		clog.Warn("NodePort service %v already exist: %v", npSvc.Name, err)
+		clog.Warn("apply NodePort service %v failed: %v", npSvc.Name, err)
 	}
 
-	// create validate webhook to target cluster
+	// apply validate webhook to target cluster
 	wh := makeWardenWebhook()
-	err = createResource(ctx, wh, cli, memberCluster.Name, "validateWebhookConfiguration")
+	err = applyResource(ctx, wh, cli, memberCluster.Name, "validateWebhookConfiguration")
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	return nil
+	return requeueAfter, nil
 }
 
 // makeDeployment set kubeconfig and jwt secret for warden
@@ -504,8 +518,7 @@ func makeCRDs() (crds []*apiextensionsv1.CustomResourceDefinition) {
 				Name:   crd.Name,
 				Labels: crd.Labels,
 			},
-			Spec:   crd.Spec,
-			Status: crd.Status,
+			Spec: crd.Spec,
 		})
 	}
 
@@ -0,0 +1,207 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1 "github.com/saashqdev/kubeworkz/pkg/apis/cluster/v1"
+	"github.com/saashqdev/kubeworkz/pkg/utils"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+	"github.com/saashqdev/kubeworkz/pkg/utils/env"
+)
+
+const (
+	// wardenRolloutTimeout is the max duration to wait for warden healthy after rolled
+	wardenRolloutTimeout = 5 * time.Minute
+
+	// wardenRolloutPollInterval is the interval to check rolling warden
+	wardenRolloutPollInterval = 10 * time.Second
+)
+
+// rollWarden applies warden deployment to cluster and records the rolling in
+// cluster status when changed, the rolling is checked by requeue until warden
+// healthy. Upgrades are serialized so that wardens roll forward cluster by
+// cluster, and an image failed health check is not rolled any further until
+// target image changed. The returned duration is when the cluster should be
+// checked again.
+func (r *ClusterReconciler) rollWarden(ctx context.Context, cli client.Client, cluster *clusterv1.Cluster) (time.Duration, error) {
+	if cluster.Status.WardenRollout != nil {
+		return r.checkWardenRollout(ctx, cli, cluster)
+	}
+
+	image := env.WardenImage()
+
+	// failed image is remembered only while kubeworkz wants it, once target
+	// image changed wardens roll forward again
+	if failed := cluster.Status.FailedWardenImage; failed != "" && failed != image {
+		log.Info("target warden image of cluster %v changed from failed %v to %v", cluster.Name, failed, image)
+		err := utils.UpdateClusterStatus(ctx, r.Client, cluster, func(cluster *clusterv1.Cluster) {
+			cluster.Status.FailedWardenImage = ""
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	key := client.ObjectKey{Name: constants.Warden, Namespace: env.KubeNamespace()}
+	current := appsv1.Deployment{}
+	err := cli.Get(ctx, key, &current)
+	if err != nil && !errors.IsNotFound(err) {
+		return 0, err
+	}
+	exist := err == nil
+
+	running := wardenImageOf(&current)
+	skewed := exist && running != image
+	if skewed {
+		failed, rolling, err := r.wardenRolloutsOf(ctx, image)
+		if err != nil {
+			return 0, err
+		}
+		if failed != "" {
+			log.Warn("warden %v of cluster %v skewed from %v, skip upgrade since %v failed health check in cluster %v", running, cluster.Name, image, image, failed)
+			return 0, nil
+		}
+		if rolling != "" && rolling != cluster.Name {
+			log.Info("warden of cluster %v skewed, wait for rolling in cluster %v", cluster.Name, rolling)
+			return wardenRolloutPollInterval, nil
+		}
+		log.Info("warden of cluster %v skewed, upgrade from %v to %v", cluster.Name, running, image)
+	}
+
+	deployment := makeDeployment(cluster.Name, cluster.Spec.IsMemberCluster)
+	err = applyResource(ctx, deployment, cli, cluster.Name, "deployment")
+	if err != nil {
+		return 0, err
+	}
+
+	// nothing changed and warden was healthy
+	if exist && deployment.Generation == current.Generation && cluster.Status.WardenImage == image {
+		return 0, nil
+	}
+
+	rollout := &clusterv1.WardenRollout{Image: image, StartTime: metav1.Now()}
+	if skewed {
+		rollout.PreviousImage = running
+	}
+	err = utils.UpdateClusterStatus(ctx, r.Client, cluster, func(cluster *clusterv1.Cluster) {
+		cluster.Status.WardenRollout = rollout
+	})
+	if err != nil {
+		return 0, err
+	}
+	return wardenRolloutPollInterval, nil
+}
+
+// checkWardenRollout checks rolling warden of cluster, warden is rolled back
+// to previous image and the image is marked as failed when not healthy in time
+func (r *ClusterReconciler) checkWardenRollout(ctx context.Context, cli client.Client, cluster *clusterv1.Cluster) (time.Duration, error) {
+	rollout := cluster.Status.WardenRollout
+	key := client.ObjectKey{Name: constants.Warden, Namespace: env.KubeNamespace()}
+	current := appsv1.Deployment{}
+	err := cli.Get(ctx, key, &current)
+	if err != nil && !errors.IsNotFound(err) {
+		return 0, err
+	}
+
+	if err == nil && wardenImageOf(&current) == rollout.Image && isRolledOut(&current) {
+		log.Info("warden %v of cluster %v is healthy", rollout.Image, cluster.Name)
+		return 0, utils.UpdateClusterStatus(ctx, r.Client, cluster, func(cluster *clusterv1.Cluster) {
+			cluster.Status.WardenImage = rollout.Image
+			cluster.Status.WardenRollout = nil
+		})
+	}
+
+	if time.Since(rollout.StartTime.Time) < wardenRolloutTimeout {
+		return wardenRolloutPollInterval, nil
+	}
+
+	log.Warn("warden %v of cluster %v not healthy in %v", rollout.Image, cluster.Name, wardenRolloutTimeout)
+	if rollout.PreviousImage != "" {
+		deployment := makeDeployment(cluster.Name, cluster.Spec.IsMemberCluster)
+		setWardenImage(deployment, rollout.PreviousImage)
+		err = applyResource(ctx, deployment, cli, cluster.Name, "deployment")
+		if err != nil {
+			return 0, err
+		}
+		log.Info("warden of cluster %v rolled back to %v", cluster.Name, rollout.PreviousImage)
+	}
+
+	return 0, utils.UpdateClusterStatus(ctx, r.Client, cluster, func(cluster *clusterv1.Cluster) {
+		cluster.Status.FailedWardenImage = rollout.Image
+		cluster.Status.WardenRollout = nil
+	})
+}
+
+// wardenRolloutsOf returns the cluster where given warden image failed health
+// check and the cluster where warden is rolling, empty if not found
+func (r *ClusterReconciler) wardenRolloutsOf(ctx context.Context, image string) (string, string, error) {
+	clusters := clusterv1.ClusterList{}
+	if err := r.List(ctx, &clusters); err != nil {
+		return "", "", err
+	}
+	failed, rolling := "", ""
+	for _, c := range clusters.Items {
+		if c.Status.FailedWardenImage == image {
+			failed = c.Name
+		}
+		if c.Status.WardenRollout != nil {
+			rolling = c.Name
+		}
+	}
+	return failed, rolling, nil
+}
+
+// isRolledOut tells if latest spec of deployment observed and all replicas
+// updated and available without old replicas left
+func isRolledOut(d *appsv1.Deployment) bool {
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	status := d.Status
+	return status.ObservedGeneration >= d.Generation &&
+		status.UpdatedReplicas == replicas &&
+		status.AvailableReplicas == replicas &&
+		status.Replicas == replicas
+}
+
+// wardenImageOf returns image of warden container in deployment
+func wardenImageOf(d *appsv1.Deployment) string {
+	for _, c := range d.Spec.Template.Spec.Containers {
+		if c.Name == constants.Warden {
+			return c.Image
+		}
+	}
+	return ""
+}
+
+// setWardenImage sets image of warden container in deployment
+func setWardenImage(d *appsv1.Deployment, image string) {
+	for i := range d.Spec.Template.Spec.Containers {
+		if d.Spec.Template.Spec.Containers[i].Name == constants.Warden {
+			d.Spec.Template.Spec.Containers[i].Image = image
+		}
+	}
+}
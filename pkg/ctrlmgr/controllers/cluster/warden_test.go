@@ -0,0 +1,207 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterv1 "github.com/saashqdev/kubeworkz/pkg/apis/cluster/v1"
+	"github.com/saashqdev/kubeworkz/pkg/clog"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+)
+
+func TestIsRolledOut(t *testing.T) {
+	d := makeDeployment("member1", true)
+	d.Generation = 2
+	d.Status = appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1}
+	if isRolledOut(d) {
+		t.Fatalf("expect not rolled out when generation not observed")
+	}
+
+	d.Status = appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 1, AvailableReplicas: 1}
+	if isRolledOut(d) {
+		t.Fatalf("expect not rolled out when old replica left")
+	}
+
+	d.Status = appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1}
+	if !isRolledOut(d) {
+		t.Fatalf("expect rolled out")
+	}
+}
+
+func TestWardenImageOf(t *testing.T) {
+	t.Setenv("WARDEN_IMAGE", "kubeworkz/warden:v2")
+	d := makeDeployment("member1", true)
+	if image := wardenImageOf(d); image != "kubeworkz/warden:v2" {
+		t.Fatalf("expect image kubeworkz/warden:v2, got %v", image)
+	}
+
+	d.Spec.Template.Spec.Containers[0].Name = constants.Warden + "-renamed"
+	if image := wardenImageOf(d); image != "" {
+		t.Fatalf("expect empty image, got %v", image)
+	}
+}
+
+func newRolloutReconciler(clusters ...client.Object) *ClusterReconciler {
+	log = clog.WithName("cluster")
+	scheme := runtime.NewScheme()
+	_ = clusterv1.AddToScheme(scheme)
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(clusters...).WithStatusSubresource(&clusterv1.Cluster{}).Build()
+	return &ClusterReconciler{Client: cli, Scheme: scheme}
+}
+
+func newWarden(image string, rolledOut bool) *appsv1.Deployment {
+	d := makeDeployment("member1", true)
+	setWardenImage(d, image)
+	d.Generation = 1
+	d.Status = appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 1, UpdatedReplicas: 1}
+	if rolledOut {
+		d.Status.AvailableReplicas = 1
+	}
+	return d
+}
+
+func TestCheckWardenRollout(t *testing.T) {
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "member1"},
+		Status: clusterv1.ClusterStatus{
+			WardenImage:   "kubeworkz/warden:v1",
+			WardenRollout: &clusterv1.WardenRollout{Image: "kubeworkz/warden:v2", StartTime: metav1.Now()},
+		},
+	}
+	r := newRolloutReconciler(cluster.DeepCopy())
+	ctx := context.Background()
+
+	member := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(newWarden("kubeworkz/warden:v2", false)).Build()
+	requeueAfter, err := r.checkWardenRollout(ctx, member, cluster.DeepCopy())
+	if err != nil || requeueAfter != wardenRolloutPollInterval {
+		t.Fatalf("expect requeue when warden not available, got %v, %v", requeueAfter, err)
+	}
+
+	member = fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(newWarden("kubeworkz/warden:v2", true)).Build()
+	requeueAfter, err = r.checkWardenRollout(ctx, member, cluster.DeepCopy())
+	if err != nil || requeueAfter != 0 {
+		t.Fatalf("expect rollout done, got %v, %v", requeueAfter, err)
+	}
+	current := clusterv1.Cluster{}
+	if err = r.Get(ctx, types.NamespacedName{Name: "member1"}, &current); err != nil {
+		t.Fatal(err)
+	}
+	if current.Status.WardenImage != "kubeworkz/warden:v2" || current.Status.WardenRollout != nil {
+		t.Fatalf("expect healthy image recorded and rollout cleared, got %+v", current.Status)
+	}
+}
+
+func TestCheckWardenRolloutTimeout(t *testing.T) {
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "member1"},
+		Status: clusterv1.ClusterStatus{
+			WardenRollout: &clusterv1.WardenRollout{
+				Image:     "kubeworkz/warden:v2",
+				StartTime: metav1.NewTime(time.Now().Add(-wardenRolloutTimeout - time.Second)),
+			},
+		},
+	}
+	r := newRolloutReconciler(cluster.DeepCopy())
+	ctx := context.Background()
+
+	member := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(newWarden("kubeworkz/warden:v2", false)).Build()
+	requeueAfter, err := r.checkWardenRollout(ctx, member, cluster.DeepCopy())
+	if err != nil || requeueAfter != 0 {
+		t.Fatalf("expect rollout stopped, got %v, %v", requeueAfter, err)
+	}
+	current := clusterv1.Cluster{}
+	if err = r.Get(ctx, types.NamespacedName{Name: "member1"}, &current); err != nil {
+		t.Fatal(err)
+	}
+	if current.Status.FailedWardenImage != "kubeworkz/warden:v2" || current.Status.WardenRollout != nil {
+		t.Fatalf("expect failed image recorded and rollout cleared, got %+v", current.Status)
+	}
+}
+
+func TestRollWardenSkipFailedImage(t *testing.T) {
+	t.Setenv("WARDEN_IMAGE", "kubeworkz/warden:v2")
+	failed := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "member2"},
+		Status:     clusterv1.ClusterStatus{FailedWardenImage: "kubeworkz/warden:v2"},
+	}
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "member1"}}
+	r := newRolloutReconciler(failed, cluster.DeepCopy())
+
+	member := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(newWarden("kubeworkz/warden:v1", true)).Build()
+	requeueAfter, err := r.rollWarden(context.Background(), member, cluster)
+	if err != nil || requeueAfter != 0 {
+		t.Fatalf("expect upgrade skipped, got %v, %v", requeueAfter, err)
+	}
+
+	failed.Status = clusterv1.ClusterStatus{
+		WardenRollout: &clusterv1.WardenRollout{Image: "kubeworkz/warden:v2", StartTime: metav1.Now()},
+	}
+	r = newRolloutReconciler(failed, cluster.DeepCopy())
+	requeueAfter, err = r.rollWarden(context.Background(), member, cluster)
+	if err != nil || requeueAfter != wardenRolloutPollInterval {
+		t.Fatalf("expect waiting for rolling in other cluster, got %v, %v", requeueAfter, err)
+	}
+
+	d := appsv1.Deployment{}
+	if err = member.Get(context.Background(), client.ObjectKeyFromObject(newWarden("", true)), &d); err != nil {
+		t.Fatal(err)
+	}
+	if image := wardenImageOf(&d); image != "kubeworkz/warden:v1" {
+		t.Fatalf("expect warden not upgraded, got %v", image)
+	}
+}
+
+func TestRollWardenAfterTargetChanged(t *testing.T) {
+	t.Setenv("WARDEN_IMAGE", "kubeworkz/warden:v3")
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "member1"},
+		Status: clusterv1.ClusterStatus{
+			WardenImage:       "kubeworkz/warden:v1",
+			FailedWardenImage: "kubeworkz/warden:v2",
+		},
+	}
+	r := newRolloutReconciler(cluster.DeepCopy())
+	ctx := context.Background()
+
+	member := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(newWarden("kubeworkz/warden:v1", true)).Build()
+	requeueAfter, err := r.rollWarden(ctx, member, cluster)
+	if err != nil || requeueAfter != wardenRolloutPollInterval {
+		t.Fatalf("expect rolling to new target, got %v, %v", requeueAfter, err)
+	}
+
+	current := clusterv1.Cluster{}
+	if err = r.Get(ctx, types.NamespacedName{Name: "member1"}, &current); err != nil {
+		t.Fatal(err)
+	}
+	if current.Status.FailedWardenImage != "" {
+		t.Fatalf("expect failed image cleared, got %v", current.Status.FailedWardenImage)
+	}
+	if rollout := current.Status.WardenRollout; rollout == nil || rollout.Image != "kubeworkz/warden:v3" || rollout.PreviousImage != "kubeworkz/warden:v1" {
+		t.Fatalf("expect rollout to kubeworkz/warden:v3 recorded, got %+v", rollout)
+	}
+}
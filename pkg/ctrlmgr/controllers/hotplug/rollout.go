@@ -0,0 +1,213 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hotplug
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	hotplugv1 "github.com/saashqdev/kubeworkz/pkg/apis/hotplug/v1"
+)
+
+const (
+	PhaseProgressing = "Progressing"
+	PhasePaused      = "Paused"
+	PhaseCompleted   = "Completed"
+
+	success = "success"
+	fail    = "fail"
+)
+
+// clusterState is the observed state of common hotplug in a cluster
+type clusterState struct {
+	Name     string
+	Labels   map[string]string
+	Revision string
+	Phase    string
+	Results  []*hotplugv1.DeployResult
+	Message  string
+}
+
+// Plan decides which clusters the revision should be released to by policy,
+// clusters released before stay released. It returns progress of rollout
+// and names of all released clusters in order.
+func Plan(policy *hotplugv1.RolloutPolicy, revision string, clusters []clusterState, released map[string]bool) (hotplugv1.RolloutStatus, []string) {
+	status := hotplugv1.RolloutStatus{Revision: revision}
+
+	isReleased := func(c clusterState) bool {
+		return released[c.Name] || c.Revision == revision
+	}
+	isDone := func(c clusterState) bool {
+		return c.Revision == revision && c.Phase == success
+	}
+	isFailed := func(c clusterState) bool {
+		return c.Revision == revision && c.Phase == fail
+	}
+	isFinished := func(c clusterState) bool {
+		return isDone(c) || (!policy.PauseOnFailure && isFailed(c))
+	}
+
+	failed := []string{}
+	for _, c := range clusters {
+		if isReleased(c) && isFailed(c) {
+			failed = append(failed, c.Name)
+		}
+	}
+	paused := policy.Paused || (policy.PauseOnFailure && len(failed) > 0)
+
+	batches := Batches(policy, clusters)
+	current := -1
+	for i, batch := range batches {
+		finished := true
+		for _, c := range batch {
+			if !isFinished(c) {
+				finished = false
+				break
+			}
+		}
+		if !finished {
+			current = i
+			break
+		}
+	}
+
+	releasing := make(map[string]bool)
+	if current >= 0 && !paused {
+		batch := batches[current]
+		budget := maxUnavailable(policy.MaxUnavailable, len(batch))
+		for _, c := range batch {
+			if isReleased(c) && !isFinished(c) {
+				budget--
+			}
+		}
+		for _, c := range batch {
+			if budget <= 0 {
+				break
+			}
+			if !isReleased(c) {
+				releasing[c.Name] = true
+				budget--
+			}
+		}
+	}
+
+	names := []string{}
+	for i, batch := range batches {
+		for _, c := range batch {
+			r := isReleased(c) || releasing[c.Name]
+			if r {
+				names = append(names, c.Name)
+			}
+			cs := hotplugv1.ClusterRolloutStatus{
+				Name:     c.Name,
+				Batch:    i,
+				Released: r,
+				Revision: c.Revision,
+				Phase:    c.Phase,
+				Results:  c.Results,
+				Message:  c.Message,
+			}
+			status.Clusters = append(status.Clusters, cs)
+		}
+	}
+
+	switch {
+	case current < 0:
+		status.Phase = PhaseCompleted
+		if len(failed) > 0 {
+			status.Message = fmt.Sprintf("failed in clusters: %v", strings.Join(failed, ", "))
+		}
+	case policy.Paused:
+		status.Phase = PhasePaused
+		status.Message = "rollout paused"
+	case paused:
+		status.Phase = PhasePaused
+		status.Message = fmt.Sprintf("rollout paused on failure of clusters: %v", strings.Join(failed, ", "))
+	default:
+		status.Phase = PhaseProgressing
+		status.Message = fmt.Sprintf("releasing batch %d of %d", current, len(batches)-1)
+	}
+
+	return status, names
+}
+
+// Batches groups clusters into batches in order of release, the first batch
+// holds canary clusters and may be empty. The rest batches are grouped by
+// value of batch label, clusters without the label come last.
+func Batches(policy *hotplugv1.RolloutPolicy, clusters []clusterState) [][]clusterState {
+	canary := make(map[string]bool, len(policy.CanaryClusters))
+	for _, name := range policy.CanaryClusters {
+		canary[name] = true
+	}
+
+	sorted := make([]clusterState, len(clusters))
+	copy(sorted, clusters)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	canaries := []clusterState{}
+	groups := make(map[string][]clusterState)
+	unlabeled := []clusterState{}
+	for _, c := range sorted {
+		if canary[c.Name] {
+			canaries = append(canaries, c)
+			continue
+		}
+		if policy.BatchLabel == "" {
+			groups[""] = append(groups[""], c)
+			continue
+		}
+		v, ok := c.Labels[policy.BatchLabel]
+		if !ok {
+			unlabeled = append(unlabeled, c)
+			continue
+		}
+		groups[v] = append(groups[v], c)
+	}
+
+	values := make([]string, 0, len(groups))
+	for v := range groups {
+		values = append(values, v)
+	}
+	sort.Strings(values)
+
+	batches := [][]clusterState{canaries}
+	for _, v := range values {
+		batches = append(batches, groups[v])
+	}
+	if len(unlabeled) > 0 {
+		batches = append(batches, unlabeled)
+	}
+	return batches
+}
+
+// maxUnavailable returns the max number of clusters of batch being updated
+// at the same time, which is at least 1
+func maxUnavailable(v *intstr.IntOrString, total int) int {
+	if v == nil {
+		return 1
+	}
+	n, err := intstr.GetScaledValueFromIntOrPercent(v, total, false)
+	if err != nil || n < 1 {
+		return 1
+	}
+	return n
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hotplug
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	hotplugv1 "github.com/saashqdev/kubeworkz/pkg/apis/hotplug/v1"
+	"github.com/saashqdev/kubeworkz/pkg/clog"
+	"github.com/saashqdev/kubeworkz/pkg/ctrlmgr/options"
+	"github.com/saashqdev/kubeworkz/pkg/multicluster"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+)
+
+const (
+	common = "common"
+
+	// progressInterval is the interval to collect progress of clusters
+	// when rollout not completed
+	progressInterval = 15 * time.Second
+)
+
+// RolloutReconciler releases changes of common hotplug to clusters by
+// rollout policy and collects progress reported by warden of each cluster
+type RolloutReconciler struct {
+	client.Client
+}
+
+func newReconciler(mgr manager.Manager) (reconcile.Reconciler, error) {
+	r := &RolloutReconciler{
+		Client: mgr.GetClient(),
+	}
+	return r, nil
+}
+
+//+kubebuilder:rbac:groups=hotplug.kubeworkz.io,resources=hotplugs,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=hotplug.kubeworkz.io,resources=hotplugs/status,verbs=get;update;patch
+
+// Reconcile collects state of common hotplug in all clusters, releases the
+// revision to next clusters by policy and reports aggregated progress.
+func (r *RolloutReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if req.Name != common {
+		return ctrl.Result{}, nil
+	}
+
+	hotplug := &hotplugv1.Hotplug{}
+	err := r.Get(ctx, req.NamespacedName, hotplug)
+	if err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if hotplug.Spec.Rollout == nil {
+		return ctrl.Result{}, r.clearRollout(ctx, hotplug)
+	}
+
+	revision := hotplug.Spec.Revision()
+	released := make(map[string]bool)
+	if hotplug.Annotations[constants.HotplugRolloutRevisionAnnotation] == revision {
+		for _, name := range strings.Split(hotplug.Annotations[constants.HotplugRolloutClustersAnnotation], ",") {
+			if name != "" {
+				released[name] = true
+			}
+		}
+	}
+
+	status, names := Plan(hotplug.Spec.Rollout, revision, r.clusterStates(ctx), released)
+
+	if err = r.release(ctx, revision, names); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err = r.updateStatus(ctx, &status); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if status.Phase != PhaseCompleted {
+		return ctrl.Result{RequeueAfter: progressInterval}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// clusterStates reads common hotplug of every cluster to know which
+// revision applied and its result
+func (r *RolloutReconciler) clusterStates(ctx context.Context) []clusterState {
+	states := []clusterState{}
+	for name, cluster := range multicluster.Interface().FuzzyCopy() {
+		state := clusterState{Name: name}
+		if cluster.RawCluster != nil {
+			state.Labels = cluster.RawCluster.Labels
+		}
+		hotplug := &hotplugv1.Hotplug{}
+		err := cluster.Client.Direct().Get(ctx, types.NamespacedName{Name: common}, hotplug)
+		if err != nil {
+			clog.Warn("get common hotplug of cluster %v failed: %v", name, err)
+			state.Message = err.Error()
+		} else {
+			state.Revision = hotplug.Status.AppliedRevision
+			state.Phase = hotplug.Status.Phase
+			state.Results = hotplug.Status.Results
+		}
+		states = append(states, state)
+	}
+	return states
+}
+
+// release records clusters the revision released to, wardens of the
+// clusters apply the revision once the annotations synced
+func (r *RolloutReconciler) release(ctx context.Context, revision string, clusters []string) error {
+	return r.updateAnnotations(ctx, func(annotations map[string]string) {
+		annotations[constants.HotplugRolloutRevisionAnnotation] = revision
+		annotations[constants.HotplugRolloutClustersAnnotation] = strings.Join(clusters, ",")
+	})
+}
+
+// clearRollout removes annotations and status of rollout when policy removed
+func (r *RolloutReconciler) clearRollout(ctx context.Context, hotplug *hotplugv1.Hotplug) error {
+	_, ok := hotplug.Annotations[constants.HotplugRolloutRevisionAnnotation]
+	if ok {
+		err := r.updateAnnotations(ctx, func(annotations map[string]string) {
+			delete(annotations, constants.HotplugRolloutRevisionAnnotation)
+			delete(annotations, constants.HotplugRolloutClustersAnnotation)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if hotplug.Status.Rollout == nil {
+		return nil
+	}
+	return r.updateStatus(ctx, nil)
+}
+
+func (r *RolloutReconciler) updateAnnotations(ctx context.Context, mutate func(annotations map[string]string)) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		hotplug := &hotplugv1.Hotplug{}
+		err := r.Get(ctx, types.NamespacedName{Name: common}, hotplug)
+		if err != nil {
+			return err
+		}
+		annotations := make(map[string]string, len(hotplug.Annotations))
+		for k, v := range hotplug.Annotations {
+			annotations[k] = v
+		}
+		mutate(annotations)
+		if equality.Semantic.DeepEqual(annotations, hotplug.Annotations) {
+			return nil
+		}
+		hotplug.Annotations = annotations
+		return r.Update(ctx, hotplug)
+	})
+}
+
+func (r *RolloutReconciler) updateStatus(ctx context.Context, status *hotplugv1.RolloutStatus) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		hotplug := &hotplugv1.Hotplug{}
+		err := r.Get(ctx, types.NamespacedName{Name: common}, hotplug)
+		if err != nil {
+			return err
+		}
+		if equality.Semantic.DeepEqual(status, hotplug.Status.Rollout) {
+			return nil
+		}
+		hotplug.Status.Rollout = status
+		return r.Status().Update(ctx, hotplug)
+	})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func SetupWithManager(mgr ctrl.Manager, _ *options.Options) error {
+	r, err := newReconciler(mgr)
+	if err != nil {
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&hotplugv1.Hotplug{}).
+		Complete(r)
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hotplug
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	hotplugv1 "github.com/saashqdev/kubeworkz/pkg/apis/hotplug/v1"
+)
+
+const rev = "new"
+
+func testClusters() []clusterState {
+	return []clusterState{
+		{Name: "pivot", Revision: "old", Phase: success},
+		{Name: "a1", Labels: map[string]string{"zone": "a"}, Revision: "old", Phase: success},
+		{Name: "a2", Labels: map[string]string{"zone": "a"}, Revision: "old", Phase: success},
+		{Name: "b1", Labels: map[string]string{"zone": "b"}, Revision: "old", Phase: success},
+		{Name: "x", Revision: "old", Phase: success},
+	}
+}
+
+func names(batches [][]clusterState) [][]string {
+	result := [][]string{}
+	for _, b := range batches {
+		n := []string{}
+		for _, c := range b {
+			n = append(n, c.Name)
+		}
+		result = append(result, n)
+	}
+	return result
+}
+
+func TestBatches(t *testing.T) {
+	policy := &hotplugv1.RolloutPolicy{CanaryClusters: []string{"pivot"}, BatchLabel: "zone"}
+	got := names(Batches(policy, testClusters()))
+	want := [][]string{{"pivot"}, {"a1", "a2"}, {"b1"}, {"x"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expect batches %v, got %v", want, got)
+	}
+
+	got = names(Batches(&hotplugv1.RolloutPolicy{}, testClusters()))
+	want = [][]string{{}, {"a1", "a2", "b1", "pivot", "x"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expect batches %v, got %v", want, got)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	policy := &hotplugv1.RolloutPolicy{CanaryClusters: []string{"pivot"}, BatchLabel: "zone"}
+	clusters := testClusters()
+
+	// canary first
+	status, released := Plan(policy, rev, clusters, nil)
+	if !reflect.DeepEqual(released, []string{"pivot"}) || status.Phase != PhaseProgressing {
+		t.Fatalf("expect canary released, got %v %v", released, status.Phase)
+	}
+
+	// canary in progress, nothing more released
+	clusters[0].Revision, clusters[0].Phase = rev, "pending"
+	_, released = Plan(policy, rev, clusters, map[string]bool{"pivot": true})
+	if !reflect.DeepEqual(released, []string{"pivot"}) {
+		t.Fatalf("expect only canary released, got %v", released)
+	}
+
+	// canary succeeded, first batch released one by one
+	clusters[0].Phase = success
+	_, released = Plan(policy, rev, clusters, map[string]bool{"pivot": true})
+	if !reflect.DeepEqual(released, []string{"pivot", "a1"}) {
+		t.Fatalf("expect a1 released, got %v", released)
+	}
+
+	// max unavailable releases the whole batch
+	policy.MaxUnavailable = &intstr.IntOrString{Type: intstr.String, StrVal: "100%"}
+	_, released = Plan(policy, rev, clusters, map[string]bool{"pivot": true})
+	if !reflect.DeepEqual(released, []string{"pivot", "a1", "a2"}) {
+		t.Fatalf("expect a1 and a2 released, got %v", released)
+	}
+
+	// failure pauses rollout
+	policy.PauseOnFailure = true
+	clusters[1].Revision, clusters[1].Phase = rev, fail
+	status, released = Plan(policy, rev, clusters, map[string]bool{"pivot": true, "a1": true})
+	if !reflect.DeepEqual(released, []string{"pivot", "a1"}) || status.Phase != PhasePaused {
+		t.Fatalf("expect rollout paused, got %v %v", released, status.Phase)
+	}
+
+	// failure ignored without pause on failure
+	policy.PauseOnFailure = false
+	status, released = Plan(policy, rev, clusters, map[string]bool{"pivot": true, "a1": true})
+	if !reflect.DeepEqual(released, []string{"pivot", "a1", "a2"}) || status.Phase != PhaseProgressing {
+		t.Fatalf("expect a2 released, got %v %v", released, status.Phase)
+	}
+
+	// paused manually
+	policy.Paused = true
+	status, _ = Plan(policy, rev, clusters, nil)
+	if status.Phase != PhasePaused {
+		t.Fatalf("expect rollout paused, got %v", status.Phase)
+	}
+
+	// completed when all clusters applied
+	policy.Paused = false
+	for i := range clusters {
+		clusters[i].Revision, clusters[i].Phase = rev, success
+	}
+	status, released = Plan(policy, rev, clusters, nil)
+	if status.Phase != PhaseCompleted || len(released) != len(clusters) {
+		t.Fatalf("expect rollout completed, got %v %v", released, status.Phase)
+	}
+}
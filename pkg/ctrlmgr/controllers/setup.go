@@ -22,9 +22,13 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	"github.com/saashqdev/kubeworkz/pkg/clog"
+	"github.com/saashqdev/kubeworkz/pkg/ctrlmgr/controllers/accessreview"
 	"github.com/saashqdev/kubeworkz/pkg/ctrlmgr/controllers/binding"
 	cluster "github.com/saashqdev/kubeworkz/pkg/ctrlmgr/controllers/cluster"
+	"github.com/saashqdev/kubeworkz/pkg/ctrlmgr/controllers/hotplug"
 	"github.com/saashqdev/kubeworkz/pkg/ctrlmgr/controllers/quota"
+	"github.com/saashqdev/kubeworkz/pkg/ctrlmgr/controllers/tenant"
+	"github.com/saashqdev/kubeworkz/pkg/ctrlmgr/controllers/user"
 	"github.com/saashqdev/kubeworkz/pkg/ctrlmgr/options"
 	"github.com/saashqdev/kubeworkz/pkg/utils/ctrlopts"
 )
@@ -37,6 +41,10 @@ func init() {
 	setupFns["kuberesourcequota"] = quota.SetupWithManager
 	setupFns["clusterrolebinding"] = binding.SetupClusterRoleBindingReconcilerWithManager
 	setupFns["rolebinding"] = binding.SetupRoleBindingReconcilerWithManager
+	setupFns["tenant"] = tenant.SetupWithManager
+	setupFns["hotplug"] = hotplug.SetupWithManager
+	setupFns["accessreview"] = accessreview.SetupWithManager
+	setupFns["userexpiration"] = user.SetupWithManager
 }
 
 // SetupWithManager set up controllers into manager
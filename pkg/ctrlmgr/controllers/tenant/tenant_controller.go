@@ -0,0 +1,282 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	tenantv1 "github.com/saashqdev/kubeworkz/pkg/apis/tenant/v1"
+	"github.com/saashqdev/kubeworkz/pkg/clog"
+	"github.com/saashqdev/kubeworkz/pkg/ctrlmgr/options"
+	"github.com/saashqdev/kubeworkz/pkg/multicluster"
+	"github.com/saashqdev/kubeworkz/pkg/utils/archive"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+	"github.com/saashqdev/kubeworkz/pkg/utils/env"
+)
+
+// cleanupInterval is the interval to recheck when children of tenant are being deleted
+const cleanupInterval = 10 * time.Second
+
+// anchorGVK is the kind of hnc anchor which subnamespace is created by
+var anchorGVK = schema.GroupVersionKind{Group: "hnc.x-k8s.io", Version: "v1alpha2", Kind: "SubnamespaceAnchor"}
+
+// TenantReconciler deletes tenant whose deletion was requested after grace period
+type TenantReconciler struct {
+	client.Client
+}
+
+func newReconciler(mgr manager.Manager) (reconcile.Reconciler, error) {
+	r := &TenantReconciler{
+		Client: mgr.GetClient(),
+	}
+	return r, nil
+}
+
+//+kubebuilder:rbac:groups=tenant.kubeworkz.io,resources=tenants,verbs=get;list;watch;update;delete
+//+kubebuilder:rbac:groups=tenant.kubeworkz.io,resources=tenants/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=tenant.kubeworkz.io,resources=projects,verbs=get;list;watch;delete
+//+kubebuilder:rbac:groups=hnc.x-k8s.io,resources=subnamespaceanchors,verbs=delete
+
+// Reconcile waits for grace period of tenant deletion, archives resources of
+// tenant and then cleans up namespaces, projects and tenant itself.
+func (r *TenantReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	tenant := &tenantv1.Tenant{}
+	err := r.Get(ctx, req.NamespacedName, tenant)
+	if err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	_, requested := tenant.Annotations[constants.DeletionRequestedAnnotation]
+	if !requested || tenant.DeletionTimestamp != nil {
+		return ctrl.Result{}, r.clearDeadline(ctx, tenant)
+	}
+
+	if tenant.Status.DeletionDeadline == nil {
+		return ctrl.Result{Requeue: true}, r.setDeadline(ctx, tenant)
+	}
+
+	if remain := time.Until(tenant.Status.DeletionDeadline.Time); remain > 0 {
+		return ctrl.Result{RequeueAfter: remain}, nil
+	}
+
+	clog.Info("grace period of tenant %v expired, begin to delete", tenant.Name)
+
+	if err = r.archive(ctx, tenant.Name); err != nil {
+		clog.Error("archive tenant %v failed: %v", tenant.Name, err)
+		return ctrl.Result{}, err
+	}
+
+	done, err := r.cleanup(ctx, tenant)
+	if err != nil {
+		clog.Warn("clean up tenant %v failed: %v", tenant.Name, err)
+		return ctrl.Result{RequeueAfter: cleanupInterval}, nil
+	}
+	if !done {
+		return ctrl.Result{RequeueAfter: cleanupInterval}, nil
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// setDeadline records the time when tenant will be deleted
+func (r *TenantReconciler) setDeadline(ctx context.Context, tenant *tenantv1.Tenant) error {
+	grace := env.TenantDeletionGracePeriod()
+	if tenant.Spec.DeletionGracePeriodSeconds != nil {
+		grace = time.Duration(*tenant.Spec.DeletionGracePeriodSeconds) * time.Second
+	}
+
+	start := time.Now()
+	if t, err := time.Parse(time.RFC3339, tenant.Annotations[constants.DeletionRequestedAnnotation]); err == nil {
+		start = t
+	}
+	deadline := metav1.NewTime(start.Add(grace))
+
+	return r.updateDeadline(ctx, tenant.Name, &deadline)
+}
+
+// clearDeadline clears deadline when deletion of tenant was canceled
+func (r *TenantReconciler) clearDeadline(ctx context.Context, tenant *tenantv1.Tenant) error {
+	if tenant.Status.DeletionDeadline == nil {
+		return nil
+	}
+	return r.updateDeadline(ctx, tenant.Name, nil)
+}
+
+func (r *TenantReconciler) updateDeadline(ctx context.Context, name string, deadline *metav1.Time) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		tenant := &tenantv1.Tenant{}
+		err := r.Get(ctx, types.NamespacedName{Name: name}, tenant)
+		if err != nil {
+			return err
+		}
+		tenant.Status.DeletionDeadline = deadline
+		return r.Status().Update(ctx, tenant)
+	})
+}
+
+// archive exports resources of tenant into archive dir if configured,
+// the bundle will not be rewritten when retry
+func (r *TenantReconciler) archive(ctx context.Context, tenant string) error {
+	dir := env.TenantArchiveDir()
+	if len(dir) == 0 {
+		return nil
+	}
+
+	file := filepath.Join(dir, fmt.Sprintf("%v.tar.gz", tenant))
+	if _, err := os.Stat(file); err == nil {
+		return nil
+	}
+
+	tmp := file + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+
+	w := archive.NewWriter(f)
+	// archive kept by platform before deletion holds data of secrets as well
+	err = archive.ExportClusters(ctx, client.MatchingLabels{constants.HncTenantLabel: tenant}, w, nil)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if err = w.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	clog.Info("resources of tenant %v archived into %v", tenant, file)
+
+	return os.Rename(tmp, file)
+}
+
+// cleanup deletes namespaces in all clusters, projects and tenant in order,
+// returns true when tenant was deleted
+func (r *TenantReconciler) cleanup(ctx context.Context, tenant *tenantv1.Tenant) (bool, error) {
+	remain := false
+	for name, cluster := range multicluster.Interface().FuzzyCopy() {
+		nsList := corev1.NamespaceList{}
+		err := cluster.Client.Direct().List(ctx, &nsList, client.MatchingLabels{constants.HncTenantLabel: tenant.Name})
+		if err != nil {
+			return false, fmt.Errorf("list namespaces of cluster %v failed: %v", name, err)
+		}
+		for i := range nsList.Items {
+			remain = true
+			ns := &nsList.Items[i]
+			// hnc refuses to delete namespace which still has subnamespaces,
+			// so namespaces are deleted from leaves up
+			if ns.DeletionTimestamp != nil || hasSubnamespaces(nsList.Items, ns.Name) {
+				continue
+			}
+			err = deleteNamespace(ctx, cluster.Client.Direct(), ns)
+			if err != nil && !errors.IsNotFound(err) {
+				return false, err
+			}
+			clog.Info("namespace %v of tenant %v in cluster %v deleted", ns.Name, tenant.Name, name)
+		}
+	}
+	if remain {
+		return false, nil
+	}
+
+	projectList := tenantv1.ProjectList{}
+	err := r.List(ctx, &projectList, client.MatchingLabels{constants.TenantLabel: tenant.Name})
+	if err != nil {
+		return false, err
+	}
+	for i := range projectList.Items {
+		p := &projectList.Items[i]
+		if p.DeletionTimestamp != nil {
+			continue
+		}
+		err = r.Delete(ctx, p)
+		if err != nil && !errors.IsNotFound(err) {
+			return false, err
+		}
+		clog.Info("project %v of tenant %v deleted", p.Name, tenant.Name)
+	}
+	if len(projectList.Items) > 0 {
+		return false, nil
+	}
+
+	err = r.Delete(ctx, tenant)
+	if err != nil && !errors.IsNotFound(err) {
+		return false, err
+	}
+	clog.Info("tenant %v deleted", tenant.Name)
+
+	return true, nil
+}
+
+// hasSubnamespaces tells whether any of namespaces is subnamespace of parent
+func hasSubnamespaces(namespaces []corev1.Namespace, parent string) bool {
+	for _, ns := range namespaces {
+		if ns.Annotations[constants.HncAnnotation] == parent {
+			return true
+		}
+	}
+	return false
+}
+
+// deleteNamespace deletes subnamespace through its anchor in parent namespace,
+// otherwise hnc would recreate it. Namespace not created by anchor or whose
+// anchor is gone is deleted directly.
+func deleteNamespace(ctx context.Context, cli client.Client, ns *corev1.Namespace) error {
+	if parent, ok := ns.Annotations[constants.HncAnnotation]; ok {
+		anchor := &unstructured.Unstructured{}
+		anchor.SetGroupVersionKind(anchorGVK)
+		anchor.SetNamespace(parent)
+		anchor.SetName(ns.Name)
+		err := cli.Delete(ctx, anchor)
+		if err == nil || (!errors.IsNotFound(err) && !meta.IsNoMatchError(err)) {
+			return err
+		}
+	}
+	return cli.Delete(ctx, ns)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func SetupWithManager(mgr ctrl.Manager, _ *options.Options) error {
+	r, err := newReconciler(mgr)
+	if err != nil {
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&tenantv1.Tenant{}).
+		Complete(r)
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package user
+
+import (
+	"context"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	userv1 "github.com/saashqdev/kubeworkz/pkg/apis/user/v1"
+	"github.com/saashqdev/kubeworkz/pkg/clog"
+	"github.com/saashqdev/kubeworkz/pkg/ctrlmgr/options"
+	"github.com/saashqdev/kubeworkz/pkg/utils/transition"
+)
+
+// ExpirationReconciler removes expired scope bindings from user spec
+// and requeues user when its next binding expires
+type ExpirationReconciler struct {
+	client.Client
+}
+
+//+kubebuilder:rbac:groups=user.kubeworkz.io,resources=users,verbs=get;list;watch;update
+
+func (r *ExpirationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	user := &userv1.User{}
+	err := r.Get(ctx, req.NamespacedName, user)
+	if err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	now := time.Now()
+	active := userv1.ActiveScopeBindings(user.Spec.ScopeBindings, now)
+	if len(active) < len(user.Spec.ScopeBindings) {
+		clog.Info("remove %v expired scope bindings of user %v", len(user.Spec.ScopeBindings)-len(active), user.Name)
+		user.Spec.ScopeBindings = active
+		if err = transition.UpdateUserSpec(ctx, r.Client, user); err != nil {
+			clog.Error("remove expired scope bindings of user %v failed: %v", user.Name, err)
+			return ctrl.Result{}, err
+		}
+	}
+
+	if next, ok := userv1.NextExpiration(active, now); ok {
+		return ctrl.Result{RequeueAfter: next}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+func SetupWithManager(mgr ctrl.Manager, _ *options.Options) error {
+	r := &ExpirationReconciler{Client: mgr.GetClient()}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("userexpiration").
+		For(&userv1.User{}).
+		Complete(r)
+}
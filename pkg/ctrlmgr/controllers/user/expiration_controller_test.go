@@ -0,0 +1,90 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package user
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	userv1 "github.com/saashqdev/kubeworkz/pkg/apis/user/v1"
+)
+
+func TestRemoveExpiredBindings(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = userv1.AddToScheme(scheme)
+
+	expired := metav1.NewTime(time.Now().Add(-time.Minute))
+	later := metav1.NewTime(time.Now().Add(time.Hour))
+	u := &userv1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "alice"},
+		Spec: userv1.UserSpec{ScopeBindings: []userv1.ScopeBinding{
+			{ScopeType: userv1.ProjectScope, ScopeName: "p1", Role: "reviewer", ExpiresAt: &expired},
+			{ScopeType: userv1.ProjectScope, ScopeName: "p2", Role: "reviewer", ExpiresAt: &later},
+			{ScopeType: userv1.TenantScope, ScopeName: "t1", Role: "tenant-admin"},
+		}},
+	}
+	r := &ExpirationReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(u).Build()}
+
+	result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "alice"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.RequeueAfter <= 0 || result.RequeueAfter > time.Hour {
+		t.Errorf("expected requeue at next expiration, got %v", result.RequeueAfter)
+	}
+
+	got := &userv1.User{}
+	if err = r.Get(context.Background(), types.NamespacedName{Name: "alice"}, got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Spec.ScopeBindings) != 2 {
+		t.Fatalf("expected 2 bindings left, got %v", got.Spec.ScopeBindings)
+	}
+	for _, b := range got.Spec.ScopeBindings {
+		if b.ScopeName == "p1" {
+			t.Errorf("expired binding %v not removed", b)
+		}
+	}
+}
+
+func TestNoExpiringBindings(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = userv1.AddToScheme(scheme)
+
+	u := &userv1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "bob"},
+		Spec: userv1.UserSpec{ScopeBindings: []userv1.ScopeBinding{
+			{ScopeType: userv1.TenantScope, ScopeName: "t1", Role: "tenant-admin"},
+		}},
+	}
+	r := &ExpirationReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(u).Build()}
+
+	result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "bob"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.RequeueAfter != 0 {
+		t.Errorf("expected no requeue, got %v", result.RequeueAfter)
+	}
+}
@@ -53,10 +53,27 @@ func (r *Validator) ValidateCreate(project *tenantv1.Project) error {
 		return err
 	}
 
+	if err := r.validateTemplate(ctx, project); err != nil {
+		return err
+	}
+
 	clog.Debug("Create validate success, project info: %v", project)
 	return nil
 }
 
+// validateTemplate ensures the project template referred by project exists
+func (r *Validator) validateTemplate(ctx context.Context, project *tenantv1.Project) error {
+	if len(project.Spec.Template) == 0 {
+		return nil
+	}
+	template := tenantv1.ProjectTemplate{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: project.Spec.Template}, &template); err != nil {
+		clog.Info("The project template %s is not exist", project.Spec.Template)
+		return fmt.Errorf("the project template %s is not exist", project.Spec.Template)
+	}
+	return nil
+}
+
 func (r *Validator) ValidateUpdate(_ *tenantv1.Project, currentProject *tenantv1.Project) error {
 
 	tenantName := currentProject.Labels[constants.TenantLabel]
@@ -76,6 +93,10 @@ func (r *Validator) ValidateUpdate(_ *tenantv1.Project, currentProject *tenantv1
 		return err
 	}
 
+	if err := r.validateTemplate(ctx, currentProject); err != nil {
+		return err
+	}
+
 	clog.Debug("Update validate success, project info: %v", currentProject)
 
 	return nil
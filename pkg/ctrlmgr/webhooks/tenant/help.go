@@ -19,6 +19,7 @@ package tenant
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -31,6 +32,15 @@ import (
 func ValidateDelete(tenant *tenantv1.Tenant) error {
 	ctx := context.Background()
 
+	// tenant must be deleted by request with grace period so that it can be
+	// archived and restored before that, unless forced
+	if tenant.Annotations[constants.ForceDeleteAnnotation] != "true" {
+		if err := validateGracePeriod(tenant, time.Now()); err != nil {
+			clog.Info("delete fail: %s", err.Error())
+			return err
+		}
+	}
+
 	clusters := multicluster.Interface().FuzzyCopy()
 	// check if exist related project
 	projectList := tenantv1.ProjectList{}
@@ -47,3 +57,16 @@ func ValidateDelete(tenant *tenantv1.Tenant) error {
 	}
 	return nil
 }
+
+// validateGracePeriod checks deletion of tenant was requested and its grace
+// period passed
+func validateGracePeriod(tenant *tenantv1.Tenant, now time.Time) error {
+	deadline := tenant.Status.DeletionDeadline
+	if _, requested := tenant.Annotations[constants.DeletionRequestedAnnotation]; !requested || deadline == nil {
+		return fmt.Errorf("deletion of tenant %v must be requested with grace period first", tenant.Name)
+	}
+	if now.Before(deadline.Time) {
+		return fmt.Errorf("tenant %v can not be deleted before %v", tenant.Name, deadline.Format(time.RFC3339))
+	}
+	return nil
+}
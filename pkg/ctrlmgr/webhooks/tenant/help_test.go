@@ -0,0 +1,44 @@
+/*
+Copyright 2022 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tenant
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tenantv1 "github.com/saashqdev/kubeworkz/pkg/apis/tenant/v1"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+)
+
+func TestValidateGracePeriod(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Now()
+
+	tenant := &tenantv1.Tenant{ObjectMeta: metav1.ObjectMeta{Name: "t1"}}
+	assert.Error(validateGracePeriod(tenant, now), "deletion not requested")
+
+	tenant.Annotations = map[string]string{constants.DeletionRequestedAnnotation: now.Format(time.RFC3339)}
+	assert.Error(validateGracePeriod(tenant, now), "deadline not set yet")
+
+	deadline := metav1.NewTime(now.Add(time.Hour))
+	tenant.Status.DeletionDeadline = &deadline
+	assert.Error(validateGracePeriod(tenant, now), "grace period not passed")
+	assert.NoError(validateGracePeriod(tenant, now.Add(2*time.Hour)))
+}
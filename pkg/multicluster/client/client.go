@@ -37,6 +37,7 @@ import (
 
 	"github.com/saashqdev/kubeworkz/pkg/apis"
 	"github.com/saashqdev/kubeworkz/pkg/clog"
+	"github.com/saashqdev/kubeworkz/pkg/multicluster/listcache"
 	"github.com/saashqdev/kubeworkz/pkg/utils/env"
 )
 
@@ -67,6 +68,9 @@ type Client interface {
 	CacheDiscovery() discovery.CachedDiscoveryInterface
 
 	RESTMapper() meta.RESTMapper
+
+	// ListCache the indexed list cache, nil if not enabled
+	ListCache() *listcache.Cache
 }
 
 type InternalClient struct {
@@ -79,6 +83,8 @@ type InternalClient struct {
 
 	// restMapper map GroupVersionKinds to Resources
 	restMapper meta.RESTMapper
+
+	listCache *listcache.Cache
 }
 
 // NewClientFor generate client by config
@@ -130,6 +136,14 @@ func NewClientFor(ctx context.Context, cfg *rest.Config) (Client, error) {
 		return nil, fmt.Errorf("new rest mapper failed: %v", err)
 	}
 
+	if config.ListCacheEnable {
+		// indexes must be registered before informers started
+		c.listCache, err = listcache.New(ctx, c.cache, c.discovery)
+		if err != nil {
+			return nil, fmt.Errorf("new list cache failed: %v", err)
+		}
+	}
+
 	go func() {
 		err = c.cache.Start(ctx)
 		if err != nil {
@@ -185,6 +199,10 @@ func (c *InternalClient) CacheDiscovery() discovery.CachedDiscoveryInterface {
 	return c.cacheDiscovery
 }
 
+func (c *InternalClient) ListCache() *listcache.Cache {
+	return c.listCache
+}
+
 // WithSchemes allow add extensions scheme to client
 func WithSchemes(fns ...func(s *runtime.Scheme) error) {
 	for _, fn := range fns {
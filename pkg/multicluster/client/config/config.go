@@ -33,4 +33,8 @@ type Config struct {
 
 	// the cluster discovery cache sync interval，unit is second, default is 60
 	ClusterCacheSyncInterval int `json:"clusterCacheSyncInterval,omitempty"`
+
+	// the indexed list cache enable, lists of resources served by extend handlers
+	// are answered from informers of cluster if enabled, default is false
+	ListCacheEnable bool `json:"listCacheEnable,omitempty"`
 }
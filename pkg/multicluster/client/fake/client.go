@@ -18,8 +18,11 @@ package fake
 
 import (
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/metrics/pkg/client/clientset/versioned"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
@@ -28,6 +31,8 @@ import (
 
 	mgrclient "github.com/saashqdev/kubeworkz/pkg/multicluster/client"
 	cacheFake "github.com/saashqdev/kubeworkz/pkg/multicluster/client/fake/cache"
+	"github.com/saashqdev/kubeworkz/pkg/multicluster/listcache"
+	discoveryFake "k8s.io/client-go/discovery/fake"
 	clientSetFake "k8s.io/client-go/kubernetes/fake"
 	metricsFake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
 	clientFake "sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -57,6 +62,8 @@ type Options struct {
 	ClientRuntimeObjs    []runtime.Object
 	ClientSetRuntimeObjs []runtime.Object
 	MetricsRuntimeObjs   []runtime.Object
+	// Resources are served by discovery, required by version conversion
+	Resources []*metav1.APIResourceList
 }
 
 // NewFakeClientsFor new fake client by customize
@@ -74,6 +81,11 @@ func NewFakeClients(opts *Options) mgrclient.Client {
 	c.client = cli
 	c.rawClientSet = clientSetFake.NewSimpleClientset(opts.ClientSetRuntimeObjs...)
 	c.metrics = metricsFake.NewSimpleClientset(opts.MetricsRuntimeObjs...)
+	fakeDiscovery := c.rawClientSet.Discovery().(*discoveryFake.FakeDiscovery)
+	fakeDiscovery.Resources = opts.Resources
+	fakeDiscovery.FakedServerVersion = &version.Info{Major: "1", Minor: "27", GitVersion: "v1.27.4"}
+	c.discovery = fakeDiscovery
+	c.cacheDiscovery = memory.NewMemCacheClient(fakeDiscovery)
 	c.cache = &cacheFake.FakeClient{
 		Client: cli,
 		Cache:  &informertest.FakeInformers{Scheme: opts.Scheme},
@@ -110,3 +122,7 @@ func (c *FakerClient) Discovery() discovery.DiscoveryInterface {
 func (c *FakerClient) CacheDiscovery() discovery.CachedDiscoveryInterface {
 	return c.cacheDiscovery
 }
+
+func (c *FakerClient) ListCache() *listcache.Cache {
+	return nil
+}
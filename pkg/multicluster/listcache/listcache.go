@@ -0,0 +1,303 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package listcache
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/saashqdev/kubeworkz/pkg/clog"
+)
+
+// Names of field indexes are json paths of fields, so that exact conditions
+// of selector are answered by index directly
+const (
+	IndexName   = "metadata.name"
+	IndexOwner  = "metadata.ownerReferences.uid"
+	IndexLabels = "metadata.labels"
+	IndexNode   = "spec.nodeName"
+	IndexPhase  = "status.phase"
+	IndexType   = "spec.type"
+)
+
+// indexOrder the preference of indexes to narrow list, the more selective first
+var indexOrder = []string{IndexOwner, IndexName, IndexNode, IndexLabels, IndexPhase, IndexType}
+
+type resource struct {
+	kind    string
+	obj     client.Object
+	newList func() client.ObjectList
+	indexes map[string]client.IndexerFunc
+}
+
+// resources the resources served by extend handlers which are cached
+var resources = map[schema.GroupVersionResource]resource{
+	corev1.SchemeGroupVersion.WithResource("pods"): {
+		kind: "Pod", obj: &corev1.Pod{}, newList: func() client.ObjectList { return &corev1.PodList{} },
+		indexes: map[string]client.IndexerFunc{
+			IndexNode: func(o client.Object) []string {
+				return nonEmpty(o.(*corev1.Pod).Spec.NodeName)
+			},
+			IndexPhase: func(o client.Object) []string {
+				return nonEmpty(string(o.(*corev1.Pod).Status.Phase))
+			},
+		},
+	},
+	corev1.SchemeGroupVersion.WithResource("services"): {
+		kind: "Service", obj: &corev1.Service{}, newList: func() client.ObjectList { return &corev1.ServiceList{} },
+		indexes: map[string]client.IndexerFunc{
+			IndexType: func(o client.Object) []string {
+				return nonEmpty(string(o.(*corev1.Service).Spec.Type))
+			},
+		},
+	},
+	corev1.SchemeGroupVersion.WithResource("persistentvolumeclaims"): {
+		kind: "PersistentVolumeClaim", obj: &corev1.PersistentVolumeClaim{}, newList: func() client.ObjectList { return &corev1.PersistentVolumeClaimList{} },
+		indexes: map[string]client.IndexerFunc{
+			IndexPhase: func(o client.Object) []string {
+				return nonEmpty(string(o.(*corev1.PersistentVolumeClaim).Status.Phase))
+			},
+		},
+	},
+	appsv1.SchemeGroupVersion.WithResource("deployments"): {
+		kind: "Deployment", obj: &appsv1.Deployment{}, newList: func() client.ObjectList { return &appsv1.DeploymentList{} },
+	},
+	appsv1.SchemeGroupVersion.WithResource("replicasets"): {
+		kind: "ReplicaSet", obj: &appsv1.ReplicaSet{}, newList: func() client.ObjectList { return &appsv1.ReplicaSetList{} },
+	},
+	batchv1.SchemeGroupVersion.WithResource("jobs"): {
+		kind: "Job", obj: &batchv1.Job{}, newList: func() client.ObjectList { return &batchv1.JobList{} },
+	},
+	batchv1.SchemeGroupVersion.WithResource("cronjobs"): {
+		kind: "CronJob", obj: &batchv1.CronJob{}, newList: func() client.ObjectList { return &batchv1.CronJobList{} },
+	},
+}
+
+// commonIndexes the indexes of all cached resources
+var commonIndexes = map[string]client.IndexerFunc{
+	IndexName: func(o client.Object) []string {
+		return []string{o.GetName()}
+	},
+	IndexOwner: func(o client.Object) []string {
+		var uids []string
+		for _, ref := range o.GetOwnerReferences() {
+			uids = append(uids, string(ref.UID))
+		}
+		return uids
+	},
+	IndexLabels: func(o client.Object) []string {
+		var pairs []string
+		for k, v := range o.GetLabels() {
+			pairs = append(pairs, k+"="+v)
+		}
+		return pairs
+	},
+}
+
+func nonEmpty(v string) []string {
+	if len(v) == 0 {
+		return nil
+	}
+	return []string{v}
+}
+
+// Cache answers lists of resources served by extend handlers from informers
+// of cluster with field indexes
+type Cache struct {
+	cache cache.Cache
+	// informers the informers of resources cached, which are served by
+	// cluster, they are registered ahead and started along with cache
+	informers map[schema.GroupVersionResource]cache.Informer
+}
+
+// New registers field indexes and informers of cached resources served by
+// cluster into cache, it must be called before cache started so that lists
+// never wait for informers created lazily
+func New(ctx context.Context, c cache.Cache, d discovery.ServerResourcesInterface) (*Cache, error) {
+	lc := &Cache{cache: c, informers: make(map[schema.GroupVersionResource]cache.Informer)}
+	for gvr, r := range resources {
+		if !served(d, gvr) {
+			clog.Info("%v not served by cluster, skip caching", gvr.String())
+			continue
+		}
+		for name, fn := range commonIndexes {
+			if err := c.IndexField(ctx, r.obj, name, fn); err != nil {
+				return nil, fmt.Errorf("index %v of %v failed: %v", name, gvr.Resource, err)
+			}
+		}
+		for name, fn := range r.indexes {
+			if err := c.IndexField(ctx, r.obj, name, fn); err != nil {
+				return nil, fmt.Errorf("index %v of %v failed: %v", name, gvr.Resource, err)
+			}
+		}
+		// informer is not waited for until cache started
+		informer, err := c.GetInformer(ctx, r.obj)
+		if err != nil {
+			return nil, fmt.Errorf("get informer of %v failed: %v", gvr.Resource, err)
+		}
+		lc.informers[gvr] = informer
+	}
+	return lc, nil
+}
+
+func served(d discovery.ServerResourcesInterface, gvr schema.GroupVersionResource) bool {
+	list, err := d.ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+	if err != nil {
+		return false
+	}
+	for _, r := range list.APIResources {
+		if r.Name == gvr.Resource {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseListURL returns resource and namespace of list url of cached resource
+// such as /api/v1/namespaces/{namespace}/pods or /apis/apps/v1/deployments
+func ParseListURL(url string) (gvr schema.GroupVersionResource, namespace string, ok bool) {
+	ss := strings.Split(strings.Trim(url, "/"), "/")
+	switch {
+	case len(ss) > 0 && ss[0] == "api":
+		ss = append([]string{"api", ""}, ss[1:]...)
+	case len(ss) > 0 && ss[0] == "apis":
+	default:
+		return gvr, "", false
+	}
+
+	// apis/{group}/{version}/[namespaces/{namespace}/]{resource}
+	switch len(ss) {
+	case 4:
+		gvr = schema.GroupVersionResource{Group: ss[1], Version: ss[2], Resource: ss[3]}
+	case 6:
+		if ss[3] != "namespaces" {
+			return gvr, "", false
+		}
+		gvr = schema.GroupVersionResource{Group: ss[1], Version: ss[2], Resource: ss[5]}
+		namespace = ss[4]
+	default:
+		return gvr, "", false
+	}
+	_, ok = resources[gvr]
+	return gvr, namespace, ok
+}
+
+// List lists cached resource narrowed by the most selective field index
+// matched by exact conditions, conditions are not fully applied and should
+// be filtered afterwards. The resourceVersion of cache is returned, and ok
+// is false if cache can not answer the request, which happens when cache is
+// not synced or older than minResourceVersion, or a consistent read is
+// requested by empty minResourceVersion.
+func (c *Cache) List(ctx context.Context, gvr schema.GroupVersionResource, namespace string, exact map[string]sets.Set[string], labelSelector labels.Selector, minResourceVersion string) (list client.ObjectList, resourceVersion string, ok bool, err error) {
+	r, found := resources[gvr]
+	informer, cached := c.informers[gvr]
+	if !found || !cached {
+		return nil, "", false, nil
+	}
+	if !informer.HasSynced() {
+		return nil, "", false, nil
+	}
+	if v, ok := informer.(interface{ LastSyncResourceVersion() string }); ok {
+		resourceVersion = v.LastSyncResourceVersion()
+	}
+	if !servable(resourceVersion, minResourceVersion) {
+		return nil, "", false, nil
+	}
+
+	opts := []client.ListOption{}
+	if len(namespace) > 0 {
+		opts = append(opts, client.InNamespace(namespace))
+	}
+	if labelSelector != nil && !labelSelector.Empty() {
+		opts = append(opts, client.MatchingLabelsSelector{Selector: labelSelector})
+	}
+	if field, value, found := pickIndex(r, exact); found {
+		opts = append(opts, client.MatchingFields{field: value})
+	}
+
+	list = r.newList()
+	if err = c.cache.List(ctx, list, opts...); err != nil {
+		return nil, "", false, err
+	}
+	list.GetObjectKind().SetGroupVersionKind(gvr.GroupVersion().WithKind(r.kind + "List"))
+	list.SetResourceVersion(resourceVersion)
+	return list, resourceVersion, true, nil
+}
+
+// pickIndex returns index and value for the most selective exact condition
+// with single value
+func pickIndex(r resource, exact map[string]sets.Set[string]) (string, string, bool) {
+	for _, name := range indexOrder {
+		if _, ok := commonIndexes[name]; !ok {
+			if _, ok = r.indexes[name]; !ok {
+				continue
+			}
+		}
+		if name == IndexLabels {
+			// labels are picked in order of keys to make it stable
+			keys := make([]string, 0, len(exact))
+			for k := range exact {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				if key, found := strings.CutPrefix(k, IndexLabels+"."); found && exact[k].Len() == 1 {
+					return IndexLabels, key + "=" + exact[k].UnsortedList()[0], true
+				}
+			}
+			continue
+		}
+		if values, ok := exact[name]; ok && values.Len() == 1 {
+			return name, values.UnsortedList()[0], true
+		}
+	}
+	return "", "", false
+}
+
+// servable returns true if cache at resourceVersion can answer list of min
+// resource version as apiserver watch cache does, zero min means any version
+// while empty min asks for a consistent read which only apiserver answers
+func servable(resourceVersion, min string) bool {
+	if len(min) == 0 {
+		return false
+	}
+	if min == "0" {
+		return true
+	}
+	rv, err := strconv.ParseUint(resourceVersion, 10, 64)
+	if err != nil {
+		return false
+	}
+	m, err := strconv.ParseUint(min, 10, 64)
+	if err != nil {
+		return false
+	}
+	return rv >= m
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package listcache
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestParseListURL(t *testing.T) {
+	cases := []struct {
+		url       string
+		resource  string
+		namespace string
+		ok        bool
+	}{
+		{"/api/v1/namespaces/ns1/pods", "pods", "ns1", true},
+		{"/api/v1/pods", "pods", "", true},
+		{"/apis/apps/v1/namespaces/ns1/deployments", "deployments", "ns1", true},
+		{"/apis/batch/v1/cronjobs", "cronjobs", "", true},
+		{"/api/v1/namespaces/ns1/pods/p1", "", "", false},
+		{"/api/v1/namespaces/ns1/configmaps", "", "", false},
+		{"/apis/apps/v1/namespaces/ns1/deployments/d1/scale", "", "", false},
+	}
+	for _, c := range cases {
+		gvr, ns, ok := ParseListURL(c.url)
+		if ok != c.ok || (ok && (gvr.Resource != c.resource || ns != c.namespace)) {
+			t.Errorf("url %v: unexpected %v %v %v", c.url, gvr, ns, ok)
+		}
+	}
+}
+
+func TestPickIndex(t *testing.T) {
+	pods := resources[corev1.SchemeGroupVersion.WithResource("pods")]
+	deployments := resources[appsv1.SchemeGroupVersion.WithResource("deployments")]
+
+	exact := map[string]sets.Set[string]{
+		"metadata.labels.app":          sets.New[string]("web"),
+		"spec.nodeName":                sets.New[string]("node1"),
+		"metadata.ownerReferences.uid": sets.New[string]("a", "b"),
+	}
+	if field, value, ok := pickIndex(pods, exact); !ok || field != IndexNode || value != "node1" {
+		t.Errorf("expect node index picked for pods, got %v %v", field, value)
+	}
+	if field, value, ok := pickIndex(deployments, exact); !ok || field != IndexLabels || value != "app=web" {
+		t.Errorf("expect labels index picked for deployments, got %v %v", field, value)
+	}
+	if _, _, ok := pickIndex(deployments, map[string]sets.Set[string]{"spec.replicas": sets.New[string]("1")}); ok {
+		t.Errorf("expect no index for unindexed field")
+	}
+
+	if servable("100", "") || !servable("100", "0") || !servable("100", "99") || servable("100", "101") {
+		t.Errorf("unexpected resource version comparison")
+	}
+}
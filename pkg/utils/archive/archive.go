@@ -0,0 +1,211 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/saashqdev/kubeworkz/pkg/clog"
+	"github.com/saashqdev/kubeworkz/pkg/multicluster"
+	"github.com/saashqdev/kubeworkz/pkg/utils/strslice"
+)
+
+// skippedResources are resources no need to archive because they are
+// generated by cluster and can not be restored
+var skippedResources = []string{"events", "pods", "endpoints", "endpointslices", "replicasets", "controllerrevisions", "leases"}
+
+// RedactedAnnotation marks secrets archived without data
+const RedactedAnnotation = "archive.kubeworkz.io/redacted"
+
+// Writer writes resources as yaml files into a tar.gz bundle
+type Writer struct {
+	gw *gzip.Writer
+	tw *tar.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	gw := gzip.NewWriter(w)
+	return &Writer{gw: gw, tw: tar.NewWriter(gw)}
+}
+
+// Add writes object into bundle with path <cluster>/<namespace>/<resource>/<name>.yaml
+func (w *Writer) Add(cluster, resource string, obj *unstructured.Unstructured) error {
+	sanitize(obj)
+	b, err := yaml.Marshal(obj.Object)
+	if err != nil {
+		return err
+	}
+	return w.AddFile(path.Join(cluster, obj.GetNamespace(), resource, obj.GetName()+".yaml"), b)
+}
+
+// AddFile writes content into bundle with given path
+func (w *Writer) AddFile(name string, content []byte) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(content)),
+		ModTime: time.Now(),
+	}
+	if err := w.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := w.tw.Write(content)
+	return err
+}
+
+// AddReader writes size bytes read from r into bundle with given path, so
+// that large content is streamed without being loaded at once
+func (w *Writer) AddReader(name string, r io.Reader, size int64) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    size,
+		ModTime: time.Now(),
+	}
+	if err := w.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := io.CopyN(w.tw, r, size)
+	return err
+}
+
+// Close flushes bundle, must be called when all resources added
+func (w *Writer) Close() error {
+	if err := w.tw.Close(); err != nil {
+		return err
+	}
+	return w.gw.Close()
+}
+
+// SecretAccess tells whether data of secrets in namespace of cluster can be
+// archived, secrets are archived without data if not
+type SecretAccess func(cluster, namespace string) bool
+
+// Export writes all namespaced resources in given namespaces of cluster into
+// bundle, data of secrets is kept only if allowed by secretAccess, nil
+// secretAccess allows all
+func Export(ctx context.Context, cli client.Client, disco discovery.DiscoveryInterface, cluster string, namespaces []string, w *Writer, secretAccess SecretAccess) error {
+	// partial result is acceptable when some group versions are unavailable
+	lists, err := discovery.ServerPreferredNamespacedResources(disco)
+	if err != nil && len(lists) == 0 {
+		return err
+	}
+
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, r := range list.APIResources {
+			if strslice.ContainsString(skippedResources, r.Name) || !strslice.ContainsString(r.Verbs, "list") {
+				continue
+			}
+			isSecret := gv.Group == corev1.GroupName && r.Name == "secrets"
+			for _, ns := range namespaces {
+				redact := isSecret && secretAccess != nil && !secretAccess(cluster, ns)
+				objs := unstructured.UnstructuredList{}
+				objs.SetGroupVersionKind(gv.WithKind(r.Kind + "List"))
+				err = cli.List(ctx, &objs, client.InNamespace(ns))
+				if err != nil {
+					clog.Warn("list %v in namespace %v of cluster %v failed: %v", r.Name, ns, cluster, err)
+					continue
+				}
+				for i := range objs.Items {
+					if redact {
+						redactSecret(&objs.Items[i])
+					}
+					if err = w.Add(cluster, r.Name, &objs.Items[i]); err != nil {
+						return fmt.Errorf("archive %v/%v failed: %v", r.Name, objs.Items[i].GetName(), err)
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// ExportClusters writes resources in namespaces matched given labels of all
+// clusters into bundle, see Export for secretAccess
+func ExportClusters(ctx context.Context, nsLabels client.MatchingLabels, w *Writer, secretAccess SecretAccess) error {
+	clusters := multicluster.Interface().FuzzyCopy()
+	names := make([]string, 0, len(clusters))
+	for name := range clusters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		cli := clusters[name].Client
+		nsList := corev1.NamespaceList{}
+		err := cli.Direct().List(ctx, &nsList, nsLabels)
+		if err != nil {
+			return fmt.Errorf("list namespaces of cluster %v failed: %v", name, err)
+		}
+		if len(nsList.Items) == 0 {
+			continue
+		}
+		namespaces := make([]string, 0, len(nsList.Items))
+		for _, ns := range nsList.Items {
+			namespaces = append(namespaces, ns.Name)
+		}
+		err = Export(ctx, cli.Direct(), cli.Discovery(), name, namespaces, w, secretAccess)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// redactSecret removes data of secret and marks it redacted, the secret must
+// be filled again when restored
+func redactSecret(obj *unstructured.Unstructured) {
+	unstructured.RemoveNestedField(obj.Object, "data")
+	unstructured.RemoveNestedField(obj.Object, "stringData")
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[RedactedAnnotation] = "true"
+	obj.SetAnnotations(annotations)
+}
+
+// sanitize removes fields generated by server which are meaningless after restored
+func sanitize(obj *unstructured.Unstructured) {
+	obj.SetManagedFields(nil)
+	obj.SetResourceVersion("")
+	obj.SetUID("")
+	obj.SetGeneration(0)
+	obj.SetSelfLink("")
+	unstructured.RemoveNestedField(obj.Object, "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(obj.Object, "status")
+}
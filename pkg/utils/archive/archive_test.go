@@ -0,0 +1,162 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	clienttesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestWriter(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":            "cm",
+			"namespace":       "ns",
+			"resourceVersion": "100",
+			"uid":             "abc",
+		},
+		"data": map[string]interface{}{"k": "v"},
+	}}
+
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf)
+	if err := w.Add("pivot", "configmaps", obj); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	gr, err := gzip.NewReader(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gr)
+	hdr, err := tr.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hdr.Name != "pivot/ns/configmaps/cm.yaml" {
+		t.Errorf("unexpected file name %v", hdr.Name)
+	}
+	b, err := io.ReadAll(tr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(b)
+	if strings.Contains(content, "resourceVersion") || strings.Contains(content, "uid") {
+		t.Errorf("server generated fields should be removed: %v", content)
+	}
+	if !strings.Contains(content, "k: v") {
+		t.Errorf("data should be kept: %v", content)
+	}
+}
+
+func TestWriterAddReader(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf)
+	if err := w.AddReader("pod/c.log", strings.NewReader("line1\nline2\n"), 12); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	gr, err := gzip.NewReader(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gr)
+	hdr, err := tr.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(tr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hdr.Name != "pod/c.log" || string(b) != "line1\nline2\n" {
+		t.Errorf("unexpected file %v: %q", hdr.Name, b)
+	}
+}
+
+func TestExportRedactsSecrets(t *testing.T) {
+	objs := []client.Object{
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "token", Namespace: "open"}, Data: map[string][]byte{"k": []byte("shown")}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "token", Namespace: "closed"}, Data: map[string][]byte{"k": []byte("hidden")}},
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objs...).Build()
+	disco := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: []*metav1.APIResourceList{{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{{Name: "secrets", Namespaced: true, Kind: "Secret", Verbs: []string{"list"}}},
+	}}}}
+
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf)
+	secretAccess := func(cluster, namespace string) bool { return namespace == "open" }
+	if err := Export(context.Background(), cli, disco, "pivot", []string{"open", "closed"}, w, secretAccess); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	gr, err := gzip.NewReader(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[hdr.Name] = string(b)
+	}
+	// data is base64 encoded in yaml
+	if content := files["pivot/open/secrets/token.yaml"]; !strings.Contains(content, "c2hvd24=") {
+		t.Errorf("data of secret should be kept: %v", content)
+	}
+	content := files["pivot/closed/secrets/token.yaml"]
+	if strings.Contains(content, "aGlkZGVu") || !strings.Contains(content, RedactedAnnotation) {
+		t.Errorf("data of secret should be redacted: %v", content)
+	}
+}
@@ -55,12 +55,31 @@ func SetAuditInfo(c *gin.Context, eventInfo *EventInfo, resourceName string, Req
 }
 
 var (
-	CreateUser       = &EventInfo{"createUser", "createUser", "user"}
-	UpdateUser       = &EventInfo{"updateUser", "updateUser", "user"}
-	DeleteKey        = &EventInfo{"deleteKey", "deleteKey", "key"}
-	CreateKey        = &EventInfo{"createKey", "createKey", "key"}
-	CreateConfigMap  = &EventInfo{"createConfigMap", "createConfigMap", "configmap"}
-	DeleteConfigMap  = &EventInfo{"deleteConfigMap", "deleteConfigMap", "configmap"}
-	UpdateConfigMap  = &EventInfo{"updateConfigMap", "updateConfigMap", "configmap"}
-	RolloutConfigMap = &EventInfo{"rolloutConfigMap", "rolloutConfigMap", "configmap"}
+	CreateUser         = &EventInfo{"createUser", "createUser", "user"}
+	UpdateUser         = &EventInfo{"updateUser", "updateUser", "user"}
+	DeleteKey          = &EventInfo{"deleteKey", "deleteKey", "key"}
+	CreateKey          = &EventInfo{"createKey", "createKey", "key"}
+	CreateConfigMap    = &EventInfo{"createConfigMap", "createConfigMap", "configmap"}
+	DeleteConfigMap    = &EventInfo{"deleteConfigMap", "deleteConfigMap", "configmap"}
+	UpdateConfigMap    = &EventInfo{"updateConfigMap", "updateConfigMap", "configmap"}
+	RolloutConfigMap   = &EventInfo{"rolloutConfigMap", "rolloutConfigMap", "configmap"}
+	SuspendTenant      = &EventInfo{"suspendTenant", "suspendTenant", "tenant"}
+	ResumeTenant       = &EventInfo{"resumeTenant", "resumeTenant", "tenant"}
+	DeleteTenant       = &EventInfo{"deleteTenant", "deleteTenant", "tenant"}
+	RestoreTenant      = &EventInfo{"restoreTenant", "restoreTenant", "tenant"}
+	CreateAccessReview = &EventInfo{"createAccessReview", "createAccessReview", "accessreview"}
+	ReviewAccess       = &EventInfo{"reviewAccess", "reviewAccess", "accessreview"}
+	RequestBinding     = &EventInfo{"requestBinding", "requestBinding", "bindingrequest"}
+	ApproveBinding     = &EventInfo{"approveBinding", "approveBinding", "bindingrequest"}
+	RejectBinding      = &EventInfo{"rejectBinding", "rejectBinding", "bindingrequest"}
+	ExecPod            = &EventInfo{"execPod", "execPod", "pod"}
+	PortForwardPod     = &EventInfo{"portForwardPod", "portForwardPod", "pod"}
+	RestartDeployment  = &EventInfo{"restartDeployment", "restartDeployment", "deployment"}
+	ScaleDeployment    = &EventInfo{"scaleDeployment", "scaleDeployment", "deployment"}
+	RollbackDeployment = &EventInfo{"rollbackDeployment", "rollbackDeployment", "deployment"}
+	PauseDeployment    = &EventInfo{"pauseDeployment", "pauseDeployment", "deployment"}
+	ResumeDeployment   = &EventInfo{"resumeDeployment", "resumeDeployment", "deployment"}
+	SuspendCronJob     = &EventInfo{"suspendCronJob", "suspendCronJob", "cronjob"}
+	ResumeCronJob      = &EventInfo{"resumeCronJob", "resumeCronJob", "cronjob"}
+	TriggerCronJob     = &EventInfo{"triggerCronJob", "triggerCronJob", "cronjob"}
 )
@@ -35,6 +35,9 @@ const (
 	DefaultPivotKubeClusterIPSvc = "kubeworkz:7443"
 
 	DefaultAuditURL = "http://audit:8888/api/v1/kube/audit/kube"
+
+	// DefaultSessionRecordDir default dir to store recordings of terminal sessions
+	DefaultSessionRecordDir = "/var/lib/kubeworkz/sessions"
 )
 
 // http content
@@ -136,6 +139,45 @@ const (
 
 	// ForceDeleteAnnotation used to force deletion of some resources that are not allowed to be deleted
 	ForceDeleteAnnotation = "kubeworkz.io/force-delete"
+
+	// DeletionRequestedAnnotation records the time deletion of tenant requested,
+	// tenant keeps suspended and restorable until grace period passed
+	DeletionRequestedAnnotation = "kubeworkz.io/deletion-requested-at"
+
+	// SuspendedLabel marks namespace of suspended tenant, writes into
+	// namespace with that label will be denied by webhook of warden
+	SuspendedLabel = "kubeworkz.io/suspended"
+
+	// SuspendedReplicasAnnotation records replicas of workload before suspended
+	SuspendedReplicasAnnotation = "kubeworkz.io/suspended-replicas"
+
+	// ProjectTemplateLabel chooses project template for namespace when set on
+	// namespace, and marks objects managed by project template
+	ProjectTemplateLabel = "kubeworkz.io/project-template"
+
+	// NetworkIsolationLabel marks network policies managed for tenant network isolation
+	NetworkIsolationLabel = "kubeworkz.io/network-isolation"
+
+	// AccessReviewCampaignLabel groups recurring access reviews by the name
+	// of the first one
+	AccessReviewCampaignLabel = "user.kubeworkz.io/access-review-campaign"
+
+	// HotplugRolloutRevisionAnnotation records the revision of common hotplug
+	// the released clusters apply to, set by rollout controller
+	HotplugRolloutRevisionAnnotation = "hotplug.kubeworkz.io/rollout-revision"
+
+	// HotplugRolloutClustersAnnotation records comma separated clusters the
+	// revision of common hotplug released to, other clusters keep the last
+	// applied components until released
+	HotplugRolloutClustersAnnotation = "hotplug.kubeworkz.io/rollout-clusters"
+
+	// HotplugChartSourceAnnotation records where chart of hotplug component
+	// loaded from, set on chart metadata of helm release
+	HotplugChartSourceAnnotation = "hotplug.kubeworkz.io/chart-source"
+
+	// HotplugChartDigestAnnotation records sha256 digest of chart archive of
+	// hotplug component, set on chart metadata of helm release
+	HotplugChartDigestAnnotation = "hotplug.kubeworkz.io/chart-digest"
 )
 
 const (
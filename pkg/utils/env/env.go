@@ -19,9 +19,11 @@ package env
 import (
 	"net/http"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/saashqdev/kubeworkz/pkg/clog"
 	"github.com/saashqdev/kubeworkz/pkg/multicluster/client/config"
@@ -98,6 +100,25 @@ func AuditEventSource() string {
 	return r
 }
 
+// SessionRecordDir the dir to store recordings of exec and port-forward sessions
+func SessionRecordDir() string {
+	d := os.Getenv("SESSION_RECORD_DIR")
+	if d == "" {
+		d = constants.DefaultSessionRecordDir
+	}
+	return d
+}
+
+// SessionRecordRetention how long recordings of sessions are kept, 30 days
+// if not set, recordings are kept forever if it is not positive
+func SessionRecordRetention() time.Duration {
+	d, err := time.ParseDuration(os.Getenv("SESSION_RECORD_RETENTION"))
+	if err != nil {
+		return 30 * 24 * time.Hour
+	}
+	return d
+}
+
 func JwtSecret() string {
 	return os.Getenv("JWT_SECRET")
 }
@@ -164,6 +185,78 @@ func EnsureManagedLabels(labels map[string]string) map[string]string {
 	return res
 }
 
+// TenantDeletionGracePeriod is the default duration tenant can be restored
+// after deletion requested, 7 days if not set
+func TenantDeletionGracePeriod() time.Duration {
+	d, err := time.ParseDuration(os.Getenv("TENANT_DELETION_GRACE_PERIOD"))
+	if err != nil {
+		return 7 * 24 * time.Hour
+	}
+	return d
+}
+
+// TenantArchiveDir is the directory where archive of tenant will be written
+// before tenant deleted, archive is skipped if not set
+func TenantArchiveDir() string {
+	return os.Getenv("TENANT_ARCHIVE_DIR")
+}
+
+// NamespaceExpireWarningPeriod is the duration before namespace expired when
+// warning event will be emitted, 1 hour if not set
+func NamespaceExpireWarningPeriod() time.Duration {
+	d, err := time.ParseDuration(os.Getenv("NAMESPACE_EXPIRE_WARNING_PERIOD"))
+	if err != nil {
+		return time.Hour
+	}
+	return d
+}
+
+// NamespaceMaxTTL is the max ttl of namespace when created or extended,
+// 30 days if not set
+func NamespaceMaxTTL() time.Duration {
+	d, err := time.ParseDuration(os.Getenv("NAMESPACE_MAX_TTL"))
+	if err != nil {
+		return 30 * 24 * time.Hour
+	}
+	return d
+}
+
+// IngressControllerNamespaces are namespaces of ingress controllers whose
+// traffic is allowed into isolated namespaces, split by comma
+func IngressControllerNamespaces() []string {
+	v := os.Getenv("INGRESS_CONTROLLER_NAMESPACES")
+	if len(v) == 0 {
+		return []string{"ingress-nginx"}
+	}
+	var namespaces []string
+	for _, ns := range strings.Split(v, ",") {
+		if ns = strings.TrimSpace(ns); len(ns) > 0 {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return namespaces
+}
+
+// HotplugChartCacheDir is the directory where charts of hotplug components
+// pulled from repositories are cached
+func HotplugChartCacheDir() string {
+	d := os.Getenv("HOTPLUG_CHART_CACHE_DIR")
+	if len(d) == 0 {
+		return filepath.Join(os.TempDir(), "hotplug-charts")
+	}
+	return d
+}
+
+// MaxBindingRequestDuration is the longest duration temporary scope binding
+// can be requested for, 24 hours if not set
+func MaxBindingRequestDuration() time.Duration {
+	d, err := time.ParseDuration(os.Getenv("MAX_BINDING_REQUEST_DURATION"))
+	if err != nil {
+		return 24 * time.Hour
+	}
+	return d
+}
+
 func CreateHNCNs() bool {
 	return os.Getenv("CREATE_HNC_NS") == "true"
 }
@@ -219,11 +312,16 @@ func GetClusterClientConfig() config.Config {
 	if err != nil {
 		clusterCacheSyncIntervalInt = 60
 	}
+	listCacheEnable, err := strconv.ParseBool(os.Getenv("LIST_CACHE_ENABLE"))
+	if err != nil {
+		listCacheEnable = false
+	}
 	return config.Config{
 		QPS:                      qpsFloat32,
 		Burst:                    burstInt,
 		TimeoutSecond:            timeoutInt,
 		ClusterCacheSyncEnable:   clusterCacheSyncEnableBool,
 		ClusterCacheSyncInterval: clusterCacheSyncIntervalInt,
+		ListCacheEnable:          listCacheEnable,
 	}
 }
@@ -0,0 +1,34 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package env
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIngressControllerNamespaces(t *testing.T) {
+	t.Setenv("INGRESS_CONTROLLER_NAMESPACES", "")
+	if got := IngressControllerNamespaces(); !reflect.DeepEqual(got, []string{"ingress-nginx"}) {
+		t.Errorf("expect default ingress namespace, got %v", got)
+	}
+
+	t.Setenv("INGRESS_CONTROLLER_NAMESPACES", " ingress-nginx , traefik,,")
+	if got := IngressControllerNamespaces(); !reflect.DeepEqual(got, []string{"ingress-nginx", "traefik"}) {
+		t.Errorf("expect trimmed ingress namespaces, got %v", got)
+	}
+}
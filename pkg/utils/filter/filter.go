@@ -17,10 +17,7 @@ limitations under the License.
 package filter
 
 import (
-	"bytes"
-	"compress/gzip"
 	"fmt"
-	"io"
 
 	jsoniter "github.com/json-iterator/go"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -67,48 +64,57 @@ type ConverterContext struct {
 	Converter     *conversion.VersionConverter
 }
 
-// ModifyResponse modify the response
+// ModifyResponse filters, sorts and pages list in response. Items are
+// decoded one by one and only those of requested page are kept in memory.
 func (f *Filter) ModifyResponse(r *http.Response, filterCondition *Condition) error {
-	// get info from response
-	var body []byte
-	var err error
-	codeType := r.Header.Get("Content-Encoding")
-	switch codeType {
-	case "gzip":
-		reader, err := gzip.NewReader(r.Body)
-		defer reader.Close()
-		if err != nil {
-			clog.Info("can not read gzip body from response, %v", err)
-			return err
-		}
-		body, err = io.ReadAll(reader)
-		if err != nil {
-			clog.Info("can not read gzip body from response, %v", err)
-			return err
-		}
-	default:
-		body, err = io.ReadAll(r.Body)
-		if err != nil {
-			clog.Info("can not read body from response, %v", err)
-			return err
-		}
+	reader, err := responseReader(r)
+	if err != nil {
+		clog.Info("can not read body from response, %v", err)
+		return err
+	}
+	if !isJsonObject(reader) {
+		passThrough(r, reader)
+		return nil
 	}
+	defer r.Body.Close()
 
-	// todo create index
-	if obj, err := f.doFilter(body, filterCondition); err == nil {
-		// return result
-		marshal, err := json.Marshal(obj)
-		if err != nil {
-			clog.Error("modify response failed: %s", err.Error())
+	sortParam := SortParam{
+		sortName:  filterCondition.SortName,
+		sortFunc:  filterCondition.SortFunc,
+		sortOrder: filterCondition.SortOrder,
+	}
+	window := &listWindow{
+		offset: filterCondition.Offset,
+		limit:  filterCondition.Limit,
+		less:   sortLess(&sortParam),
+	}
+	fields, isList, _, err := decodeList(reader, func(item unstructured.Unstructured) (bool, error) {
+		matched, err := f.match(item, filterCondition)
+		if err != nil || !matched {
+			return false, err
 		}
-		body = marshal
-	} else {
+		window.add(item)
+		return false, nil
+	})
+	if err != nil {
 		clog.Warn("modify response failed: %s", err.Error())
+		return err
+	}
+
+	var result interface{}
+	if isList {
+		list := f.listResult(fields, window.page())
+		list["total"] = window.total
+		result = list
+	} else {
+		result = f.objectResult(fields, filterCondition)
+	}
+	body, err := json.Marshal(result)
+	if err != nil {
+		clog.Error("modify response failed: %s", err.Error())
+		return err
 	}
-	buf := bytes.NewBuffer(body)
-	r.Body = io.NopCloser(buf)
-	r.Header["Content-Length"] = []string{fmt.Sprint(buf.Len())}
-	delete(r.Header, "Content-Encoding")
+	setBody(r, body)
 	return nil
 }
 
@@ -182,12 +188,17 @@ func (f *Filter) FilterObjectList(object runtime.Object, filterCondition *Condit
 func (f *Filter) filter(listObject []unstructured.Unstructured, filterCondition *Condition) ([]unstructured.Unstructured, int, error) {
 	listObject, err := ExactFilter(listObject, filterCondition.Exact)
 	if err != nil {
-		clog.Debug("exact filter error: %s", err)
+		return nil, 0, fmt.Errorf("exact filter error: %v", err)
 	}
 
 	listObject, err = FuzzyFilter(listObject, filterCondition.Fuzzy)
 	if err != nil {
-		clog.Debug("fuzzy filter error: %s", err)
+		return nil, 0, fmt.Errorf("fuzzy filter error: %v", err)
+	}
+
+	listObject, err = SelectorFilter(listObject, filterCondition.Selector)
+	if err != nil {
+		return nil, 0, fmt.Errorf("selector filter error: %v", err)
 	}
 
 	sortParam := SortParam{
@@ -16,11 +16,19 @@ limitations under the License.
 
 package filter
 
-import "k8s.io/apimachinery/pkg/util/sets"
+import (
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/saashqdev/kubeworkz/pkg/utils/selector"
+)
 
 type Condition struct {
-	Exact     map[string]sets.Set[string]
-	Fuzzy     map[string][]string
+	Exact map[string]sets.Set[string]
+	Fuzzy map[string][]string
+	// Selector the compiled clauses which can not be expressed by exact and fuzzy
+	Selector *selector.Selector
+	// Continue paginates by continue token instead of page number if not nil
+	Continue  *ContinueToken
 	Limit     int
 	Offset    int
 	SortName  string
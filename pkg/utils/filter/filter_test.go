@@ -30,6 +30,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	v1 "github.com/saashqdev/kubeworkz/pkg/apis/cluster/v1"
+	"github.com/saashqdev/kubeworkz/pkg/utils/selector"
 )
 
 var _ = ginkgo.Describe("Filter", func() {
@@ -108,6 +109,122 @@ var _ = ginkgo.Describe("Filter", func() {
 		}
 	})
 
+	ginkgo.It("TestModifyChunkedResponse", func() {
+		chunk := func(items []v1.Cluster, token string) *http.Response {
+			l := v1.ClusterList{Items: items}
+			l.Kind = "ClusterList"
+			l.APIVersion = "cluster.kubeworkz.io/v1"
+			l.Continue = token
+			data, _ := json.Marshal(l)
+			req, _ := http.NewRequest(http.MethodGet, "http://127.0.0.1/apis/cluster.kubeworkz.io/v1/clusters?limit=10", nil)
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(bytes.NewReader(data)), Request: req}
+		}
+		transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			Expect(req.URL.Query().Get("continue")).To(Equal("c1"))
+			return chunk(list.Items[10:], ""), nil
+		})
+		names := func(r *http.Response) ([]string, string) {
+			body, err := io.ReadAll(r.Body)
+			Expect(err).To(BeNil())
+			result := struct {
+				v1.ClusterList
+				KwContinue string `json:"kwContinue"`
+			}{}
+			Expect(json.Unmarshal(body, &result)).To(BeNil())
+			Expect(result.Continue).To(BeEmpty())
+			var names []string
+			for _, item := range result.Items {
+				names = append(names, item.Name)
+			}
+			return names, result.KwContinue
+		}
+
+		condition := &Condition{
+			Fuzzy:    map[string][]string{"metadata.name": {"name1"}},
+			Limit:    3,
+			Continue: &ContinueToken{},
+		}
+		r := chunk(list.Items[:10], "c1")
+		Expect(GetEmptyFilter().ModifyChunkedResponse(r, condition, transport)).To(BeNil())
+		page, token := names(r)
+		Expect(page).To(Equal([]string{"a-name1", "a-name10", "b-name11"}))
+
+		condition.Continue, _ = ParseContinueToken(token)
+		Expect(*condition.Continue).To(Equal(ContinueToken{Continue: "c1", Skip: 2}))
+		r = chunk(list.Items[10:], "")
+		Expect(GetEmptyFilter().ModifyChunkedResponse(r, condition, transport)).To(BeNil())
+		page, token = names(r)
+		Expect(page).To(Equal([]string{"b-name12", "b-name13", "b-name14"}))
+		Expect(token).NotTo(BeEmpty())
+
+		_, err := ParseContinueToken("not-a-token")
+		Expect(err).NotTo(BeNil())
+	})
+
+	ginkgo.It("TestModifyResponseNativeContinue", func() {
+		// pages of apiserver are filtered one by one and continue token of
+		// apiserver is handed back to client untouched
+		page := func(items []v1.Cluster, token string) *http.Response {
+			l := v1.ClusterList{Items: items}
+			l.Kind = "ClusterList"
+			l.APIVersion = "cluster.kubeworkz.io/v1"
+			l.Continue = token
+			data, _ := json.Marshal(l)
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(bytes.NewReader(data))}
+		}
+		condition := &Condition{Fuzzy: map[string][]string{"metadata.name": {"name1"}}}
+
+		token := ""
+		var names []string
+		for _, items := range [][]v1.Cluster{list.Items[:10], list.Items[10:]} {
+			next := ""
+			if len(names) == 0 {
+				next = "eyJ2IjoibWV0YS5rOHMuaW8vdjEiLCJydiI6MTAwLCJzdGFydCI6ImEtbmFtZTkifQ"
+			}
+			r := page(items, next)
+			Expect(GetEmptyFilter().ModifyResponse(r, condition)).To(BeNil())
+			body, err := io.ReadAll(r.Body)
+			Expect(err).To(BeNil())
+			result := v1.ClusterList{}
+			Expect(json.Unmarshal(body, &result)).To(BeNil())
+			Expect(result.Continue).To(Equal(next))
+			for _, item := range result.Items {
+				names = append(names, item.Name)
+			}
+			token = result.Continue
+		}
+		Expect(token).To(BeEmpty())
+		Expect(names).To(Equal([]string{"a-name1", "a-name10", "b-name11", "b-name12", "b-name13", "b-name14",
+			"b-name15", "b-name16", "b-name17", "b-name18", "b-name19"}))
+	})
+
+	ginkgo.It("TestModifyResponseWindow", func() {
+		listJson, _ := json.Marshal(list)
+		r := http.Response{Header: http.Header{}, Body: io.NopCloser(bytes.NewReader(listJson))}
+		condition := &Condition{
+			Limit:     3,
+			Offset:    3,
+			SortName:  "metadata.name",
+			SortFunc:  "string",
+			SortOrder: "desc",
+		}
+		Expect(GetEmptyFilter().ModifyResponse(&r, condition)).To(BeNil())
+		body, _ := io.ReadAll(r.Body)
+		result := map[string]interface{}{}
+		Expect(json.Unmarshal(body, &result)).To(BeNil())
+		Expect(result["total"]).To(Equal(float64(20)))
+		items := result["items"].([]interface{})
+		Expect(3).To(Equal(len(items)))
+		for i, name := range []string{"b-name16", "b-name15", "b-name14"} {
+			Expect(items[i].(map[string]interface{})["metadata"].(map[string]interface{})["name"]).To(Equal(name))
+		}
+
+		r = http.Response{Header: http.Header{}, Body: io.NopCloser(bytes.NewBufferString("plain log line"))}
+		Expect(GetEmptyFilter().ModifyResponse(&r, condition)).To(BeNil())
+		body, _ = io.ReadAll(r.Body)
+		Expect(string(body)).To(Equal("plain log line"))
+	})
+
 	ginkgo.It("TestFilterResult", func() {
 		// create condition
 		fuzzy := make(map[string][]string)
@@ -212,6 +329,23 @@ var _ = ginkgo.Describe("Filter", func() {
 		Expect("b-name12").To(Equal(list.Items[1].Name))
 	})
 
+	ginkgo.It("TestSelectorMatch", func() {
+		s, err := selector.Compile("metadata.annotations.kubeworkz.test.io/index>=15,metadata.name!=b-name16,!metadata.labels.missing,(metadata.name~b-name17;metadata.name=b-name18|b-name19),metadata.labels.hello=world")
+		Expect(err).To(BeNil())
+		exact, fuzzy, rest := s.Split()
+		condition := &Condition{
+			Exact:    exact,
+			Fuzzy:    fuzzy,
+			Selector: rest,
+		}
+		_, err = GetEmptyFilter().FilterObjectList(&list, condition)
+		Expect(err).To(BeNil())
+		Expect(3).To(Equal(len(list.Items)))
+		Expect("b-name17").To(Equal(list.Items[0].Name))
+		Expect("b-name18").To(Equal(list.Items[1].Name))
+		Expect("b-name19").To(Equal(list.Items[2].Name))
+	})
+
 	ginkgo.It("TestSort", func() {
 		// create condition
 		exact := make(map[string]sets.Set[string])
@@ -270,3 +404,9 @@ var _ = ginkgo.Describe("Filter", func() {
 	})
 
 })
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
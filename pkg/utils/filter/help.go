@@ -70,6 +70,34 @@ func GetDeepValue(item interface{}, keyStr string) ([]string, error) {
 	}
 }
 
+// GetDeepValues get values of any type by metadata.xx.xx.xx, values of
+// arrays are flattened
+func GetDeepValues(item interface{}, keyStr string) ([]interface{}, error) {
+	fields := strings.Split(keyStr, ".")
+	v, err := getRes(item, 0, fields)
+	if err != nil {
+		return nil, err
+	}
+	return flatten(v, nil), nil
+}
+
+func flatten(v interface{}, result []interface{}) []interface{} {
+	switch val := v.(type) {
+	case []interface{}:
+		for _, e := range val {
+			result = flatten(e, result)
+		}
+	case []string:
+		for _, e := range val {
+			result = append(result, e)
+		}
+	case nil:
+	default:
+		result = append(result, val)
+	}
+	return result
+}
+
 func getRes(item interface{}, index int, fields []string) (interface{}, error) {
 	switch item.(type) {
 	// if this value is map[string]interface{}, we need to get the value which key is, and return next index to get next key
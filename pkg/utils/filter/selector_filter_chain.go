@@ -0,0 +1,43 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filter
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/saashqdev/kubeworkz/pkg/utils/selector"
+)
+
+// SelectorFilter keeps items matching negation, comparison, existence and
+// OR group clauses of selector
+func SelectorFilter(items []unstructured.Unstructured, s *selector.Selector) ([]unstructured.Unstructured, error) {
+	if len(items) < 1 || s.Empty() {
+		return items, nil
+	}
+	result := make([]unstructured.Unstructured, 0)
+	for _, item := range items {
+		values := func(key string) ([]interface{}, bool) {
+			// key of array elements which all miss the field is regarded as not exists
+			v, err := GetDeepValues(item, key)
+			return v, err == nil && len(v) > 0
+		}
+		if s.Matches(values) {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
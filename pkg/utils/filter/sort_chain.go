@@ -36,12 +36,24 @@ func SortHandler(items []unstructured.Unstructured, param *SortParam) ([]unstruc
 	if len(items) == 0 {
 		return items, nil
 	}
-	if len(param.sortFunc) == 0 || len(param.sortName) == 0 {
+	less := sortLess(param)
+	if less == nil {
 		return items, nil
 	}
 	sort.Slice(items, func(i, j int) bool {
-		getStringFunc := func(items []unstructured.Unstructured, i int, j int) (string, string, error) {
-			si, err := GetDeepValue(items[i], param.sortName)
+		return less(items[i], items[j])
+	})
+	return items, nil
+}
+
+// sortLess returns the order of items by sort param, nil if no sort required
+func sortLess(param *SortParam) func(a, b unstructured.Unstructured) bool {
+	if len(param.sortFunc) == 0 || len(param.sortName) == 0 {
+		return nil
+	}
+	return func(a, b unstructured.Unstructured) bool {
+		getStringFunc := func(a, b unstructured.Unstructured) (string, string, error) {
+			si, err := GetDeepValue(a, param.sortName)
 			if err != nil {
 				clog.Warn("get sort value error, err: %s", err)
 				return "", "", err
@@ -50,7 +62,7 @@ func SortHandler(items []unstructured.Unstructured, param *SortParam) ([]unstruc
 				clog.Warn("not support array value, val: %s", si)
 				return "", "", err
 			}
-			sj, err := GetDeepValue(items[j], param.sortName)
+			sj, err := GetDeepValue(b, param.sortName)
 			if err != nil {
 				clog.Warn("get sort value error, err: %s", err)
 				return "", "", err
@@ -65,7 +77,7 @@ func SortHandler(items []unstructured.Unstructured, param *SortParam) ([]unstruc
 		}
 		switch param.sortFunc {
 		case "string":
-			before, after, err := getStringFunc(items, i, j)
+			before, after, err := getStringFunc(a, b)
 			if err != nil {
 				return false
 			}
@@ -75,7 +87,7 @@ func SortHandler(items []unstructured.Unstructured, param *SortParam) ([]unstruc
 				return strings.Compare(before, after) == 1
 			}
 		case "time":
-			before, after, err := getStringFunc(items, i, j)
+			before, after, err := getStringFunc(a, b)
 			if err != nil {
 				return false
 			}
@@ -94,8 +106,8 @@ func SortHandler(items []unstructured.Unstructured, param *SortParam) ([]unstruc
 				return ti.After(tj)
 			}
 		case "number":
-			ni := GetDeepFloat64(items[i], param.sortName)
-			nj := GetDeepFloat64(items[j], param.sortName)
+			ni := GetDeepFloat64(a, param.sortName)
+			nj := GetDeepFloat64(b, param.sortName)
 			if param.sortOrder == "asc" {
 				return ni < nj
 			} else if param.sortOrder == "desc" {
@@ -104,7 +116,7 @@ func SortHandler(items []unstructured.Unstructured, param *SortParam) ([]unstruc
 				return ni < nj
 			}
 		default:
-			before, after, err := getStringFunc(items, i, j)
+			before, after, err := getStringFunc(a, b)
 			if err != nil {
 				return false
 			}
@@ -114,6 +126,5 @@ func SortHandler(items []unstructured.Unstructured, param *SortParam) ([]unstruc
 				return strings.Compare(before, after) == 1
 			}
 		}
-	})
-	return items, nil
+	}
 }
@@ -0,0 +1,418 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filter
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	stdjson "encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+
+	"github.com/saashqdev/kubeworkz/pkg/clog"
+)
+
+// ChunkSize the number of items requested from apiserver each time when
+// paginating by continue token
+const ChunkSize = 500
+
+// ContinueParam the query parameter clients opt in to paginating filtered
+// list by, and the field of list the token of next page is handed out in.
+// It is kept apart from continue of apiserver which is passed through as is.
+const ContinueParam = "kwContinue"
+
+// ContinueToken locates next page when paginating by continue token, it is
+// handed to clients in kwContinue field of list as an opaque string
+type ContinueToken struct {
+	// Continue the continue token of apiserver for the chunk next page starts in
+	Continue string `json:"c,omitempty"`
+	// Skip the number of matched items of the chunk which were returned
+	Skip int `json:"s,omitempty"`
+}
+
+// ParseContinueToken parses continue token given by client, empty string
+// means the first page
+func ParseContinueToken(s string) (*ContinueToken, error) {
+	t := &ContinueToken{}
+	if len(s) == 0 {
+		return t, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid continue token")
+	}
+	if err = json.Unmarshal(data, t); err != nil || t.Skip < 0 {
+		return nil, fmt.Errorf("invalid continue token")
+	}
+	return t, nil
+}
+
+func (t *ContinueToken) String() string {
+	data, _ := json.Marshal(t)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// listWindow keeps the matched items of requested page only, items are
+// ordered as they come when there is no sort
+type listWindow struct {
+	offset int
+	limit  int
+	less   func(a, b unstructured.Unstructured) bool
+	items  []unstructured.Unstructured
+	total  int
+}
+
+func (w *listWindow) add(item unstructured.Unstructured) {
+	w.total++
+	switch {
+	case w.limit == 0:
+		w.items = append(w.items, item)
+	case w.less == nil:
+		if w.total > w.offset && w.total <= w.offset+w.limit {
+			w.items = append(w.items, item)
+		}
+	default:
+		// keep the first offset+limit items in order
+		size := w.offset + w.limit
+		i := sort.Search(len(w.items), func(i int) bool {
+			return w.less(item, w.items[i])
+		})
+		if i >= size {
+			return
+		}
+		w.items = append(w.items, unstructured.Unstructured{})
+		copy(w.items[i+1:], w.items[i:])
+		w.items[i] = item
+		if len(w.items) > size {
+			w.items = w.items[:size]
+		}
+	}
+}
+
+func (w *listWindow) page() []unstructured.Unstructured {
+	switch {
+	case w.limit == 0:
+		if w.less != nil {
+			sort.Slice(w.items, func(i, j int) bool {
+				return w.less(w.items[i], w.items[j])
+			})
+		}
+		return w.items
+	case w.less == nil:
+		return w.items
+	default:
+		if w.offset >= len(w.items) {
+			return nil
+		}
+		return w.items[w.offset:]
+	}
+}
+
+// match returns true if item passes exact, fuzzy and selector filters
+func (f *Filter) match(item unstructured.Unstructured, filterCondition *Condition) (bool, error) {
+	items, err := ExactFilter([]unstructured.Unstructured{item}, filterCondition.Exact)
+	if err != nil {
+		return false, err
+	}
+	items, err = FuzzyFilter(items, filterCondition.Fuzzy)
+	if err != nil {
+		return false, err
+	}
+	items, err = SelectorFilter(items, filterCondition.Selector)
+	if err != nil {
+		return false, err
+	}
+	return len(items) > 0, nil
+}
+
+// decodeList decodes json object field by field, elements of items are
+// handed to onItem one by one so that the whole list is never held in
+// memory, decoding stops once onItem returns true. Fields other than items
+// are returned.
+func decodeList(r io.Reader, onItem func(item unstructured.Unstructured) (bool, error)) (fields map[string]interface{}, isList bool, stopped bool, err error) {
+	dec := stdjson.NewDecoder(r)
+	if _, err = dec.Token(); err != nil {
+		return
+	}
+	fields = make(map[string]interface{})
+	for dec.More() {
+		var tok stdjson.Token
+		if tok, err = dec.Token(); err != nil {
+			return
+		}
+		key, _ := tok.(string)
+		kind, _ := fields["kind"].(string)
+		if key != "items" || (len(kind) > 0 && !strings.HasSuffix(kind, "List")) {
+			var raw stdjson.RawMessage
+			if err = dec.Decode(&raw); err != nil {
+				return
+			}
+			var v interface{}
+			if err = utiljson.Unmarshal(raw, &v); err != nil {
+				return
+			}
+			fields[key] = v
+			continue
+		}
+
+		isList = true
+		if tok, err = dec.Token(); err != nil {
+			return
+		}
+		if tok == nil {
+			continue
+		}
+		if d, ok := tok.(stdjson.Delim); !ok || d != '[' {
+			err = fmt.Errorf("items of list is not an array")
+			return
+		}
+		for dec.More() {
+			var raw stdjson.RawMessage
+			if err = dec.Decode(&raw); err != nil {
+				return
+			}
+			item := unstructured.Unstructured{}
+			if err = utiljson.Unmarshal(raw, &item.Object); err != nil {
+				return
+			}
+			if stopped, err = onItem(item); err != nil || stopped {
+				return
+			}
+		}
+		if _, err = dec.Token(); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// responseReader returns the decompressed body of response
+func responseReader(r *http.Response) (*bufio.Reader, error) {
+	var reader io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		reader = gz
+	}
+	return bufio.NewReader(reader), nil
+}
+
+// isJsonObject skips leading spaces and tells whether body is json object
+func isJsonObject(r *bufio.Reader) bool {
+	for {
+		b, err := r.Peek(1)
+		if err != nil {
+			return false
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			_, _ = r.ReadByte()
+		case '{':
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+// passThrough hands decompressed body to client as it is, such as logs
+func passThrough(r *http.Response, reader io.Reader) {
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{reader, r.Body}
+	r.ContentLength = -1
+	delete(r.Header, "Content-Length")
+	delete(r.Header, "Content-Encoding")
+}
+
+func setBody(r *http.Response, body []byte) {
+	buf := bytes.NewBuffer(body)
+	r.Body = io.NopCloser(buf)
+	r.ContentLength = int64(buf.Len())
+	r.Header["Content-Length"] = []string{fmt.Sprint(buf.Len())}
+	delete(r.Header, "Content-Encoding")
+}
+
+// listResult assembles list of top level fields and items
+func (f *Filter) listResult(fields map[string]interface{}, items []unstructured.Unstructured) map[string]interface{} {
+	kind, _ := fields["kind"].(string)
+	apiVersion, _ := fields["apiVersion"].(string)
+	for i := range items {
+		if len(items[i].GetKind()) == 0 {
+			items[i].SetKind(strings.TrimSuffix(kind, "List"))
+		}
+		if len(items[i].GetAPIVersion()) == 0 {
+			items[i].SetAPIVersion(apiVersion)
+		}
+	}
+	items, err := f.versionConvert(items)
+	if err != nil {
+		clog.Error("convert obj error: %s", err)
+	}
+	objects := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		objects = append(objects, item.Object)
+	}
+	fields["items"] = objects
+	return fields
+}
+
+// objectResult converts single object which is not filtered
+func (f *Filter) objectResult(fields map[string]interface{}, filterCondition *Condition) interface{} {
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return fields
+	}
+	obj, err := f.doFilter(data, filterCondition)
+	if err != nil {
+		clog.Warn("modify response failed: %s", err.Error())
+		return fields
+	}
+	return obj
+}
+
+// ModifyChunkedResponse filters list chunk by chunk following continue
+// tokens of apiserver until page is filled, and hands out continue token of
+// next page in kwContinue field instead of total. Items of chunk being
+// added or removed between requests may shift the next page slightly.
+func (f *Filter) ModifyChunkedResponse(r *http.Response, filterCondition *Condition, transport http.RoundTripper) error {
+	if r.StatusCode != http.StatusOK || filterCondition.Continue == nil {
+		return f.ModifyResponse(r, filterCondition)
+	}
+
+	var (
+		fields map[string]interface{}
+		items  []unstructured.Unstructured
+		next   *ContinueToken
+		resp   = r
+		chunk  = filterCondition.Continue.Continue
+		skip   = filterCondition.Continue.Skip
+		limit  = filterCondition.Limit
+	)
+	for {
+		reader, err := responseReader(resp)
+		if err != nil {
+			return err
+		}
+		if resp == r && !isJsonObject(reader) {
+			passThrough(r, reader)
+			return nil
+		}
+
+		matched := 0
+		chunkFields, isList, stopped, err := decodeList(reader, func(item unstructured.Unstructured) (bool, error) {
+			ok, err := f.match(item, filterCondition)
+			if err != nil || !ok {
+				return false, err
+			}
+			matched++
+			if matched <= skip {
+				return false, nil
+			}
+			// stop at the first item of next page
+			if limit > 0 && len(items) == limit {
+				return true, nil
+			}
+			items = append(items, item)
+			return false, nil
+		})
+		_ = resp.Body.Close()
+		if err != nil {
+			return err
+		}
+		if !isList {
+			if resp != r {
+				return fmt.Errorf("response of next chunk is not a list")
+			}
+			body, err := json.Marshal(f.objectResult(chunkFields, filterCondition))
+			if err != nil {
+				return err
+			}
+			setBody(r, body)
+			return nil
+		}
+		if fields == nil {
+			fields = chunkFields
+		}
+		if stopped {
+			next = &ContinueToken{Continue: chunk, Skip: matched - 1}
+			break
+		}
+
+		metadata, _ := chunkFields["metadata"].(map[string]interface{})
+		upstream, _ := metadata["continue"].(string)
+		if len(upstream) == 0 {
+			break
+		}
+		if limit > 0 && len(items) == limit {
+			next = &ContinueToken{Continue: upstream}
+			break
+		}
+
+		chunk, skip = upstream, 0
+		resp, err = fetchChunk(r.Request, upstream, transport)
+		if err != nil {
+			return err
+		}
+	}
+
+	metadata, _ := fields["metadata"].(map[string]interface{})
+	if metadata == nil {
+		metadata = make(map[string]interface{})
+		fields["metadata"] = metadata
+	}
+	delete(metadata, "continue")
+	delete(metadata, "remainingItemCount")
+	if next != nil {
+		fields[ContinueParam] = next.String()
+	}
+
+	body, err := json.Marshal(f.listResult(fields, items))
+	if err != nil {
+		return err
+	}
+	setBody(r, body)
+	return nil
+}
+
+// fetchChunk requests next chunk of list with continue token of apiserver
+func fetchChunk(req *http.Request, token string, transport http.RoundTripper) (*http.Response, error) {
+	chunkReq := req.Clone(req.Context())
+	query := chunkReq.URL.Query()
+	query.Set("continue", token)
+	chunkReq.URL.RawQuery = query.Encode()
+	resp, err := transport.RoundTrip(chunkReq)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("list next chunk failed: %v", resp.Status)
+	}
+	return resp, nil
+}
@@ -0,0 +1,336 @@
+/*
+Copyright 2024 Kubeworkz Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hotplug
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/registry"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hotplugv1 "github.com/saashqdev/kubeworkz/pkg/apis/hotplug/v1"
+	"github.com/saashqdev/kubeworkz/pkg/clog"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+	"github.com/saashqdev/kubeworkz/pkg/utils/env"
+)
+
+const (
+	defaultChartArchiveKey = "chart.tgz"
+	ociScheme              = "oci://"
+)
+
+// ChartLoader loads chart of component from local helmchartpkg directory,
+// helm http repository, oci registry, configmap or secret
+type ChartLoader struct {
+	Client   client.Reader
+	CacheDir string
+}
+
+func NewChartLoader(cli client.Reader) *ChartLoader {
+	return &ChartLoader{
+		Client:   cli,
+		CacheDir: env.HotplugChartCacheDir(),
+	}
+}
+
+// Load loads chart of component and verifies digest if pinned
+func (l *ChartLoader) Load(ctx context.Context, c hotplugv1.ComponentConfig) (*chart.Chart, error) {
+	if c.Chart == nil {
+		return loader.LoadFile(localChartPath(c.PkgName))
+	}
+
+	src := c.Chart
+	var (
+		data []byte
+		err  error
+	)
+	switch {
+	case src.ConfigMapRef != nil:
+		if err = checkArchiveNamespace(c, src.ConfigMapRef); err != nil {
+			return nil, err
+		}
+		data, err = l.archiveFromConfigMap(ctx, src.ConfigMapRef)
+	case src.SecretRef != nil:
+		if err = checkArchiveNamespace(c, src.SecretRef); err != nil {
+			return nil, err
+		}
+		data, err = l.archiveFromSecret(ctx, src.SecretRef)
+	case src.RepoURL != "":
+		data, err = l.archiveFromRepo(ctx, src)
+	default:
+		err = fmt.Errorf("chart of component %v has none of repoURL, configMapRef and secretRef", c.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = VerifyDigest(data, src.Digest); err != nil {
+		return nil, err
+	}
+
+	loaded, err := loader.LoadArchive(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	// record source and digest in chart metadata so that they are kept
+	// with release and compared with component later
+	if loaded.Metadata.Annotations == nil {
+		loaded.Metadata.Annotations = make(map[string]string)
+	}
+	loaded.Metadata.Annotations[constants.HotplugChartSourceAnnotation] = ChartSourceOf(src)
+	loaded.Metadata.Annotations[constants.HotplugChartDigestAnnotation] = archiveDigest(data)
+
+	return loaded, nil
+}
+
+// ChartSourceOf returns identity of where chart archive loaded from
+func ChartSourceOf(src *hotplugv1.ChartSource) string {
+	switch {
+	case src.ConfigMapRef != nil:
+		key := archiveObjectKey(src.ConfigMapRef)
+		return fmt.Sprintf("configmap://%v/%v/%v", key.Namespace, key.Name, archiveKey(src.ConfigMapRef))
+	case src.SecretRef != nil:
+		key := archiveObjectKey(src.SecretRef)
+		return fmt.Sprintf("secret://%v/%v/%v", key.Namespace, key.Name, archiveKey(src.SecretRef))
+	default:
+		return strings.TrimSuffix(src.RepoURL, "/") + "/" + src.Name
+	}
+}
+
+// checkArchiveNamespace only allows chart archive referred in namespace of
+// component or kubeworkz namespace, so that hotplug can not be used to read
+// configmaps and secrets of any namespace
+func checkArchiveNamespace(c hotplugv1.ComponentConfig, ref *hotplugv1.ChartArchiveReference) error {
+	namespace := archiveObjectKey(ref).Namespace
+	if namespace != env.KubeNamespace() && namespace != c.Namespace {
+		return fmt.Errorf("chart archive of component %v must be in namespace %v or %v, not %v",
+			c.Name, c.Namespace, env.KubeNamespace(), namespace)
+	}
+	return nil
+}
+
+// VerifyDigest checks sha256 digest of chart archive, empty digest is ignored
+func VerifyDigest(data []byte, digest string) error {
+	if digest == "" {
+		return nil
+	}
+	if actual := archiveDigest(data); actual != digest {
+		return fmt.Errorf("digest of chart archive mismatch, expect %v but got %v", digest, actual)
+	}
+	return nil
+}
+
+// ChartSatisfied returns true if chart of release is loaded from the same
+// source, matches pinned digest and satisfies version of chart source, so
+// that no upgrade needed for chart
+func ChartSatisfied(c hotplugv1.ComponentConfig, release *chart.Chart) bool {
+	if release == nil || release.Metadata == nil {
+		return true
+	}
+	annotations := release.Metadata.Annotations
+	if c.Chart == nil {
+		// chart from local helmchartpkg directory has no source recorded
+		return annotations[constants.HotplugChartSourceAnnotation] == ""
+	}
+	if annotations[constants.HotplugChartSourceAnnotation] != ChartSourceOf(c.Chart) {
+		return false
+	}
+	if c.Chart.Digest != "" && annotations[constants.HotplugChartDigestAnnotation] != c.Chart.Digest {
+		return false
+	}
+	if c.Chart.Name != "" && release.Metadata.Name != c.Chart.Name {
+		return false
+	}
+	if c.Chart.Version == "" {
+		return true
+	}
+	constraint, err := semver.NewConstraint(c.Chart.Version)
+	if err != nil {
+		return c.Chart.Version == release.Metadata.Version
+	}
+	v, err := semver.NewVersion(release.Metadata.Version)
+	if err != nil {
+		return false
+	}
+	return constraint.Check(v)
+}
+
+func archiveDigest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func (l *ChartLoader) archiveFromConfigMap(ctx context.Context, ref *hotplugv1.ChartArchiveReference) ([]byte, error) {
+	cm := corev1.ConfigMap{}
+	key := archiveObjectKey(ref)
+	if err := l.Client.Get(ctx, key, &cm); err != nil {
+		return nil, err
+	}
+	k := archiveKey(ref)
+	if data, ok := cm.BinaryData[k]; ok {
+		return data, nil
+	}
+	return nil, fmt.Errorf("key %v not found in binaryData of configmap %v", k, key)
+}
+
+func (l *ChartLoader) archiveFromSecret(ctx context.Context, ref *hotplugv1.ChartArchiveReference) ([]byte, error) {
+	secret := corev1.Secret{}
+	key := archiveObjectKey(ref)
+	if err := l.Client.Get(ctx, key, &secret); err != nil {
+		return nil, err
+	}
+	k := archiveKey(ref)
+	if data, ok := secret.Data[k]; ok {
+		return data, nil
+	}
+	return nil, fmt.Errorf("key %v not found in secret %v", k, key)
+}
+
+func archiveObjectKey(ref *hotplugv1.ChartArchiveReference) types.NamespacedName {
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = env.KubeNamespace()
+	}
+	return types.NamespacedName{Namespace: namespace, Name: ref.Name}
+}
+
+func archiveKey(ref *hotplugv1.ChartArchiveReference) string {
+	if ref.Key == "" {
+		return defaultChartArchiveKey
+	}
+	return ref.Key
+}
+
+// archiveFromRepo pulls chart archive from helm repository or oci registry.
+// Archive pinned by digest is read from cache without pulling, and the last
+// pulled archive is used when repository unreachable.
+func (l *ChartLoader) archiveFromRepo(ctx context.Context, src *hotplugv1.ChartSource) ([]byte, error) {
+	if src.Digest != "" {
+		if data, err := os.ReadFile(l.digestCachePath(src.Digest)); err == nil && VerifyDigest(data, src.Digest) == nil {
+			return data, nil
+		}
+	}
+
+	data, err := l.pull(ctx, src)
+	if err != nil {
+		last, readErr := os.ReadFile(l.lastCachePath(src))
+		if readErr != nil {
+			return nil, err
+		}
+		clog.Warn("pull chart %v from %v failed, use cached one: %v", src.Name, src.RepoURL, err)
+		return last, nil
+	}
+
+	if err = os.MkdirAll(l.CacheDir, 0755); err == nil {
+		if err = os.WriteFile(l.digestCachePath(archiveDigest(data)), data, 0644); err == nil {
+			err = os.WriteFile(l.lastCachePath(src), data, 0644)
+		}
+	}
+	if err != nil {
+		clog.Warn("cache chart %v failed: %v", src.Name, err)
+	}
+
+	return data, nil
+}
+
+func (l *ChartLoader) pull(ctx context.Context, src *hotplugv1.ChartSource) ([]byte, error) {
+	username, password, err := l.credentials(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := cli.New()
+	settings.RepositoryCache = filepath.Join(l.CacheDir, "repository")
+	settings.RepositoryConfig = filepath.Join(l.CacheDir, "repositories.yaml")
+	settings.RegistryConfig = filepath.Join(l.CacheDir, "registry.json")
+
+	registryClient, err := registry.NewClient(
+		registry.ClientOptCredentialsFile(settings.RegistryConfig),
+		registry.ClientOptWriter(io.Discard),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	install := action.NewInstall(&action.Configuration{})
+	install.SetRegistryClient(registryClient)
+	install.ChartPathOptions.Version = src.Version
+	install.ChartPathOptions.InsecureSkipTLSverify = src.InsecureSkipTLSVerify
+
+	name := src.Name
+	if strings.HasPrefix(src.RepoURL, ociScheme) {
+		name = strings.TrimSuffix(src.RepoURL, "/") + "/" + src.Name
+		if username != "" {
+			u, err := url.Parse(src.RepoURL)
+			if err != nil {
+				return nil, err
+			}
+			err = registryClient.Login(u.Host,
+				registry.LoginOptBasicAuth(username, password),
+				registry.LoginOptInsecure(src.InsecureSkipTLSVerify))
+			if err != nil {
+				return nil, fmt.Errorf("login registry %v failed: %v", u.Host, err)
+			}
+		}
+	} else {
+		install.ChartPathOptions.RepoURL = src.RepoURL
+		install.ChartPathOptions.Username = username
+		install.ChartPathOptions.Password = password
+	}
+
+	path, err := install.ChartPathOptions.LocateChart(name, settings)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
+
+func (l *ChartLoader) credentials(ctx context.Context, src *hotplugv1.ChartSource) (string, string, error) {
+	if src.CredentialsSecret == "" {
+		return "", "", nil
+	}
+	secret := corev1.Secret{}
+	err := l.Client.Get(ctx, types.NamespacedName{Namespace: env.KubeNamespace(), Name: src.CredentialsSecret}, &secret)
+	if err != nil {
+		return "", "", err
+	}
+	return string(secret.Data["username"]), string(secret.Data["password"]), nil
+}
+
+func (l *ChartLoader) digestCachePath(digest string) string {
+	return filepath.Join(l.CacheDir, strings.ReplaceAll(digest, ":", "-")+".tgz")
+}
+
+// lastCachePath returns path of archive last pulled for the same source
+func (l *ChartLoader) lastCachePath(src *hotplugv1.ChartSource) string {
+	sum := sha256.Sum256([]byte(src.RepoURL + "/" + src.Name + "@" + src.Version))
+	return filepath.Join(l.CacheDir, "last-"+hex.EncodeToString(sum[:8])+".tgz")
+}
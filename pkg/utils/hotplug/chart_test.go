@@ -0,0 +1,141 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hotplug_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	hotplugv1 "github.com/saashqdev/kubeworkz/pkg/apis/hotplug/v1"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+	"github.com/saashqdev/kubeworkz/pkg/utils/hotplug"
+)
+
+var _ = Describe("Chart", func() {
+
+	packageChart := func() []byte {
+		dir, err := os.MkdirTemp("", "chart-test")
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(os.RemoveAll, dir)
+		c := &chart.Chart{Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "demo", Version: "1.2.3"}}
+		file, err := chartutil.Save(c, dir)
+		Expect(err).NotTo(HaveOccurred())
+		data, err := os.ReadFile(file)
+		Expect(err).NotTo(HaveOccurred())
+		return data
+	}
+
+	It("test load chart from configmap with digest", func() {
+		data := packageChart()
+		sum := sha256.Sum256(data)
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo-chart", Namespace: "kubeworkz-system"},
+			BinaryData: map[string][]byte{"chart.tgz": data},
+		}
+		cli := fake.NewClientBuilder().WithObjects(cm).Build()
+		loader := &hotplug.ChartLoader{Client: cli, CacheDir: GinkgoT().TempDir()}
+
+		component := hotplugv1.ComponentConfig{
+			Name: "demo",
+			Chart: &hotplugv1.ChartSource{
+				ConfigMapRef: &hotplugv1.ChartArchiveReference{Namespace: "kubeworkz-system", Name: "demo-chart"},
+				Digest:       "sha256:" + hex.EncodeToString(sum[:]),
+			},
+		}
+		c, err := loader.Load(context.Background(), component)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Metadata.Name).To(Equal("demo"))
+		Expect(c.Metadata.Annotations).To(HaveKeyWithValue(constants.HotplugChartDigestAnnotation, component.Chart.Digest))
+		Expect(hotplug.ChartSatisfied(component, c)).To(BeTrue())
+
+		moved := component
+		moved.Chart = &hotplugv1.ChartSource{
+			ConfigMapRef: &hotplugv1.ChartArchiveReference{Namespace: "kubeworkz-system", Name: "demo-chart-v2"},
+		}
+		Expect(hotplug.ChartSatisfied(moved, c)).To(BeFalse())
+		Expect(hotplug.ChartSatisfied(hotplugv1.ComponentConfig{Name: "demo"}, c)).To(BeFalse())
+
+		component.Chart.Digest = "sha256:" + hex.EncodeToString(make([]byte, 32))
+		_, err = loader.Load(context.Background(), component)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("test load chart from configmap of other namespace", func() {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo-chart", Namespace: "default"},
+			BinaryData: map[string][]byte{"chart.tgz": packageChart()},
+		}
+		cli := fake.NewClientBuilder().WithObjects(cm).Build()
+		loader := &hotplug.ChartLoader{Client: cli, CacheDir: GinkgoT().TempDir()}
+
+		component := hotplugv1.ComponentConfig{
+			Name:      "demo",
+			Namespace: "demo-system",
+			Chart: &hotplugv1.ChartSource{
+				ConfigMapRef: &hotplugv1.ChartArchiveReference{Namespace: "default", Name: "demo-chart"},
+			},
+		}
+		_, err := loader.Load(context.Background(), component)
+		Expect(err).To(HaveOccurred())
+
+		component.Namespace = "default"
+		_, err = loader.Load(context.Background(), component)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("test chart satisfied", func() {
+		released := &chart.Chart{Metadata: &chart.Metadata{Name: "demo", Version: "1.2.3"}}
+		component := hotplugv1.ComponentConfig{Name: "demo"}
+		Expect(hotplug.ChartSatisfied(component, released)).To(BeTrue())
+
+		component.Chart = &hotplugv1.ChartSource{RepoURL: "https://charts.example.com", Name: "demo", Version: "~1.2.0"}
+		Expect(hotplug.ChartSatisfied(component, released)).To(BeFalse())
+
+		released.Metadata.Annotations = map[string]string{
+			constants.HotplugChartSourceAnnotation: "https://charts.example.com/demo",
+			constants.HotplugChartDigestAnnotation: "sha256:" + hex.EncodeToString(make([]byte, 32)),
+		}
+		Expect(hotplug.ChartSatisfied(component, released)).To(BeTrue())
+
+		component.Chart.Digest = "sha256:" + hex.EncodeToString(make([]byte, 32))
+		Expect(hotplug.ChartSatisfied(component, released)).To(BeTrue())
+
+		component.Chart.Digest = "sha256:" + hex.EncodeToString(append(make([]byte, 31), 1))
+		Expect(hotplug.ChartSatisfied(component, released)).To(BeFalse())
+
+		component.Chart = &hotplugv1.ChartSource{RepoURL: "oci://registry.example.com/charts", Name: "demo", Version: "~1.2.0"}
+		Expect(hotplug.ChartSatisfied(component, released)).To(BeFalse())
+
+		component.Chart = &hotplugv1.ChartSource{RepoURL: "https://charts.example.com", Name: "demo", Version: "~1.2.0"}
+
+		component.Chart.Version = ">=1.3.0"
+		Expect(hotplug.ChartSatisfied(component, released)).To(BeFalse())
+
+		component.Chart = &hotplugv1.ChartSource{RepoURL: "https://charts.example.com", Name: "other", Version: "1.2.3"}
+		Expect(hotplug.ChartSatisfied(component, released)).To(BeFalse())
+	})
+})
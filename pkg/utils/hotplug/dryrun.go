@@ -0,0 +1,131 @@
+/*
+Copyright 2024 Kubeworkz Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hotplug
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pmezard/go-difflib/difflib"
+
+	hotplugv1 "github.com/saashqdev/kubeworkz/pkg/apis/hotplug/v1"
+	"github.com/saashqdev/kubeworkz/pkg/utils/redact"
+)
+
+const (
+	// enabled is the status of component to be installed
+	enabled = "enabled"
+
+	ActionNone      = "none"
+	ActionInstall   = "install"
+	ActionUpgrade   = "upgrade"
+	ActionUninstall = "uninstall"
+)
+
+// ComponentPreview is the dry-run result of component
+type ComponentPreview struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// Status the desired status of component
+	Status string `json:"status"`
+	// Action the helm action would be taken, one of none, install, upgrade and uninstall
+	Action string `json:"action"`
+	// CurrentRevision the revision of current release, 0 if not installed
+	CurrentRevision int `json:"currentRevision,omitempty"`
+	// Values the merged values of component
+	Values string `json:"values,omitempty"`
+	// Diff the unified diff from manifest of current release to rendered one
+	Diff string `json:"diff,omitempty"`
+	// Error is set when component can not be rendered
+	Error string `json:"error,omitempty"`
+}
+
+// Preview renders components by helm dry-run against cluster of helm and
+// diffs rendered manifests with current releases, nothing is changed.
+func Preview(ctx context.Context, helm *Helm, charts *ChartLoader, components []hotplugv1.ComponentConfig) []ComponentPreview {
+	previews := make([]ComponentPreview, 0, len(components))
+	for _, c := range components {
+		preview := ComponentPreview{Name: c.Name, Namespace: c.Namespace, Status: c.Status, Values: c.Env}
+		secrets := redact.New()
+		if err := previewComponent(ctx, helm, charts, c, &preview, secrets); err != nil {
+			preview.Error = secrets.String(err.Error())
+		}
+		preview.Diff = secrets.String(preview.Diff)
+		previews = append(previews, preview)
+	}
+	return previews
+}
+
+func previewComponent(ctx context.Context, helm *Helm, charts *ChartLoader, c hotplugv1.ComponentConfig, preview *ComponentPreview, secrets *redact.Redactor) error {
+	current, err := ReleaseOf(helm, c)
+	if err != nil {
+		return fmt.Errorf("get release fail, %v", err)
+	}
+	currentManifest := ""
+	if current != nil {
+		currentManifest = current.Manifest
+		preview.CurrentRevision = current.Version
+	}
+
+	desiredManifest := ""
+	switch {
+	case c.Status != enabled && current == nil:
+		preview.Action = ActionNone
+	case c.Status != enabled:
+		preview.Action = ActionUninstall
+	default:
+		envs, err := ResolveValues(ctx, charts.Client, c, secrets)
+		if err != nil {
+			return err
+		}
+		chart, err := charts.Load(ctx, c)
+		if err != nil {
+			return fmt.Errorf("load chart fail, %v", err)
+		}
+		rendered, err := helm.DryRun(c.Namespace, c.Name, chart, envs, current != nil)
+		if err != nil {
+			return fmt.Errorf("helm dry-run fail, %v", err)
+		}
+		desiredManifest = rendered.Manifest
+		preview.Action = ActionInstall
+		if current != nil {
+			preview.Action = ActionUpgrade
+			values, err := helm.GetValues(c.Namespace, c.Name)
+			if err != nil {
+				return fmt.Errorf("helm get values fail, %v", err)
+			}
+			if JudgeJsonEqual(envs, values) && currentManifest == desiredManifest {
+				preview.Action = ActionNone
+			}
+		}
+	}
+
+	diff, err := ManifestDiff(currentManifest, desiredManifest)
+	if err != nil {
+		return err
+	}
+	preview.Diff = diff
+	return nil
+}
+
+// ManifestDiff returns unified diff from current manifest to desired one
+func ManifestDiff(current, desired string) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(current),
+		B:        difflib.SplitLines(desired),
+		FromFile: "current",
+		ToFile:   "desired",
+		Context:  3,
+	})
+}
@@ -0,0 +1,38 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hotplug_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/saashqdev/kubeworkz/pkg/utils/hotplug"
+)
+
+var _ = Describe("DryRun", func() {
+
+	It("test manifest diff", func() {
+		diff, err := hotplug.ManifestDiff("a: 1\nb: 2\n", "a: 1\nb: 3\n")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(diff).To(ContainSubstring("-b: 2"))
+		Expect(diff).To(ContainSubstring("+b: 3"))
+
+		diff, err = hotplug.ManifestDiff("a: 1\n", "a: 1\n")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(diff).To(BeEmpty())
+	})
+})
@@ -17,15 +17,18 @@ limitations under the License.
 package hotplug
 
 import (
-	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
+	"sort"
 
+	hotplugv1 "github.com/saashqdev/kubeworkz/pkg/apis/hotplug/v1"
 	"github.com/saashqdev/kubeworkz/pkg/clog"
 	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/release"
-	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"helm.sh/helm/v3/pkg/storage/driver"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/util/homedir"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -36,6 +39,14 @@ type Helm struct {
 	ActionConfig map[string]*action.Configuration
 }
 
+// NewHelmForConfig creates helm operating cluster of given config
+func NewHelmForConfig(config *rest.Config) *Helm {
+	return &Helm{
+		K8sConfig:    config,
+		ActionConfig: make(map[string]*action.Configuration),
+	}
+}
+
 func NewHelm() *Helm {
 
 	config, err := ctrl.GetConfig()
@@ -56,11 +67,7 @@ func (h *Helm) GetActionConfig(namespace string) (*action.Configuration, error)
 	}
 	// init action config
 	actionConfig := new(action.Configuration)
-	kubeConfig := genericclioptions.NewConfigFlags(false)
-	kubeConfig.APIServer = &h.K8sConfig.Host
-	kubeConfig.BearerToken = &h.K8sConfig.BearerToken
-	kubeConfig.CAFile = &h.K8sConfig.CAFile
-	kubeConfig.Namespace = &namespace
+	kubeConfig := newRESTClientGetter(h.K8sConfig, namespace)
 	log := clog.WithName("hotplug-helm")
 	err := actionConfig.Init(kubeConfig, namespace, os.Getenv("HELM_DRIVER"), log.Info)
 	if err != nil {
@@ -103,18 +110,16 @@ func (h *Helm) GetValues(namespace string, name string) (map[string]interface{},
 
 // helm install
 func (h *Helm) Install(namespace, name, pkgName string, envs map[string]interface{}) (*release.Release, error) {
-	actionConfig, err := h.GetActionConfig(namespace)
+	c, err := loader.LoadFile(localChartPath(pkgName))
 	if err != nil {
 		return nil, err
 	}
-	file := ""
-	if home := homedir.HomeDir(); home != "" {
-		file = filepath.Join(home, "helmchartpkg", pkgName)
-	} else {
-		currentPath, _ := os.Getwd()
-		file = filepath.Join(currentPath, "helmchartpkg", pkgName)
-	}
-	c, err := loader.LoadFile(file)
+	return h.InstallChart(namespace, name, c, envs)
+}
+
+// helm install with loaded chart
+func (h *Helm) InstallChart(namespace, name string, c *chart.Chart, envs map[string]interface{}) (*release.Release, error) {
+	actionConfig, err := h.GetActionConfig(namespace)
 	if err != nil {
 		return nil, err
 	}
@@ -124,7 +129,8 @@ func (h *Helm) Install(namespace, name, pkgName string, envs map[string]interfac
 	installAction.CreateNamespace = true
 	relaese, err := installAction.Run(c, envs)
 	if err != nil {
-		clog.Info("can not install the release, %v", err)
+		// error may carry values, leave it to be logged by caller after redacted
+		clog.Info("can not install the release %v", name)
 		return nil, err
 	}
 	clog.Info("install the release success: %s", relaese.Name)
@@ -133,34 +139,92 @@ func (h *Helm) Install(namespace, name, pkgName string, envs map[string]interfac
 
 // helm upgrade
 func (h *Helm) Upgrade(namespace, name, pkgName string, envs map[string]interface{}) (*release.Release, error) {
-	actionConfig, err := h.GetActionConfig(namespace)
+	c, err := loader.LoadFile(localChartPath(pkgName))
 	if err != nil {
 		return nil, err
 	}
-	file := ""
-	if home := homedir.HomeDir(); home != "" {
-		file = filepath.Join(home, "helmchartpkg", pkgName)
-	} else {
-		currentPath, _ := os.Getwd()
-		file = filepath.Join(currentPath, "helmchartpkg", pkgName)
-	}
-	c, err := loader.LoadFile(file)
+	return h.UpgradeChart(namespace, name, c, envs)
+}
+
+// helm upgrade with loaded chart
+func (h *Helm) UpgradeChart(namespace, name string, c *chart.Chart, envs map[string]interface{}) (*release.Release, error) {
+	actionConfig, err := h.GetActionConfig(namespace)
 	if err != nil {
 		return nil, err
 	}
 	upgradeAction := action.NewUpgrade(actionConfig)
 	upgradeAction.Namespace = namespace
 	relaese, err := upgradeAction.Run(name, c, envs)
-	e, _ := json.Marshal(envs)
-	clog.Info("%v", string(e))
 	if err != nil {
-		clog.Info("can not upgrade the release, %v", err)
+		// error may carry values, leave it to be logged by caller after redacted
+		clog.Info("can not upgrade the release %v", name)
 		return nil, err
 	}
 	clog.Info("upgrade the release success: %v", relaese.Name)
 	return relaese, err
 }
 
+// localChartPath returns path of chart package in helmchartpkg directory
+func localChartPath(pkgName string) string {
+	if home := homedir.HomeDir(); home != "" {
+		return filepath.Join(home, "helmchartpkg", pkgName)
+	}
+	currentPath, _ := os.Getwd()
+	return filepath.Join(currentPath, "helmchartpkg", pkgName)
+}
+
+// helm history, newest revision first
+func (h *Helm) History(namespace string, name string, max int) ([]*release.Release, error) {
+	actionConfig, err := h.GetActionConfig(namespace)
+	if err != nil {
+		return nil, err
+	}
+	historyAction := action.NewHistory(actionConfig)
+	historyAction.Max = max
+	releases, err := historyAction.Run(name)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(releases, func(i, j int) bool { return releases[i].Version > releases[j].Version })
+	return releases, nil
+}
+
+// helm rollback
+func (h *Helm) Rollback(namespace string, name string, revision int) error {
+	actionConfig, err := h.GetActionConfig(namespace)
+	if err != nil {
+		return err
+	}
+	rollbackAction := action.NewRollback(actionConfig)
+	rollbackAction.Version = revision
+	if err = rollbackAction.Run(name); err != nil {
+		clog.Info("can not rollback the release %v to revision %v, %v", name, revision, err)
+		return err
+	}
+	clog.Info("rollback the release %v to revision %v success", name, revision)
+	return nil
+}
+
+// helm install/upgrade --dry-run, release is not changed
+func (h *Helm) DryRun(namespace, name string, c *chart.Chart, envs map[string]interface{}, upgrade bool) (*release.Release, error) {
+	actionConfig, err := h.GetActionConfig(namespace)
+	if err != nil {
+		return nil, err
+	}
+	if upgrade {
+		upgradeAction := action.NewUpgrade(actionConfig)
+		upgradeAction.Namespace = namespace
+		upgradeAction.DryRun = true
+		return upgradeAction.Run(name, c, envs)
+	}
+	installAction := action.NewInstall(actionConfig)
+	installAction.ReleaseName = name
+	installAction.Namespace = namespace
+	installAction.DryRun = true
+	installAction.Replace = true
+	return installAction.Run(c, envs)
+}
+
 // helm uninstall
 func (h *Helm) Uninstall(namespace string, name string) error {
 	actionConfig, err := h.GetActionConfig(namespace)
@@ -176,3 +240,19 @@ func (h *Helm) Uninstall(namespace string, name string) error {
 	clog.Info("uninstall the release success: %v", relaese.Info)
 	return nil
 }
+
+// ReleaseOf returns the release of component, nil if not installed
+func ReleaseOf(helm *Helm, c hotplugv1.ComponentConfig) (*release.Release, error) {
+	r, err := helm.Status(c.Namespace, c.Name)
+	if err != nil {
+		if !errors.Is(err, driver.ErrReleaseNotFound) {
+			return nil, err
+		}
+		return nil, nil
+	}
+	// any way we found release about chart, we think it exists
+	if r == nil || r.Info.Status == release.StatusUninstalled {
+		return nil, nil
+	}
+	return r, nil
+}
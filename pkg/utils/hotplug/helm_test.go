@@ -36,7 +36,7 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 
 	"github.com/saashqdev/kubeworkz/pkg/clog"
-	"github.com/saashqdev/kubeworkz/pkg/warden/localmgr/controllers/hotplug"
+	"github.com/saashqdev/kubeworkz/pkg/utils/hotplug"
 )
 
 var _ = Describe("Helm", func() {
@@ -0,0 +1,197 @@
+/*
+Copyright 2024 Kubeworkz Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hotplug
+
+import (
+	"bytes"
+	"encoding/json"
+	"text/template"
+
+	k8syaml "sigs.k8s.io/yaml"
+
+	hotplugv1 "github.com/saashqdev/kubeworkz/pkg/apis/hotplug/v1"
+	"github.com/saashqdev/kubeworkz/pkg/clog"
+)
+
+// translate string to json
+func YamlStringToJson(yamlStr string) (map[string]interface{}, error) {
+	b, err := k8syaml.YAMLToJSON([]byte(yamlStr))
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	err = json.Unmarshal(b, &m)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// merge json
+func MergeJson(a, b map[string]interface{}) map[string]interface{} {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	for key, bVal := range b {
+		if aVal, ok := a[key]; ok {
+			aMap, ok1 := aVal.(map[string]interface{})
+			bMap, ok2 := bVal.(map[string]interface{})
+			if ok1 && ok2 {
+				bVal = MergeJson(aMap, bMap)
+			}
+		}
+		if bVal != nil {
+			a[key] = bVal
+		}
+	}
+	return a
+}
+
+// merge yaml string
+func MergeYamlString(ayaml, byaml string) (string, error) {
+	ajson, err := YamlStringToJson(ayaml)
+	if err != nil {
+		return ayaml, err
+	}
+	bjson, err := YamlStringToJson(byaml)
+	if err != nil {
+		return ayaml, err
+	}
+	ret := MergeJson(ajson, bjson)
+	retb, err := k8syaml.Marshal(ret)
+	if err != nil {
+		return ayaml, err
+	}
+	return string(retb), nil
+}
+
+// MergeHotplugForCluster use clusterConfig to cover commonConfig and renders
+// env with given cluster name, so that it can be used out of warden.
+func MergeHotplugForCluster(commonConfig, clusterConfig hotplugv1.Hotplug, clusterName string) hotplugv1.Hotplug {
+	clusterComponentMap := make(map[string]hotplugv1.ComponentConfig)
+	for _, item := range clusterConfig.Spec.Component {
+		clusterComponentMap[item.Name] = item
+	}
+	var componentArr []hotplugv1.ComponentConfig
+	for _, i := range commonConfig.Spec.Component {
+		j, ok := clusterComponentMap[i.Name]
+		if !ok {
+			i.Env = convertYaml(i.Env, clusterName)
+			componentArr = append(componentArr, i)
+			continue
+		}
+		if j.Namespace != "" {
+			i.Namespace = j.Namespace
+		}
+		if j.Status != "" {
+			i.Status = j.Status
+		}
+		if j.PkgName != "" {
+			i.PkgName = j.PkgName
+		}
+		if len(j.ValuesFrom) > 0 {
+			// values from cluster config are merged after common ones
+			i.ValuesFrom = append(append([]hotplugv1.ValuesReference{}, i.ValuesFrom...), j.ValuesFrom...)
+		}
+		if j.Chart != nil {
+			i.Chart = j.Chart
+		}
+		if len(j.DependsOn) > 0 {
+			i.DependsOn = j.DependsOn
+		}
+		if j.Priority != 0 {
+			i.Priority = j.Priority
+		}
+		if j.Readiness != nil {
+			i.Readiness = j.Readiness
+		}
+		if j.DisableAutoRollback != nil {
+			i.DisableAutoRollback = j.DisableAutoRollback
+		}
+		if j.Env != "" {
+			env, err := MergeYamlString(i.Env, j.Env)
+			if err != nil {
+				clog.Info("can not merge cluster config env to common config env, %s", i.Name)
+				continue
+			}
+			i.Env = env
+		}
+		i.Env = convertYaml(i.Env, clusterName)
+		componentArr = append(componentArr, i)
+	}
+	commonConfig.Spec.Component = componentArr
+	return commonConfig
+}
+
+// judge json equal
+func JudgeJsonEqual(a interface{}, b interface{}) bool {
+	am, aok := a.(map[string]interface{})
+	bm, bok := b.(map[string]interface{})
+	if !aok || !bok {
+		aj, err := json.Marshal(a)
+		if err != nil {
+			return false
+		}
+		bj, err := json.Marshal(b)
+		if err != nil {
+			return false
+		}
+		return string(aj) == string(bj)
+	}
+	for k, av := range am {
+		if bv, ok := bm[k]; ok {
+			ret := JudgeJsonEqual(av, bv)
+			if !ret {
+				return false
+			}
+		} else {
+			return false
+		}
+	}
+	for k, bv := range bm {
+		if av, ok := am[k]; ok {
+			ret := JudgeJsonEqual(bv, av)
+			if !ret {
+				return false
+			}
+		} else {
+			return false
+		}
+	}
+	return true
+}
+
+// replace {{.cluster}} to real clusterName
+func convertYaml(yamlStr, clusterName string) string {
+	if yamlStr == "" {
+		return ""
+	}
+	templateParams := make(map[string]string)
+	templateParams["cluster"] = clusterName
+	tmpl, err := template.New("env").Parse(yamlStr)
+	if err != nil {
+		clog.Info("can not parse env %v, %v", yamlStr, err)
+		return yamlStr
+	}
+	var b1 bytes.Buffer
+	err = tmpl.Execute(&b1, templateParams)
+	if err != nil {
+		clog.Info("can not parse env and set clusterName, %v, %v", yamlStr, err)
+		return yamlStr
+	}
+	return b1.String()
+}
@@ -23,10 +23,10 @@ import (
 	. "github.com/onsi/gomega"
 
 	hotplugv1 "github.com/saashqdev/kubeworkz/pkg/apis/hotplug/v1"
-	"github.com/saashqdev/kubeworkz/pkg/warden/localmgr/controllers/hotplug"
+	"github.com/saashqdev/kubeworkz/pkg/utils/hotplug"
 )
 
-var _ = Describe("Util", func() {
+var _ = Describe("Merge", func() {
 
 	It("test yanl string to json", func() {
 		yamlStr := `
@@ -145,7 +145,7 @@ d:
 				},
 			},
 		}
-		c := hotplug.MergeHotplug(c1, c2)
+		c := hotplug.MergeHotplugForCluster(c1, c2, "pivot-cluster")
 		Expect(len(c.Spec.Component)).To(Equal(2))
 		for _, c := range c.Spec.Component {
 			switch c.Name {
@@ -161,6 +161,32 @@ d:
 		}
 	})
 
+	It("test merging auto rollback of cluster over common", func() {
+		disabled, enabled := true, false
+		common := hotplugv1.Hotplug{
+			Spec: hotplugv1.HotplugSpec{
+				Component: []hotplugv1.ComponentConfig{
+					{Name: "a", Status: "enabled", DisableAutoRollback: &disabled},
+					{Name: "b", Status: "enabled", DisableAutoRollback: &disabled},
+					{Name: "c", Status: "enabled"},
+				},
+			},
+		}
+		cluster := hotplugv1.Hotplug{
+			Spec: hotplugv1.HotplugSpec{
+				Component: []hotplugv1.ComponentConfig{
+					{Name: "a", DisableAutoRollback: &enabled},
+					{Name: "b"},
+					{Name: "c", DisableAutoRollback: &disabled},
+				},
+			},
+		}
+		merged := hotplug.MergeHotplugForCluster(common, cluster, "pivot-cluster")
+		Expect(merged.Spec.Component[0].AutoRollbackDisabled()).To(BeFalse())
+		Expect(merged.Spec.Component[1].AutoRollbackDisabled()).To(BeTrue())
+		Expect(merged.Spec.Component[2].AutoRollbackDisabled()).To(BeTrue())
+	})
+
 	It("test judge json equal", func() {
 		json1 := "{\"a\": {\"aa\": \"aa\"}, \"b\": [{\"bb\": \"bb\"}], \"c\": {\"cc\": {\"ccc\": \"ccc1\"}}}"
 		json2 := "{\"a\": {\"ab\": \"ab\"}, \"c\": {\"cc\": {\"ccc\": \"ccc2\"}}, \"d\": {\"dd\": \"dd\"}}"
@@ -0,0 +1,147 @@
+/*
+Copyright 2024 Kubeworkz Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hotplug
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierros "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hotplugv1 "github.com/saashqdev/kubeworkz/pkg/apis/hotplug/v1"
+	"github.com/saashqdev/kubeworkz/pkg/utils/env"
+	"github.com/saashqdev/kubeworkz/pkg/utils/redact"
+)
+
+// ResolveValues builds values of component from Env and ValuesFrom. Values
+// read from secrets are added to redactor, which should be applied to any
+// message derived from values before it is logged or written to status.
+func ResolveValues(ctx context.Context, cli client.Reader, c hotplugv1.ComponentConfig, redactor *redact.Redactor) (map[string]interface{}, error) {
+	values, err := YamlStringToJson(c.Env)
+	if err != nil {
+		return nil, fmt.Errorf("parse env yaml fail, %v", err)
+	}
+	if values == nil {
+		values = make(map[string]interface{})
+	}
+
+	for _, ref := range c.ValuesFrom {
+		key := ValuesSecretKey(ref)
+		namespace := key.Namespace
+		if namespace != env.KubeNamespace() && namespace != c.Namespace {
+			return nil, fmt.Errorf("values secret %v of component %v must be in namespace %v or %v",
+				key, c.Name, c.Namespace, env.KubeNamespace())
+		}
+		secret := corev1.Secret{}
+		err = cli.Get(ctx, key, &secret)
+		if err != nil {
+			if apierros.IsNotFound(err) && ref.Optional {
+				continue
+			}
+			return nil, fmt.Errorf("get secret %v/%v fail, %v", namespace, ref.Name, err)
+		}
+		data, ok := secret.Data[ref.Key]
+		if !ok {
+			if ref.Optional {
+				continue
+			}
+			return nil, fmt.Errorf("key %v not found in secret %v/%v", ref.Key, namespace, ref.Name)
+		}
+
+		if ref.TargetPath == "" {
+			v, err := YamlStringToJson(string(data))
+			if err != nil {
+				return nil, fmt.Errorf("parse values of secret %v/%v key %v fail", namespace, ref.Name, ref.Key)
+			}
+			addLeafValues(redactor, v)
+			values = MergeJson(values, v)
+			continue
+		}
+
+		redactor.Add(string(data))
+		if err = SetValue(values, ref.TargetPath, string(data)); err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+// ValuesSecretKey returns key of secret referred by values reference
+func ValuesSecretKey(ref hotplugv1.ValuesReference) types.NamespacedName {
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = env.KubeNamespace()
+	}
+	return types.NamespacedName{Namespace: namespace, Name: ref.Name}
+}
+
+// ReferencesSecret returns true if values of any component of hotplug are
+// read from given secret
+func ReferencesSecret(hotplug hotplugv1.Hotplug, key types.NamespacedName) bool {
+	for _, c := range hotplug.Spec.Component {
+		for _, ref := range c.ValuesFrom {
+			if ValuesSecretKey(ref) == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SetValue sets value at dot separated path of values, intermediate maps
+// are created if missing
+func SetValue(values map[string]interface{}, path string, value interface{}) error {
+	keys := strings.Split(path, ".")
+	m := values
+	for i, k := range keys {
+		if k == "" {
+			return fmt.Errorf("invalid values path %v", path)
+		}
+		if i == len(keys)-1 {
+			m[k] = value
+			return nil
+		}
+		next, ok := m[k].(map[string]interface{})
+		if !ok {
+			if _, exist := m[k]; exist {
+				return fmt.Errorf("values path %v conflicts at %v", path, k)
+			}
+			next = make(map[string]interface{})
+			m[k] = next
+		}
+		m = next
+	}
+	return nil
+}
+
+// addLeafValues records all string leaves of values read from secret
+func addLeafValues(redactor *redact.Redactor, v interface{}) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for _, item := range val {
+			addLeafValues(redactor, item)
+		}
+	case []interface{}:
+		for _, item := range val {
+			addLeafValues(redactor, item)
+		}
+	case string:
+		redactor.Add(val)
+	}
+}
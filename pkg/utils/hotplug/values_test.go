@@ -0,0 +1,129 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hotplug_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	hotplugv1 "github.com/saashqdev/kubeworkz/pkg/apis/hotplug/v1"
+	"github.com/saashqdev/kubeworkz/pkg/utils/hotplug"
+	"github.com/saashqdev/kubeworkz/pkg/utils/redact"
+)
+
+var _ = Describe("Values", func() {
+
+	It("test resolve values from secrets", func() {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "kubeworkz-system"},
+			Data: map[string][]byte{
+				"password": []byte("s3cr3t-password"),
+				"values":   []byte("storage:\n  accessKey: my-access-key\n"),
+			},
+		}
+		cli := fake.NewClientBuilder().WithObjects(secret).Build()
+		component := hotplugv1.ComponentConfig{
+			Name: "audit",
+			Env:  "database:\n  host: mysql\n",
+			ValuesFrom: []hotplugv1.ValuesReference{
+				{Namespace: "kubeworkz-system", Name: "db", Key: "password", TargetPath: "database.password"},
+				{Namespace: "kubeworkz-system", Name: "db", Key: "values"},
+				{Namespace: "kubeworkz-system", Name: "missing", Key: "any", Optional: true},
+			},
+		}
+
+		secrets := redact.New()
+		values, err := hotplug.ResolveValues(context.Background(), cli, component, secrets)
+		Expect(err).NotTo(HaveOccurred())
+		database := values["database"].(map[string]interface{})
+		Expect(database["host"]).To(Equal("mysql"))
+		Expect(database["password"]).To(Equal("s3cr3t-password"))
+		Expect(values["storage"].(map[string]interface{})["accessKey"]).To(Equal("my-access-key"))
+
+		message := secrets.String("install fail with s3cr3t-password and my-access-key on mysql")
+		Expect(message).To(Equal("install fail with ****** and ****** on mysql"))
+
+		component.ValuesFrom = []hotplugv1.ValuesReference{{Namespace: "kubeworkz-system", Name: "db", Key: "missing"}}
+		_, err = hotplug.ResolveValues(context.Background(), cli, component, redact.New())
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("test redact only string values from secrets", func() {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "kubeworkz-system"},
+			Data:       map[string][]byte{"values": []byte("database:\n  password: s3cr3t\n  port: 3306\n  tls: true\n")},
+		}
+		cli := fake.NewClientBuilder().WithObjects(secret).Build()
+		component := hotplugv1.ComponentConfig{
+			Name:       "audit",
+			ValuesFrom: []hotplugv1.ValuesReference{{Name: "db", Key: "values"}},
+		}
+
+		secrets := redact.New()
+		_, err := hotplug.ResolveValues(context.Background(), cli, component, secrets)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(secrets.String("password s3cr3t on port 3306 with tls true")).To(Equal("password ****** on port 3306 with tls true"))
+	})
+
+	It("test resolve values from secret of other namespace", func() {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+			Data:       map[string][]byte{"password": []byte("s3cr3t")},
+		}
+		cli := fake.NewClientBuilder().WithObjects(secret).Build()
+		component := hotplugv1.ComponentConfig{
+			Name:      "audit",
+			Namespace: "audit-system",
+			ValuesFrom: []hotplugv1.ValuesReference{
+				{Namespace: "default", Name: "db", Key: "password", TargetPath: "database.password"},
+			},
+		}
+		_, err := hotplug.ResolveValues(context.Background(), cli, component, redact.New())
+		Expect(err).To(HaveOccurred())
+
+		component.Namespace = "default"
+		values, err := hotplug.ResolveValues(context.Background(), cli, component, redact.New())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(values["database"].(map[string]interface{})["password"]).To(Equal("s3cr3t"))
+	})
+
+	It("test hotplug references secret", func() {
+		hp := hotplugv1.Hotplug{
+			Spec: hotplugv1.HotplugSpec{
+				Component: []hotplugv1.ComponentConfig{
+					{Name: "audit", ValuesFrom: []hotplugv1.ValuesReference{{Name: "db", Key: "password"}}},
+				},
+			},
+		}
+		Expect(hotplug.ReferencesSecret(hp, types.NamespacedName{Namespace: "kubeworkz-system", Name: "db"})).To(BeTrue())
+		Expect(hotplug.ReferencesSecret(hp, types.NamespacedName{Namespace: "default", Name: "db"})).To(BeFalse())
+	})
+
+	It("test set value by path", func() {
+		values := map[string]interface{}{"a": "b"}
+		Expect(hotplug.SetValue(values, "x.y.z", "v")).To(Succeed())
+		Expect(values["x"].(map[string]interface{})["y"].(map[string]interface{})["z"]).To(Equal("v"))
+		Expect(hotplug.SetValue(values, "a.b", "v")).NotTo(Succeed())
+		Expect(hotplug.SetValue(values, "a..b", "v")).NotTo(Succeed())
+	})
+})
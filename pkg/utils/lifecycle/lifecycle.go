@@ -0,0 +1,142 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lifecycle
+
+import (
+	"encoding/json"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// PhaseAnnotation records lifecycle phase of namespace created by kubeworkz
+	PhaseAnnotation = "kubeworkz.io/phase"
+
+	// MessageAnnotation records details of phase
+	MessageAnnotation = "kubeworkz.io/phase-message"
+
+	// ExpireAtAnnotation records the time when namespace will be deleted
+	ExpireAtAnnotation = "kubeworkz.io/expire-at"
+
+	// ExpireWarnedAnnotation records the expire time which was warned
+	ExpireWarnedAnnotation = "kubeworkz.io/expire-warned"
+
+	// PendingQuotaAnnotation carries resource quota to be created in namespace
+	PendingQuotaAnnotation = "kubeworkz.io/pending-resourcequota"
+)
+
+// Phase is the lifecycle phase of namespace
+type Phase string
+
+const (
+	// PhasePending means resources of namespace are being provisioned
+	PhasePending Phase = "Pending"
+
+	// PhaseReady means quota created and rbac spread into namespace
+	PhaseReady Phase = "Ready"
+
+	// PhaseFailed means provision of namespace failed
+	PhaseFailed Phase = "Failed"
+
+	// PhaseExpiring means namespace will be deleted soon
+	PhaseExpiring Phase = "Expiring"
+)
+
+// Status is the lifecycle status of namespace
+type Status struct {
+	Namespace string     `json:"namespace"`
+	Phase     Phase      `json:"phase"`
+	Message   string     `json:"message,omitempty"`
+	ExpireAt  *time.Time `json:"expireAt,omitempty"`
+}
+
+// StatusOf reads lifecycle status from annotations of namespace, namespaces
+// not managed by lifecycle are considered ready
+func StatusOf(ns *corev1.Namespace) Status {
+	s := Status{Namespace: ns.Name, Phase: PhaseReady}
+	if v, ok := ns.Annotations[PhaseAnnotation]; ok {
+		s.Phase = Phase(v)
+	}
+	s.Message = ns.Annotations[MessageAnnotation]
+	s.ExpireAt = ExpireAt(ns)
+	return s
+}
+
+// ExpireAt returns expire time of namespace, nil if never expires
+func ExpireAt(ns *corev1.Namespace) *time.Time {
+	v, ok := ns.Annotations[ExpireAtAnnotation]
+	if !ok {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+// SetExpireAt sets expire time of namespace, clears expire time if t is nil
+func SetExpireAt(ns *corev1.Namespace, t *time.Time) {
+	if t == nil {
+		delete(ns.Annotations, ExpireAtAnnotation)
+		return
+	}
+	setAnnotation(ns, ExpireAtAnnotation, t.UTC().Format(time.RFC3339))
+}
+
+// SetPhase sets phase and message of namespace
+func SetPhase(ns *corev1.Namespace, phase Phase, message string) {
+	setAnnotation(ns, PhaseAnnotation, string(phase))
+	if len(message) == 0 {
+		delete(ns.Annotations, MessageAnnotation)
+		return
+	}
+	setAnnotation(ns, MessageAnnotation, message)
+}
+
+// SetPendingQuota stores resource quota into namespace which will be created
+// by warden asynchronously
+func SetPendingQuota(ns *corev1.Namespace, quota *corev1.ResourceQuota) error {
+	b, err := json.Marshal(quota)
+	if err != nil {
+		return err
+	}
+	setAnnotation(ns, PendingQuotaAnnotation, string(b))
+	return nil
+}
+
+// PendingQuota returns resource quota waiting to be created, nil if none
+func PendingQuota(ns *corev1.Namespace) (*corev1.ResourceQuota, error) {
+	v, ok := ns.Annotations[PendingQuotaAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	quota := &corev1.ResourceQuota{}
+	err := json.Unmarshal([]byte(v), quota)
+	if err != nil {
+		return nil, err
+	}
+	return quota, nil
+}
+
+func setAnnotation(ns *corev1.Namespace, key, value string) {
+	if ns.Annotations == nil {
+		ns.Annotations = make(map[string]string)
+	}
+	ns.Annotations[key] = value
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redact
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+const (
+	// Mask replaces sensitive values
+	Mask = "******"
+
+	// minLength is the min length of value to be redacted, shorter values
+	// would mask too much of unrelated text
+	minLength = 4
+)
+
+// Redactor masks known sensitive values and their base64 encodings in text
+type Redactor struct {
+	values []string
+}
+
+func New(values ...string) *Redactor {
+	r := &Redactor{}
+	r.Add(values...)
+	return r
+}
+
+// Add records sensitive values to be masked
+func (r *Redactor) Add(values ...string) {
+	for _, v := range values {
+		if len(v) < minLength {
+			continue
+		}
+		r.values = append(r.values, v, base64.StdEncoding.EncodeToString([]byte(v)))
+	}
+	// replace longer values first so that overlapping ones are masked entirely
+	sort.Slice(r.values, func(i, j int) bool { return len(r.values[i]) > len(r.values[j]) })
+}
+
+// String masks sensitive values in s, nil redactor returns s as it is
+func (r *Redactor) String(s string) string {
+	if r == nil {
+		return s
+	}
+	for _, v := range r.values {
+		s = strings.ReplaceAll(s, v, Mask)
+	}
+	return s
+}
+
+// SecretJSON masks values of data and stringData of Secret or items of
+// SecretList in json, input is returned as it is if not json object
+func SecretJSON(s string) string {
+	obj := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(s), &obj); err != nil {
+		return s
+	}
+
+	maskSecret(obj)
+	if items, ok := obj["items"].([]interface{}); ok {
+		for _, item := range items {
+			if m, ok := item.(map[string]interface{}); ok {
+				maskSecret(m)
+			}
+		}
+	}
+
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return s
+	}
+	return string(b)
+}
+
+func maskSecret(obj map[string]interface{}) {
+	for _, field := range []string{"data", "stringData"} {
+		data, ok := obj[field].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for k := range data {
+			data[k] = Mask
+		}
+	}
+	// last-applied-configuration annotation holds data as well
+	if metadata, ok := obj["metadata"].(map[string]interface{}); ok {
+		if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+			if _, ok := annotations["kubectl.kubernetes.io/last-applied-configuration"]; ok {
+				annotations["kubectl.kubernetes.io/last-applied-configuration"] = Mask
+			}
+		}
+	}
+}
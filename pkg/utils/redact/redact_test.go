@@ -0,0 +1,59 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redact
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactorString(t *testing.T) {
+	r := New("s3cr3t-password", "abc")
+	msg := "connect failed with password s3cr3t-password, encoded czNjcjN0LXBhc3N3b3Jk, abc kept"
+	got := r.String(msg)
+	if strings.Contains(got, "s3cr3t-password") || strings.Contains(got, "czNjcjN0LXBhc3N3b3Jk") {
+		t.Errorf("secret not redacted: %v", got)
+	}
+	if !strings.Contains(got, "abc kept") {
+		t.Errorf("short value should not be redacted: %v", got)
+	}
+
+	var nilRedactor *Redactor
+	if nilRedactor.String(msg) != msg {
+		t.Errorf("nil redactor should keep message")
+	}
+}
+
+func TestSecretJSON(t *testing.T) {
+	in := `{"kind":"Secret","metadata":{"name":"db"},"data":{"password":"czNjcjN0"},"stringData":{"user":"admin"}}`
+	got := SecretJSON(in)
+	if strings.Contains(got, "czNjcjN0") || strings.Contains(got, "admin") {
+		t.Errorf("secret data not redacted: %v", got)
+	}
+	if !strings.Contains(got, `"name":"db"`) {
+		t.Errorf("metadata should be kept: %v", got)
+	}
+
+	list := `{"kind":"SecretList","items":[{"data":{"k":"dmFsdWU="}}]}`
+	if got = SecretJSON(list); strings.Contains(got, "dmFsdWU=") {
+		t.Errorf("secret list not redacted: %v", got)
+	}
+
+	if got = SecretJSON("not json"); got != "not json" {
+		t.Errorf("non json should be kept: %v", got)
+	}
+}
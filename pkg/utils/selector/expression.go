@@ -0,0 +1,358 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package selector
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+type Operator string
+
+const (
+	Equals              Operator = "="
+	NotEquals           Operator = "!="
+	Contains            Operator = "~"
+	NotContains         Operator = "!~"
+	GreaterThan         Operator = ">"
+	GreaterThanOrEquals Operator = ">="
+	LessThan            Operator = "<"
+	LessThanOrEquals    Operator = "<="
+	Exists              Operator = "?"
+	DoesNotExist        Operator = "!"
+)
+
+// operators ordered so that two-char operators are matched first
+var operators = []Operator{NotEquals, NotContains, GreaterThanOrEquals, LessThanOrEquals, Equals, Contains, GreaterThan, LessThan}
+
+// Requirement is a single clause of selector
+type Requirement struct {
+	// Key the json path of value, such as metadata.labels.app, elements of
+	// array are matched if any of them matches, spec.containers[].image
+	// is the same as spec.containers.image
+	Key      string
+	Operator Operator
+	Values   []string
+
+	// operand of comparison, either number or time
+	number *float64
+	time   *time.Time
+}
+
+// Selector the compiled selector expression, all of the requirements and
+// at least one requirement of every OR group must match
+type Selector struct {
+	Requirements []Requirement
+	Groups       [][]Requirement
+}
+
+// ValuesFunc returns the values found by key, false if key not exists
+type ValuesFunc func(key string) ([]interface{}, bool)
+
+// Compile parses selector expression:
+// clauses are separated by comma and all of them must match: key1=v1,key2~v2
+// exact match of any value: key=v1|v2, negation: key!=v1|v2
+// fuzzy match of any value: key~v1|v2, negation: key!~v1|v2
+// number or RFC3339 time comparison: key>1, key<=2024-01-01T00:00:00Z
+// existence: ?key, non-existence: !key
+// OR group in which any clause matches: (key1=v1;key2~v2)
+//
+// Empty clauses such as trailing comma and bare keys without operator are
+// ignored, and values may contain parentheses and semicolons outside of
+// groups, as the former parser did. Unlike the former parser, a clause
+// without key is rejected.
+func Compile(selectorStr string) (*Selector, error) {
+	s := &Selector{}
+	if len(selectorStr) == 0 {
+		return s, nil
+	}
+
+	for _, clause := range strings.Split(selectorStr, ",") {
+		if len(clause) == 0 {
+			continue
+		}
+		if strings.HasPrefix(clause, "(") {
+			if !strings.HasSuffix(clause, ")") {
+				return nil, fmt.Errorf("unclosed group %q", clause)
+			}
+			var group []Requirement
+			for _, c := range strings.Split(clause[1:len(clause)-1], ";") {
+				r, err := parseRequirement(c)
+				if err != nil {
+					return nil, err
+				}
+				if r != nil {
+					group = append(group, *r)
+				}
+			}
+			if len(group) > 0 {
+				s.Groups = append(s.Groups, group)
+			}
+			continue
+		}
+		r, err := parseRequirement(clause)
+		if err != nil {
+			return nil, err
+		}
+		if r != nil {
+			s.Requirements = append(s.Requirements, *r)
+		}
+	}
+	return s, nil
+}
+
+// parseRequirement parses clause of selector, nil is returned for bare key
+func parseRequirement(clause string) (*Requirement, error) {
+	i := strings.IndexAny(clause, "!=~<>")
+	// values are free text, only keys are not allowed to open groups
+	key := clause
+	if i >= 0 {
+		key = clause[:i]
+	}
+	if strings.ContainsAny(key, "();") {
+		return nil, fmt.Errorf("invalid clause %q: groups can not be nested", clause)
+	}
+
+	if i < 0 {
+		if strings.HasPrefix(clause, string(Exists)) {
+			return newRequirement(clause[len(Exists):], Exists, nil)
+		}
+		return nil, nil
+	}
+	if i == 0 && clause[0] == '!' && !strings.ContainsAny(clause[1:], "!=~<>") {
+		return newRequirement(clause[1:], DoesNotExist, nil)
+	}
+
+	for _, op := range operators {
+		if strings.HasPrefix(clause[i:], string(op)) {
+			return newRequirement(clause[:i], op, strings.Split(clause[i+len(op):], "|"))
+		}
+	}
+	return nil, fmt.Errorf("invalid clause %q: unknown operator", clause)
+}
+
+func newRequirement(key string, op Operator, values []string) (*Requirement, error) {
+	key = strings.ReplaceAll(key, "[]", "")
+	if len(key) == 0 {
+		return nil, fmt.Errorf("missing key of operator %v", op)
+	}
+	r := &Requirement{Key: key, Operator: op, Values: values}
+
+	switch op {
+	case GreaterThan, GreaterThanOrEquals, LessThan, LessThanOrEquals:
+		if len(values) != 1 {
+			return nil, fmt.Errorf("operator %v of key %v requires single value", op, key)
+		}
+		if n, err := strconv.ParseFloat(values[0], 64); err == nil {
+			r.number = &n
+		} else if t, err := time.Parse(time.RFC3339, values[0]); err == nil {
+			r.time = &t
+		} else {
+			return nil, fmt.Errorf("operator %v of key %v requires number or RFC3339 time, got %q", op, key, values[0])
+		}
+	}
+	return r, nil
+}
+
+// Split moves plain exact and fuzzy requirements into maps used by exact and
+// fuzzy filters, and returns the remaining selector
+func (s *Selector) Split() (exact map[string]sets.Set[string], fuzzy map[string][]string, rest *Selector) {
+	exact = make(map[string]sets.Set[string])
+	fuzzy = make(map[string][]string)
+	rest = &Selector{Groups: s.Groups}
+
+	for _, r := range s.Requirements {
+		_, isExact := exact[r.Key]
+		_, isFuzzy := fuzzy[r.Key]
+		switch {
+		case r.Operator == Equals && !isExact:
+			exact[r.Key] = sets.New[string](r.Values...)
+		case r.Operator == Contains && !isFuzzy:
+			fuzzy[r.Key] = r.Values
+		default:
+			rest.Requirements = append(rest.Requirements, r)
+		}
+	}
+	return
+}
+
+// Empty returns true if selector has nothing to match
+func (s *Selector) Empty() bool {
+	return s == nil || (len(s.Requirements) == 0 && len(s.Groups) == 0)
+}
+
+// Matches returns true if values got by keys match the selector
+func (s *Selector) Matches(values ValuesFunc) bool {
+	if s.Empty() {
+		return true
+	}
+	for i := range s.Requirements {
+		if !s.Requirements[i].Matches(values) {
+			return false
+		}
+	}
+	for _, group := range s.Groups {
+		matched := false
+		for i := range group {
+			if group[i].Matches(values) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// Matches returns true if values got by key match the requirement
+func (r *Requirement) Matches(values ValuesFunc) bool {
+	vals, ok := values(r.Key)
+	switch r.Operator {
+	case Exists:
+		return ok
+	case DoesNotExist:
+		return !ok
+	case NotEquals:
+		return !ok || !r.any(vals, r.equals)
+	case NotContains:
+		return !ok || !r.any(vals, r.contains)
+	}
+	if !ok {
+		return false
+	}
+	switch r.Operator {
+	case Equals:
+		return r.any(vals, r.equals)
+	case Contains:
+		return r.any(vals, r.contains)
+	default:
+		return r.any(vals, r.compare)
+	}
+}
+
+func (r *Requirement) any(vals []interface{}, match func(interface{}) bool) bool {
+	for _, v := range vals {
+		if match(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Requirement) equals(v interface{}) bool {
+	s, ok := toString(v)
+	if !ok {
+		return false
+	}
+	for _, value := range r.Values {
+		if s == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Requirement) contains(v interface{}) bool {
+	s, ok := toString(v)
+	if !ok {
+		return false
+	}
+	for _, value := range r.Values {
+		if strings.Contains(s, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Requirement) compare(v interface{}) bool {
+	var c int
+	switch {
+	case r.number != nil:
+		n, ok := toNumber(v)
+		if !ok {
+			return false
+		}
+		switch {
+		case n < *r.number:
+			c = -1
+		case n > *r.number:
+			c = 1
+		}
+	case r.time != nil:
+		s, ok := v.(string)
+		if !ok {
+			return false
+		}
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return false
+		}
+		c = t.Compare(*r.time)
+	default:
+		return false
+	}
+
+	switch r.Operator {
+	case GreaterThan:
+		return c > 0
+	case GreaterThanOrEquals:
+		return c >= 0
+	case LessThan:
+		return c < 0
+	case LessThanOrEquals:
+		return c <= 0
+	}
+	return false
+}
+
+func toString(v interface{}) (string, bool) {
+	switch val := v.(type) {
+	case string:
+		return val, true
+	case bool:
+		return strconv.FormatBool(val), true
+	case int64:
+		return strconv.FormatInt(val, 10), true
+	case int:
+		return strconv.Itoa(val), true
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64), true
+	}
+	return "", false
+}
+
+func toNumber(v interface{}) (float64, bool) {
+	switch val := v.(type) {
+	case int64:
+		return float64(val), true
+	case int:
+		return float64(val), true
+	case float64:
+		return val, true
+	case string:
+		n, err := strconv.ParseFloat(val, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
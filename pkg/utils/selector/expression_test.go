@@ -0,0 +1,128 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package selector
+
+import "testing"
+
+func TestCompile(t *testing.T) {
+	values := map[string][]interface{}{
+		"metadata.name":                       {"nginx-1"},
+		"spec.replicas":                       {float64(3)},
+		"spec.containers.image":               {"nginx:1.25", "busybox"},
+		"metadata.creationTimestamp":          {"2024-03-01T00:00:00Z"},
+		"metadata.labels.app":                 {"web"},
+		"status.conditions.status":            {"True", "False"},
+		"metadata.annotations.kubeworkz.io/x": {"1"},
+	}
+	get := func(key string) ([]interface{}, bool) {
+		v, ok := values[key]
+		return v, ok
+	}
+
+	cases := []struct {
+		selector string
+		match    bool
+	}{
+		{"metadata.name=nginx-1|nginx-2", true},
+		{"metadata.name!=nginx-1", false},
+		{"metadata.labels.tier!=db", true},
+		{"metadata.name!~redis", true},
+		{"spec.replicas>2,spec.replicas<=3", true},
+		{"spec.replicas>=4", false},
+		{"metadata.creationTimestamp>2024-01-01T00:00:00Z", true},
+		{"metadata.creationTimestamp<2024-01-01T00:00:00Z", false},
+		{"spec.containers[].image~busy", true},
+		{"spec.containers[].image=redis", false},
+		{"?metadata.labels.app,!metadata.labels.tier", true},
+		{"?metadata.labels.tier", false},
+		{"!metadata.labels.app", false},
+		{"(metadata.labels.app=db;spec.replicas>1)", true},
+		{"(metadata.labels.app=db;spec.replicas>5)", false},
+		{"status.conditions.status=False", true},
+	}
+	for _, c := range cases {
+		s, err := Compile(c.selector)
+		if err != nil {
+			t.Fatalf("compile %q: %v", c.selector, err)
+		}
+		if got := s.Matches(get); got != c.match {
+			t.Errorf("selector %q expect %v, got %v", c.selector, c.match, got)
+		}
+	}
+
+	invalid := []string{"a>b", "a>1|2", "=b", "(a=1", "a!b", "(a=1;(b=2))", "!", "?"}
+	for _, v := range invalid {
+		if _, err := Compile(v); err == nil {
+			t.Errorf("expect selector %q invalid", v)
+		}
+	}
+}
+
+// TestCompileFormerForms makes sure selectors accepted by the former parser
+// are still accepted
+func TestCompileFormerForms(t *testing.T) {
+	values := map[string][]interface{}{
+		"metadata.name":       {"job(1);daily"},
+		"metadata.labels.app": {"web"},
+	}
+	get := func(key string) ([]interface{}, bool) {
+		v, ok := values[key]
+		return v, ok
+	}
+
+	cases := []struct {
+		selector string
+		match    bool
+	}{
+		{"metadata.labels.app=web,", true},
+		{"metadata.labels.app=web,,metadata.name~job", true},
+		{"metadata.name=job(1);daily", true},
+		{"metadata.name~(1)", true},
+		{"metadata.name~;daily|x", true},
+		{"metadata.name=job(2)", false},
+		{",", true},
+		{"metadata.labels.tier", true},
+		{"metadata.labels.tier,metadata.name=job(2)", false},
+		{"(metadata.labels.tier)", true},
+	}
+	for _, c := range cases {
+		s, err := Compile(c.selector)
+		if err != nil {
+			t.Fatalf("compile %q: %v", c.selector, err)
+		}
+		if got := s.Matches(get); got != c.match {
+			t.Errorf("selector %q expect %v, got %v", c.selector, c.match, got)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	s, err := Compile("a=1|2,b~x,a=3,c!=4,(d=5;e=6)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exact, fuzzy, rest := s.Split()
+	if !exact["a"].Has("2") || len(fuzzy["b"]) != 1 {
+		t.Errorf("unexpected exact %v fuzzy %v", exact, fuzzy)
+	}
+	if len(rest.Requirements) != 2 || len(rest.Groups) != 1 {
+		t.Errorf("unexpected rest %+v", rest)
+	}
+	if got := ParseLabelSelector("metadata.labels.app=web,metadata.labels.tier!=db,(metadata.labels.x=1;metadata.labels.y=2)"); len(got) != 1 {
+		t.Errorf("expect only plain label clause pushed down, got %v", got)
+	}
+}
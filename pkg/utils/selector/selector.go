@@ -70,7 +70,8 @@ func ParseLabelSelector(selectorStr string) (labelSelector map[string][]string)
 	labels := strings.Split(selectorStr, ",")
 	for _, label := range labels {
 		if i := strings.IndexAny(label, "~="); i > 0 {
-			if label[i] == '=' {
+			// only plain exact clauses can be pushed down, skip negation and comparison
+			if label[i] == '=' && !strings.ContainsAny(label[:i], "!<>()") {
 				values := strings.Split(label[i+1:], "|")
 				var set []string
 				for _, value := range values {
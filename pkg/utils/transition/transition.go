@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
@@ -61,6 +62,10 @@ func SubNs2Ns(subNs *SubnamespaceAnchor) *v1.Namespace {
 		},
 	}
 
+	if template, ok := subNs.Labels[constants.ProjectTemplateLabel]; ok {
+		ns.Labels[constants.ProjectTemplateLabel] = template
+	}
+
 	return ns
 }
 
@@ -102,22 +107,28 @@ func TransBinding(labels map[string]string, sub rbacv1.Subject, ref rbacv1.RoleR
 	return
 }
 
+// AddUserScopeBindings adds permanent scope binding to user, existing
+// temporary binding of the same scope and role becomes permanent
 func AddUserScopeBindings(user *userv1.User, scopeType, scopeName, role string) {
-	bindingUnique := []string{}
-	for _, binding := range user.Spec.ScopeBindings {
-		bindingUnique = append(bindingUnique, ScopeBindingUnique(binding))
+	for i := range user.Spec.ScopeBindings {
+		b := &user.Spec.ScopeBindings[i]
+		if ScopeBindingUnique(*b) != scopeName+scopeType+role {
+			continue
+		}
+		if b.ExpiresAt != nil {
+			b.ExpiresAt = nil
+			b.Reason = ""
+			clog.Info("make ScopeBinding permanent for user %v: type (%v), scope (%v), role (%v)", user.Name, scopeType, scopeName, role)
+		}
+		return
 	}
 
-	bindingUniqueSet := sets.New[string](bindingUnique...)
-
-	if !bindingUniqueSet.Has(scopeName + scopeType + role) {
-		user.Spec.ScopeBindings = append(user.Spec.ScopeBindings, userv1.ScopeBinding{
-			ScopeName: scopeName,
-			ScopeType: userv1.BindingScopeType(scopeType),
-			Role:      role,
-		})
-		clog.Info("add ScopeBinding for user %v: type (%v), scope (%v), role (%v))", user.Name, scopeType, scopeName, role)
-	}
+	user.Spec.ScopeBindings = append(user.Spec.ScopeBindings, userv1.ScopeBinding{
+		ScopeName: scopeName,
+		ScopeType: userv1.BindingScopeType(scopeType),
+		Role:      role,
+	})
+	clog.Info("add ScopeBinding for user %v: type (%v), scope (%v), role (%v))", user.Name, scopeType, scopeName, role)
 }
 
 func RemoveUserScopeBindings(user *userv1.User, scopeType, scopeName, role string) {
@@ -132,6 +143,36 @@ func RemoveUserScopeBindings(user *userv1.User, scopeType, scopeName, role strin
 	user.Spec.ScopeBindings = newScopeBindings
 }
 
+// GrantTemporaryScopeBinding adds scope binding expiring at given time to user,
+// expiration of existing binding is extended, permanent binding is kept as it is
+func GrantTemporaryScopeBinding(user *userv1.User, scopeType, scopeName, role string, expiresAt metav1.Time, reason string) {
+	for i := range user.Spec.ScopeBindings {
+		b := &user.Spec.ScopeBindings[i]
+		if b.ScopeName != scopeName || string(b.ScopeType) != scopeType || b.Role != role {
+			continue
+		}
+		if b.ExpiresAt == nil {
+			clog.Info("user %v already has permanent ScopeBinding: type (%v), scope (%v), role (%v)", user.Name, scopeType, scopeName, role)
+			return
+		}
+		if b.ExpiresAt.Before(&expiresAt) {
+			b.ExpiresAt = &expiresAt
+			b.Reason = reason
+		}
+		clog.Info("extend ScopeBinding for user %v: type (%v), scope (%v), role (%v) until %v", user.Name, scopeType, scopeName, role, b.ExpiresAt)
+		return
+	}
+
+	user.Spec.ScopeBindings = append(user.Spec.ScopeBindings, userv1.ScopeBinding{
+		ScopeName: scopeName,
+		ScopeType: userv1.BindingScopeType(scopeType),
+		Role:      role,
+		ExpiresAt: &expiresAt,
+		Reason:    reason,
+	})
+	clog.Info("add ScopeBinding for user %v: type (%v), scope (%v), role (%v) until %v", user.Name, scopeType, scopeName, role, expiresAt)
+}
+
 func ScopeBindingUnique(b userv1.ScopeBinding) string {
 	return b.ScopeName + string(b.ScopeType) + b.Role
 }
@@ -160,7 +201,8 @@ func RefreshUserStatus(ctx context.Context, user *userv1.User, cli client.Client
 	user.Status.BelongProjectInfos = make([]userv1.ProjectInfo, 0)
 	user.Status.PlatformAdmin = false
 
-	for _, binding := range user.Spec.ScopeBindings {
+	// expired bindings take no effect
+	for _, binding := range userv1.ActiveScopeBindings(user.Spec.ScopeBindings, time.Now()) {
 		switch binding.ScopeType {
 		case userv1.TenantScope:
 			addUserToTenant(user, binding.ScopeName)
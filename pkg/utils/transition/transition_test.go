@@ -0,0 +1,48 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transition
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	userv1 "github.com/saashqdev/kubeworkz/pkg/apis/user/v1"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+)
+
+func TestAddUserScopeBindingsMakesTemporaryPermanent(t *testing.T) {
+	assert := assert.New(t)
+	user := &userv1.User{ObjectMeta: metav1.ObjectMeta{Name: "u1"}}
+	expiresAt := metav1.NewTime(time.Now().Add(time.Hour))
+
+	GrantTemporaryScopeBinding(user, string(userv1.TenantScope), "t1", constants.TenantAdmin, expiresAt, "incident")
+	AddUserScopeBindings(user, string(userv1.TenantScope), "t1", constants.TenantAdmin)
+	assert.Len(user.Spec.ScopeBindings, 1)
+	assert.Nil(user.Spec.ScopeBindings[0].ExpiresAt)
+	assert.Empty(user.Spec.ScopeBindings[0].Reason)
+
+	// permanent binding is never shortened by temporary grant
+	GrantTemporaryScopeBinding(user, string(userv1.TenantScope), "t1", constants.TenantAdmin, expiresAt, "incident")
+	assert.Len(user.Spec.ScopeBindings, 1)
+	assert.Nil(user.Spec.ScopeBindings[0].ExpiresAt)
+
+	AddUserScopeBindings(user, string(userv1.ProjectScope), "p1", constants.ProjectAdmin)
+	assert.Len(user.Spec.ScopeBindings, 2)
+}
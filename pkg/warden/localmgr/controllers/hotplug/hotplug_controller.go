@@ -15,21 +15,27 @@ package hotplug
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	helmrelease "helm.sh/helm/v3/pkg/release"
-	"helm.sh/helm/v3/pkg/storage/driver"
+	corev1 "k8s.io/api/core/v1"
 	apierros "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	hotplugv1 "github.com/saashqdev/kubeworkz/pkg/apis/hotplug/v1"
 	"github.com/saashqdev/kubeworkz/pkg/clog"
+	hotplugutil "github.com/saashqdev/kubeworkz/pkg/utils/hotplug"
+	"github.com/saashqdev/kubeworkz/pkg/utils/redact"
 	"github.com/saashqdev/kubeworkz/pkg/warden/utils"
 )
 
@@ -37,8 +43,12 @@ const (
 	common  = "common"
 	fail    = "fail"
 	success = "success"
+	pending = "pending"
 	enabled = "enabled"
 	//disabled = "disabled"
+
+	// readinessRequeuePeriod is the interval to recheck components not ready
+	readinessRequeuePeriod = 15 * time.Second
 )
 
 var _ reconcile.Reconciler = &HotplugReconciler{}
@@ -46,7 +56,10 @@ var _ reconcile.Reconciler = &HotplugReconciler{}
 // HotplugReconciler reconciles a Hotplug object
 type HotplugReconciler struct {
 	client.Client
-	Scheme          *runtime.Scheme
+	Scheme *runtime.Scheme
+	// apiReader reads secrets and configmaps directly from apiserver, only
+	// metadata of secrets is cached for watching
+	apiReader       client.Reader
 	isMemberCluster bool
 	clusterName     string
 }
@@ -55,6 +68,7 @@ func newReconciler(mgr manager.Manager, isMemberCluster bool, clusterName string
 	r := &HotplugReconciler{
 		Client:          mgr.GetClient(),
 		Scheme:          mgr.GetScheme(),
+		apiReader:       mgr.GetAPIReader(),
 		isMemberCluster: isMemberCluster,
 		clusterName:     clusterName,
 	}
@@ -64,6 +78,7 @@ func newReconciler(mgr manager.Manager, isMemberCluster bool, clusterName string
 //+kubebuilder:rbac:groups=hotplug.kubeworkz.io,resources=hotplugs,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=hotplug.kubeworkz.io,resources=hotplugs/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=hotplug.kubeworkz.io,resources=hotplugs/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
 
 // list/watch hotplug config and helm install/upgrade components
 // 1、get change config and parse
@@ -74,7 +89,6 @@ func (h *HotplugReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	// get hotplug info
 	commonConfig := hotplugv1.Hotplug{}
 	clusterConfig := hotplugv1.Hotplug{}
-	hotplugConfig := hotplugv1.Hotplug{}
 	switch req.Name {
 	case common:
 		err := h.Client.Get(ctx, req.NamespacedName, &commonConfig)
@@ -86,15 +100,9 @@ func (h *HotplugReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 			return ctrl.Result{}, err
 		}
 		err = h.Client.Get(ctx, types.NamespacedName{Name: utils.Cluster}, &clusterConfig)
-		if err != nil {
-			if !apierros.IsNotFound(err) {
-				log.Error("get cluster hotplug fail, %v", err)
-				return ctrl.Result{}, err
-			}
-			// cluster config is nil
-			hotplugConfig = MergeHotplug(commonConfig, clusterConfig)
-		} else {
-			hotplugConfig = MergeHotplug(commonConfig, clusterConfig)
+		if err != nil && !apierros.IsNotFound(err) {
+			log.Error("get cluster hotplug fail, %v", err)
+			return ctrl.Result{}, err
 		}
 	case utils.Cluster:
 		err := h.Client.Get(ctx, req.NamespacedName, &clusterConfig)
@@ -110,92 +118,134 @@ func (h *HotplugReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 			log.Warn("get common hotplug fail, %v", err)
 			return ctrl.Result{}, err
 		}
-		hotplugConfig = MergeHotplug(commonConfig, clusterConfig)
 	default:
 		log.Warn("this hotplug not match this cluster, %s != %s", req.Name, utils.Cluster)
 		return ctrl.Result{}, nil
 	}
 
+	// apply components of common hotplug only when released to this cluster
+	releasedConfig, released := ReleasedCommon(commonConfig, utils.Cluster)
+	if !released {
+		log.Info("revision %v of common hotplug not released to this cluster yet", commonConfig.Spec.Revision())
+	}
+	hotplugConfig := MergeHotplug(releasedConfig, clusterConfig)
+
 	// helm do
 	results := []*hotplugv1.DeployResult{}
-	helm := NewHelm()
+	resultMap := make(map[string]*hotplugv1.DeployResult)
+	components := make(map[string]hotplugv1.ComponentConfig)
 	for _, c := range hotplugConfig.Spec.Component {
-		namespace := c.Namespace
-		name := c.Name
-		result := &hotplugv1.DeployResult{Name: name, Status: c.Status}
+		result := &hotplugv1.DeployResult{Name: c.Name, Status: c.Status}
 		results = append(results, result)
-
-		// query release status
-		isReleaseExist := false
-		release, err := helm.Status(namespace, name)
-		if err != nil {
-			if !errors.Is(err, driver.ErrReleaseNotFound) {
-				log.Info("get release %v failed: %v", name, err)
-				return ctrl.Result{}, err
-			}
+		resultMap[c.Name] = result
+		components[c.Name] = c
+	}
+	conditions := make([]hotplugv1.ComponentCondition, 0, len(results))
+	for _, cond := range commonConfig.Status.Conditions {
+		if _, ok := components[cond.Name]; ok {
+			conditions = append(conditions, cond)
 		}
+	}
+	histories := []hotplugv1.ComponentHistory{}
+	requeue := false
 
-		// any way we found release about chart, we think it exists
-		if release != nil && release.Info.Status != helmrelease.StatusUninstalled {
-			isReleaseExist = true
-			log.Info("release (%v/%v) exist and status is %v", release.Name, release.Namespace, release.Info.Status)
+	ordered, err := SortComponents(hotplugConfig.Spec.Component)
+	if err != nil {
+		log.Warn("sort components failed: %v", err)
+		histories = commonConfig.Status.History
+		for _, result := range results {
+			addFailResult(result, err.Error())
+			conditions = setCondition(conditions, result.Name, metav1.ConditionFalse, reasonDependencyCycle, err.Error())
 		}
+	} else {
+		helm := hotplugutil.NewHelm()
+		charts := hotplugutil.NewChartLoader(h.apiReader)
 
-		switch {
-		case !isReleaseExist && c.Status != enabled: // release no exist & disabled, do nothing
-			addSuccessResult(result, "clear")
-			continue
-		case !isReleaseExist && c.Status == enabled: // release no exist & enable, need install
-			envs, err := YamlStringToJson(c.Env)
-			if err != nil {
-				addFailResult(result, fmt.Sprintf("parse env yaml fail, %v", err))
+		// uninstall in reverse order so that dependents are removed first
+		for i := len(ordered) - 1; i >= 0; i-- {
+			c := ordered[i]
+			if c.Status == enabled {
 				continue
 			}
-			log.Info("install helm chart (%v/%v)", name, namespace)
-			_, err = helm.Install(namespace, name, c.PkgName, envs)
-			if err != nil {
-				log.Info("install helm chart (%v/%v) failed: %v", name, namespace, err)
-				addFailResult(result, fmt.Sprintf("helm install fail, %v", err))
-				continue
-			}
-			addSuccessResult(result, "helm install success")
-		case isReleaseExist && c.Status != enabled: // release exist & disabled, need uninstall
-			log.Info("uninstall helm chart (%v/%v)", name, namespace)
-			err := helm.Uninstall(namespace, name)
-			if err != nil {
-				log.Info("uninstall helm chart (%v/%v) failed", name, namespace, err)
-				addFailResult(result, fmt.Sprintf("helm uninstall fail, %v", err))
+			// keep release of disabled component until no enabled component depends on it
+			if dependents := EnabledDependents(c.Name, hotplugConfig.Spec.Component); len(dependents) > 0 {
+				message := fmt.Sprintf("component is disabled but still required by enabled components: %v", strings.Join(dependents, ", "))
+				addFailResult(resultMap[c.Name], message)
+				conditions = setCondition(conditions, c.Name, metav1.ConditionFalse, reasonRequired, message)
 				continue
 			}
-			addSuccessResult(result, "helm uninstall success")
-		case isReleaseExist && c.Status == enabled: // release exist & enabled, need upgrade
-			envs, err := YamlStringToJson(c.Env)
-			if err != nil {
-				addFailResult(result, fmt.Sprintf("parse env yaml fail, %v", err))
-				continue
+			if err := h.uninstallComponent(helm, c, resultMap[c.Name]); err != nil {
+				return ctrl.Result{}, err
 			}
-			// get release values
-			values, err := helm.GetValues(namespace, name)
-			if err != nil {
-				addFailResult(result, fmt.Sprintf("helm get values fail, %v", err))
+			conditions = setCondition(conditions, c.Name, metav1.ConditionFalse, reasonDisabled, "component is disabled")
+		}
+
+		// install in order and only when dependencies are ready
+		ready := make(map[string]bool)
+		for _, c := range ordered {
+			if c.Status != enabled {
 				continue
 			}
-			if JudgeJsonEqual(envs, values) {
-				if release.Info.Status != helmrelease.StatusDeployed {
-					addSuccessResult(result, "release is existing but status not ok please check")
+			result := resultMap[c.Name]
+			history := historyOf(commonConfig.Status.History, c.Name)
+			if reason, message := blockedBy(c, components, resultMap, ready); reason != "" {
+				if reason == reasonDependencyWaiting {
+					addPendingResult(result, message)
+					requeue = true
 				} else {
-					addSuccessResult(result, "release is running")
+					addFailResult(result, message)
 				}
+				conditions = setCondition(conditions, c.Name, metav1.ConditionFalse, reason, message)
+				histories = append(histories, history)
 				continue
 			}
-			log.Info("upgrade helm chart (%v/%v)", name, namespace)
-			_, err = helm.Upgrade(namespace, name, c.PkgName, envs)
+
+			secrets := redact.New()
+			values, err := hotplugutil.ResolveValues(ctx, h.apiReader, c, secrets)
 			if err != nil {
-				log.Info("upgrade helm chart (%v/%v) failed", name, namespace, err)
-				addFailResult(result, fmt.Sprintf("helm upgrade fail, %v", err))
+				message := secrets.String(err.Error())
+				addFailResult(result, message)
+				conditions = setCondition(conditions, c.Name, metav1.ConditionFalse, reasonFailed, message)
+				histories = append(histories, history)
 				continue
 			}
-			addSuccessResult(result, "upgrade success")
+			desired := desiredRelease{values: values, hash: ConfigHash(c, values), secrets: secrets}
+
+			release, err := h.installComponent(ctx, helm, charts, c, desired, result, &history)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if result.Result == fail {
+				conditions = setCondition(conditions, c.Name, metav1.ConditionFalse, reasonFailed, result.Message)
+			} else {
+				isReady, reason, message := h.componentReadiness(ctx, c, release)
+				switch {
+				case isReady:
+					ready[c.Name] = true
+					history.ReadyRevision = release.Version
+					conditions = setCondition(conditions, c.Name, metav1.ConditionTrue, reasonReady, "")
+				case reason == reasonProgressing:
+					requeue = true
+					addPendingResult(result, message)
+					conditions = setCondition(conditions, c.Name, metav1.ConditionFalse, reason, message)
+				default:
+					// keep checking until workloads ready even if timeout exceeded
+					requeue = true
+					if reason == reasonTimeout && !c.AutoRollbackDisabled() {
+						message += secrets.String(rollbackUnhealthyUpgrade(helm, c, &history, release, desired.hash))
+					}
+					addFailResult(result, message)
+					conditions = setCondition(conditions, c.Name, metav1.ConditionFalse, reason, message)
+				}
+			}
+
+			if err := recordRevisions(helm, c, &history); err != nil {
+				log.Warn("get history of component %v failed: %v", c.Name, secrets.String(err.Error()))
+			}
+			for i := range history.Revisions {
+				history.Revisions[i].Description = secrets.String(history.Revisions[i].Description)
+			}
+			histories = append(histories, history)
 		}
 	}
 
@@ -204,7 +254,10 @@ func (h *HotplugReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	for _, r := range results {
 		if r.Result == fail {
 			phase = fail
-			continue
+			break
+		}
+		if r.Result == pending {
+			phase = pending
 		}
 	}
 
@@ -219,7 +272,13 @@ func (h *HotplugReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	// update status
 	commonConfig.Status.Phase = phase
 	commonConfig.Status.Results = results
-	err := h.Client.Status().Update(ctx, &commonConfig)
+	commonConfig.Status.Conditions = conditions
+	commonConfig.Status.History = histories
+	if released {
+		commonConfig.Status.AppliedRevision = commonConfig.Spec.Revision()
+		commonConfig.Status.AppliedComponents = commonConfig.Spec.Component
+	}
+	err = h.Client.Status().Update(ctx, &commonConfig)
 	if err != nil {
 		log.Warn("update common hotplug fail and will keep retry: %v", err)
 		return ctrl.Result{}, err
@@ -227,6 +286,8 @@ func (h *HotplugReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	if req.Name == utils.Cluster {
 		clusterConfig.Status.Phase = phase
 		clusterConfig.Status.Results = results
+		clusterConfig.Status.Conditions = conditions
+		clusterConfig.Status.History = histories
 		err := h.Client.Status().Update(ctx, &clusterConfig)
 		if err != nil {
 			log.Warn("update cluster %v hotplug fail and will keep retry: %v", req.Name, err)
@@ -234,9 +295,164 @@ func (h *HotplugReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		}
 	}
 
+	if requeue {
+		return ctrl.Result{RequeueAfter: readinessRequeuePeriod}, nil
+	}
 	return ctrl.Result{}, nil
 }
 
+// uninstallComponent uninstalls release of disabled component if exists
+func (h *HotplugReconciler) uninstallComponent(helm *hotplugutil.Helm, c hotplugv1.ComponentConfig, result *hotplugv1.DeployResult) error {
+	namespace, name := c.Namespace, c.Name
+	release, err := hotplugutil.ReleaseOf(helm, c)
+	if err != nil {
+		clog.Info("get release %v failed: %v", name, err)
+		return err
+	}
+	if release == nil { // release no exist & disabled, do nothing
+		addSuccessResult(result, "clear")
+		return nil
+	}
+	clog.Info("uninstall helm chart (%v/%v)", name, namespace)
+	if err = helm.Uninstall(namespace, name); err != nil {
+		clog.Info("uninstall helm chart (%v/%v) failed: %v", name, namespace, err)
+		addFailResult(result, fmt.Sprintf("helm uninstall fail, %v", err))
+		return nil
+	}
+	addSuccessResult(result, "helm uninstall success")
+	return nil
+}
+
+// desiredRelease is the desired state of release resolved from component
+type desiredRelease struct {
+	values map[string]interface{}
+	// hash of chart and values
+	hash string
+	// secrets redacts values read from secrets
+	secrets *redact.Redactor
+}
+
+// installComponent installs or upgrades release of enabled component, the
+// returned error means status of release can not be queried and should retry
+func (h *HotplugReconciler) installComponent(ctx context.Context, helm *hotplugutil.Helm, charts *hotplugutil.ChartLoader, c hotplugv1.ComponentConfig, desired desiredRelease, result *hotplugv1.DeployResult, history *hotplugv1.ComponentHistory) (*helmrelease.Release, error) {
+	namespace, name := c.Namespace, c.Name
+	release, err := hotplugutil.ReleaseOf(helm, c)
+	if err != nil {
+		clog.Info("get release %v failed: %v", name, err)
+		return nil, err
+	}
+
+	if release == nil { // release no exist & enable, need install
+		chart, err := charts.Load(ctx, c)
+		if err != nil {
+			addFailResult(result, fmt.Sprintf("load chart fail, %v", err))
+			return nil, nil
+		}
+		clog.Info("install helm chart (%v/%v)", name, namespace)
+		release, err = helm.InstallChart(namespace, name, chart, desired.values)
+		if err != nil {
+			message := desired.secrets.String(err.Error())
+			clog.Info("install helm chart (%v/%v) failed: %v", name, namespace, message)
+			addFailResult(result, fmt.Sprintf("helm install fail, %v", message))
+			return nil, nil
+		}
+		addSuccessResult(result, "helm install success")
+		return release, nil
+	}
+
+	// release exist & enabled, need upgrade
+	clog.Info("release (%v/%v) exist and status is %v", release.Name, release.Namespace, release.Info.Status)
+	values, err := helm.GetValues(namespace, name)
+	if err != nil {
+		addFailResult(result, fmt.Sprintf("helm get values fail, %v", err))
+		return nil, nil
+	}
+	if hotplugutil.JudgeJsonEqual(desired.values, values) && hotplugutil.ChartSatisfied(c, release.Chart) {
+		if history.RolledBackConfig != desired.hash {
+			history.RolledBackConfig = ""
+		}
+		if release.Info.Status != helmrelease.StatusDeployed {
+			addSuccessResult(result, "release is existing but status not ok please check")
+		} else {
+			addSuccessResult(result, "release is running")
+		}
+		return release, nil
+	}
+	if history.RolledBackConfig == desired.hash {
+		addFailResult(result, "upgrade with current config was rolled back, change config to retry")
+		return release, nil
+	}
+	chart, err := charts.Load(ctx, c)
+	if err != nil {
+		addFailResult(result, fmt.Sprintf("load chart fail, %v", err))
+		return nil, nil
+	}
+	clog.Info("upgrade helm chart (%v/%v)", name, namespace)
+	upgraded, err := helm.UpgradeChart(namespace, name, chart, desired.values)
+	if err != nil {
+		message := fmt.Sprintf("helm upgrade fail, %v", err)
+		if !c.AutoRollbackDisabled() {
+			message += rollbackFailedUpgrade(helm, c, history, desired.hash)
+		}
+		message = desired.secrets.String(message)
+		clog.Info("upgrade helm chart (%v/%v) failed: %v", name, namespace, message)
+		addFailResult(result, message)
+		return nil, nil
+	}
+	release = upgraded
+	history.RolledBackConfig = ""
+	addSuccessResult(result, "upgrade success")
+	return release, nil
+}
+
+// componentReadiness judges whether workloads of installed component are
+// ready, reason is returned when not ready
+func (h *HotplugReconciler) componentReadiness(ctx context.Context, c hotplugv1.ComponentConfig, release *helmrelease.Release) (bool, string, string) {
+	if release.Info.Status == helmrelease.StatusFailed {
+		return false, reasonFailed, fmt.Sprintf("release status is %v: %v", release.Info.Status, release.Info.Description)
+	}
+
+	isReady, message, err := CheckReadiness(ctx, h.Client, c)
+	if err != nil {
+		message = fmt.Sprintf("check readiness failed, %v", err)
+	}
+	if isReady && release.Info.Status == helmrelease.StatusDeployed {
+		return true, "", ""
+	}
+	if message == "" {
+		message = fmt.Sprintf("release status is %v", release.Info.Status)
+	}
+
+	if time.Since(release.Info.LastDeployed.Time) > readinessTimeout(c) {
+		return false, reasonTimeout, fmt.Sprintf("not ready in %v: %v", readinessTimeout(c), message)
+	}
+	return false, reasonProgressing, message
+}
+
+// blockedBy returns reason if any dependency of component is not ready
+func blockedBy(c hotplugv1.ComponentConfig, components map[string]hotplugv1.ComponentConfig, results map[string]*hotplugv1.DeployResult, ready map[string]bool) (string, string) {
+	for _, d := range c.DependsOn {
+		dep, ok := components[d]
+		switch {
+		case !ok:
+			return reasonDependencyMissing, fmt.Sprintf("dependency %v not found", d)
+		case dep.Status != enabled:
+			return reasonDependencyMissing, fmt.Sprintf("dependency %v is disabled", d)
+		case results[d].Result == fail:
+			return reasonDependencyMissing, fmt.Sprintf("dependency %v failed", d)
+		case !ready[d]:
+			return reasonDependencyWaiting, fmt.Sprintf("waiting for dependency %v to be ready", d)
+		}
+	}
+	return "", ""
+}
+
+func addPendingResult(result *hotplugv1.DeployResult, message string) {
+	clog.Info("component:%s, message:%s", result.Name, message)
+	result.Result = pending
+	result.Message = message
+}
+
 func addSuccessResult(result *hotplugv1.DeployResult, message string) {
 	clog.Info("component:%s, message:%s", result.Name, message)
 	result.Result = success
@@ -257,5 +473,26 @@ func SetupWithManager(mgr ctrl.Manager, isMemberCluster bool, clusterName string
 	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&hotplugv1.Hotplug{}).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.hotplugsOfSecret), builder.OnlyMetadata).
 		Complete(r)
 }
+
+// hotplugsOfSecret enqueues hotplugs reading values from the secret, so that
+// components are upgraded when values in secret changed
+func (h *HotplugReconciler) hotplugsOfSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	key := types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+	requests := []reconcile.Request{}
+	for _, name := range []string{common, utils.Cluster} {
+		hotplug := hotplugv1.Hotplug{}
+		if err := h.Get(ctx, types.NamespacedName{Name: name}, &hotplug); err != nil {
+			if !apierros.IsNotFound(err) {
+				clog.Warn("get hotplug %v failed: %v", name, err)
+			}
+			continue
+		}
+		if hotplugutil.ReferencesSecret(hotplug, key) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: name}})
+		}
+	}
+	return requests
+}
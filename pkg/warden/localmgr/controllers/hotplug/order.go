@@ -0,0 +1,112 @@
+/*
+Copyright 2024 Kubeworkz Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hotplug
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	hotplugv1 "github.com/saashqdev/kubeworkz/pkg/apis/hotplug/v1"
+)
+
+// SortComponents orders components so that every component comes after
+// the components it depends on, components without dependency between each
+// other are ordered by priority and then by their position in spec.
+// Dependencies not found in components are ignored here and left to caller.
+func SortComponents(components []hotplugv1.ComponentConfig) ([]hotplugv1.ComponentConfig, error) {
+	index := make(map[string]int, len(components))
+	for i, c := range components {
+		index[c.Name] = i
+	}
+
+	inDegree := make([]int, len(components))
+	dependents := make([][]int, len(components))
+	for i, c := range components {
+		for _, d := range c.DependsOn {
+			j, ok := index[d]
+			if !ok {
+				continue
+			}
+			inDegree[i]++
+			dependents[j] = append(dependents[j], i)
+		}
+	}
+
+	less := func(a, b int) bool {
+		if components[a].Priority != components[b].Priority {
+			return components[a].Priority < components[b].Priority
+		}
+		return a < b
+	}
+
+	queue := []int{}
+	for i := range components {
+		if inDegree[i] == 0 {
+			queue = append(queue, i)
+		}
+	}
+
+	sorted := make([]hotplugv1.ComponentConfig, 0, len(components))
+	for len(queue) > 0 {
+		sort.SliceStable(queue, func(x, y int) bool { return less(queue[x], queue[y]) })
+		i := queue[0]
+		queue = queue[1:]
+		sorted = append(sorted, components[i])
+		for _, j := range dependents[i] {
+			inDegree[j]--
+			if inDegree[j] == 0 {
+				queue = append(queue, j)
+			}
+		}
+	}
+
+	if len(sorted) != len(components) {
+		cycle := []string{}
+		for i, c := range components {
+			if inDegree[i] > 0 {
+				cycle = append(cycle, c.Name)
+			}
+		}
+		return nil, fmt.Errorf("dependency cycle found among components: %v", strings.Join(cycle, ", "))
+	}
+
+	return sorted, nil
+}
+
+// EnabledDependents returns names of enabled components depending on the
+// given component directly or transitively
+func EnabledDependents(name string, components []hotplugv1.ComponentConfig) []string {
+	dependents := []string{}
+	visited := map[string]bool{name: true}
+	queue := []string{name}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, c := range components {
+			if visited[c.Name] || c.Status != enabled {
+				continue
+			}
+			for _, d := range c.DependsOn {
+				if d == current {
+					visited[c.Name] = true
+					dependents = append(dependents, c.Name)
+					queue = append(queue, c.Name)
+					break
+				}
+			}
+		}
+	}
+	return dependents
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hotplug_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	hotplugv1 "github.com/saashqdev/kubeworkz/pkg/apis/hotplug/v1"
+	"github.com/saashqdev/kubeworkz/pkg/warden/localmgr/controllers/hotplug"
+)
+
+var _ = Describe("Order", func() {
+
+	names := func(components []hotplugv1.ComponentConfig) []string {
+		ret := []string{}
+		for _, c := range components {
+			ret = append(ret, c.Name)
+		}
+		return ret
+	}
+
+	It("test sort components by dependencies and priority", func() {
+		components := []hotplugv1.ComponentConfig{
+			{Name: "logging", DependsOn: []string{"elasticsearch"}},
+			{Name: "audit", Priority: 2},
+			{Name: "elasticsearch", Priority: 3},
+			{Name: "monitoring", Priority: 1, DependsOn: []string{"unknown"}},
+		}
+		sorted, err := hotplug.SortComponents(components)
+		Expect(err).To(BeNil())
+		Expect(names(sorted)).To(Equal([]string{"monitoring", "audit", "elasticsearch", "logging"}))
+	})
+
+	It("test sort components with cycle", func() {
+		components := []hotplugv1.ComponentConfig{
+			{Name: "a", DependsOn: []string{"c"}},
+			{Name: "b", DependsOn: []string{"a"}},
+			{Name: "c", DependsOn: []string{"b"}},
+			{Name: "d"},
+		}
+		_, err := hotplug.SortComponents(components)
+		Expect(err).NotTo(BeNil())
+		Expect(err.Error()).To(ContainSubstring("a, b, c"))
+	})
+
+	It("test enabled dependents", func() {
+		components := []hotplugv1.ComponentConfig{
+			{Name: "elasticsearch", Status: "disabled"},
+			{Name: "logging", Status: "enabled", DependsOn: []string{"elasticsearch"}},
+			{Name: "audit", Status: "enabled", DependsOn: []string{"logging"}},
+			{Name: "kibana", Status: "disabled", DependsOn: []string{"elasticsearch"}},
+			{Name: "monitoring", Status: "enabled"},
+		}
+		Expect(hotplug.EnabledDependents("elasticsearch", components)).To(Equal([]string{"logging", "audit"}))
+		Expect(hotplug.EnabledDependents("monitoring", components)).To(BeEmpty())
+	})
+})
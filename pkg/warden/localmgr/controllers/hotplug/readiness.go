@@ -0,0 +1,191 @@
+/*
+Copyright 2024 Kubeworkz Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hotplug
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierros "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hotplugv1 "github.com/saashqdev/kubeworkz/pkg/apis/hotplug/v1"
+)
+
+const (
+	// instanceLabel is the well known label set on workloads by helm charts
+	instanceLabel = "app.kubernetes.io/instance"
+
+	defaultReadinessTimeout = 5 * time.Minute
+
+	reasonReady             = "Ready"
+	reasonProgressing       = "Progressing"
+	reasonTimeout           = "ReadinessTimeout"
+	reasonFailed            = "Failed"
+	reasonDisabled          = "Disabled"
+	reasonDependencyMissing = "DependencyNotSatisfied"
+	reasonDependencyWaiting = "WaitingForDependency"
+	reasonDependencyCycle   = "DependencyCycle"
+	reasonRequired          = "RequiredByDependents"
+)
+
+// readinessTimeout returns the max duration to wait for component ready
+func readinessTimeout(c hotplugv1.ComponentConfig) time.Duration {
+	if c.Readiness == nil || c.Readiness.TimeoutSeconds <= 0 {
+		return defaultReadinessTimeout
+	}
+	return time.Duration(c.Readiness.TimeoutSeconds) * time.Second
+}
+
+// CheckReadiness checks whether workloads of component are ready, it
+// returns the not ready message if not.
+func CheckReadiness(ctx context.Context, cli client.Client, c hotplugv1.ComponentConfig) (bool, string, error) {
+	if c.Readiness != nil && c.Readiness.Disabled {
+		return true, "", nil
+	}
+
+	workloads, err := listWorkloads(ctx, cli, c)
+	if err != nil {
+		return false, "", err
+	}
+
+	for _, w := range workloads {
+		if ok, msg := workloadReady(w); !ok {
+			return false, msg, nil
+		}
+	}
+	return true, "", nil
+}
+
+func listWorkloads(ctx context.Context, cli client.Client, c hotplugv1.ComponentConfig) ([]client.Object, error) {
+	workloads := []client.Object{}
+
+	if c.Readiness != nil && len(c.Readiness.Workloads) > 0 {
+		for _, ref := range c.Readiness.Workloads {
+			var obj client.Object
+			switch ref.Kind {
+			case "Deployment":
+				obj = &appsv1.Deployment{}
+			case "StatefulSet":
+				obj = &appsv1.StatefulSet{}
+			case "DaemonSet":
+				obj = &appsv1.DaemonSet{}
+			default:
+				return nil, fmt.Errorf("unsupported workload kind %v", ref.Kind)
+			}
+			err := cli.Get(ctx, types.NamespacedName{Namespace: c.Namespace, Name: ref.Name}, obj)
+			if err != nil {
+				if apierros.IsNotFound(err) {
+					return nil, fmt.Errorf("%v %v/%v not found", ref.Kind, c.Namespace, ref.Name)
+				}
+				return nil, err
+			}
+			workloads = append(workloads, obj)
+		}
+		return workloads, nil
+	}
+
+	opts := []client.ListOption{client.InNamespace(c.Namespace), client.MatchingLabels{instanceLabel: c.Name}}
+
+	deployments := appsv1.DeploymentList{}
+	if err := cli.List(ctx, &deployments, opts...); err != nil {
+		return nil, err
+	}
+	for i := range deployments.Items {
+		workloads = append(workloads, &deployments.Items[i])
+	}
+
+	statefulSets := appsv1.StatefulSetList{}
+	if err := cli.List(ctx, &statefulSets, opts...); err != nil {
+		return nil, err
+	}
+	for i := range statefulSets.Items {
+		workloads = append(workloads, &statefulSets.Items[i])
+	}
+
+	daemonSets := appsv1.DaemonSetList{}
+	if err := cli.List(ctx, &daemonSets, opts...); err != nil {
+		return nil, err
+	}
+	for i := range daemonSets.Items {
+		workloads = append(workloads, &daemonSets.Items[i])
+	}
+
+	return workloads, nil
+}
+
+func workloadReady(obj client.Object) (bool, string) {
+	switch w := obj.(type) {
+	case *appsv1.Deployment:
+		replicas := replicasOf(w.Spec.Replicas)
+		if w.Status.ObservedGeneration < w.Generation {
+			return false, fmt.Sprintf("deployment %v spec not observed yet", w.Name)
+		}
+		if w.Status.UpdatedReplicas < replicas || w.Status.AvailableReplicas < replicas {
+			return false, fmt.Sprintf("deployment %v %d of %d replicas available", w.Name, w.Status.AvailableReplicas, replicas)
+		}
+	case *appsv1.StatefulSet:
+		replicas := replicasOf(w.Spec.Replicas)
+		if w.Status.ObservedGeneration < w.Generation {
+			return false, fmt.Sprintf("statefulset %v spec not observed yet", w.Name)
+		}
+		if w.Status.ReadyReplicas < replicas {
+			return false, fmt.Sprintf("statefulset %v %d of %d replicas ready", w.Name, w.Status.ReadyReplicas, replicas)
+		}
+	case *appsv1.DaemonSet:
+		if w.Status.ObservedGeneration < w.Generation {
+			return false, fmt.Sprintf("daemonset %v spec not observed yet", w.Name)
+		}
+		desired := w.Status.DesiredNumberScheduled
+		if w.Status.UpdatedNumberScheduled < desired || w.Status.NumberReady < desired {
+			return false, fmt.Sprintf("daemonset %v %d of %d pods ready", w.Name, w.Status.NumberReady, desired)
+		}
+	}
+	return true, ""
+}
+
+func replicasOf(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+// setCondition sets condition of component and keeps last transition time
+// if status not changed
+func setCondition(conditions []hotplugv1.ComponentCondition, name string, status metav1.ConditionStatus, reason, message string) []hotplugv1.ComponentCondition {
+	for i := range conditions {
+		if conditions[i].Name != name {
+			continue
+		}
+		if conditions[i].Status != status {
+			conditions[i].LastTransitionTime = metav1.Now()
+		}
+		conditions[i].Status = status
+		conditions[i].Reason = reason
+		conditions[i].Message = message
+		return conditions
+	}
+	return append(conditions, hotplugv1.ComponentCondition{
+		Name:               name,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: metav1.Now(),
+	})
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2024 Kubeworkz Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hotplug
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	helmrelease "helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	hotplugv1 "github.com/saashqdev/kubeworkz/pkg/apis/hotplug/v1"
+	hotplugutil "github.com/saashqdev/kubeworkz/pkg/utils/hotplug"
+)
+
+// maxHistory is the max revisions of component recorded in status
+const maxHistory = 10
+
+// ConfigHash returns hash of chart and resolved values of component which
+// decide helm release, values read from secrets are covered as well
+func ConfigHash(c hotplugv1.ComponentConfig, values map[string]interface{}) string {
+	b, _ := json.Marshal(struct {
+		PkgName string                 `json:"pkgName"`
+		Chart   *hotplugv1.ChartSource `json:"chart"`
+		Values  map[string]interface{} `json:"values"`
+	}{c.PkgName, c.Chart, values})
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:8])
+}
+
+// LastGoodRevision returns the newest revision older than current one which
+// was deployed successfully, 0 if not found. Releases are newest first.
+func LastGoodRevision(releases []*helmrelease.Release, current int) int {
+	for _, r := range releases {
+		if r.Version >= current || r.Info == nil {
+			continue
+		}
+		if r.Info.Status == helmrelease.StatusSuperseded || r.Info.Status == helmrelease.StatusDeployed {
+			return r.Version
+		}
+	}
+	return 0
+}
+
+// rollbackFailedUpgrade rolls release back to the last good revision after
+// upgrade failed, returns message to be appended to result
+func rollbackFailedUpgrade(helm *hotplugutil.Helm, c hotplugv1.ComponentConfig, history *hotplugv1.ComponentHistory, hash string) string {
+	releases, err := helm.History(c.Namespace, c.Name, maxHistory)
+	if err != nil {
+		return fmt.Sprintf(", get history for rollback fail, %v", err)
+	}
+	if len(releases) == 0 {
+		return ", no revision to rollback"
+	}
+	revision := LastGoodRevision(releases, releases[0].Version)
+	if revision == 0 {
+		return ", no good revision to rollback"
+	}
+	return rollbackTo(helm, c, history, revision, hash)
+}
+
+// rollbackUnhealthyUpgrade rolls release back to the revision observed ready
+// when upgraded revision never gets ready, returns message to be appended
+func rollbackUnhealthyUpgrade(helm *hotplugutil.Helm, c hotplugv1.ComponentConfig, history *hotplugv1.ComponentHistory, release *helmrelease.Release, hash string) string {
+	// only revisions upgraded from a ready one are rolled back, so that
+	// temporary unavailability of a running release does not trigger it
+	if history.ReadyRevision == 0 || release.Version <= history.ReadyRevision {
+		return ""
+	}
+	if history.RolledBackConfig == hash {
+		return ""
+	}
+	return rollbackTo(helm, c, history, history.ReadyRevision, hash)
+}
+
+func rollbackTo(helm *hotplugutil.Helm, c hotplugv1.ComponentConfig, history *hotplugv1.ComponentHistory, revision int, hash string) string {
+	if err := helm.Rollback(c.Namespace, c.Name, revision); err != nil {
+		return fmt.Sprintf(", rollback to revision %d fail, %v", revision, err)
+	}
+	history.RolledBackConfig = hash
+	return fmt.Sprintf(", rolled back to revision %d", revision)
+}
+
+// recordRevisions records recent revisions of component into history
+func recordRevisions(helm *hotplugutil.Helm, c hotplugv1.ComponentConfig, history *hotplugv1.ComponentHistory) error {
+	releases, err := helm.History(c.Namespace, c.Name, maxHistory)
+	if err != nil {
+		if errors.Is(err, driver.ErrReleaseNotFound) {
+			history.Revisions = nil
+			return nil
+		}
+		return err
+	}
+	history.Revisions = RevisionsOf(releases)
+	return nil
+}
+
+// RevisionsOf converts helm releases into revisions
+func RevisionsOf(releases []*helmrelease.Release) []hotplugv1.ReleaseRevision {
+	revisions := make([]hotplugv1.ReleaseRevision, 0, len(releases))
+	for _, r := range releases {
+		revision := hotplugv1.ReleaseRevision{Revision: r.Version}
+		if r.Chart != nil && r.Chart.Metadata != nil {
+			revision.ChartVersion = r.Chart.Metadata.Version
+			revision.AppVersion = r.Chart.Metadata.AppVersion
+		}
+		if r.Info != nil {
+			revision.Status = r.Info.Status.String()
+			revision.Description = r.Info.Description
+			revision.Updated = metav1.NewTime(r.Info.LastDeployed.Time)
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions
+}
+
+// historyOf returns history of component in status, empty one if not found
+func historyOf(histories []hotplugv1.ComponentHistory, name string) hotplugv1.ComponentHistory {
+	for _, h := range histories {
+		if h.Name == name {
+			return *h.DeepCopy()
+		}
+	}
+	return hotplugv1.ComponentHistory{Name: name}
+}
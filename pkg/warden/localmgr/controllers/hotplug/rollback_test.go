@@ -0,0 +1,94 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hotplug_test
+
+import (
+	"io"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+
+	hotplugv1 "github.com/saashqdev/kubeworkz/pkg/apis/hotplug/v1"
+	"github.com/saashqdev/kubeworkz/pkg/clog"
+	hotplugutil "github.com/saashqdev/kubeworkz/pkg/utils/hotplug"
+	"github.com/saashqdev/kubeworkz/pkg/warden/localmgr/controllers/hotplug"
+)
+
+var _ = Describe("Rollback", func() {
+
+	revision := func(version int, status release.Status) *release.Release {
+		return &release.Release{
+			Name:      "test-name",
+			Namespace: "test-ns",
+			Version:   version,
+			Info:      &release.Info{Status: status},
+			Chart: &chart.Chart{
+				Metadata:  &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "hello", Version: "0.1.0"},
+				Templates: []*chart.File{{Name: "templates/hello", Data: []byte("hello: world")}},
+			},
+		}
+	}
+
+	It("test history and rollback to last good revision", func() {
+		actionConfig := &action.Configuration{
+			Releases:     storage.Init(driver.NewMemory()),
+			KubeClient:   &kubefake.FailingKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard}},
+			Capabilities: chartutil.DefaultCapabilities,
+			Log:          clog.Info,
+		}
+		helm := hotplugutil.NewHelm()
+		helm.ActionConfig = map[string]*action.Configuration{"test-ns": actionConfig}
+		Expect(actionConfig.Releases.Create(revision(1, release.StatusSuperseded))).To(Succeed())
+		Expect(actionConfig.Releases.Create(revision(2, release.StatusFailed))).To(Succeed())
+		Expect(actionConfig.Releases.Create(revision(3, release.StatusFailed))).To(Succeed())
+
+		releases, err := helm.History("test-ns", "test-name", 10)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(len(releases)).To(Equal(3))
+		Expect(releases[0].Version).To(Equal(3))
+
+		revisions := hotplug.RevisionsOf(releases)
+		Expect(revisions[2].Status).To(Equal("superseded"))
+
+		good := hotplug.LastGoodRevision(releases, releases[0].Version)
+		Expect(good).To(Equal(1))
+		Expect(helm.Rollback("test-ns", "test-name", good)).To(Succeed())
+
+		releases, err = helm.History("test-ns", "test-name", 10)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(releases[0].Version).To(Equal(4))
+		Expect(releases[0].Info.Status).To(Equal(release.StatusDeployed))
+	})
+
+	It("test config hash", func() {
+		a := hotplugv1.ComponentConfig{Name: "a", PkgName: "a-1.0.0.tgz"}
+		b := a
+		b.Status = "disabled"
+		values := map[string]interface{}{"k": "v"}
+		Expect(hotplug.ConfigHash(a, values)).To(Equal(hotplug.ConfigHash(b, values)))
+		Expect(hotplug.ConfigHash(a, values)).NotTo(Equal(hotplug.ConfigHash(a, map[string]interface{}{"k": "w"})))
+		b.PkgName = "a-1.0.1.tgz"
+		Expect(hotplug.ConfigHash(a, values)).NotTo(Equal(hotplug.ConfigHash(b, values)))
+	})
+})
@@ -0,0 +1,58 @@
+/*
+Copyright 2024 Kubeworkz Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hotplug
+
+import (
+	"strings"
+
+	hotplugv1 "github.com/saashqdev/kubeworkz/pkg/apis/hotplug/v1"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+)
+
+// ReleasedCommon returns common hotplug with components released to cluster
+// and whether the current revision of common hotplug was released. When
+// common hotplug has rollout policy and its revision not released to cluster
+// yet, components last applied in cluster are kept.
+func ReleasedCommon(commonConfig hotplugv1.Hotplug, clusterName string) (hotplugv1.Hotplug, bool) {
+	if commonConfig.Spec.Rollout == nil || IsReleased(commonConfig, clusterName) {
+		return commonConfig, true
+	}
+	status := commonConfig.Status
+	// nothing applied before, there is nothing to keep
+	if status.AppliedRevision == "" && len(status.AppliedComponents) == 0 {
+		return commonConfig, true
+	}
+
+	released := *commonConfig.DeepCopy()
+	released.Spec.Component = status.AppliedComponents
+	return released, false
+}
+
+// IsReleased returns true if current revision of common hotplug was applied
+// in cluster or released to cluster by rollout controller
+func IsReleased(commonConfig hotplugv1.Hotplug, clusterName string) bool {
+	revision := commonConfig.Spec.Revision()
+	if commonConfig.Status.AppliedRevision == revision {
+		return true
+	}
+	if commonConfig.Annotations[constants.HotplugRolloutRevisionAnnotation] != revision {
+		return false
+	}
+	for _, c := range strings.Split(commonConfig.Annotations[constants.HotplugRolloutClustersAnnotation], ",") {
+		if c == clusterName {
+			return true
+		}
+	}
+	return false
+}
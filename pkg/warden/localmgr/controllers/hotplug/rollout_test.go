@@ -0,0 +1,73 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hotplug_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	hotplugv1 "github.com/saashqdev/kubeworkz/pkg/apis/hotplug/v1"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+	"github.com/saashqdev/kubeworkz/pkg/warden/localmgr/controllers/hotplug"
+)
+
+var _ = Describe("Rollout", func() {
+
+	applied := []hotplugv1.ComponentConfig{{Name: "audit", Status: "disabled"}}
+	newCommon := func() hotplugv1.Hotplug {
+		return hotplugv1.Hotplug{
+			ObjectMeta: metav1.ObjectMeta{Name: "common"},
+			Spec: hotplugv1.HotplugSpec{
+				Component: []hotplugv1.ComponentConfig{{Name: "audit", Status: "enabled"}},
+				Rollout:   &hotplugv1.RolloutPolicy{},
+			},
+			Status: hotplugv1.HotplugStatus{AppliedRevision: "old", AppliedComponents: applied},
+		}
+	}
+
+	It("test keep applied components until released", func() {
+		common := newCommon()
+		config, released := hotplug.ReleasedCommon(common, "member1")
+		Expect(released).To(BeFalse())
+		Expect(config.Spec.Component).To(Equal(applied))
+		Expect(common.Spec.Component[0].Status).To(Equal("enabled"))
+
+		common.Annotations = map[string]string{
+			constants.HotplugRolloutRevisionAnnotation: common.Spec.Revision(),
+			constants.HotplugRolloutClustersAnnotation: "pivot,member1",
+		}
+		config, released = hotplug.ReleasedCommon(common, "member1")
+		Expect(released).To(BeTrue())
+		Expect(config.Spec.Component).To(Equal(common.Spec.Component))
+
+		_, released = hotplug.ReleasedCommon(common, "member2")
+		Expect(released).To(BeFalse())
+	})
+
+	It("test apply at once without policy or applied components", func() {
+		common := newCommon()
+		common.Spec.Rollout = nil
+		_, released := hotplug.ReleasedCommon(common, "member1")
+		Expect(released).To(BeTrue())
+
+		common = newCommon()
+		common.Status = hotplugv1.HotplugStatus{}
+		_, released = hotplug.ReleasedCommon(common, "member1")
+		Expect(released).To(BeTrue())
+	})
+})
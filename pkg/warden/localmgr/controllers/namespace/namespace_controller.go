@@ -0,0 +1,188 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package namespace
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/saashqdev/kubeworkz/pkg/clog"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+	"github.com/saashqdev/kubeworkz/pkg/utils/env"
+	"github.com/saashqdev/kubeworkz/pkg/utils/lifecycle"
+)
+
+const (
+	// rbacWaitInterval is the interval to check if rbac spread into namespace
+	rbacWaitInterval = time.Second
+
+	// rbacWaitTimeout is the max duration to wait for rbac spread, namespace
+	// turns ready after that even no role binding found, such as namespace
+	// created by platform admin
+	rbacWaitTimeout = 30 * time.Second
+
+	reasonExpiring = "NamespaceExpiring"
+	reasonExpired  = "NamespaceExpired"
+)
+
+var _ reconcile.Reconciler = &NamespaceReconciler{}
+
+// NamespaceReconciler provisions namespaces asynchronously and cleans up
+// namespaces after expired
+type NamespaceReconciler struct {
+	client.Client
+	Recorder record.EventRecorder
+
+	// now is used to mock time in test
+	now func() time.Time
+}
+
+func newReconciler(mgr manager.Manager) (*NamespaceReconciler, error) {
+	r := &NamespaceReconciler{
+		Client:   mgr.GetClient(),
+		Recorder: mgr.GetEventRecorderFor("namespace-lifecycle"),
+		now:      time.Now,
+	}
+	return r, nil
+}
+
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch;update;delete
+//+kubebuilder:rbac:groups="",resources=resourcequotas,verbs=create
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Reconcile creates pending resource quota of namespace, waits for rbac spread
+// and deletes namespace when expired.
+func (r *NamespaceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ns := &corev1.Namespace{}
+	err := r.Get(ctx, req.NamespacedName, ns)
+	if err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// only sub namespaces of tenant are managed by lifecycle
+	if _, ok := ns.Labels[constants.HncTenantLabel]; !ok || ns.DeletionTimestamp != nil {
+		return ctrl.Result{}, nil
+	}
+
+	result, err := r.provision(ctx, ns)
+	if err != nil || result.RequeueAfter > 0 {
+		return result, err
+	}
+
+	return r.expire(ctx, ns)
+}
+
+// provision waits for rbac spread, then creates resource quota carried by
+// namespace and marks namespace ready
+func (r *NamespaceReconciler) provision(ctx context.Context, ns *corev1.Namespace) (ctrl.Result, error) {
+	if lifecycle.Phase(ns.Annotations[lifecycle.PhaseAnnotation]) != lifecycle.PhasePending {
+		return ctrl.Result{}, nil
+	}
+
+	bindings := rbacv1.RoleBindingList{}
+	err := r.List(ctx, &bindings, client.InNamespace(ns.Name))
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(bindings.Items) == 0 && r.now().Sub(ns.CreationTimestamp.Time) < rbacWaitTimeout {
+		return ctrl.Result{RequeueAfter: rbacWaitInterval}, nil
+	}
+
+	quota, err := lifecycle.PendingQuota(ns)
+	if err != nil {
+		return ctrl.Result{}, r.fail(ctx, ns, fmt.Sprintf("invalid resource quota: %v", err))
+	}
+	if quota != nil {
+		quota.Namespace = ns.Name
+		quota.ResourceVersion = ""
+		err = r.Create(ctx, quota)
+		if err != nil && !errors.IsAlreadyExists(err) {
+			clog.Warn("create resource quota of namespace %v failed: %v", ns.Name, err)
+			return ctrl.Result{}, r.fail(ctx, ns, err.Error())
+		}
+		delete(ns.Annotations, lifecycle.PendingQuotaAnnotation)
+		if err = r.Update(ctx, ns); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	lifecycle.SetPhase(ns, lifecycle.PhaseReady, "")
+	clog.Info("namespace %v provisioned", ns.Name)
+	return ctrl.Result{}, r.Update(ctx, ns)
+}
+
+func (r *NamespaceReconciler) fail(ctx context.Context, ns *corev1.Namespace, message string) error {
+	lifecycle.SetPhase(ns, lifecycle.PhaseFailed, message)
+	return r.Update(ctx, ns)
+}
+
+// expire warns before namespace expired and deletes it after expired
+func (r *NamespaceReconciler) expire(ctx context.Context, ns *corev1.Namespace) (ctrl.Result, error) {
+	expireAt := lifecycle.ExpireAt(ns)
+	if expireAt == nil {
+		return ctrl.Result{}, nil
+	}
+
+	remain := expireAt.Sub(r.now())
+	if remain <= 0 {
+		r.Recorder.Eventf(ns, corev1.EventTypeNormal, reasonExpired, "namespace %v expired at %v, deleting", ns.Name, expireAt.Format(time.RFC3339))
+		clog.Info("namespace %v expired at %v, deleting", ns.Name, expireAt)
+		return ctrl.Result{}, client.IgnoreNotFound(r.Delete(ctx, ns))
+	}
+
+	warning := env.NamespaceExpireWarningPeriod()
+	if remain > warning {
+		return ctrl.Result{RequeueAfter: remain - warning}, nil
+	}
+
+	// warn once for each expire time, expire time may be extended by members
+	expireAtStr := ns.Annotations[lifecycle.ExpireAtAnnotation]
+	if ns.Annotations[lifecycle.ExpireWarnedAnnotation] != expireAtStr {
+		r.Recorder.Eventf(ns, corev1.EventTypeWarning, reasonExpiring, "namespace %v will be deleted at %v", ns.Name, expireAtStr)
+		ns.Annotations[lifecycle.ExpireWarnedAnnotation] = expireAtStr
+		if lifecycle.Phase(ns.Annotations[lifecycle.PhaseAnnotation]) == lifecycle.PhaseReady {
+			lifecycle.SetPhase(ns, lifecycle.PhaseExpiring, fmt.Sprintf("will be deleted at %v", expireAtStr))
+		}
+		if err := r.Update(ctx, ns); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	return ctrl.Result{RequeueAfter: remain}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func SetupWithManager(mgr ctrl.Manager) error {
+	r, err := newReconciler(mgr)
+	if err != nil {
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("namespace-lifecycle").
+		For(&corev1.Namespace{}).
+		Complete(r)
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package namespace
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+	"github.com/saashqdev/kubeworkz/pkg/utils/lifecycle"
+)
+
+func newNamespace(name string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:              name,
+		Labels:            map[string]string{constants.HncTenantLabel: "tenant-1"},
+		CreationTimestamp: metav1.Now(),
+	}}
+}
+
+func TestProvision(t *testing.T) {
+	ns := newNamespace("ns-1")
+	lifecycle.SetPhase(ns, lifecycle.PhasePending, "")
+	_ = lifecycle.SetPendingQuota(ns, &corev1.ResourceQuota{ObjectMeta: metav1.ObjectMeta{Name: "quota"}})
+	binding := &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "binding", Namespace: "ns-1"}}
+
+	cli := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(ns, binding).Build()
+	r := &NamespaceReconciler{Client: cli, Recorder: record.NewFakeRecorder(10), now: time.Now}
+	ctx := context.Background()
+
+	_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "ns-1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = cli.Get(ctx, types.NamespacedName{Name: "quota", Namespace: "ns-1"}, &corev1.ResourceQuota{})
+	if err != nil {
+		t.Errorf("expect resource quota created: %v", err)
+	}
+	got := &corev1.Namespace{}
+	_ = cli.Get(ctx, types.NamespacedName{Name: "ns-1"}, got)
+	if s := lifecycle.StatusOf(got); s.Phase != lifecycle.PhaseReady {
+		t.Errorf("expect namespace ready, got %v", s.Phase)
+	}
+	if _, ok := got.Annotations[lifecycle.PendingQuotaAnnotation]; ok {
+		t.Errorf("expect pending quota annotation removed")
+	}
+}
+
+func TestProvisionWaitForRbac(t *testing.T) {
+	ns := newNamespace("ns-1")
+	lifecycle.SetPhase(ns, lifecycle.PhasePending, "")
+	_ = lifecycle.SetPendingQuota(ns, &corev1.ResourceQuota{ObjectMeta: metav1.ObjectMeta{Name: "quota"}})
+
+	cli := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(ns).Build()
+	r := &NamespaceReconciler{Client: cli, Recorder: record.NewFakeRecorder(10), now: time.Now}
+	ctx := context.Background()
+
+	result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "ns-1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.RequeueAfter != rbacWaitInterval {
+		t.Errorf("expect requeue after %v, got %v", rbacWaitInterval, result.RequeueAfter)
+	}
+	err = cli.Get(ctx, types.NamespacedName{Name: "quota", Namespace: "ns-1"}, &corev1.ResourceQuota{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("expect resource quota not created before rbac spread, got %v", err)
+	}
+}
+
+func TestExpire(t *testing.T) {
+	now := time.Now()
+	expireAt := now.Add(30 * time.Minute)
+	ns := newNamespace("ns-1")
+	lifecycle.SetExpireAt(ns, &expireAt)
+
+	cli := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(ns).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &NamespaceReconciler{Client: cli, Recorder: recorder, now: func() time.Time { return now }}
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "ns-1"}}
+
+	result, err := r.Reconcile(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.RequeueAfter <= 0 {
+		t.Errorf("expect requeue until expired")
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("expect warning event emitted before expired")
+	}
+
+	// warning should be emitted only once
+	_, _ = r.Reconcile(ctx, req)
+	if len(recorder.Events) != 1 {
+		t.Errorf("expect warning event emitted once, got %v", len(recorder.Events))
+	}
+
+	r.now = func() time.Time { return expireAt.Add(time.Second) }
+	if _, err = r.Reconcile(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	err = cli.Get(ctx, types.NamespacedName{Name: "ns-1"}, &corev1.Namespace{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("expect namespace deleted after expired, got %v", err)
+	}
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkpolicy
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	tenantv1 "github.com/saashqdev/kubeworkz/pkg/apis/tenant/v1"
+	"github.com/saashqdev/kubeworkz/pkg/clog"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+	"github.com/saashqdev/kubeworkz/pkg/utils/env"
+)
+
+// PolicyName is the name of network policy managed in each namespace of tenant
+const PolicyName = "kubeworkz-network-isolation"
+
+var _ reconcile.Reconciler = &NetworkPolicyReconciler{}
+
+// NetworkPolicyReconciler enforces network isolation of tenant and project
+// by network policies in namespaces of them
+type NetworkPolicyReconciler struct {
+	client.Client
+}
+
+func newReconciler(mgr manager.Manager) (*NetworkPolicyReconciler, error) {
+	r := &NetworkPolicyReconciler{
+		Client: mgr.GetClient(),
+	}
+	return r, nil
+}
+
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;delete
+
+// Reconcile takes namespace as request and ensures network policy of it
+// consistent with isolation mode of tenant and project.
+func (r *NetworkPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := clog.WithName("reconcile").WithValues("namespace", req.Name)
+
+	ns := &corev1.Namespace{}
+	err := r.Get(ctx, req.NamespacedName, ns)
+	if err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if ns.DeletionTimestamp != nil {
+		return ctrl.Result{}, nil
+	}
+
+	tenantName, ok := ns.Labels[constants.HncTenantLabel]
+	if !ok {
+		return ctrl.Result{}, nil
+	}
+
+	tenant := &tenantv1.Tenant{}
+	err = r.Get(ctx, types.NamespacedName{Name: tenantName}, tenant)
+	if err != nil && !errors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+	if errors.IsNotFound(err) {
+		tenant = nil
+	}
+
+	var project *tenantv1.Project
+	if projectName, ok := ns.Labels[constants.HncProjectLabel]; ok {
+		project = &tenantv1.Project{}
+		err = r.Get(ctx, types.NamespacedName{Name: projectName}, project)
+		if err != nil && !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		if errors.IsNotFound(err) {
+			project = nil
+		}
+	}
+
+	systemNamespaces := append([]string{env.KubeNamespace()}, env.IngressControllerNamespaces()...)
+	expect := BuildPolicy(ns, tenant, project, systemNamespaces)
+
+	err = r.ensurePolicy(ctx, ns.Name, expect)
+	if err != nil {
+		log.Warn("ensure network policy failed: %v", err)
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// ensurePolicy creates, updates or deletes managed network policy of
+// namespace, policy will be deleted if expect is nil
+func (r *NetworkPolicyReconciler) ensurePolicy(ctx context.Context, namespace string, expect *networkingv1.NetworkPolicy) error {
+	current := &networkingv1.NetworkPolicy{}
+	err := r.Get(ctx, types.NamespacedName{Name: PolicyName, Namespace: namespace}, current)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	exist := err == nil
+
+	switch {
+	case expect == nil && exist:
+		clog.Info("network isolation of namespace %v disabled", namespace)
+		return client.IgnoreNotFound(r.Delete(ctx, current))
+	case expect == nil:
+		return nil
+	case !exist:
+		clog.Info("network isolation of namespace %v enabled", namespace)
+		return r.Create(ctx, expect)
+	case equality.Semantic.DeepEqual(current.Spec, expect.Spec) && equality.Semantic.DeepEqual(current.Labels, expect.Labels):
+		return nil
+	default:
+		current.Spec = expect.Spec
+		current.Labels = expect.Labels
+		return r.Update(ctx, current)
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func SetupWithManager(mgr ctrl.Manager) error {
+	r, err := newReconciler(mgr)
+	if err != nil {
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("networkpolicy").
+		For(&corev1.Namespace{}).
+		Watches(&tenantv1.Tenant{}, handler.EnqueueRequestsFromMapFunc(r.namespacesOf(constants.HncTenantLabel))).
+		Watches(&tenantv1.Project{}, handler.EnqueueRequestsFromMapFunc(r.namespacesOf(constants.HncProjectLabel))).
+		Watches(&networkingv1.NetworkPolicy{}, handler.EnqueueRequestsFromMapFunc(managedToNamespace)).
+		Complete(r)
+}
+
+// namespacesOf enqueues namespaces labelled with name of tenant or project
+func (r *NetworkPolicyReconciler) namespacesOf(label string) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		nsList := corev1.NamespaceList{}
+		err := r.List(ctx, &nsList, client.MatchingLabels{label: obj.GetName()})
+		if err != nil {
+			clog.Warn("list namespaces of %v failed: %v", obj.GetName(), err)
+			return nil
+		}
+		requests := make([]reconcile.Request, 0, len(nsList.Items))
+		for _, ns := range nsList.Items {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: ns.Name}})
+		}
+		return requests
+	}
+}
+
+// managedToNamespace enqueues namespace of managed network policy,
+// so that changes made by hand will be reverted
+func managedToNamespace(_ context.Context, obj client.Object) []reconcile.Request {
+	if _, ok := obj.GetLabels()[constants.NetworkIsolationLabel]; !ok {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: obj.GetNamespace()}}}
+}
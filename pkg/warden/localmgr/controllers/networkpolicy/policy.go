@@ -0,0 +1,86 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkpolicy
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tenantv1 "github.com/saashqdev/kubeworkz/pkg/apis/tenant/v1"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+)
+
+// BuildPolicy builds network policy of namespace by isolation mode of tenant
+// and project, nil returned if namespace no need to be isolated. Traffic from
+// systemNamespaces, such as namespaces of kubeworkz components and ingress
+// controllers, is always allowed.
+func BuildPolicy(ns *corev1.Namespace, tenant *tenantv1.Tenant, project *tenantv1.Project, systemNamespaces []string) *networkingv1.NetworkPolicy {
+	mode := tenantv1.EffectiveNetworkIsolation(tenant, project)
+
+	var peers []networkingv1.NetworkPolicyPeer
+	switch mode {
+	case tenantv1.NetworkIsolationTenant:
+		peers = append(peers, namespacePeer(map[string]string{constants.HncTenantLabel: ns.Labels[constants.HncTenantLabel]}))
+	case tenantv1.NetworkIsolationProject:
+		projectName, ok := ns.Labels[constants.HncProjectLabel]
+		if !ok {
+			// namespace not belongs to any project falls back to tenant isolation
+			peers = append(peers, namespacePeer(map[string]string{constants.HncTenantLabel: ns.Labels[constants.HncTenantLabel]}))
+		} else {
+			peers = append(peers, namespacePeer(map[string]string{constants.HncProjectLabel: projectName}))
+		}
+	default:
+		return nil
+	}
+
+	if tenant != nil {
+		for _, allowed := range tenant.Spec.AllowedTenants {
+			peers = append(peers, namespacePeer(map[string]string{constants.HncTenantLabel: allowed}))
+		}
+	}
+
+	// traffic from ingress controllers and kubeworkz components
+	peers = append(peers, networkingv1.NetworkPolicyPeer{
+		NamespaceSelector: &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{{
+				Key:      corev1.LabelMetadataName,
+				Operator: metav1.LabelSelectorOpIn,
+				Values:   systemNamespaces,
+			}},
+		},
+	})
+
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      PolicyName,
+			Namespace: ns.Name,
+			Labels:    map[string]string{constants.NetworkIsolationLabel: string(mode)},
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress:     []networkingv1.NetworkPolicyIngressRule{{From: peers}},
+		},
+	}
+}
+
+func namespacePeer(labels map[string]string) networkingv1.NetworkPolicyPeer {
+	return networkingv1.NetworkPolicyPeer{
+		NamespaceSelector: &metav1.LabelSelector{MatchLabels: labels},
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkpolicy
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tenantv1 "github.com/saashqdev/kubeworkz/pkg/apis/tenant/v1"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+)
+
+func TestBuildPolicy(t *testing.T) {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name: "ns-1",
+		Labels: map[string]string{
+			constants.HncTenantLabel:  "tenant-1",
+			constants.HncProjectLabel: "project-1",
+		},
+	}}
+
+	tenant := &tenantv1.Tenant{ObjectMeta: metav1.ObjectMeta{Name: "tenant-1"}}
+	if p := BuildPolicy(ns, tenant, nil, nil); p != nil {
+		t.Errorf("expect no policy when isolation not set, got %v", p)
+	}
+
+	tenant.Spec.NetworkIsolation = tenantv1.NetworkIsolationTenant
+	tenant.Spec.AllowedTenants = []string{"tenant-2"}
+	p := BuildPolicy(ns, tenant, nil, []string{"kubeworkz-system", "ingress-nginx"})
+	if p == nil {
+		t.Fatalf("expect policy built")
+	}
+	peers := p.Spec.Ingress[0].From
+	if len(peers) != 3 {
+		t.Fatalf("expect 3 peers: own tenant, allowed tenant and system namespaces, got %v", peers)
+	}
+	if peers[0].NamespaceSelector.MatchLabels[constants.HncTenantLabel] != "tenant-1" {
+		t.Errorf("expect traffic of own tenant allowed, got %v", peers[0])
+	}
+	if peers[1].NamespaceSelector.MatchLabels[constants.HncTenantLabel] != "tenant-2" {
+		t.Errorf("expect traffic of allowed tenant allowed, got %v", peers[1])
+	}
+	if values := peers[2].NamespaceSelector.MatchExpressions[0].Values; !reflect.DeepEqual(values, []string{"kubeworkz-system", "ingress-nginx"}) {
+		t.Errorf("expect traffic of system namespaces allowed, got %v", values)
+	}
+
+	project := &tenantv1.Project{
+		ObjectMeta: metav1.ObjectMeta{Name: "project-1"},
+		Spec:       tenantv1.ProjectSpec{NetworkIsolation: tenantv1.NetworkIsolationProject},
+	}
+	p = BuildPolicy(ns, tenant, project, nil)
+	if p.Spec.Ingress[0].From[0].NamespaceSelector.MatchLabels[constants.HncProjectLabel] != "project-1" {
+		t.Errorf("expect project isolation overrides tenant, got %v", p.Spec.Ingress[0].From[0])
+	}
+	if p.Labels[constants.NetworkIsolationLabel] != string(tenantv1.NetworkIsolationProject) {
+		t.Errorf("expect policy labelled with isolation mode, got %v", p.Labels)
+	}
+}
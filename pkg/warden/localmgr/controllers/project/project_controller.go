@@ -29,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
@@ -44,12 +45,18 @@ var _ reconcile.Reconciler = &ProjectReconciler{}
 type ProjectReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// pivotClient used to report status of project to pivot cluster
+	pivotClient client.Client
+	cluster     string
 }
 
-func newReconciler(mgr manager.Manager) (*ProjectReconciler, error) {
+func newReconciler(mgr manager.Manager, pivotClient client.Client, cluster string) (*ProjectReconciler, error) {
 	r := &ProjectReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:      mgr.GetClient(),
+		Scheme:      mgr.GetScheme(),
+		pivotClient: pivotClient,
+		cluster:     cluster,
 	}
 	return r, nil
 }
@@ -126,15 +133,21 @@ func (r *ProjectReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return ctrl.Result{}, fmt.Errorf("the tenant %s do not content .spec.namespace", tenantName)
 	}
 
+	var nsErr error
 	if env.CreateHNCNs() {
-		err = r.crateProjectNamespace(ctx, tenantName, project.Name)
-		if err != nil {
-			clog.Error(err.Error())
-			return ctrl.Result{}, err
+		nsErr = r.crateProjectNamespace(ctx, tenantName, project.Name)
+		if nsErr != nil {
+			clog.Error(nsErr.Error())
 		}
 	}
 
-	return ctrl.Result{}, nil
+	err = r.updateStatus(ctx, project.Name, nsErr)
+	if err != nil {
+		log.Warn("update status of project failed: %v", err)
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nsErr
 }
 
 func (r *ProjectReconciler) deleteProject(projectName string) (ctrl.Result, error) {
@@ -188,16 +201,27 @@ func (r *ProjectReconciler) deleteSubNSOfProject(projectName string) error {
 }
 
 // SetupWithManager sets up the controller with the Manager.
-func SetupWithManager(mgr ctrl.Manager) error {
-	r, err := newReconciler(mgr)
+func SetupWithManager(mgr ctrl.Manager, pivotClient client.Client, cluster string) error {
+	r, err := newReconciler(mgr, pivotClient, cluster)
 	if err != nil {
 		return err
 	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&tenantv1.Project{}).
+		Watches(&v1.Namespace{}, handler.EnqueueRequestsFromMapFunc(namespaceToProject)).
 		Complete(r)
 }
 
+// namespaceToProject enqueues the project which namespace belongs to,
+// so that namespace count of project status keeps fresh
+func namespaceToProject(_ context.Context, obj client.Object) []reconcile.Request {
+	project, ok := obj.GetLabels()[constants.HncProjectLabel]
+	if !ok || len(project) == 0 {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: project}}}
+}
+
 func (r *ProjectReconciler) crateProjectNamespace(ctx context.Context, tenant, project string) error {
 	ns := &v1.Namespace{
 		ObjectMeta: metav1.ObjectMeta{
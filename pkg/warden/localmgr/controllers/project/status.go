@@ -0,0 +1,95 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	tenantv1 "github.com/saashqdev/kubeworkz/pkg/apis/tenant/v1"
+	userv1 "github.com/saashqdev/kubeworkz/pkg/apis/user/v1"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+	"github.com/saashqdev/kubeworkz/pkg/warden/utils"
+)
+
+// updateStatus reports condition of local cluster into project on pivot
+// cluster and refreshes the phase and summary of project.
+func (r *ProjectReconciler) updateStatus(ctx context.Context, name string, reconcileErr error) error {
+	if r.pivotClient == nil {
+		return nil
+	}
+
+	cond, err := utils.ClusterCondition(ctx, r.Client, r.cluster, client.MatchingLabels{constants.HncProjectLabel: name}, reconcileErr)
+	if err != nil {
+		return err
+	}
+
+	clusters, err := utils.ClusterNames(ctx, r.pivotClient)
+	if err != nil {
+		return err
+	}
+
+	memberCount, err := r.memberCount(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		project := &tenantv1.Project{}
+		err := r.pivotClient.Get(ctx, types.NamespacedName{Name: name}, project)
+		if err != nil {
+			return err
+		}
+
+		status := project.Status.DeepCopy()
+		status.Conditions, _ = tenantv1.SetClusterCondition(status.Conditions, cond)
+		status.Conditions, _ = tenantv1.PruneClusterConditions(status.Conditions, clusters)
+		status.Phase = tenantv1.ComputePhase(status.Conditions, clusters, project.DeletionTimestamp != nil)
+		status.Summary = tenantv1.SummarizeConditions(status.Conditions)
+		status.Summary.MemberCount = memberCount
+
+		// avoid of update loop between clusters
+		if equality.Semantic.DeepEqual(status, &project.Status) {
+			return nil
+		}
+
+		project.Status = *status
+		return r.pivotClient.Status().Update(ctx, project)
+	})
+}
+
+// memberCount counts users belong to given project
+func (r *ProjectReconciler) memberCount(ctx context.Context, project string) (int, error) {
+	userList := userv1.UserList{}
+	err := r.pivotClient.List(ctx, &userList)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := range userList.Items {
+		if userv1.BelongsToProject(&userList.Items[i], project) {
+			count++
+		}
+	}
+
+	return count, nil
+}
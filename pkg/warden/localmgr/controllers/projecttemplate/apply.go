@@ -0,0 +1,252 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projecttemplate
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	tenantv1 "github.com/saashqdev/kubeworkz/pkg/apis/tenant/v1"
+	"github.com/saashqdev/kubeworkz/pkg/clog"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+	"github.com/saashqdev/kubeworkz/pkg/utils/env"
+)
+
+const defaultServiceAccount = "default"
+
+// apply makes objects defined in template exist in namespace
+func (r *ProjectTemplateReconciler) apply(ctx context.Context, ns *corev1.Namespace, template *tenantv1.ProjectTemplate) error {
+	if err := r.applyNamespaceMeta(ctx, ns, template); err != nil {
+		return err
+	}
+
+	for _, lr := range template.Spec.LimitRanges {
+		obj := &corev1.LimitRange{}
+		obj.Name, obj.Namespace = lr.Name, ns.Name
+		spec := lr.Spec
+		if err := r.createOrUpdate(ctx, obj, template.Name, func() { obj.Spec = spec }); err != nil {
+			return err
+		}
+	}
+
+	for _, rq := range template.Spec.ResourceQuotas {
+		obj := &corev1.ResourceQuota{}
+		obj.Name, obj.Namespace = rq.Name, ns.Name
+		spec := rq.Spec
+		if err := r.createOrUpdate(ctx, obj, template.Name, func() { obj.Spec = spec }); err != nil {
+			return err
+		}
+	}
+
+	for _, np := range template.Spec.NetworkPolicies {
+		obj := &networkingv1.NetworkPolicy{}
+		obj.Name, obj.Namespace = np.Name, ns.Name
+		spec := np.Spec
+		if err := r.createOrUpdate(ctx, obj, template.Name, func() { obj.Spec = spec }); err != nil {
+			return err
+		}
+	}
+
+	for _, rb := range template.Spec.RoleBindings {
+		obj := &rbacv1.RoleBinding{}
+		obj.Name, obj.Namespace = rb.Name, ns.Name
+		subjects, roleRef := rb.Subjects, rb.RoleRef
+		if err := r.createOrUpdate(ctx, obj, template.Name, func() {
+			obj.Subjects = subjects
+			obj.RoleRef = roleRef
+		}); err != nil {
+			return err
+		}
+	}
+
+	return r.applyImagePullSecrets(ctx, ns.Name, template)
+}
+
+// applyNamespaceMeta adds labels and annotations of template into namespace
+func (r *ProjectTemplateReconciler) applyNamespaceMeta(ctx context.Context, ns *corev1.Namespace, template *tenantv1.ProjectTemplate) error {
+	needUpdate := false
+	if ns.Labels == nil {
+		ns.Labels = make(map[string]string)
+	}
+	for k, v := range template.Spec.Labels {
+		if ns.Labels[k] != v {
+			ns.Labels[k] = v
+			needUpdate = true
+		}
+	}
+	if ns.Annotations == nil {
+		ns.Annotations = make(map[string]string)
+	}
+	for k, v := range template.Spec.Annotations {
+		if ns.Annotations[k] != v {
+			ns.Annotations[k] = v
+			needUpdate = true
+		}
+	}
+	if !needUpdate {
+		return nil
+	}
+	return r.Update(ctx, ns)
+}
+
+// applyImagePullSecrets copies image pull secrets from kubeworkz system
+// namespace and refers them in default service account
+func (r *ProjectTemplateReconciler) applyImagePullSecrets(ctx context.Context, namespace string, template *tenantv1.ProjectTemplate) error {
+	if len(template.Spec.ImagePullSecrets) == 0 {
+		return nil
+	}
+
+	for _, name := range template.Spec.ImagePullSecrets {
+		source := &corev1.Secret{}
+		err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: env.KubeNamespace()}, source)
+		if err != nil {
+			return fmt.Errorf("get image pull secret %v failed: %v", name, err)
+		}
+		obj := &corev1.Secret{}
+		obj.Name, obj.Namespace = name, namespace
+		if err = r.createOrUpdate(ctx, obj, template.Name, func() {
+			obj.Type = source.Type
+			obj.Data = source.Data
+		}); err != nil {
+			return err
+		}
+	}
+
+	sa := &corev1.ServiceAccount{}
+	err := r.Get(ctx, types.NamespacedName{Name: defaultServiceAccount, Namespace: namespace}, sa)
+	if err != nil {
+		// default service account may not be created yet, retry later
+		return err
+	}
+	needUpdate := false
+	for _, name := range template.Spec.ImagePullSecrets {
+		if !hasImagePullSecret(sa, name) {
+			sa.ImagePullSecrets = append(sa.ImagePullSecrets, corev1.LocalObjectReference{Name: name})
+			needUpdate = true
+		}
+	}
+	if !needUpdate {
+		return nil
+	}
+	return r.Update(ctx, sa)
+}
+
+// createOrUpdate creates or updates object with given mutate func and marks
+// it as managed by template
+func (r *ProjectTemplateReconciler) createOrUpdate(ctx context.Context, obj client.Object, template string, mutate func()) error {
+	result, err := controllerutil.CreateOrUpdate(ctx, r.Client, obj, func() error {
+		mutate()
+		labels := obj.GetLabels()
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		labels[constants.ProjectTemplateLabel] = template
+		obj.SetLabels(labels)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("apply %T %v/%v failed: %v", obj, obj.GetNamespace(), obj.GetName(), err)
+	}
+	if result != controllerutil.OperationResultNone {
+		clog.Info("%T %v/%v of project template %v %v", obj, obj.GetNamespace(), obj.GetName(), template, result)
+	}
+	return nil
+}
+
+// prune deletes objects managed by template but no longer defined in it,
+// all managed objects will be deleted if template is nil
+func (r *ProjectTemplateReconciler) prune(ctx context.Context, namespace string, template *tenantv1.ProjectTemplate) error {
+	desired := desiredObjects(template)
+
+	lists := []client.ObjectList{
+		&corev1.LimitRangeList{},
+		&corev1.ResourceQuotaList{},
+		&networkingv1.NetworkPolicyList{},
+		&rbacv1.RoleBindingList{},
+		&corev1.SecretList{},
+	}
+
+	for _, list := range lists {
+		err := r.List(ctx, list, client.InNamespace(namespace), client.HasLabels{constants.ProjectTemplateLabel})
+		if err != nil {
+			return err
+		}
+		objs, err := meta.ExtractList(list)
+		if err != nil {
+			return err
+		}
+		for _, o := range objs {
+			obj, ok := o.(client.Object)
+			if !ok {
+				continue
+			}
+			key := fmt.Sprintf("%T/%v", obj, obj.GetName())
+			if template != nil && obj.GetLabels()[constants.ProjectTemplateLabel] == template.Name && desired[key] {
+				continue
+			}
+			err = r.Delete(ctx, obj)
+			if err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+			clog.Info("%T %v/%v no longer defined in project template, deleted", obj, namespace, obj.GetName())
+		}
+	}
+
+	return nil
+}
+
+// desiredObjects returns keys of objects defined in template
+func desiredObjects(template *tenantv1.ProjectTemplate) map[string]bool {
+	desired := make(map[string]bool)
+	if template == nil {
+		return desired
+	}
+	for _, o := range template.Spec.LimitRanges {
+		desired[fmt.Sprintf("%T/%v", &corev1.LimitRange{}, o.Name)] = true
+	}
+	for _, o := range template.Spec.ResourceQuotas {
+		desired[fmt.Sprintf("%T/%v", &corev1.ResourceQuota{}, o.Name)] = true
+	}
+	for _, o := range template.Spec.NetworkPolicies {
+		desired[fmt.Sprintf("%T/%v", &networkingv1.NetworkPolicy{}, o.Name)] = true
+	}
+	for _, o := range template.Spec.RoleBindings {
+		desired[fmt.Sprintf("%T/%v", &rbacv1.RoleBinding{}, o.Name)] = true
+	}
+	for _, name := range template.Spec.ImagePullSecrets {
+		desired[fmt.Sprintf("%T/%v", &corev1.Secret{}, name)] = true
+	}
+	return desired
+}
+
+func hasImagePullSecret(sa *corev1.ServiceAccount, name string) bool {
+	for _, s := range sa.ImagePullSecrets {
+		if s.Name == name {
+			return true
+		}
+	}
+	return false
+}
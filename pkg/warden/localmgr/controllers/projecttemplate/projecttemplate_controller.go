@@ -0,0 +1,184 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projecttemplate
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	tenantv1 "github.com/saashqdev/kubeworkz/pkg/apis/tenant/v1"
+	"github.com/saashqdev/kubeworkz/pkg/clog"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+)
+
+var _ reconcile.Reconciler = &ProjectTemplateReconciler{}
+
+// ProjectTemplateReconciler applies objects defined in project template into
+// namespaces of project and keeps them consistent with template
+type ProjectTemplateReconciler struct {
+	client.Client
+}
+
+func newReconciler(mgr manager.Manager) (*ProjectTemplateReconciler, error) {
+	r := &ProjectTemplateReconciler{
+		Client: mgr.GetClient(),
+	}
+	return r, nil
+}
+
+//+kubebuilder:rbac:groups=tenant.kubeworkz.io,resources=projecttemplates,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch;update
+//+kubebuilder:rbac:groups="",resources=limitranges;resourcequotas;secrets;serviceaccounts,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;list;watch;create;update;delete
+
+// Reconcile takes namespace as request, finds out the project template of
+// namespace and applies it.
+func (r *ProjectTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := clog.WithName("reconcile").WithValues("namespace", req.Name)
+
+	ns := &corev1.Namespace{}
+	err := r.Get(ctx, req.NamespacedName, ns)
+	if err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if ns.DeletionTimestamp != nil {
+		return ctrl.Result{}, nil
+	}
+
+	project, ok := ns.Labels[constants.HncProjectLabel]
+	if !ok {
+		return ctrl.Result{}, nil
+	}
+
+	template, err := r.templateOf(ctx, ns, project)
+	if err != nil {
+		log.Warn("get project template failed: %v", err)
+		return ctrl.Result{}, err
+	}
+
+	if template != nil {
+		if err = r.apply(ctx, ns, template); err != nil {
+			log.Warn("apply project template %v failed: %v", template.Name, err)
+			return ctrl.Result{}, err
+		}
+	}
+
+	if err = r.prune(ctx, ns.Name, template); err != nil {
+		log.Warn("prune objects of project template failed: %v", err)
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// templateOf returns the template chosen by namespace or inherited from
+// project, nil returned if namespace has no template
+func (r *ProjectTemplateReconciler) templateOf(ctx context.Context, ns *corev1.Namespace, project string) (*tenantv1.ProjectTemplate, error) {
+	name := ns.Labels[constants.ProjectTemplateLabel]
+	if len(name) == 0 {
+		p := &tenantv1.Project{}
+		err := r.Get(ctx, types.NamespacedName{Name: project}, p)
+		if err != nil {
+			return nil, client.IgnoreNotFound(err)
+		}
+		name = p.Spec.Template
+	}
+	if len(name) == 0 {
+		return nil, nil
+	}
+
+	template := &tenantv1.ProjectTemplate{}
+	err := r.Get(ctx, types.NamespacedName{Name: name}, template)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			clog.Warn("project template %v of namespace %v not found", name, ns.Name)
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return template, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func SetupWithManager(mgr ctrl.Manager) error {
+	r, err := newReconciler(mgr)
+	if err != nil {
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("projecttemplate").
+		For(&corev1.Namespace{}).
+		Watches(&tenantv1.ProjectTemplate{}, handler.EnqueueRequestsFromMapFunc(r.templateToNamespaces)).
+		Watches(&tenantv1.Project{}, handler.EnqueueRequestsFromMapFunc(r.projectToNamespaces)).
+		Watches(&corev1.LimitRange{}, handler.EnqueueRequestsFromMapFunc(managedToNamespace)).
+		Watches(&corev1.ResourceQuota{}, handler.EnqueueRequestsFromMapFunc(managedToNamespace)).
+		Watches(&networkingv1.NetworkPolicy{}, handler.EnqueueRequestsFromMapFunc(managedToNamespace)).
+		Watches(&rbacv1.RoleBinding{}, handler.EnqueueRequestsFromMapFunc(managedToNamespace)).
+		Complete(r)
+}
+
+// templateToNamespaces enqueues all namespaces of projects, template of
+// namespace will be resolved when reconcile
+func (r *ProjectTemplateReconciler) templateToNamespaces(ctx context.Context, _ client.Object) []reconcile.Request {
+	nsList := corev1.NamespaceList{}
+	err := r.List(ctx, &nsList, client.HasLabels{constants.HncProjectLabel})
+	if err != nil {
+		clog.Warn("list namespaces of projects failed: %v", err)
+		return nil
+	}
+	return toRequests(nsList.Items)
+}
+
+// projectToNamespaces enqueues namespaces of project
+func (r *ProjectTemplateReconciler) projectToNamespaces(ctx context.Context, obj client.Object) []reconcile.Request {
+	nsList := corev1.NamespaceList{}
+	err := r.List(ctx, &nsList, client.MatchingLabels{constants.HncProjectLabel: obj.GetName()})
+	if err != nil {
+		clog.Warn("list namespaces of project %v failed: %v", obj.GetName(), err)
+		return nil
+	}
+	return toRequests(nsList.Items)
+}
+
+// managedToNamespace enqueues namespace of object managed by template,
+// so that changes made by hand will be reverted
+func managedToNamespace(_ context.Context, obj client.Object) []reconcile.Request {
+	if _, ok := obj.GetLabels()[constants.ProjectTemplateLabel]; !ok {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: obj.GetNamespace()}}}
+}
+
+func toRequests(namespaces []corev1.Namespace) []reconcile.Request {
+	requests := make([]reconcile.Request, 0, len(namespaces))
+	for _, ns := range namespaces {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: ns.Name}})
+	}
+	return requests
+}
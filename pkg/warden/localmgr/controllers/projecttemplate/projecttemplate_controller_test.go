@@ -0,0 +1,90 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projecttemplate
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	tenantv1 "github.com/saashqdev/kubeworkz/pkg/apis/tenant/v1"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+)
+
+func TestReconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = tenantv1.AddToScheme(scheme)
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "ns-1",
+		Labels: map[string]string{constants.HncProjectLabel: "project-1"},
+	}}
+	project := &tenantv1.Project{
+		ObjectMeta: metav1.ObjectMeta{Name: "project-1"},
+		Spec:       tenantv1.ProjectSpec{Template: "default"},
+	}
+	template := &tenantv1.ProjectTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec: tenantv1.ProjectTemplateSpec{
+			Labels:      map[string]string{"env": "dev"},
+			LimitRanges: []tenantv1.LimitRangeTemplate{{Name: "default-limits"}},
+		},
+	}
+	stale := &corev1.LimitRange{ObjectMeta: metav1.ObjectMeta{
+		Name:      "stale",
+		Namespace: "ns-1",
+		Labels:    map[string]string{constants.ProjectTemplateLabel: "default"},
+	}}
+
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(ns, project, template, stale).Build()
+	r := &ProjectTemplateReconciler{Client: cli}
+	ctx := context.Background()
+
+	_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "ns-1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gotNs := &corev1.Namespace{}
+	_ = cli.Get(ctx, types.NamespacedName{Name: "ns-1"}, gotNs)
+	if gotNs.Labels["env"] != "dev" {
+		t.Errorf("expect labels of template added into namespace, got %v", gotNs.Labels)
+	}
+
+	lr := &corev1.LimitRange{}
+	err = cli.Get(ctx, types.NamespacedName{Name: "default-limits", Namespace: "ns-1"}, lr)
+	if err != nil {
+		t.Fatalf("expect limit range of template created: %v", err)
+	}
+	if lr.Labels[constants.ProjectTemplateLabel] != "default" {
+		t.Errorf("expect limit range marked as managed by template")
+	}
+
+	err = cli.Get(ctx, types.NamespacedName{Name: "stale", Namespace: "ns-1"}, &corev1.LimitRange{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("expect stale limit range pruned, got %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	tenantv1 "github.com/saashqdev/kubeworkz/pkg/apis/tenant/v1"
+	userv1 "github.com/saashqdev/kubeworkz/pkg/apis/user/v1"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+	"github.com/saashqdev/kubeworkz/pkg/warden/utils"
+)
+
+// updateStatus reports condition of local cluster into tenant on pivot
+// cluster and refreshes the phase and summary of tenant.
+func (r *TenantReconciler) updateStatus(ctx context.Context, name string, reconcileErr error) error {
+	if r.pivotClient == nil {
+		return nil
+	}
+
+	cond, err := utils.ClusterCondition(ctx, r.Client, r.cluster, client.MatchingLabels{constants.HncTenantLabel: name}, reconcileErr)
+	if err != nil {
+		return err
+	}
+
+	clusters, err := utils.ClusterNames(ctx, r.pivotClient)
+	if err != nil {
+		return err
+	}
+
+	memberCount, err := r.memberCount(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	projectList := tenantv1.ProjectList{}
+	err = r.pivotClient.List(ctx, &projectList, client.MatchingLabels{constants.TenantLabel: name})
+	if err != nil {
+		return err
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		tenant := &tenantv1.Tenant{}
+		err := r.pivotClient.Get(ctx, types.NamespacedName{Name: name}, tenant)
+		if err != nil {
+			return err
+		}
+
+		status := tenant.Status.DeepCopy()
+		status.Conditions, _ = tenantv1.SetClusterCondition(status.Conditions, cond)
+		status.Conditions, _ = tenantv1.PruneClusterConditions(status.Conditions, clusters)
+		_, deletionRequested := tenant.Annotations[constants.DeletionRequestedAnnotation]
+		status.Phase = tenantv1.ComputePhase(status.Conditions, clusters, tenant.DeletionTimestamp != nil || deletionRequested)
+		if status.Phase == tenantv1.PhaseActive && tenant.Spec.Suspended {
+			status.Phase = tenantv1.PhaseSuspended
+		}
+		status.ProjectCount = len(projectList.Items)
+		status.Summary = tenantv1.SummarizeConditions(status.Conditions)
+		status.Summary.MemberCount = memberCount
+
+		// avoid of update loop between clusters
+		if equality.Semantic.DeepEqual(status, &tenant.Status) {
+			return nil
+		}
+
+		tenant.Status = *status
+		return r.pivotClient.Status().Update(ctx, tenant)
+	})
+}
+
+// memberCount counts users belong to given tenant
+func (r *TenantReconciler) memberCount(ctx context.Context, tenant string) (int, error) {
+	userList := userv1.UserList{}
+	err := r.pivotClient.List(ctx, &userList)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := range userList.Items {
+		if userv1.BelongsToTenant(&userList.Items[i], tenant) {
+			count++
+		}
+	}
+
+	return count, nil
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/saashqdev/kubeworkz/pkg/apis"
+	clusterv1 "github.com/saashqdev/kubeworkz/pkg/apis/cluster/v1"
+	tenantv1 "github.com/saashqdev/kubeworkz/pkg/apis/tenant/v1"
+)
+
+func TestUpdateStatusPrunesRemovedClusters(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = apis.AddToScheme(scheme)
+
+	tenant := &tenantv1.Tenant{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant-1"},
+		Status: tenantv1.TenantStatus{
+			Phase: tenantv1.PhaseFailed,
+			Conditions: []tenantv1.ClusterCondition{
+				{Cluster: "removed", Status: metav1.ConditionFalse, Reason: "NamespaceFailed"},
+			},
+		},
+	}
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "local"}}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tenant, cluster).WithStatusSubresource(tenant).Build()
+	r := &TenantReconciler{Client: cli, Scheme: scheme, pivotClient: cli, cluster: "local"}
+	ctx := context.Background()
+
+	if err := r.updateStatus(ctx, "tenant-1", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	got := &tenantv1.Tenant{}
+	if err := cli.Get(ctx, types.NamespacedName{Name: "tenant-1"}, got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Status.Conditions) != 1 || got.Status.Conditions[0].Cluster != "local" {
+		t.Errorf("expect only condition of local cluster, got %v", got.Status.Conditions)
+	}
+	if got.Status.Phase != tenantv1.PhaseActive {
+		t.Errorf("expect phase Active after removed cluster pruned, got %v", got.Status.Phase)
+	}
+}
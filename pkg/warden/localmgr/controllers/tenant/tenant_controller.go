@@ -29,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	v1 "github.com/saashqdev/kubeworkz/pkg/apis/quota/v1"
@@ -36,6 +37,7 @@ import (
 	"github.com/saashqdev/kubeworkz/pkg/clog"
 	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
 	"github.com/saashqdev/kubeworkz/pkg/utils/env"
+	"github.com/saashqdev/kubeworkz/pkg/warden/utils"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -51,12 +53,18 @@ const (
 type TenantReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// pivotClient used to report status of tenant to pivot cluster
+	pivotClient client.Client
+	cluster     string
 }
 
-func newReconciler(mgr manager.Manager) (*TenantReconciler, error) {
+func newReconciler(mgr manager.Manager, pivotClient client.Client, cluster string) (*TenantReconciler, error) {
 	r := &TenantReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:      mgr.GetClient(),
+		Scheme:      mgr.GetScheme(),
+		pivotClient: pivotClient,
+		cluster:     cluster,
 	}
 	return r, nil
 }
@@ -64,6 +72,9 @@ func newReconciler(mgr manager.Manager) (*TenantReconciler, error) {
 //+kubebuilder:rbac:groups=tenant.kubeworkz.io,resources=tenants,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=tenant.kubeworkz.io,resources=tenants/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=tenant.kubeworkz.io,resources=tenants/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch;create;update
+//+kubebuilder:rbac:groups=apps,resources=deployments;statefulsets,verbs=get;list;update
+//+kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;update
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -115,16 +126,43 @@ func (r *TenantReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		}
 	}
 
+	var nsErr error
 	if env.CreateHNCNs() {
-		err = r.crateTenantNamespace(ctx, tenant.Name)
-		if err != nil {
-			clog.Error(err.Error())
-			return ctrl.Result{}, err
+		nsErr = r.crateTenantNamespace(ctx, tenant.Name)
+		if nsErr != nil {
+			clog.Error(nsErr.Error())
 		}
 	}
 
-	return ctrl.Result{}, nil
+	suspended := isSuspended(&tenant)
+	if suspended {
+		err = utils.SuspendNamespaces(ctx, r.Client, client.MatchingLabels{constants.HncTenantLabel: tenant.Name})
+	} else {
+		err = utils.ResumeNamespaces(ctx, r.Client, client.MatchingLabels{constants.HncTenantLabel: tenant.Name})
+	}
+	if err != nil {
+		log.Warn("suspend or resume namespaces of tenant failed: %v", err)
+		return ctrl.Result{}, err
+	}
+
+	err = r.updateStatus(ctx, tenant.Name, nsErr)
+	if err != nil {
+		log.Warn("update status of tenant failed: %v", err)
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nsErr
+}
+
+// isSuspended returns true if tenant suspended by user or waiting for deletion
+func isSuspended(tenant *tenantv1.Tenant) bool {
+	if tenant.Spec.Suspended {
+		return true
+	}
+	_, ok := tenant.Annotations[constants.DeletionRequestedAnnotation]
+	return ok
 }
+
 func (r *TenantReconciler) deleteTenant(tenantName string) (ctrl.Result, error) {
 	// get projects in tenant
 	// delete namespace of tenant
@@ -179,16 +217,27 @@ func (r *TenantReconciler) deleteKubeResourceQuotaOfTenant(tenantName string) er
 }
 
 // SetupWithManager sets up the controller with the Manager.
-func SetupWithManager(mgr ctrl.Manager) error {
-	r, err := newReconciler(mgr)
+func SetupWithManager(mgr ctrl.Manager, pivotClient client.Client, cluster string) error {
+	r, err := newReconciler(mgr, pivotClient, cluster)
 	if err != nil {
 		return err
 	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&tenantv1.Tenant{}).
+		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(namespaceToTenant)).
 		Complete(r)
 }
 
+// namespaceToTenant enqueues the tenant which namespace belongs to,
+// so that namespace count of tenant status keeps fresh
+func namespaceToTenant(_ context.Context, obj client.Object) []reconcile.Request {
+	tenant, ok := obj.GetLabels()[constants.HncTenantLabel]
+	if !ok || len(tenant) == 0 {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: tenant}}}
+}
+
 func (r *TenantReconciler) crateTenantNamespace(ctx context.Context, tenant string) error {
 	ns := &corev1.Namespace{
 		ObjectMeta: metav1.ObjectMeta{
@@ -0,0 +1,122 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/saashqdev/kubeworkz/pkg/apis"
+	tenantv1 "github.com/saashqdev/kubeworkz/pkg/apis/tenant/v1"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+)
+
+func TestReconcileSuspendAndResume(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = apis.AddToScheme(scheme)
+
+	replicas := int32(2)
+	tenant := &tenantv1.Tenant{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant-1"},
+		Spec:       tenantv1.TenantSpec{Suspended: true},
+	}
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "ns-1",
+		Labels: map[string]string{constants.HncTenantLabel: "tenant-1"},
+	}}
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "deploy-1", Namespace: "ns-1"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tenant, ns, deploy).Build()
+	r := &TenantReconciler{Client: cli, Scheme: scheme}
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "tenant-1"}}
+
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+
+	gotNs := &corev1.Namespace{}
+	_ = cli.Get(ctx, types.NamespacedName{Name: "ns-1"}, gotNs)
+	if gotNs.Labels[constants.SuspendedLabel] != constants.TrueStr {
+		t.Errorf("expect namespace of suspended tenant marked as suspended")
+	}
+	gotDeploy := &appsv1.Deployment{}
+	_ = cli.Get(ctx, client.ObjectKeyFromObject(deploy), gotDeploy)
+	if *gotDeploy.Spec.Replicas != 0 {
+		t.Errorf("expect deployment scaled to zero, got %v", *gotDeploy.Spec.Replicas)
+	}
+
+	gotTenant := &tenantv1.Tenant{}
+	_ = cli.Get(ctx, req.NamespacedName, gotTenant)
+	gotTenant.Spec.Suspended = false
+	if err := cli.Update(ctx, gotTenant); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = cli.Get(ctx, types.NamespacedName{Name: "ns-1"}, gotNs)
+	if _, ok := gotNs.Labels[constants.SuspendedLabel]; ok {
+		t.Errorf("expect suspended label removed after resume")
+	}
+	_ = cli.Get(ctx, client.ObjectKeyFromObject(deploy), gotDeploy)
+	if *gotDeploy.Spec.Replicas != 2 {
+		t.Errorf("expect replicas restored to 2, got %v", *gotDeploy.Spec.Replicas)
+	}
+}
+
+func TestReconcileSuspendOnDeletionRequested(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = apis.AddToScheme(scheme)
+
+	tenant := &tenantv1.Tenant{ObjectMeta: metav1.ObjectMeta{
+		Name:        "tenant-1",
+		Annotations: map[string]string{constants.DeletionRequestedAnnotation: metav1.Now().Format("2006-01-02T15:04:05Z07:00")},
+	}}
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "ns-1",
+		Labels: map[string]string{constants.HncTenantLabel: "tenant-1"},
+	}}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tenant, ns).Build()
+	r := &TenantReconciler{Client: cli, Scheme: scheme}
+	ctx := context.Background()
+
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "tenant-1"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	gotNs := &corev1.Namespace{}
+	_ = cli.Get(ctx, types.NamespacedName{Name: "ns-1"}, gotNs)
+	if gotNs.Labels[constants.SuspendedLabel] != constants.TrueStr {
+		t.Errorf("expect namespace suspended when tenant deletion requested")
+	}
+}
@@ -19,6 +19,7 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/rbac/v1"
@@ -127,6 +128,11 @@ func (r *UserReconciler) syncUser(ctx context.Context, user *userv1.User) (ctrl.
 		return ctrl.Result{}, err
 	}
 
+	// revisit when the next temporary binding expires
+	if d, ok := userv1.NextExpiration(user.Spec.ScopeBindings, time.Now()); ok {
+		return ctrl.Result{RequeueAfter: d}, nil
+	}
+
 	return ctrl.Result{}, nil
 }
 
@@ -142,8 +148,9 @@ func (r *UserReconciler) cleanOrphanBindings(ctx context.Context, user *userv1.U
 		return err
 	}
 
+	// bindings generated for expired scope bindings are orphans as well
 	bindingUnique := []string{}
-	for _, binding := range user.Spec.ScopeBindings {
+	for _, binding := range userv1.ActiveScopeBindings(user.Spec.ScopeBindings, time.Now()) {
 		bindingUnique = append(bindingUnique, transition.ScopeBindingUnique(binding))
 	}
 
@@ -207,7 +214,7 @@ func (r *UserReconciler) refreshBindings(ctx context.Context, user *userv1.User)
 	)
 
 	// ignore any errors happen in refreshing, return all errors if had.
-	for _, binding := range user.Spec.ScopeBindings {
+	for _, binding := range userv1.ActiveScopeBindings(user.Spec.ScopeBindings, time.Now()) {
 		if binding.ScopeType == userv1.PlatformScope {
 			errs = append(errs, r.refreshPlatformBinding(ctx, user.Name, binding))
 		}
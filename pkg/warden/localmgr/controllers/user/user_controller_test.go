@@ -0,0 +1,104 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	tenantv1 "github.com/saashqdev/kubeworkz/pkg/apis/tenant/v1"
+	userv1 "github.com/saashqdev/kubeworkz/pkg/apis/user/v1"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+	"github.com/saashqdev/kubeworkz/pkg/utils/hash"
+)
+
+func TestExpiredBindingsCleanedUp(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = userv1.AddToScheme(scheme)
+	_ = tenantv1.AddToScheme(scheme)
+
+	expired := metav1.NewTime(time.Now().Add(-time.Minute))
+	expiring := metav1.NewTime(time.Now().Add(time.Hour))
+	user := &userv1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "alice", Finalizers: []string{finalizerUser}},
+		Spec: userv1.UserSpec{ScopeBindings: []userv1.ScopeBinding{
+			{ScopeType: userv1.TenantScope, ScopeName: "t1", Role: constants.TenantAdmin, ExpiresAt: &expired, Reason: "incident"},
+			{ScopeType: userv1.ProjectScope, ScopeName: "p1", Role: constants.ProjectAdmin, ExpiresAt: &expiring},
+		}},
+	}
+	project := &tenantv1.Project{ObjectMeta: metav1.ObjectMeta{Name: "p1", Labels: map[string]string{constants.TenantLabel: "t1"}}}
+	tenantNs := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kubeworkz-tenant-t1", Labels: map[string]string{constants.HncTenantLabel: "t1"}}}
+	projectNs := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kubeworkz-project-p1", Labels: map[string]string{constants.HncProjectLabel: "p1"}}}
+	tenantBinding := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      hash.GenerateBindingName("alice", constants.TenantAdmin, tenantNs.Name),
+			Namespace: tenantNs.Name,
+			Labels: map[string]string{
+				constants.RbacLabel:         constants.TrueStr,
+				constants.LabelRelationship: "alice",
+				constants.TenantLabel:       "t1",
+			},
+		},
+		RoleRef:  rbacv1.RoleRef{APIGroup: constants.K8sGroupRBAC, Kind: constants.KindClusterRole, Name: constants.TenantAdmin},
+		Subjects: []rbacv1.Subject{{APIGroup: constants.K8sGroupRBAC, Kind: "User", Name: "alice"}},
+	}
+
+	cli := fake.NewClientBuilder().WithScheme(scheme).
+		WithStatusSubresource(&userv1.User{}).
+		WithObjects(user, project, tenantNs, projectNs, tenantBinding).Build()
+	r := &UserReconciler{Client: cli, Scheme: scheme}
+	ctx := context.Background()
+
+	result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "alice"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.RequeueAfter <= 0 || result.RequeueAfter > time.Hour {
+		t.Errorf("expect requeue when project binding expires, got %v", result.RequeueAfter)
+	}
+
+	err = cli.Get(ctx, types.NamespacedName{Name: tenantBinding.Name, Namespace: tenantNs.Name}, &rbacv1.RoleBinding{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("expect RoleBinding of expired binding deleted, got %v", err)
+	}
+	name := hash.GenerateBindingName("alice", constants.ProjectAdmin, projectNs.Name)
+	err = cli.Get(ctx, types.NamespacedName{Name: name, Namespace: projectNs.Name}, &rbacv1.RoleBinding{})
+	if err != nil {
+		t.Errorf("expect RoleBinding of active binding created: %v", err)
+	}
+
+	got := &userv1.User{}
+	_ = cli.Get(ctx, types.NamespacedName{Name: "alice"}, got)
+	if len(got.Status.BelongTenants) != 0 {
+		t.Errorf("expect expired tenant binding ignored in status, got %v", got.Status.BelongTenants)
+	}
+	if len(got.Status.BelongProjectInfos) != 1 {
+		t.Errorf("expect user belongs to project p1, got %v", got.Status.BelongProjectInfos)
+	}
+}
@@ -18,11 +18,13 @@ import (
 	"fmt"
 	"net/http"
 
+	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 
 	"github.com/saashqdev/kubeworkz/pkg/apis"
@@ -79,6 +81,11 @@ func (m *LocalManager) Initialize() error {
 		LeaderElectionNamespace: env.KubeNamespace(),
 		HealthProbeBindAddress:  healthProbeAddr,
 		MetricsBindAddress:      "0",
+		// secrets are read directly from apiserver instead of being cached,
+		// controllers watch metadata of secrets only
+		Client: client.Options{
+			Cache: &client.CacheOptions{DisableFor: []client.Object{&corev1.Secret{}}},
+		},
 	})
 
 	if err != nil {
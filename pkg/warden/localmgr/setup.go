@@ -23,13 +23,17 @@ import (
 	"github.com/saashqdev/kubeworkz/pkg/utils/ctrlopts"
 	"github.com/saashqdev/kubeworkz/pkg/warden/localmgr/controllers/crds"
 	"github.com/saashqdev/kubeworkz/pkg/warden/localmgr/controllers/hotplug"
+	"github.com/saashqdev/kubeworkz/pkg/warden/localmgr/controllers/namespace"
+	"github.com/saashqdev/kubeworkz/pkg/warden/localmgr/controllers/networkpolicy"
 	project "github.com/saashqdev/kubeworkz/pkg/warden/localmgr/controllers/project"
+	"github.com/saashqdev/kubeworkz/pkg/warden/localmgr/controllers/projecttemplate"
 	"github.com/saashqdev/kubeworkz/pkg/warden/localmgr/controllers/quota"
 	tenant "github.com/saashqdev/kubeworkz/pkg/warden/localmgr/controllers/tenant"
 	user "github.com/saashqdev/kubeworkz/pkg/warden/localmgr/controllers/user"
 	hotplug2 "github.com/saashqdev/kubeworkz/pkg/warden/localmgr/webhooks/hotplug"
 	project2 "github.com/saashqdev/kubeworkz/pkg/warden/localmgr/webhooks/project"
 	quota2 "github.com/saashqdev/kubeworkz/pkg/warden/localmgr/webhooks/quota"
+	suspend2 "github.com/saashqdev/kubeworkz/pkg/warden/localmgr/webhooks/suspend"
 	tenant2 "github.com/saashqdev/kubeworkz/pkg/warden/localmgr/webhooks/tenant"
 )
 
@@ -52,14 +56,35 @@ func setupControllersWithManager(m *LocalManager, controllers string) error {
 	//}
 
 	if ctrlopts.IsControllerEnabled("tenant", ctrls) {
-		err = tenant.SetupWithManager(m.Manager)
+		err = tenant.SetupWithManager(m.Manager, m.PivotClient.Direct(), m.Cluster)
 		if err != nil {
 			return err
 		}
 	}
 
 	if ctrlopts.IsControllerEnabled("project", ctrls) {
-		err = project.SetupWithManager(m.Manager)
+		err = project.SetupWithManager(m.Manager, m.PivotClient.Direct(), m.Cluster)
+		if err != nil {
+			return err
+		}
+	}
+
+	if ctrlopts.IsControllerEnabled("projecttemplate", ctrls) {
+		err = projecttemplate.SetupWithManager(m.Manager)
+		if err != nil {
+			return err
+		}
+	}
+
+	if ctrlopts.IsControllerEnabled("namespace", ctrls) {
+		err = namespace.SetupWithManager(m.Manager)
+		if err != nil {
+			return err
+		}
+	}
+
+	if ctrlopts.IsControllerEnabled("networkpolicy", ctrls) {
+		err = networkpolicy.SetupWithManager(m.Manager)
 		if err != nil {
 			return err
 		}
@@ -97,4 +122,5 @@ func setupWithWebhooks(m *LocalManager) {
 	hookServer.Register("/warden-validate-tenant-kubeworkz-io-v1-project", &webhook.Admission{Handler: project2.NewValidator(m.GetClient(), m.IsMemberCluster, decoder)})
 	hookServer.Register("/validate-core-kubernetes-v1-resource-quota", &webhook.Admission{Handler: quota2.NewValidator(m.PivotClient.Direct(), m.GetClient(), decoder)})
 	hookServer.Register("/warden-validate-hotplug-kubeworkz-io-v1-hotplug", admisson.ValidatingWebhookFor(m.GetScheme(), hotplug2.NewHotplugValidator(m.IsMemberCluster)))
+	hookServer.Register("/warden-validate-suspended-namespace", &webhook.Admission{Handler: suspend2.NewValidator(m.GetClient())})
 }
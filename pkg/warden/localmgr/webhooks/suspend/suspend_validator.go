@@ -0,0 +1,88 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suspend
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+	"github.com/saashqdev/kubeworkz/pkg/utils/env"
+)
+
+const scaleSubResource = "scale"
+
+// Validator denies writes into namespaces of suspended tenant
+type Validator struct {
+	Client client.Client
+}
+
+func NewValidator(client client.Client) *Validator {
+	return &Validator{
+		Client: client,
+	}
+}
+
+func (r *Validator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != v1.Create && req.Operation != v1.Update && req.Operation != v1.Connect {
+		return admission.Allowed("")
+	}
+
+	if isKubeworkzUser(req.UserInfo.Username) {
+		return admission.Allowed("")
+	}
+
+	// scaling requests of kubernetes controllers are blocked too, otherwise
+	// hpa controller scales suspended workloads back up
+	if isSystemUser(req.UserInfo.Username) && req.SubResource != scaleSubResource {
+		return admission.Allowed("")
+	}
+
+	ns := corev1.Namespace{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: req.Namespace}, &ns)
+	if err != nil {
+		return admission.Denied(fmt.Sprintf("can not verify whether namespace %v is suspended: %v", req.Namespace, err))
+	}
+
+	if ns.Labels[constants.SuspendedLabel] == constants.TrueStr {
+		return admission.Denied(fmt.Sprintf("namespace %v is suspended", req.Namespace))
+	}
+
+	return admission.Allowed("")
+}
+
+// isSystemUser returns true if request comes from kubernetes controllers
+// which should not be blocked
+func isSystemUser(username string) bool {
+	if username == "system:kube-controller-manager" || username == "system:kube-scheduler" {
+		return true
+	}
+	return strings.HasPrefix(username, "system:serviceaccount:kube-system:")
+}
+
+// isKubeworkzUser returns true if request comes from kubeworkz components
+// which suspend and resume workloads
+func isKubeworkzUser(username string) bool {
+	return strings.HasPrefix(username, fmt.Sprintf("system:serviceaccount:%v:", env.KubeNamespace()))
+}
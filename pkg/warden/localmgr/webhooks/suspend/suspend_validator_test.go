@@ -0,0 +1,74 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suspend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+	"github.com/saashqdev/kubeworkz/pkg/utils/env"
+)
+
+func newRequest(username string, operation v1.Operation, namespace string, subResource string) admission.Request {
+	return admission.Request{AdmissionRequest: v1.AdmissionRequest{
+		Operation:   operation,
+		Namespace:   namespace,
+		SubResource: subResource,
+		UserInfo:    authenticationv1.UserInfo{Username: username},
+	}}
+}
+
+func TestHandle(t *testing.T) {
+	assert := assert.New(t)
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "ns-1",
+		Labels: map[string]string{constants.SuspendedLabel: constants.TrueStr},
+	}}
+	cli := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(ns).Build()
+	v := NewValidator(cli)
+	ctx := context.Background()
+
+	resp := v.Handle(ctx, newRequest("alice", v1.Update, "ns-1", ""))
+	assert.False(resp.Allowed, "writes of user should be denied")
+
+	resp = v.Handle(ctx, newRequest("alice", v1.Connect, "ns-1", "exec"))
+	assert.False(resp.Allowed, "exec of user should be denied")
+
+	resp = v.Handle(ctx, newRequest("system:serviceaccount:kube-system:deployment-controller", v1.Update, "ns-1", "status"))
+	assert.True(resp.Allowed, "status updates of controllers should be allowed")
+
+	resp = v.Handle(ctx, newRequest("system:serviceaccount:kube-system:horizontal-pod-autoscaler", v1.Update, "ns-1", "scale"))
+	assert.False(resp.Allowed, "scaling of hpa controller should be denied")
+
+	resp = v.Handle(ctx, newRequest("system:serviceaccount:"+env.KubeNamespace()+":warden", v1.Update, "ns-1", "scale"))
+	assert.True(resp.Allowed, "kubeworkz components should be allowed")
+
+	resp = v.Handle(ctx, newRequest("alice", v1.Create, "not-exist", ""))
+	assert.False(resp.Allowed, "request should be denied when namespace is unknown")
+	assert.Contains(resp.Result.Message, "can not verify")
+}
@@ -83,24 +83,33 @@ func (h *Handler) SetHandlerTS(restConfig *rest.Config) error {
 		return err
 	}
 
-	responder := &responder{}
-	ts, err := rest.TransportFor(restConfig)
+	p, err := NewUpgradeAwareHandler(target, restConfig)
 	if err != nil {
 		return err
 	}
+	h.proxy = p
+
+	return nil
+}
+
+// NewUpgradeAwareHandler returns proxy to apiserver of restConfig which
+// supports upgraded streams such as exec and port-forward, request url is
+// used as location of backend
+func NewUpgradeAwareHandler(target *url.URL, restConfig *rest.Config) (*proxy.UpgradeAwareHandler, error) {
+	ts, err := rest.TransportFor(restConfig)
+	if err != nil {
+		return nil, err
+	}
 
 	upgradeTransport, err := makeUpgradeTransport(restConfig, 30*time.Second)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	p := proxy.NewUpgradeAwareHandler(target, ts, false, false, responder)
+	p := proxy.NewUpgradeAwareHandler(target, ts, false, false, &responder{})
 	p.UpgradeTransport = upgradeTransport
 	p.UseRequestLocation = true
-
-	h.proxy = p
-
-	return nil
+	return p, nil
 }
 
 func (h *Handler) SetHandlerClient(cli client.Client) {
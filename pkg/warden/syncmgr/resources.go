@@ -43,6 +43,7 @@ var syncResources = []client.Object{
 	&hotplug.Hotplug{},
 	&tenant.Tenant{},
 	&tenant.Project{},
+	&tenant.ProjectTemplate{},
 	&user.User{},
 	&extension.ExternalResource{},
 	&quota.KubeResourceQuota{},
@@ -59,6 +60,7 @@ var syncListResources = []client.ObjectList{
 	&hotplug.HotplugList{},
 	&tenant.TenantList{},
 	&tenant.ProjectList{},
+	&tenant.ProjectTemplateList{},
 	&user.UserList{},
 	&extension.ExternalResourceList{},
 	&quota.KubeResourceQuotaList{},
@@ -85,6 +87,8 @@ func newGenericObj(obj client.Object) (client.Object, error) {
 		return &tenant.Project{}, nil
 	case *tenant.Tenant:
 		return &tenant.Tenant{}, nil
+	case *tenant.ProjectTemplate:
+		return &tenant.ProjectTemplate{}, nil
 	case *quota.KubeResourceQuota:
 		return &quota.KubeResourceQuota{}, nil
 	case *corev1.Namespace:
@@ -0,0 +1,90 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1 "github.com/saashqdev/kubeworkz/pkg/apis/cluster/v1"
+	tenantv1 "github.com/saashqdev/kubeworkz/pkg/apis/tenant/v1"
+)
+
+const (
+	// ReasonReady means all resources were created in cluster
+	ReasonReady = "Ready"
+
+	// ReasonNamespaceFailed means namespace of tenant or project failed to create
+	ReasonNamespaceFailed = "NamespaceFailed"
+)
+
+// ClusterCondition collects namespaces and resource quotas matched given labels
+// in local cluster and builds the condition of local cluster, reconcileErr
+// represents error occurred when create resources in local cluster.
+func ClusterCondition(ctx context.Context, cli client.Client, cluster string, nsLabels client.MatchingLabels, reconcileErr error) (tenantv1.ClusterCondition, error) {
+	cond := tenantv1.ClusterCondition{
+		Cluster: cluster,
+		Status:  metav1.ConditionTrue,
+		Reason:  ReasonReady,
+	}
+
+	if reconcileErr != nil {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = ReasonNamespaceFailed
+		cond.Message = reconcileErr.Error()
+	}
+
+	nsList := corev1.NamespaceList{}
+	err := cli.List(ctx, &nsList, nsLabels)
+	if err != nil {
+		return cond, err
+	}
+	cond.NamespaceCount = len(nsList.Items)
+
+	for _, ns := range nsList.Items {
+		quotaList := corev1.ResourceQuotaList{}
+		err = cli.List(ctx, &quotaList, client.InNamespace(ns.Name))
+		if err != nil {
+			return cond, err
+		}
+		for _, q := range quotaList.Items {
+			cond.Hard = tenantv1.AddResourceList(cond.Hard, q.Status.Hard)
+			cond.Used = tenantv1.AddResourceList(cond.Used, q.Status.Used)
+		}
+	}
+
+	return cond, nil
+}
+
+// ClusterNames returns names of all clusters managed by pivot cluster
+func ClusterNames(ctx context.Context, pivotClient client.Client) ([]string, error) {
+	clusterList := clusterv1.ClusterList{}
+	err := pivotClient.List(ctx, &clusterList)
+	if err != nil {
+		return nil, err
+	}
+
+	clusters := make([]string, 0, len(clusterList.Items))
+	for _, c := range clusterList.Items {
+		clusters = append(clusters, c.Name)
+	}
+
+	return clusters, nil
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"strconv"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/saashqdev/kubeworkz/pkg/clog"
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+)
+
+// SuspendNamespaces scales workloads in namespaces matched given labels to zero,
+// suspends cronjobs and then marks namespaces as suspended so that new writes
+// will be denied by webhook.
+func SuspendNamespaces(ctx context.Context, cli client.Client, nsLabels client.MatchingLabels) error {
+	nsList := corev1.NamespaceList{}
+	err := cli.List(ctx, &nsList, nsLabels)
+	if err != nil {
+		return err
+	}
+
+	errs := []error{}
+	for i := range nsList.Items {
+		ns := &nsList.Items[i]
+		if ns.Labels[constants.SuspendedLabel] == constants.TrueStr {
+			continue
+		}
+		if err = suspendWorkloads(ctx, cli, ns.Name); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		ns.Labels[constants.SuspendedLabel] = constants.TrueStr
+		if err = cli.Update(ctx, ns); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		clog.Info("namespace %v suspended", ns.Name)
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// ResumeNamespaces unmarks suspended namespaces matched given labels and
+// restores replicas of workloads recorded when suspended.
+func ResumeNamespaces(ctx context.Context, cli client.Client, nsLabels client.MatchingLabels) error {
+	nsList := corev1.NamespaceList{}
+	err := cli.List(ctx, &nsList, nsLabels)
+	if err != nil {
+		return err
+	}
+
+	errs := []error{}
+	for i := range nsList.Items {
+		ns := &nsList.Items[i]
+		if _, ok := ns.Labels[constants.SuspendedLabel]; !ok {
+			continue
+		}
+		// unmark first, otherwise restore would be denied by webhook
+		delete(ns.Labels, constants.SuspendedLabel)
+		if err = cli.Update(ctx, ns); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err = resumeWorkloads(ctx, cli, ns.Name); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		clog.Info("namespace %v resumed", ns.Name)
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+func suspendWorkloads(ctx context.Context, cli client.Client, namespace string) error {
+	deployments := appsv1.DeploymentList{}
+	if err := cli.List(ctx, &deployments, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+	for i := range deployments.Items {
+		d := &deployments.Items[i]
+		if !recordReplicas(d, d.Spec.Replicas) {
+			continue
+		}
+		d.Spec.Replicas = new(int32)
+		if err := cli.Update(ctx, d); err != nil {
+			return err
+		}
+	}
+
+	statefulSets := appsv1.StatefulSetList{}
+	if err := cli.List(ctx, &statefulSets, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+	for i := range statefulSets.Items {
+		s := &statefulSets.Items[i]
+		if !recordReplicas(s, s.Spec.Replicas) {
+			continue
+		}
+		s.Spec.Replicas = new(int32)
+		if err := cli.Update(ctx, s); err != nil {
+			return err
+		}
+	}
+
+	cronJobs := batchv1.CronJobList{}
+	if err := cli.List(ctx, &cronJobs, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+	for i := range cronJobs.Items {
+		c := &cronJobs.Items[i]
+		if c.Spec.Suspend != nil && *c.Spec.Suspend {
+			continue
+		}
+		setAnnotation(c, constants.SuspendedReplicasAnnotation, constants.FalseStr)
+		suspend := true
+		c.Spec.Suspend = &suspend
+		if err := cli.Update(ctx, c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func resumeWorkloads(ctx context.Context, cli client.Client, namespace string) error {
+	deployments := appsv1.DeploymentList{}
+	if err := cli.List(ctx, &deployments, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+	for i := range deployments.Items {
+		d := &deployments.Items[i]
+		replicas, ok := restoreReplicas(d)
+		if !ok {
+			continue
+		}
+		// the annotation is removed even if recorded replicas is invalid
+		if replicas != nil {
+			d.Spec.Replicas = replicas
+		}
+		if err := cli.Update(ctx, d); err != nil {
+			return err
+		}
+	}
+
+	statefulSets := appsv1.StatefulSetList{}
+	if err := cli.List(ctx, &statefulSets, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+	for i := range statefulSets.Items {
+		s := &statefulSets.Items[i]
+		replicas, ok := restoreReplicas(s)
+		if !ok {
+			continue
+		}
+		// the annotation is removed even if recorded replicas is invalid
+		if replicas != nil {
+			s.Spec.Replicas = replicas
+		}
+		if err := cli.Update(ctx, s); err != nil {
+			return err
+		}
+	}
+
+	cronJobs := batchv1.CronJobList{}
+	if err := cli.List(ctx, &cronJobs, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+	for i := range cronJobs.Items {
+		c := &cronJobs.Items[i]
+		if _, ok := c.Annotations[constants.SuspendedReplicasAnnotation]; !ok {
+			continue
+		}
+		delete(c.Annotations, constants.SuspendedReplicasAnnotation)
+		suspend := false
+		c.Spec.Suspend = &suspend
+		if err := cli.Update(ctx, c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// recordReplicas records replicas into annotation of object, returns
+// false if object has no need to scale down
+func recordReplicas(obj client.Object, replicas *int32) bool {
+	if replicas != nil && *replicas == 0 {
+		return false
+	}
+	r := int32(1)
+	if replicas != nil {
+		r = *replicas
+	}
+	setAnnotation(obj, constants.SuspendedReplicasAnnotation, strconv.Itoa(int(r)))
+	return true
+}
+
+// restoreReplicas takes replicas recorded in annotation of object away,
+// returns false if nothing recorded. The replicas is nil if recorded value
+// is invalid, object should still be updated to drop the annotation.
+func restoreReplicas(obj client.Object) (*int32, bool) {
+	annotations := obj.GetAnnotations()
+	v, ok := annotations[constants.SuspendedReplicasAnnotation]
+	if !ok {
+		return nil, false
+	}
+	delete(annotations, constants.SuspendedReplicasAnnotation)
+	obj.SetAnnotations(annotations)
+
+	r, err := strconv.Atoi(v)
+	if err != nil {
+		clog.Warn("invalid replicas %v recorded in %v/%v: %v", v, obj.GetNamespace(), obj.GetName(), err)
+		return nil, true
+	}
+	replicas := int32(r)
+	return &replicas, true
+}
+
+func setAnnotation(obj client.Object, key, value string) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[key] = value
+	obj.SetAnnotations(annotations)
+}
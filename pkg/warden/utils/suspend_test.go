@@ -0,0 +1,109 @@
+/*
+Copyright 2024 Kubeworkz Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/saashqdev/kubeworkz/pkg/utils/constants"
+)
+
+func TestSuspendAndResumeNamespaces(t *testing.T) {
+	replicas := int32(3)
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "ns-1",
+		Labels: map[string]string{constants.HncTenantLabel: "tenant-1"},
+	}}
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "deploy-1", Namespace: "ns-1"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+	}
+	cli := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(ns, deploy).Build()
+	ctx := context.Background()
+	labels := client.MatchingLabels{constants.HncTenantLabel: "tenant-1"}
+
+	if err := SuspendNamespaces(ctx, cli, labels); err != nil {
+		t.Fatal(err)
+	}
+
+	gotNs := &corev1.Namespace{}
+	_ = cli.Get(ctx, types.NamespacedName{Name: "ns-1"}, gotNs)
+	if gotNs.Labels[constants.SuspendedLabel] != constants.TrueStr {
+		t.Errorf("expect namespace marked as suspended")
+	}
+	gotDeploy := &appsv1.Deployment{}
+	_ = cli.Get(ctx, types.NamespacedName{Name: "deploy-1", Namespace: "ns-1"}, gotDeploy)
+	if *gotDeploy.Spec.Replicas != 0 || gotDeploy.Annotations[constants.SuspendedReplicasAnnotation] != "3" {
+		t.Errorf("expect deployment scaled to zero with replicas recorded, got %v %v", *gotDeploy.Spec.Replicas, gotDeploy.Annotations)
+	}
+
+	if err := ResumeNamespaces(ctx, cli, labels); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = cli.Get(ctx, types.NamespacedName{Name: "ns-1"}, gotNs)
+	if _, ok := gotNs.Labels[constants.SuspendedLabel]; ok {
+		t.Errorf("expect suspended label removed")
+	}
+	_ = cli.Get(ctx, types.NamespacedName{Name: "deploy-1", Namespace: "ns-1"}, gotDeploy)
+	if *gotDeploy.Spec.Replicas != 3 {
+		t.Errorf("expect replicas restored to 3, got %v", *gotDeploy.Spec.Replicas)
+	}
+	if _, ok := gotDeploy.Annotations[constants.SuspendedReplicasAnnotation]; ok {
+		t.Errorf("expect replicas annotation removed")
+	}
+}
+
+func TestResumeInvalidReplicas(t *testing.T) {
+	replicas := int32(0)
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "ns-1",
+		Labels: map[string]string{constants.HncTenantLabel: "tenant-1", constants.SuspendedLabel: constants.TrueStr},
+	}}
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "deploy-1",
+			Namespace:   "ns-1",
+			Annotations: map[string]string{constants.SuspendedReplicasAnnotation: "invalid"},
+		},
+		Spec: appsv1.DeploymentSpec{Replicas: &replicas},
+	}
+	cli := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(ns, deploy).Build()
+	ctx := context.Background()
+
+	if err := ResumeNamespaces(ctx, cli, client.MatchingLabels{constants.HncTenantLabel: "tenant-1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	gotDeploy := &appsv1.Deployment{}
+	_ = cli.Get(ctx, types.NamespacedName{Name: "deploy-1", Namespace: "ns-1"}, gotDeploy)
+	if _, ok := gotDeploy.Annotations[constants.SuspendedReplicasAnnotation]; ok {
+		t.Errorf("expect invalid replicas annotation removed")
+	}
+	if *gotDeploy.Spec.Replicas != 0 {
+		t.Errorf("expect replicas unchanged, got %v", *gotDeploy.Spec.Replicas)
+	}
+}